@@ -0,0 +1,257 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/api"
+	"github.com/kristofferrisa/sky-cli/internal/formatter"
+	"github.com/kristofferrisa/sky-cli/internal/models"
+	"github.com/kristofferrisa/sky-cli/internal/notify"
+	"github.com/kristofferrisa/sky-cli/internal/rules"
+	"github.com/spf13/cobra"
+)
+
+// checkMatchedStatus is the exit status of sky check when a rule matched.
+// Errors exit with 1 as for every other command.
+const checkMatchedStatus = 2
+
+var (
+	// Check command flags
+	checkRuleNames []string
+	checkWhen      []string
+	checkFormat    string
+	checkList      bool
+	checkTo        []string
+)
+
+// checkCmd represents the check command
+var checkCmd = &cobra.Command{
+	Use:   "check [location]",
+	Short: "Check the forecast against your rules",
+	Long: `Check the forecast against the rules in the config file and print the rules
+that match. The exit status is 0 when no rule matched, 2 when a rule matched and
+1 on errors, so cron jobs and CI pipelines can act on it.
+
+A rule compares forecast variables with numbers and combines the comparisons
+with and, or and not:
+
+  temp, feels_like    temperature in °C
+  humidity            relative humidity in %
+  wind, gust          wind speed and gusts in m/s
+  precip              precipitation in mm for the period
+  precip_prob         probability of precipitation in %
+  hour                local hour of the day, 0-23
+  weekday             local day of the week, 1 (Monday) to 7 (Sunday)
+
+Ranges are inclusive, as in "hour in 7..9". A rule looks 24 hours ahead unless
+it ends with a window such as "within 12h" or "within 2d". It matches when any
+forecast period in the window satisfies it.
+
+Add rules with 'sky config set rules.frost.when "temp < 0 within 12h"', and an
+optional message with 'sky config set rules.frost.message "Cover the plants"'.
+
+With --to, matched rules are also sent to notifiers from the config file, such
+as a chat room or a phone (see sky notify).
+
+Examples:
+  sky check                                    # All rules, default location
+  sky check coast                              # Every location in a group
+  sky check --rule frost --rule gusts          # Only some rules
+  sky check --when "gust > 20 within 12h"      # A rule from the command line
+  sky check --list                             # Show the rules
+  sky check --to team --to phone               # Notify chat and phone
+  sky check -f json || notify-send "Weather"   # React to a match`,
+	RunE: runCheck,
+}
+
+func init() {
+	addLocationFlags(checkCmd)
+	checkCmd.Flags().StringArrayVarP(&checkRuleNames, "rule", "r", nil, "Rule to check (repeatable; default: all rules)")
+	checkCmd.Flags().StringArrayVar(&checkWhen, "when", nil, "Check a rule given on the command line (repeatable)")
+	checkCmd.Flags().StringVarP(&checkFormat, "format", "f", "", "Output format (full, json, summary, markdown)")
+	checkCmd.Flags().BoolVar(&checkList, "list", false, "List the rules in the config file")
+	checkCmd.Flags().StringArrayVar(&checkTo, "to", nil, "Notifier to send matched rules to (repeatable)")
+
+	rootCmd.AddCommand(checkCmd)
+}
+
+func runCheck(cmd *cobra.Command, args []string) error {
+	if checkList {
+		return listRules()
+	}
+
+	selected, err := selectRules(checkRuleNames, checkWhen)
+	if err != nil {
+		return err
+	}
+	notifiers, err := selectNotifiers(checkTo)
+	if err != nil {
+		return err
+	}
+
+	locs, err := resolveLocations(cmd, args)
+	if err != nil {
+		return err
+	}
+	if locs == nil {
+		loc, err := resolveLocation(cmd, args)
+		if err != nil {
+			return err
+		}
+		locs = []*models.Location{loc}
+	}
+
+	format := checkFormat
+	if format == "" && len(locs) == 1 {
+		format = locs[0].Format
+	}
+	fmtr, err := compareFormatter(format)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	check, err := checkRules(ctx, getWeatherClient(), locs, selected, time.Now())
+	if err != nil {
+		return err
+	}
+
+	opts := formatter.Options{
+		NoColor:    cfg.NoColor,
+		NoEmoji:    cfg.NoEmoji,
+		TimeFormat: "2006-01-02 15:04:05",
+	}
+	if err := fmtr.FormatCheck(os.Stdout, check, opts); err != nil {
+		return err
+	}
+
+	if len(check.Alerts) > 0 && len(notifiers) > 0 {
+		msg, err := notify.CheckMessage(check, opts)
+		if err != nil {
+			return err
+		}
+		sendNotification(ctx, notifiers, msg)
+	}
+
+	if len(check.Alerts) > 0 {
+		// The matched rules are the output; only the status is left
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+		return &exitError{code: checkMatchedStatus}
+	}
+	return nil
+}
+
+// namedRule is a rule with the name it is reported under
+type namedRule struct {
+	name string
+	rule *models.Rule
+}
+
+// selectRules returns the configured rules with the given names, or all
+// of them, followed by the rules given on the command line, which are
+// named after their condition
+func selectRules(names, when []string) ([]namedRule, error) {
+	if len(names) == 0 && len(when) == 0 {
+		names = cfg.RuleNames()
+		if len(names) == 0 {
+			return nil, fmt.Errorf("no rules configured (add one with 'sky config set rules.frost.when \"temp < 0 within 12h\"' or use --when)")
+		}
+	}
+
+	var selected []namedRule
+	for _, name := range names {
+		rule, ok := cfg.Rules[strings.ToLower(name)]
+		if !ok || rule == nil {
+			return nil, fmt.Errorf("unknown rule '%s' (available: %s)", name, strings.Join(cfg.RuleNames(), ", "))
+		}
+		if _, err := rules.Compile(rule.When); err != nil {
+			return nil, fmt.Errorf("rule '%s': %w", name, err)
+		}
+		selected = append(selected, namedRule{strings.ToLower(name), rule})
+	}
+	for _, w := range when {
+		if _, err := rules.Compile(w); err != nil {
+			return nil, fmt.Errorf("invalid rule '%s': %w", w, err)
+		}
+		selected = append(selected, namedRule{w, &models.Rule{When: w}})
+	}
+	return selected, nil
+}
+
+// checkRules fetches the forecast for every location, far enough ahead
+// for the rule that looks furthest, and checks the rules against it.
+// Locations whose forecast fails are reported on stderr and skipped.
+func checkRules(ctx context.Context, client api.WeatherClient, locs []*models.Location, selected []namedRule, now time.Time) (*models.Check, error) {
+	within := time.Duration(0)
+	for _, r := range selected {
+		x, err := rules.Compile(r.rule.When)
+		if err != nil {
+			return nil, fmt.Errorf("rule '%s': %w", r.name, err)
+		}
+		within = max(within, x.Within())
+	}
+
+	// The current period started up to an hour ago
+	hours := int(math.Ceil(within.Hours())) + 1
+	results := fetchAll(ctx, locs, maxParallelRequests, func(ctx context.Context, loc *models.Location) (*models.Forecast, error) {
+		return client.GetHourlyForecast(ctx, loc, hours)
+	})
+	if err := checkResults(results); err != nil {
+		return nil, err
+	}
+
+	check := &models.Check{Time: now, Rules: len(selected)}
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  Could not fetch the forecast for %s: %v\n", r.Location, r.Err)
+			continue
+		}
+		check.Locations = append(check.Locations, r.Location)
+
+		for _, nr := range selected {
+			alert, err := rules.Check(nr.name, nr.rule, r.Data, now)
+			if err != nil {
+				return nil, fmt.Errorf("rule '%s': %w", nr.name, err)
+			}
+			if alert != nil {
+				check.Alerts = append(check.Alerts, *alert)
+			}
+		}
+	}
+	return check, nil
+}
+
+// listRules prints the rules in the config file
+func listRules() error {
+	names := cfg.RuleNames()
+	if len(names) == 0 {
+		fmt.Println("No rules configured.")
+		fmt.Println("\nAdd one with: sky config set rules.frost.when \"temp < 0 within 12h\"")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "RULE\tCONDITION\tMESSAGE")
+	fmt.Fprintln(w, "────\t─────────\t───────")
+	for _, name := range names {
+		rule := cfg.Rules[name]
+		if rule == nil {
+			rule = &models.Rule{}
+		}
+		message := rule.Message
+		if message == "" {
+			message = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", name, rule.When, message)
+	}
+	return w.Flush()
+}
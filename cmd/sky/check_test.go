@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+func TestCheckRules(t *testing.T) {
+	now := time.Date(2026, 1, 5, 6, 30, 0, 0, time.UTC)
+	client := &fakeForecastClient{start: now.Truncate(time.Hour), fail: 61, hours: make(map[int]bool)}
+
+	// The fake forecast's temperature is the latitude
+	locs := []*models.Location{
+		{Name: "Warm", Latitude: 5, Longitude: 10},
+		{Name: "Cold", Latitude: -3, Longitude: 10},
+		{Name: "Broken", Latitude: 61, Longitude: 10},
+	}
+	selected := []namedRule{
+		{"frost", &models.Rule{When: "temp < 0 within 12h", Message: "Cover the plants"}},
+		{"mild", &models.Rule{When: "temp > 4 and hour in 7..8 within 2d"}},
+	}
+
+	check, err := checkRules(context.Background(), client, locs, selected, now)
+	if err != nil {
+		t.Fatalf("checkRules() failed: %v", err)
+	}
+
+	// Every location is fetched once, far enough ahead for the longest rule
+	if len(client.requests) != 3 || !client.hours[49] || len(client.hours) != 1 {
+		t.Errorf("checkRules() requested %d forecasts for %v hours; want 3 for 49", len(client.requests), client.hours)
+	}
+
+	if check.Rules != 2 || len(check.Locations) != 2 {
+		t.Errorf("checkRules() = %d rules at %d locations; want 2 at 2", check.Rules, len(check.Locations))
+	}
+
+	// Alerts are ordered by location, then by rule
+	want := []struct {
+		rule, location string
+		periods        int
+	}{
+		{"mild", "Warm", 4},
+		{"frost", "Cold", 13},
+	}
+	if len(check.Alerts) != len(want) {
+		t.Fatalf("checkRules() returned %d alerts; want %d: %+v", len(check.Alerts), len(want), check.Alerts)
+	}
+	for i, w := range want {
+		a := check.Alerts[i]
+		if a.Rule != w.rule || a.Location.Name != w.location || a.Periods != w.periods {
+			t.Errorf("alert %d = %s at %s in %d periods; want %s at %s in %d", i, a.Rule, a.Location.Name, a.Periods, w.rule, w.location, w.periods)
+		}
+	}
+	if check.Alerts[1].Message != "Cover the plants" {
+		t.Errorf("alert message = %q; want %q", check.Alerts[1].Message, "Cover the plants")
+	}
+
+	// A run where every location fails is an error
+	if _, err := checkRules(context.Background(), client, locs[2:], selected, now); err == nil {
+		t.Error("checkRules() with only failing locations expected error but got none")
+	}
+}
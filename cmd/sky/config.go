@@ -0,0 +1,393 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kristofferrisa/sky-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Migrate command flags
+	migrateDryRun bool
+)
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect and change configuration",
+	Long: `Inspect and change the configuration file.
+
+Keys use dotted notation, for example:
+  default_format
+  cache.ttl_minutes
+  locations.oslo.latitude`,
+}
+
+// showConfigCmd shows the effective configuration
+var showConfigCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the effective configuration",
+	Long:  `Display every configuration value together with where it came from.`,
+	Args:  cobra.NoArgs,
+	RunE:  runShowConfig,
+}
+
+// getConfigCmd prints a single value
+var getConfigCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print a configuration value",
+	Long:  `Print the effective value of a configuration key.`,
+	Args:  cobra.ExactArgs(1),
+	RunE:  runGetConfig,
+}
+
+// setConfigCmd sets a single value
+var setConfigCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Set a configuration value",
+	Long: `Set a configuration value and save the configuration file.
+
+Examples:
+  sky config set default_format json
+  sky config set cache.ttl_minutes 30
+  sky config set locations.oslo.timezone Europe/Oslo`,
+	Args: cobra.ExactArgs(2),
+	RunE: runSetConfig,
+}
+
+// unsetConfigCmd resets a single value
+var unsetConfigCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Reset a configuration value to its default",
+	Long:  `Reset a configuration value to its built-in default and save the configuration file.`,
+	Args:  cobra.ExactArgs(1),
+	RunE:  runUnsetConfig,
+}
+
+// pathConfigCmd prints the config file path
+var pathConfigCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Print the configuration file path",
+	Long:  `Print the path of the configuration file in use, or where it will be created.`,
+	Args:  cobra.NoArgs,
+	RunE:  runPathConfig,
+}
+
+// editConfigCmd opens the config file in an editor
+var editConfigCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Open the configuration file in $EDITOR",
+	Long:  `Open the configuration file in $EDITOR (or $VISUAL) and validate it afterwards.`,
+	Args:  cobra.NoArgs,
+	RunE:  runEditConfig,
+}
+
+// validateConfigCmd validates the configuration
+var validateConfigCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the configuration for problems",
+	Long:  `Check formats, the default location and every saved location.`,
+	Args:  cobra.NoArgs,
+	RunE:  runValidateConfig,
+}
+
+// migrateConfigCmd upgrades the config file
+var migrateConfigCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade the configuration file to the current version",
+	Long: `Upgrade the configuration file to the current version, one version at a
+time. The original file is kept as config.yaml.v<version>.bak.
+
+Outdated files are also upgraded automatically by every other command.
+Use --dry-run to see the changes without writing them.`,
+	Args: cobra.NoArgs,
+	RunE: runMigrateConfig,
+}
+
+// schemaConfigCmd prints the JSON Schema
+var schemaConfigCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of the configuration file",
+	Long: `Print the JSON Schema of the configuration file, for editor completion
+and validation. With the YAML language server, add this line to the top
+of config.yaml:
+
+  # yaml-language-server: $schema=https://raw.githubusercontent.com/kristofferrisa/sky-cli/main/internal/config/schema.json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		os.Stdout.Write(config.Schema())
+	},
+}
+
+// profilesConfigCmd groups the profile commands
+var profilesConfigCmd = &cobra.Command{
+	Use:   "profiles",
+	Short: "Manage config profiles",
+	Long: `Manage named config profiles.
+
+A profile overrides top-level settings and can inherit from another
+profile. Select one per run with --profile or SKY_PROFILE, or make it
+the default with 'sky config profiles use'.
+
+Example:
+  profiles:
+    work:
+      default_format: json
+      cache:
+        ttl_minutes: 2
+    work-oslo:
+      inherits: work
+      default_location: oslo`,
+}
+
+// listProfilesCmd lists profiles
+var listProfilesCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List config profiles",
+	Long:  `Display all profiles with the profile they inherit from and the keys they set.`,
+	Args:  cobra.NoArgs,
+	RunE:  runListProfiles,
+}
+
+// useProfileCmd sets the default profile
+var useProfileCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Set the default profile",
+	Long: `Set the profile applied when neither --profile nor SKY_PROFILE is given.
+
+Use 'sky config unset profile' to go back to no profile.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runUseProfile,
+}
+
+func init() {
+	// Add subcommands
+	configCmd.AddCommand(showConfigCmd)
+	configCmd.AddCommand(getConfigCmd)
+	configCmd.AddCommand(setConfigCmd)
+	configCmd.AddCommand(unsetConfigCmd)
+	configCmd.AddCommand(pathConfigCmd)
+	configCmd.AddCommand(editConfigCmd)
+	configCmd.AddCommand(validateConfigCmd)
+	configCmd.AddCommand(migrateConfigCmd)
+	configCmd.AddCommand(schemaConfigCmd)
+	configCmd.AddCommand(profilesConfigCmd)
+
+	migrateConfigCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "Show the migrated file without writing it")
+
+	profilesConfigCmd.AddCommand(listProfilesCmd)
+	profilesConfigCmd.AddCommand(useProfileCmd)
+
+	rootCmd.AddCommand(configCmd)
+}
+
+func runShowConfig(cmd *cobra.Command, args []string) error {
+	fmt.Printf("# %s\n", cfg.Path())
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+	fmt.Fprintln(w, "───\t─────\t──────")
+
+	for _, s := range cfg.Settings() {
+		value := s.Value
+		if value == "" {
+			value = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", s.Key, value, s.Source)
+	}
+
+	return w.Flush()
+}
+
+func runGetConfig(cmd *cobra.Command, args []string) error {
+	value, err := cfg.Get(args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(value)
+	return nil
+}
+
+func runSetConfig(cmd *cobra.Command, args []string) error {
+	key, value := args[0], args[1]
+
+	if err := cfg.Set(key, value); err != nil {
+		return err
+	}
+
+	printProblems(cfg.Validate())
+
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Printf("✓ %s = %s\n", key, value)
+	return nil
+}
+
+func runUnsetConfig(cmd *cobra.Command, args []string) error {
+	key := args[0]
+
+	if err := cfg.Unset(key); err != nil {
+		return err
+	}
+
+	printProblems(cfg.Validate())
+
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Printf("✓ %s unset\n", key)
+	return nil
+}
+
+func runPathConfig(cmd *cobra.Command, args []string) error {
+	fmt.Println(cfg.Path())
+	return nil
+}
+
+func runEditConfig(cmd *cobra.Command, args []string) error {
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = os.Getenv("VISUAL")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	// Write the current configuration first so there is something to edit
+	if !cfg.Exists() {
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf("failed to create config: %w", err)
+		}
+	}
+
+	// $EDITOR may include arguments, e.g. "code --wait"
+	parts := strings.Fields(editor)
+	edit := exec.Command(parts[0], append(parts[1:], cfg.Path())...)
+	edit.Stdin = os.Stdin
+	edit.Stdout = os.Stdout
+	edit.Stderr = os.Stderr
+	if err := edit.Run(); err != nil {
+		return fmt.Errorf("editor failed: %w", err)
+	}
+
+	// Reload and validate the edited file
+	edited, err := config.Load(config.LoadOptions{File: cfg.Path()})
+	if err != nil {
+		return fmt.Errorf("config is no longer readable: %w", err)
+	}
+
+	problems := edited.Validate()
+	printProblems(problems)
+	if len(problems) == 0 {
+		fmt.Println("✓ Configuration is valid")
+	}
+
+	return nil
+}
+
+func runValidateConfig(cmd *cobra.Command, args []string) error {
+	problems := cfg.Validate()
+	if len(problems) == 0 {
+		fmt.Printf("✓ Configuration is valid (%s)\n", cfg.Path())
+		return nil
+	}
+
+	printProblems(problems)
+	return fmt.Errorf("configuration has %d problem(s)", len(problems))
+}
+
+func runMigrateConfig(cmd *cobra.Command, args []string) error {
+	if !cfg.Exists() {
+		fmt.Println("No configuration file to migrate")
+		return nil
+	}
+
+	m, err := config.Migrate(cfg.Path(), migrateDryRun)
+	if err != nil {
+		return err
+	}
+
+	if !m.Changed() {
+		fmt.Printf("✓ %s is up to date (version %d)\n", m.Path, m.To)
+		return nil
+	}
+
+	fmt.Printf("Migrating %s from version %d to %d:\n", m.Path, m.From, m.To)
+	for _, step := range m.Steps {
+		fmt.Printf("  %s\n", step)
+	}
+
+	if migrateDryRun {
+		fmt.Printf("\n%s\n", m.Data)
+		fmt.Println("Dry run: no changes written")
+		return nil
+	}
+
+	fmt.Printf("✓ Configuration migrated (backup: %s)\n", m.Backup)
+	return nil
+}
+
+func runListProfiles(cmd *cobra.Command, args []string) error {
+	names := cfg.ProfileNames()
+	if len(names) == 0 {
+		fmt.Println("No profiles defined")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NAME\tINHERITS\tSETTINGS\tACTIVE")
+	fmt.Fprintln(w, "────\t────────\t────────\t──────")
+
+	for _, name := range names {
+		p := cfg.Profiles[name]
+
+		inherits := p.Inherits()
+		if inherits == "" {
+			inherits = "-"
+		}
+
+		active := ""
+		if name == cfg.ActiveProfile() {
+			active = "✓"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", name, inherits, strings.Join(p.Keys(), ", "), active)
+	}
+
+	return w.Flush()
+}
+
+func runUseProfile(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	if _, ok := cfg.Profiles[name]; !ok {
+		return fmt.Errorf("profile '%s' not found", name)
+	}
+
+	if err := cfg.Set("profile", name); err != nil {
+		return err
+	}
+
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Printf("✓ Default profile set to '%s'\n", name)
+	return nil
+}
+
+// printProblems prints validation problems to stderr
+func printProblems(problems []error) {
+	for _, p := range problems {
+		fmt.Fprintf(os.Stderr, "⚠️  %v\n", p)
+	}
+}
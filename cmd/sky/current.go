@@ -13,9 +13,6 @@ import (
 
 var (
 	// Current command flags
-	locationName  string
-	latitude      float64
-	longitude     float64
 	showForecast  bool
 	showSummary   bool
 	forecastHours int
@@ -30,13 +27,23 @@ var currentCmd = &cobra.Command{
 
 You can specify a location by:
   - Name (from saved locations): sky current stavern
-  - Coordinates: sky current --lat 59.0 --lon 10.0
+  - Place name (offline lookup): sky current Tromsø
+  - Coordinates: sky current 59.05,10.03 or sky current --lat 59.05 --lon 10.03
+  - Degrees and minutes: sky current "59°03'N 10°02'E"
+  - geo: URI, plus code or geohash: sky current geo:59.05,10.03
+  - Several saved locations, a group or --all: sky current stavern oslo
   - Default location (if no arguments): sky current
 
 Examples:
   sky current                          # Use default location
   sky current stavern                  # Use saved location 'stavern'
+  sky current Tromsø                   # Look up a place by name
+  sky current "Bergen, NL" --save      # Look up a place and save it
   sky current --lat 59.0 --lon 10.0   # Use coordinates
+  sky current 5.6037,-0.1870           # Coordinates as an argument
+  sky current stavern larvik           # Compare saved locations
+  sky current coast                    # Every location in a group
+  sky current --all                    # Every saved location
   sky current --forecast               # Include 12-hour forecast
   sky current --summary                # Include daily summary
   sky current --format json            # JSON output
@@ -46,9 +53,7 @@ Examples:
 }
 
 func init() {
-	currentCmd.Flags().StringVarP(&locationName, "location", "l", "", "Location name from config")
-	currentCmd.Flags().Float64Var(&latitude, "lat", 0, "Latitude")
-	currentCmd.Flags().Float64Var(&longitude, "lon", 0, "Longitude")
+	addLocationFlags(currentCmd)
 	currentCmd.Flags().BoolVar(&showForecast, "forecast", false, "Include hourly forecast")
 	currentCmd.Flags().BoolVar(&showSummary, "summary", false, "Include daily summary")
 	currentCmd.Flags().IntVar(&forecastHours, "hours", 12, "Number of hours for forecast")
@@ -61,8 +66,39 @@ func runCurrent(cmd *cobra.Command, args []string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	// Several locations are compared side by side
+	locs, err := resolveLocations(cmd, args)
+	if err != nil {
+		return err
+	}
+	if locs != nil {
+		if showForecast || showSummary {
+			return fmt.Errorf("--forecast and --summary cannot be used with several locations")
+		}
+
+		fmtr, err := compareFormatter(formatType)
+		if err != nil {
+			return err
+		}
+
+		client := getWeatherClient()
+		results := fetchAll(ctx, locs, maxParallelRequests, func(ctx context.Context, loc *models.Location) (*models.Weather, error) {
+			return client.GetCurrentWeather(ctx, loc)
+		})
+
+		opts := formatter.Options{
+			NoColor:    cfg.NoColor,
+			NoEmoji:    cfg.NoEmoji,
+			TimeFormat: "2006-01-02 15:04:05",
+		}
+		if err := fmtr.CompareCurrent(os.Stdout, results, opts); err != nil {
+			return err
+		}
+		return checkResults(results)
+	}
+
 	// Determine location
-	loc, err := getLocation(args)
+	loc, err := resolveLocation(cmd, args)
 	if err != nil {
 		return err
 	}
@@ -97,7 +133,7 @@ func runCurrent(cmd *cobra.Command, args []string) error {
 	// Determine format
 	format := formatType
 	if format == "" {
-		format = cfg.DefaultFormat
+		format = cfg.OutputFormat(loc.Format)
 	}
 	if format == "" {
 		format = "full"
@@ -140,34 +176,3 @@ func runCurrent(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
-
-// getLocation determines the location from command arguments and flags
-func getLocation(args []string) (*models.Location, error) {
-	// Priority 1: Coordinates from flags
-	if latitude != 0 || longitude != 0 {
-		if latitude == 0 || longitude == 0 {
-			return nil, fmt.Errorf("both --lat and --lon must be specified")
-		}
-		loc := &models.Location{
-			Latitude:  latitude,
-			Longitude: longitude,
-		}
-		if err := loc.Validate(); err != nil {
-			return nil, err
-		}
-		return loc, nil
-	}
-
-	// Priority 2: Location name from flag
-	if locationName != "" {
-		return cfg.GetLocation(locationName)
-	}
-
-	// Priority 3: Location name from argument
-	if len(args) > 0 {
-		return cfg.GetLocation(args[0])
-	}
-
-	// Priority 4: Default location from config
-	return cfg.GetDefaultLocation()
-}
@@ -13,11 +13,8 @@ import (
 
 var (
 	// Daily command flags
-	dailyLocation string
-	dailyLat      float64
-	dailyLon      float64
-	dailyDays     int
-	dailyFormat   string
+	dailyDays   int
+	dailyFormat string
 )
 
 // dailyCmd represents the daily command
@@ -28,13 +25,21 @@ var dailyCmd = &cobra.Command{
 
 You can specify a location by:
   - Name (from saved locations): sky daily stavern
-  - Coordinates: sky daily --lat 59.0 --lon 10.0
+  - Place name (offline lookup): sky daily Tromsø
+  - Coordinates: sky daily 59.05,10.03 or sky daily --lat 59.05 --lon 10.03
+  - Degrees and minutes: sky daily "59°03'N 10°02'E"
+  - geo: URI, plus code or geohash: sky daily geo:59.05,10.03
+  - Several saved locations, a group or --all: sky daily stavern oslo
   - Default location (if no arguments): sky daily
 
 Examples:
   sky daily                       # 7-day forecast (default location)
   sky daily stavern               # 7-day forecast for saved location
+  sky daily Tromsø --save         # Look up a place and save it
   sky daily --lat 59.0 --lon 10.0 # Forecast for coordinates
+  sky daily geo:51.4779,0         # geo: URI
+  sky daily stavern oslo          # Compare saved locations
+  sky daily --all                 # Every saved location
   sky daily --days 3              # 3-day forecast
   sky daily --days 10             # 10-day forecast
   sky daily --format json         # JSON output
@@ -43,9 +48,7 @@ Examples:
 }
 
 func init() {
-	dailyCmd.Flags().StringVarP(&dailyLocation, "location", "l", "", "Location name from config")
-	dailyCmd.Flags().Float64Var(&dailyLat, "lat", 0, "Latitude")
-	dailyCmd.Flags().Float64Var(&dailyLon, "lon", 0, "Longitude")
+	addLocationFlags(dailyCmd)
 	dailyCmd.Flags().IntVar(&dailyDays, "days", 7, "Number of days for forecast (default: 7)")
 	dailyCmd.Flags().StringVarP(&dailyFormat, "format", "f", "", "Output format (full, json, summary, markdown)")
 
@@ -56,8 +59,35 @@ func runDaily(cmd *cobra.Command, args []string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
 	defer cancel()
 
+	// Several locations are compared side by side
+	locs, err := resolveLocations(cmd, args)
+	if err != nil {
+		return err
+	}
+	if locs != nil {
+		fmtr, err := compareFormatter(dailyFormat)
+		if err != nil {
+			return err
+		}
+
+		client := getWeatherClient()
+		results := fetchAll(ctx, locs, maxParallelRequests, func(ctx context.Context, loc *models.Location) (*models.DailyForecast, error) {
+			return client.GetDailyForecast(ctx, loc, dailyDays)
+		})
+
+		opts := formatter.Options{
+			NoColor:    cfg.NoColor,
+			NoEmoji:    cfg.NoEmoji,
+			TimeFormat: "2006-01-02 15:04:05",
+		}
+		if err := fmtr.CompareDailyForecast(os.Stdout, results, opts); err != nil {
+			return err
+		}
+		return checkResults(results)
+	}
+
 	// Determine location
-	loc, err := getDailyLocation(args)
+	loc, err := resolveLocation(cmd, args)
 	if err != nil {
 		return err
 	}
@@ -74,7 +104,7 @@ func runDaily(cmd *cobra.Command, args []string) error {
 	// Determine format
 	format := dailyFormat
 	if format == "" {
-		format = cfg.DefaultFormat
+		format = cfg.OutputFormat(loc.Format)
 	}
 	if format == "" {
 		format = "full"
@@ -96,34 +126,3 @@ func runDaily(cmd *cobra.Command, args []string) error {
 	// Format and display
 	return fmtr.FormatDailyForecast(os.Stdout, dailyForecast, opts)
 }
-
-// getDailyLocation determines the location from command arguments and flags
-func getDailyLocation(args []string) (*models.Location, error) {
-	// Priority 1: Coordinates from flags
-	if dailyLat != 0 || dailyLon != 0 {
-		if dailyLat == 0 || dailyLon == 0 {
-			return nil, fmt.Errorf("both --lat and --lon must be specified")
-		}
-		loc := &models.Location{
-			Latitude:  dailyLat,
-			Longitude: dailyLon,
-		}
-		if err := loc.Validate(); err != nil {
-			return nil, err
-		}
-		return loc, nil
-	}
-
-	// Priority 2: Location name from flag
-	if dailyLocation != "" {
-		return cfg.GetLocation(dailyLocation)
-	}
-
-	// Priority 3: Location name from argument
-	if len(args) > 0 {
-		return cfg.GetLocation(args[0])
-	}
-
-	// Priority 4: Default location from config
-	return cfg.GetDefaultLocation()
-}
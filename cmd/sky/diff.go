@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/config"
+	"github.com/kristofferrisa/sky-cli/internal/diff"
+	"github.com/kristofferrisa/sky-cli/internal/formatter"
+	"github.com/kristofferrisa/sky-cli/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// diffForecastHours is how much of the forecast is kept to compare with
+// next time: all of it
+const diffForecastHours = 240
+
+var (
+	// Diff command flags
+	diffHours  int
+	diffFormat string
+	diffNoSave bool
+)
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff [location]",
+	Short: "Show what changed in the forecast since last time",
+	Long: `Compare the forecast with the one seen the last time sky diff ran for the
+location, and show the hours whose forecast changed, such as:
+
+  Tomorrow 14:00   rain now 3.2 mm (was 0.4), temp -2.0°C (now 3.0)
+
+The first run for a location only remembers the forecast. The forecasts seen are
+kept in $XDG_DATA_HOME/sky/forecasts, or ~/.sky/forecasts, and do not expire like
+the cache does.
+
+Changes smaller than these thresholds are left out; set them in the config file
+under diff, such as 'sky config set diff.precipitation 0.5':
+
+  temperature                 2 °C
+  precipitation               1 mm
+  precipitation_probability   20 percentage points
+  wind                        3 m/s
+  gust                        5 m/s
+
+Examples:
+  sky diff oslo                  # What changed since last time
+  sky diff --hours 48            # Only the next two days
+  sky diff stavern -f markdown   # A markdown table for a chat or a note
+  sky diff --no-save -f json     # Peek without remembering this forecast`,
+	RunE: runDiff,
+}
+
+func init() {
+	addLocationFlags(diffCmd)
+	diffCmd.Flags().IntVar(&diffHours, "hours", 0, "Only compare this many hours ahead (default: the whole forecast)")
+	diffCmd.Flags().StringVarP(&diffFormat, "format", "f", "", "Output format (full, json, summary, markdown)")
+	diffCmd.Flags().BoolVar(&diffNoSave, "no-save", false, "Do not remember this forecast for the next comparison")
+
+	rootCmd.AddCommand(diffCmd)
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	if diffHours < 0 {
+		return fmt.Errorf("--hours must not be negative")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	locs, err := resolveLocations(cmd, args)
+	if err != nil {
+		return err
+	}
+	if locs != nil {
+		return fmt.Errorf("diff works on one location at a time")
+	}
+	loc, err := resolveLocation(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	format := diffFormat
+	if format == "" {
+		format = cfg.OutputFormat(loc.Format)
+	}
+	if format == "" {
+		format = "full"
+	}
+	fmtr, err := formatter.GetFormatter(format)
+	if err != nil {
+		return err
+	}
+
+	forecast, err := getWeatherClient().GetHourlyForecast(ctx, loc, diffForecastHours)
+	if err != nil {
+		return fmt.Errorf("failed to fetch forecast: %w", err)
+	}
+
+	store := &diff.Store{Dir: filepath.Join(config.DefaultDataDir(), "forecasts")}
+	seen, err := store.Load(loc)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  %v; starting over\n", err)
+	}
+
+	now := time.Now()
+	changes := &models.ForecastDiff{Location: loc, SeenAt: now}
+	if seen != nil {
+		changes.PreviousSeenAt = seen.SeenAt
+		changes.Hours = diff.Compare(seen.Forecast, forecast, diffThresholds(), now)
+	}
+	if diffHours > 0 {
+		end := now.Add(time.Duration(diffHours) * time.Hour)
+		for i, hour := range changes.Hours {
+			if !hour.Time.Before(end) {
+				changes.Hours = changes.Hours[:i]
+				break
+			}
+		}
+	}
+
+	if !diffNoSave {
+		if err := store.Save(&diff.Seen{SeenAt: now, Forecast: forecast}); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+		}
+	}
+
+	opts := formatter.Options{
+		NoColor:    cfg.NoColor,
+		NoEmoji:    cfg.NoEmoji,
+		TimeFormat: "2006-01-02 15:04:05",
+	}
+	return fmtr.FormatDiff(os.Stdout, changes, opts)
+}
+
+// diffThresholds returns the configured thresholds of sky diff
+func diffThresholds() diff.Thresholds {
+	return diff.Thresholds{
+		Temperature:              cfg.Diff.Temperature,
+		Precipitation:            cfg.Diff.Precipitation,
+		PrecipitationProbability: cfg.Diff.PrecipitationProbability,
+		Wind:                     cfg.Diff.Wind,
+		Gust:                     cfg.Diff.Gust,
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kristofferrisa/sky-cli/internal/geocode"
+	"github.com/kristofferrisa/sky-cli/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// nearestLocationsCmd lists the saved locations nearest to a point
+var nearestLocationsCmd = &cobra.Command{
+	Use:   "nearest [location]",
+	Short: "List the saved locations nearest to a point",
+	Long: `List the saved locations nearest to a point, with their distance and
+direction. The point can be given with --lat and --lon, as coordinates, as a
+place name or as a saved location, which is then left out of the list.
+Without a point, the default location is used.
+
+Examples:
+  sky locations nearest --lat 59.05 --lon 10.03
+  sky locations nearest 59.05,10.03 -n 5
+  sky locations nearest Tønsberg
+  sky locations nearest stavern`,
+	RunE: runNearestLocations,
+}
+
+// distanceCmd shows the distance between two points
+var distanceCmd = &cobra.Command{
+	Use:   "distance <from> <to>",
+	Short: "Show the distance and bearing between two locations",
+	Long: `Show the great-circle distance and initial bearing between two saved
+locations, place names or coordinates. Quote names and coordinates that
+contain spaces.
+
+Examples:
+  sky locations distance stavern oslo
+  sky locations distance home "59°03'N 10°02'E"
+  sky locations distance Bergen Tromsø`,
+	Args: cobra.ExactArgs(2),
+	RunE: runDistance,
+}
+
+var (
+	// Nearest command flags
+	nearestCount int
+)
+
+func init() {
+	locationsCmd.AddCommand(nearestLocationsCmd)
+	locationsCmd.AddCommand(distanceCmd)
+
+	nearestLocationsCmd.Flags().IntVarP(&nearestCount, "count", "n", 3, "Number of locations to list")
+	nearestLocationsCmd.Flags().Float64("lat", 0, "Latitude")
+	nearestLocationsCmd.Flags().Float64("lon", 0, "Longitude")
+}
+
+func runNearestLocations(cmd *cobra.Command, args []string) error {
+	if nearestCount < 1 {
+		return fmt.Errorf("--count must be at least 1")
+	}
+	if len(cfg.Locations) == 0 {
+		return fmt.Errorf("no saved locations (add one with 'sky locations add')")
+	}
+
+	var key string
+	var origin *models.Location
+	var err error
+
+	flags := cmd.Flags()
+	switch {
+	case flags.Changed("lat") || flags.Changed("lon"):
+		if !flags.Changed("lat") || !flags.Changed("lon") {
+			return fmt.Errorf("both --lat and --lon must be specified")
+		}
+		lat, _ := flags.GetFloat64("lat")
+		lon, _ := flags.GetFloat64("lon")
+
+		origin = &models.Location{Latitude: lat, Longitude: lon}
+		if err := origin.Validate(); err != nil {
+			return err
+		}
+	case len(args) > 0:
+		key, origin, err = locatePoint(strings.Join(args, " "))
+		if err != nil {
+			return err
+		}
+	default:
+		if origin, err = cfg.GetDefaultLocation(); err != nil {
+			return err
+		}
+		key = cfg.DefaultLocation
+	}
+
+	// A saved location is not its own neighbor
+	candidates := make(map[string]*models.Location, len(cfg.Locations))
+	for k, loc := range cfg.Locations {
+		if k != key {
+			candidates[k] = loc
+		}
+	}
+	if len(candidates) == 0 {
+		return fmt.Errorf("no other saved locations")
+	}
+
+	fmt.Printf("Nearest to %s:\n\n", origin)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NAME\tLOCATION\tDISTANCE\tDIRECTION")
+	fmt.Fprintln(w, "────\t────────\t────────\t─────────")
+	for _, n := range models.Nearest(origin, candidates, nearestCount) {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s (%.0f°)\n",
+			n.Key, orDash(n.Location.Name), formatDistance(n.Distance), models.CompassPoint(n.Bearing), n.Bearing)
+	}
+	return w.Flush()
+}
+
+func runDistance(cmd *cobra.Command, args []string) error {
+	_, from, err := locatePoint(args[0])
+	if err != nil {
+		return err
+	}
+	_, to, err := locatePoint(args[1])
+	if err != nil {
+		return err
+	}
+
+	bearing := from.BearingTo(to)
+
+	fmt.Printf("%s → %s\n", from, to)
+	fmt.Printf("  Distance: %s\n", formatDistance(from.DistanceTo(to)))
+	fmt.Printf("  Bearing:  %.0f° (%s)\n", bearing, models.CompassPoint(bearing))
+
+	return nil
+}
+
+// locatePoint finds a saved location, coordinates or place. Unlike
+// lookupLocation, coordinates are never snapped to a saved location. The
+// key is set when name is a saved location.
+func locatePoint(name string) (string, *models.Location, error) {
+	if key, loc, ok := findSavedLocation(name); ok {
+		return key, loc, nil
+	}
+
+	loc, err := geocode.ParseCoordinates(name)
+	if err == nil {
+		return "", loc, nil
+	}
+	if !errors.Is(err, geocode.ErrNotCoordinates) {
+		return "", nil, fmt.Errorf("invalid coordinates '%s': %w", name, err)
+	}
+
+	loc, err = lookupLocation(name, false)
+	return "", loc, err
+}
+
+// formatDistance formats a distance in kilometers, using meters below one
+// kilometer
+func formatDistance(km float64) string {
+	switch {
+	case km < 1:
+		return fmt.Sprintf("%.0f m", km*1000)
+	case km < 10:
+		return fmt.Sprintf("%.1f km", km)
+	default:
+		return fmt.Sprintf("%.0f km", km)
+	}
+}
@@ -13,11 +13,9 @@ import (
 
 var (
 	// Forecast command flags
-	forecastLocation string
-	forecastLat      float64
-	forecastLon      float64
 	forecastHoursCmd int
 	forecastFormat   string
+	forecastChart    bool
 )
 
 // forecastCmd represents the forecast command
@@ -28,35 +26,76 @@ var forecastCmd = &cobra.Command{
 
 You can specify a location by:
   - Name (from saved locations): sky forecast stavern
-  - Coordinates: sky forecast --lat 59.0 --lon 10.0
+  - Place name (offline lookup): sky forecast Tromsø
+  - Coordinates: sky forecast 59.05,10.03 or sky forecast --lat 59.05 --lon 10.03
+  - Degrees and minutes: sky forecast "59°03'N 10°02'E"
+  - geo: URI, plus code or geohash: sky forecast geo:59.05,10.03
+  - Several saved locations, a group or --all: sky forecast stavern oslo
   - Default location (if no arguments): sky forecast
 
 Examples:
   sky forecast                         # Use default location (12 hours)
   sky forecast stavern                 # Use saved location
+  sky forecast Tromsø                  # Look up a place by name
   sky forecast --lat 59.0 --lon 10.0  # Use coordinates
+  sky forecast "59°03'N 10°02'E"      # Degrees and minutes
+  sky forecast coast                   # Every location in a group
   sky forecast --hours 24              # 24-hour forecast
   sky forecast --format json           # JSON output
-  sky forecast --format summary        # Brief summary`,
+  sky forecast --format summary        # Brief summary
+  sky forecast --chart --hours 48      # Charts of temperature, rain and wind`,
 	RunE: runForecast,
 }
 
 func init() {
-	forecastCmd.Flags().StringVarP(&forecastLocation, "location", "l", "", "Location name from config")
-	forecastCmd.Flags().Float64Var(&forecastLat, "lat", 0, "Latitude")
-	forecastCmd.Flags().Float64Var(&forecastLon, "lon", 0, "Longitude")
+	addLocationFlags(forecastCmd)
 	forecastCmd.Flags().IntVar(&forecastHoursCmd, "hours", 12, "Number of hours for forecast")
-	forecastCmd.Flags().StringVarP(&forecastFormat, "format", "f", "", "Output format (full, json, summary, markdown)")
+	forecastCmd.Flags().StringVarP(&forecastFormat, "format", "f", "", "Output format (full, json, summary, markdown, chart)")
+	forecastCmd.Flags().BoolVar(&forecastChart, "chart", false, "Draw charts of the forecast (same as --format chart)")
 
 	rootCmd.AddCommand(forecastCmd)
 }
 
 func runForecast(cmd *cobra.Command, args []string) error {
+	if forecastChart {
+		if forecastFormat != "" && forecastFormat != "chart" {
+			return fmt.Errorf("--chart cannot be used with --format %s", forecastFormat)
+		}
+		forecastFormat = "chart"
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	// Several locations are compared side by side
+	locs, err := resolveLocations(cmd, args)
+	if err != nil {
+		return err
+	}
+	if locs != nil {
+		fmtr, err := compareFormatter(forecastFormat)
+		if err != nil {
+			return err
+		}
+
+		client := getWeatherClient()
+		results := fetchAll(ctx, locs, maxParallelRequests, func(ctx context.Context, loc *models.Location) (*models.Forecast, error) {
+			return client.GetHourlyForecast(ctx, loc, forecastHoursCmd)
+		})
+
+		opts := formatter.Options{
+			NoColor:    cfg.NoColor,
+			NoEmoji:    cfg.NoEmoji,
+			TimeFormat: "2006-01-02 15:04:05",
+		}
+		if err := fmtr.CompareForecast(os.Stdout, results, opts); err != nil {
+			return err
+		}
+		return checkResults(results)
+	}
+
 	// Determine location
-	loc, err := getForecastLocation(args)
+	loc, err := resolveLocation(cmd, args)
 	if err != nil {
 		return err
 	}
@@ -73,7 +112,7 @@ func runForecast(cmd *cobra.Command, args []string) error {
 	// Determine format
 	format := forecastFormat
 	if format == "" {
-		format = cfg.DefaultFormat
+		format = cfg.OutputFormat(loc.Format)
 	}
 	if format == "" {
 		format = "full"
@@ -95,34 +134,3 @@ func runForecast(cmd *cobra.Command, args []string) error {
 	// Format and display
 	return fmtr.FormatForecast(os.Stdout, forecast, opts)
 }
-
-// getForecastLocation determines the location from command arguments and flags
-func getForecastLocation(args []string) (*models.Location, error) {
-	// Priority 1: Coordinates from flags
-	if forecastLat != 0 || forecastLon != 0 {
-		if forecastLat == 0 || forecastLon == 0 {
-			return nil, fmt.Errorf("both --lat and --lon must be specified")
-		}
-		loc := &models.Location{
-			Latitude:  forecastLat,
-			Longitude: forecastLon,
-		}
-		if err := loc.Validate(); err != nil {
-			return nil, err
-		}
-		return loc, nil
-	}
-
-	// Priority 2: Location name from flag
-	if forecastLocation != "" {
-		return cfg.GetLocation(forecastLocation)
-	}
-
-	// Priority 3: Location name from argument
-	if len(args) > 0 {
-		return cfg.GetLocation(args[0])
-	}
-
-	// Priority 4: Default location from config
-	return cfg.GetDefaultLocation()
-}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/geocode"
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+// lookupLocation finds a location by name. Saved locations are tried
+// first, then coordinates such as "59.05,10.03", then saved locations
+// ignoring case and diacritics, and then the offline gazetteer. With
+// save, a place found in the gazetteer is added to the config.
+func lookupLocation(name string, save bool) (*models.Location, error) {
+	return findLocation(name, save, isTerminal(os.Stdin))
+}
+
+// findLocation is lookupLocation for callers that may not be able to ask
+// the user: with prompt, the user chooses between places with the same
+// name, and without, an ambiguous name is an error
+func findLocation(name string, save, prompt bool) (*models.Location, error) {
+	if loc, err := cfg.GetLocation(name); err == nil {
+		return loc, nil
+	}
+
+	if loc, err := parseLocation(name); !errors.Is(err, geocode.ErrNotCoordinates) {
+		return loc, err
+	}
+
+	if _, loc, ok := findSavedLocation(name); ok {
+		return loc, nil
+	}
+
+	gazetteer, err := geocode.NewGazetteer()
+	if err != nil {
+		return nil, err
+	}
+
+	place, err := findPlace(gazetteer, name, prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	if save {
+		if err := savePlace(place); err != nil {
+			return nil, err
+		}
+	}
+
+	return place.Location(), nil
+}
+
+// findPlace geocodes a place name. When the name is ambiguous, it asks the
+// user to choose if prompt is set, and fails otherwise.
+func findPlace(g geocode.Geocoder, name string, prompt bool) (geocode.Place, error) {
+	places, err := g.Search(context.Background(), name)
+	if err != nil {
+		return geocode.Place{}, err
+	}
+	if len(places) == 0 {
+		return geocode.Place{}, fmt.Errorf("%w: '%s' is not a saved location or a known place", geocode.ErrNotFound, name)
+	}
+
+	candidates := geocode.Ambiguous(places)
+	if candidates == nil {
+		return places[0], nil
+	}
+
+	if !prompt {
+		hint := candidates[0].Region
+		if hint == "" {
+			hint = geocode.CountryName(candidates[0].Country)
+		}
+
+		var names []string
+		for _, p := range candidates {
+			names = append(names, p.String())
+		}
+		return geocode.Place{}, fmt.Errorf("%w: '%s' matches several places: %s\nAdd a region or country, for example '%s, %s'",
+			geocode.ErrAmbiguous, name, strings.Join(names, "; "), candidates[0].Name, hint)
+	}
+
+	return choosePlace(name, candidates)
+}
+
+// choosePlace asks the user to pick one of several places
+func choosePlace(name string, places []geocode.Place) (geocode.Place, error) {
+	fmt.Fprintf(os.Stderr, "Several places match '%s':\n", name)
+	for i, p := range places {
+		fmt.Fprintf(os.Stderr, "  %d) %s\n", i+1, p)
+	}
+	fmt.Fprintf(os.Stderr, "Choose a place [1-%d]: ", len(places))
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		return geocode.Place{}, fmt.Errorf("no place chosen")
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(answer))
+	if err != nil || n < 1 || n > len(places) {
+		return geocode.Place{}, fmt.Errorf("invalid choice: %s", strings.TrimSpace(answer))
+	}
+	return places[n-1], nil
+}
+
+// savePlace adds a geocoded place to the saved locations
+func savePlace(place geocode.Place) error {
+	key := geocode.Slug(place.Name)
+	if _, ok := cfg.Locations[key]; ok {
+		fmt.Fprintf(os.Stderr, "⚠️  A location named '%s' already exists, not saving %s\n", key, place)
+		return nil
+	}
+
+	if err := cfg.AddLocation(key, place.Location()); err != nil {
+		return err
+	}
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "✓ Saved %s as '%s'\n", place, key)
+	return nil
+}
+
+// reverseCacheTTL is how long place names for coordinates are cached
+const reverseCacheTTL = 30 * 24 * time.Hour
+
+// labelLocation names a coordinate-only location after the nearest place,
+// such as "4.2 km NE of Larvik". The configured Nominatim server is
+// tried first, then the offline gazetteer. Lookup failures leave the
+// location unnamed.
+func labelLocation(loc *models.Location) {
+	if loc.Name != "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var geocoders []geocode.ReverseGeocoder
+	if url := cfg.Geocoding.ReverseURL; url != "" {
+		var r geocode.ReverseGeocoder = geocode.NewNominatim(url)
+		if c := getCache(); c != nil {
+			r = geocode.NewCachedReverseGeocoder(r, c, reverseCacheTTL, url)
+		}
+		geocoders = append(geocoders, r)
+	}
+	if gazetteer, err := geocode.NewGazetteer(); err == nil {
+		geocoders = append(geocoders, gazetteer)
+	}
+
+	for _, r := range geocoders {
+		if nearby, err := r.Reverse(ctx, loc.Latitude, loc.Longitude); err == nil {
+			loc.Name = nearby.Label()
+			return
+		}
+	}
+}
+
+// isTerminal reports whether f is an interactive terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+	"github.com/kristofferrisa/sky-cli/internal/position"
+)
+
+// currentPosition returns the position of the device from the configured
+// sources, snapped to a saved location or named after the nearest place.
+// When no source knows the position in time, it warns and falls back to
+// the default location.
+func currentPosition() (*models.Location, error) {
+	timeout := time.Duration(cfg.Position.TimeoutSeconds) * time.Second
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	loc, err := position.First(ctx, positionSources()...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Could not determine the current position (%v), using the default location\n", err)
+		return cfg.GetDefaultLocation()
+	}
+
+	if saved := snapLocation(loc); saved != nil {
+		return saved, nil
+	}
+	labelLocation(loc)
+	return loc, nil
+}
+
+// positionSources returns the configured sources of the current position
+func positionSources() []position.Source {
+	var sources []position.Source
+	if addr := cfg.Position.GPSD; addr != "" {
+		sources = append(sources, position.NewGPSD(addr))
+	}
+	if path := cfg.Position.NMEA; path != "" {
+		sources = append(sources, position.NewNMEA(path))
+	}
+	if path := cfg.Position.File; path != "" {
+		maxAge := time.Duration(cfg.Position.MaxAgeMinutes) * time.Minute
+		sources = append(sources, position.NewFile(path, maxAge))
+	}
+	return sources
+}
@@ -0,0 +1,391 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/history"
+	"github.com/kristofferrisa/sky-cli/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// History command flags
+	historyFrom   string
+	historyTo     string
+	historyStats  []string
+	historyBy     string
+	historyFormat string
+
+	// History export command flags
+	historyExportFormat   string
+	historyExportOutput   string
+	historyExportAnalyses bool
+)
+
+// historyCmd represents the history command
+var historyCmd = &cobra.Command{
+	Use:   "history [location]",
+	Short: "Query the archive of past forecasts",
+	Long: `Query the archive of the forecasts sky has fetched.
+
+When the history is enabled, every forecast fetched from MET Norway is archived
+per location and the time MET Norway issued it, so it stays around after the
+cache expires. Enable it with:
+
+  sky config set history.enabled true
+
+Without --stat, the analyses in the period are listed: the first hour of every
+archived forecast, which is the closest to an observation MET Norway publishes.
+With --stat, statistics of those hours are computed, per --by period or over
+the whole period:
+
+  max-temp, min-temp, mean-temp     temperature (°C)
+  total-precip, max-precip          precipitation (mm); total-precip sums the
+                                    archived hours, so it misses hours sky did
+                                    not fetch
+  max-wind, mean-wind, max-gust     wind (m/s)
+  mean-humidity, mean-cloud-cover   percentages
+  mean-pressure                     air pressure (hPa)
+
+Dates are in local time, as 2006-01-02 or 2006-01-02T15:04; --to includes the
+whole day it names. Forecasts issued more than history.retention_days ago (365
+by default) are deleted.
+
+Examples:
+  sky history oslo --from 2026-01-01 --stat max-temp --by week
+  sky history --stat min-temp,max-temp,total-precip --by day
+  sky history stavern --from 2026-06-01 --to 2026-06-30
+  sky history export --format parquet -o history.parquet`,
+	RunE: runHistory,
+}
+
+// historyExportCmd exports the archive
+var historyExportCmd = &cobra.Command{
+	Use:   "export [location]",
+	Short: "Export the archive as CSV or Parquet",
+	Long: `Export the archived forecasts to standard output or a file, one row per
+forecast hour with the location, when the forecast was issued and fetched, its
+lead time in hours and the weather. Without a location, every location is
+exported.
+
+Examples:
+  sky history export > history.csv
+  sky history export oslo --from 2026-01-01 --format parquet -o oslo.parquet
+  sky history export --analyses                  # Only the first hours`,
+	RunE: runHistoryExport,
+}
+
+func init() {
+	addLocationFlags(historyCmd)
+	for _, cmd := range []*cobra.Command{historyCmd, historyExportCmd} {
+		cmd.Flags().StringVar(&historyFrom, "from", "", "Start of the period (2006-01-02 or 2006-01-02T15:04)")
+		cmd.Flags().StringVar(&historyTo, "to", "", "End of the period, included")
+	}
+	historyCmd.Flags().StringSliceVarP(&historyStats, "stat", "s", nil, fmt.Sprintf("Statistics to compute (%s)", strings.Join(history.StatNames(), ", ")))
+	historyCmd.Flags().StringVar(&historyBy, "by", "", fmt.Sprintf("Period to group statistics by (%s; default: the whole period)", strings.Join(history.Periods, ", ")))
+	historyCmd.Flags().StringVarP(&historyFormat, "format", "f", "full", "Output format (full, json)")
+
+	historyExportCmd.Flags().StringVarP(&historyExportFormat, "format", "f", "", fmt.Sprintf("File format (%s; default: from the extension of --output, or csv)", strings.Join(history.ExportFormats, ", ")))
+	historyExportCmd.Flags().StringVarP(&historyExportOutput, "output", "o", "", "Write to a file instead of standard output")
+	historyExportCmd.Flags().BoolVar(&historyExportAnalyses, "analyses", false, "Only export the first hour of every forecast")
+
+	historyCmd.AddCommand(historyExportCmd)
+	rootCmd.AddCommand(historyCmd)
+}
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	if historyFormat != "full" && historyFormat != "json" {
+		return fmt.Errorf("unknown format '%s' (available: full, json)", historyFormat)
+	}
+	var selected []history.Stat
+	for _, name := range historyStats {
+		stat, err := history.FindStat(strings.TrimSpace(name))
+		if err != nil {
+			return err
+		}
+		selected = append(selected, stat)
+	}
+	if historyBy != "" && len(selected) == 0 {
+		return fmt.Errorf("--by needs --stat")
+	}
+
+	filter, err := historyFilter()
+	if err != nil {
+		return err
+	}
+	locs, err := resolveLocations(cmd, args)
+	if err != nil {
+		return err
+	}
+	if locs != nil {
+		return fmt.Errorf("history works on one location at a time")
+	}
+	if filter.Location, err = resolveLocation(cmd, args); err != nil {
+		return err
+	}
+	filter.Analyses = true
+
+	snapshots, err := readHistory(filter)
+	if err != nil {
+		return err
+	}
+	hours := history.Analyses(snapshots)
+	if len(hours) == 0 {
+		return fmt.Errorf("no forecasts for %s in the history%s", filter.Location, periodText(filter))
+	}
+
+	if len(selected) == 0 {
+		if historyFormat == "json" {
+			return writeHistoryJSON(os.Stdout, filter.Location, hours)
+		}
+		return printAnalyses(os.Stdout, filter.Location, hours)
+	}
+
+	rows, err := history.Summarize(hours, selected, historyBy, time.Local)
+	if err != nil {
+		return err
+	}
+	if historyFormat == "json" {
+		return writeStatsJSON(os.Stdout, filter.Location, selected, rows)
+	}
+	return printStats(os.Stdout, filter.Location, selected, rows)
+}
+
+func runHistoryExport(cmd *cobra.Command, args []string) error {
+	format := historyExportFormat
+	if format == "" {
+		format = "csv"
+		if strings.HasSuffix(strings.ToLower(historyExportOutput), ".parquet") {
+			format = "parquet"
+		}
+	}
+
+	filter, err := historyFilter()
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		if filter.Location, err = lookupLocation(strings.Join(args, " "), false); err != nil {
+			return err
+		}
+	}
+	filter.Analyses = historyExportAnalyses
+
+	snapshots, err := readHistory(filter)
+	if err != nil {
+		return err
+	}
+
+	if historyExportOutput == "" {
+		return history.Export(os.Stdout, snapshots, format)
+	}
+	f, err := os.Create(historyExportOutput)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", historyExportOutput, err)
+	}
+	if err := history.Export(f, snapshots, format); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	rows := 0
+	for _, s := range snapshots {
+		rows += len(s.Hours)
+	}
+	fmt.Printf("✓ Exported %d hours of %d forecasts to %s\n", rows, len(snapshots), historyExportOutput)
+	return nil
+}
+
+// readHistory reads the snapshots that match a filter from the archive
+func readHistory(filter history.Filter) ([]*models.Snapshot, error) {
+	path := historyPath()
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		if !cfg.History.Enabled {
+			return nil, fmt.Errorf("there is no history yet; record it with 'sky config set history.enabled true'")
+		}
+		return nil, fmt.Errorf("there is no history yet at %s; it is recorded when forecasts are fetched", path)
+	}
+
+	archive, err := history.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+	return archive.Snapshots(filter)
+}
+
+// historyFilter returns the period selected by --from and --to
+func historyFilter() (history.Filter, error) {
+	var filter history.Filter
+	var err error
+	if historyFrom != "" {
+		if filter.From, _, err = parseHistoryTime(historyFrom); err != nil {
+			return filter, fmt.Errorf("invalid --from: %w", err)
+		}
+	}
+	if historyTo != "" {
+		var dateOnly bool
+		if filter.To, dateOnly, err = parseHistoryTime(historyTo); err != nil {
+			return filter, fmt.Errorf("invalid --to: %w", err)
+		}
+		if dateOnly {
+			filter.To = filter.To.AddDate(0, 0, 1)
+		} else {
+			filter.To = filter.To.Add(time.Minute)
+		}
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return filter, fmt.Errorf("--from must be before --to")
+	}
+	return filter, nil
+}
+
+// parseHistoryTime parses a date or a time in local time, and reports
+// whether it was only a date
+func parseHistoryTime(s string) (time.Time, bool, error) {
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, true, nil
+	}
+	for _, layout := range []string{"2006-01-02T15:04", "2006-01-02 15:04"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, false, nil
+		}
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, false, nil
+	}
+	return time.Time{}, false, fmt.Errorf("'%s' is not a date like 2006-01-02 or a time like 2006-01-02T15:04", s)
+}
+
+// periodText describes the period of a filter, such as " from 2026-01-01"
+func periodText(filter history.Filter) string {
+	text := ""
+	if historyFrom != "" {
+		text += " from " + historyFrom
+	}
+	if historyTo != "" {
+		text += " to " + historyTo
+	}
+	return text
+}
+
+// printAnalyses prints the analyses as a table
+func printAnalyses(out io.Writer, loc *models.Location, hours []models.SnapshotHour) error {
+	fmt.Fprintf(out, "%s: %d hours\n\n", loc, len(hours))
+
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "TIME\tTEMP\tWIND\tGUST\tPRECIP\tHUMIDITY\tPRESSURE\tCLOUDS")
+	fmt.Fprintln(w, "────\t────\t────\t────\t──────\t────────\t────────\t──────")
+	for _, h := range hours {
+		fmt.Fprintf(w, "%s\t%.1f°C\t%.1f m/s\t%.1f m/s\t%.1f mm\t%.0f%%\t%.0f hPa\t%.0f%%\n",
+			h.Time.Local().Format("2006-01-02 15:04"), h.Temperature, h.WindSpeed, h.WindGust,
+			h.Precipitation, h.Humidity, h.Pressure, h.CloudCover)
+	}
+	return w.Flush()
+}
+
+// printStats prints statistics as a table with a row per period
+func printStats(out io.Writer, loc *models.Location, selected []history.Stat, rows []history.Row) error {
+	fmt.Fprintf(out, "%s\n\n", loc)
+
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	header := []string{"PERIOD", "HOURS"}
+	underline := []string{"──────", "─────"}
+	for _, s := range selected {
+		title := strings.ToUpper(s.Name)
+		header = append(header, title)
+		underline = append(underline, strings.Repeat("─", len(title)))
+	}
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	fmt.Fprintln(w, strings.Join(underline, "\t"))
+
+	for _, row := range rows {
+		cells := []string{row.Period, fmt.Sprint(row.Samples)}
+		for i, s := range selected {
+			cells = append(cells, fmt.Sprintf("%.1f %s", row.Values[i], s.Unit))
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+	return w.Flush()
+}
+
+// jsonHistoryHour is an analysis in the JSON output
+type jsonHistoryHour struct {
+	Time                     string  `json:"time"`
+	Temperature              float64 `json:"temperature"`
+	Humidity                 float64 `json:"humidity"`
+	Pressure                 float64 `json:"pressure"`
+	CloudCover               float64 `json:"cloud_cover"`
+	WindSpeed                float64 `json:"wind_speed"`
+	WindGust                 float64 `json:"wind_gust"`
+	WindDegrees              float64 `json:"wind_degrees"`
+	Precipitation            float64 `json:"precipitation"`
+	PrecipitationProbability float64 `json:"precipitation_probability"`
+	Symbol                   string  `json:"symbol"`
+}
+
+func writeHistoryJSON(out io.Writer, loc *models.Location, hours []models.SnapshotHour) error {
+	result := struct {
+		Location *models.Location  `json:"location"`
+		Hours    []jsonHistoryHour `json:"hours"`
+	}{Location: loc, Hours: make([]jsonHistoryHour, len(hours))}
+
+	for i, h := range hours {
+		result.Hours[i] = jsonHistoryHour{
+			Time:                     h.Time.Format(time.RFC3339),
+			Temperature:              h.Temperature,
+			Humidity:                 h.Humidity,
+			Pressure:                 h.Pressure,
+			CloudCover:               h.CloudCover,
+			WindSpeed:                h.WindSpeed,
+			WindGust:                 h.WindGust,
+			WindDegrees:              h.WindDir,
+			Precipitation:            h.Precipitation,
+			PrecipitationProbability: h.PrecipitationProbability,
+			Symbol:                   h.Symbol,
+		}
+	}
+	return writeIndentedJSON(out, result)
+}
+
+func writeStatsJSON(out io.Writer, loc *models.Location, selected []history.Stat, rows []history.Row) error {
+	type jsonRow struct {
+		Period string             `json:"period"`
+		Hours  int                `json:"hours"`
+		Stats  map[string]float64 `json:"stats"`
+	}
+	result := struct {
+		Location *models.Location  `json:"location"`
+		By       string            `json:"by,omitempty"`
+		Units    map[string]string `json:"units"`
+		Periods  []jsonRow         `json:"periods"`
+	}{Location: loc, By: historyBy, Units: make(map[string]string), Periods: make([]jsonRow, len(rows))}
+
+	for _, s := range selected {
+		result.Units[s.Name] = s.Unit
+	}
+	for i, row := range rows {
+		result.Periods[i] = jsonRow{Period: row.Period, Hours: row.Samples, Stats: make(map[string]float64)}
+		for j, s := range selected {
+			result.Periods[i].Stats[s.Name] = math.Round(row.Values[j]*100) / 100
+		}
+	}
+	return writeIndentedJSON(out, result)
+}
+
+func writeIndentedJSON(out io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kristofferrisa/sky-cli/internal/geocode"
+	"github.com/kristofferrisa/sky-cli/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// addLocationFlags adds the flags read by resolveLocation and
+// resolveLocations to cmd
+func addLocationFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("location", "l", "", "Saved location, group, place name or coordinates")
+	cmd.Flags().Bool("all", false, "Use all saved locations")
+	cmd.Flags().Bool("here", false, "Use the current position from gpsd, an NMEA device or a location file")
+	cmd.Flags().Bool("save", false, "Save a place found by name to the config")
+	cmd.Flags().Float64("lat", 0, "Latitude")
+	cmd.Flags().Float64("lon", 0, "Longitude")
+}
+
+// resolveLocation determines the location from the arguments and the
+// flags added by addLocationFlags, in order of priority: --here, --lat and
+// --lon, --location, the arguments and finally the default location
+func resolveLocation(cmd *cobra.Command, args []string) (*models.Location, error) {
+	flags := cmd.Flags()
+
+	// Priority 0: The current position of the device
+	if here, _ := flags.GetBool("here"); here {
+		if len(args) > 0 || flags.Changed("location") || flags.Changed("lat") || flags.Changed("lon") {
+			return nil, fmt.Errorf("--here cannot be combined with a location")
+		}
+		return currentPosition()
+	}
+
+	// Priority 1: Coordinates from flags. Zero is a valid latitude and
+	// longitude, so the flags count as given when they are set at all.
+	if flags.Changed("lat") || flags.Changed("lon") {
+		if !flags.Changed("lat") || !flags.Changed("lon") {
+			return nil, fmt.Errorf("both --lat and --lon must be specified")
+		}
+		lat, _ := flags.GetFloat64("lat")
+		lon, _ := flags.GetFloat64("lon")
+
+		loc := &models.Location{Latitude: lat, Longitude: lon}
+		if err := loc.Validate(); err != nil {
+			return nil, err
+		}
+		if saved := snapLocation(loc); saved != nil {
+			return saved, nil
+		}
+		labelLocation(loc)
+		return loc, nil
+	}
+
+	save, _ := flags.GetBool("save")
+
+	// Priority 2: Location from flag
+	if name, _ := flags.GetString("location"); name != "" {
+		return lookupLocation(name, save)
+	}
+
+	// Priority 3: Location from arguments
+	if len(args) > 0 {
+		return lookupLocation(strings.Join(args, " "), save)
+	}
+
+	// Priority 4: Default location from config
+	return cfg.GetDefaultLocation()
+}
+
+// parseLocation returns the location written as coordinates in s: a saved
+// location within the snap radius, or else the coordinates named after the
+// nearest place. It returns geocode.ErrNotCoordinates when s is
+// not coordinates.
+func parseLocation(s string) (*models.Location, error) {
+	loc, err := geocode.ParseCoordinates(s)
+	if err != nil {
+		if !errors.Is(err, geocode.ErrNotCoordinates) {
+			return nil, fmt.Errorf("%w '%s': %w", geocode.ErrInvalidCoordinates, s, err)
+		}
+		return nil, err
+	}
+	if saved := snapLocation(loc); saved != nil {
+		return saved, nil
+	}
+	labelLocation(loc)
+	return loc, nil
+}
+
+// snapLocation returns the saved location within the configured snap
+// radius of loc, or nil if there is none
+func snapLocation(loc *models.Location) *models.Location {
+	radius := cfg.Geocoding.SnapRadiusMeters
+	if radius <= 0 || len(cfg.Locations) == 0 {
+		return nil
+	}
+
+	nearest := models.Nearest(loc, cfg.Locations, 1)[0]
+	if nearest.Distance*1000 > float64(radius) {
+		return nil
+	}
+	return savedLocations([]string{nearest.Key})[0]
+}
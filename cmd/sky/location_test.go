@@ -0,0 +1,193 @@
+package main
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kristofferrisa/sky-cli/internal/config"
+	"github.com/kristofferrisa/sky-cli/internal/models"
+	"github.com/spf13/cobra"
+)
+
+func TestResolveLocation(t *testing.T) {
+	saved := &config.Config{
+		DefaultLocation: "stavern",
+		Locations: map[string]*models.Location{
+			"stavern": {Name: "Stavern", Latitude: 59.0, Longitude: 10.03},
+			"home":    {Name: "Home", Latitude: 59.91, Longitude: 10.75},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		flags    map[string]string
+		lat, lon float64
+		label    string
+		err      bool
+	}{
+		{name: "Default location", lat: 59.0, lon: 10.03, label: "Stavern"},
+		{name: "Saved location", args: []string{"home"}, lat: 59.91, lon: 10.75, label: "Home"},
+		{name: "Saved location from flag", flags: map[string]string{"location": "home"}, lat: 59.91, lon: 10.75, label: "Home"},
+		{name: "Saved location ignoring case", args: []string{"HOME"}, lat: 59.91, lon: 10.75, label: "Home"},
+		{name: "Place name", args: []string{"Tromsø"}, lat: 69.6496, lon: 18.9560, label: "Tromsø, Norway"},
+		{name: "Place name in several arguments", args: []string{"Mo", "i", "Rana"}, lat: 66.3128, lon: 14.1428},
+		{name: "Flag takes priority over arguments", args: []string{"home"}, flags: map[string]string{"location": "stavern"}, lat: 59.0, lon: 10.03},
+
+		{name: "Coordinate flags", flags: map[string]string{"lat": "59.91", "lon": "10.75"}, lat: 59.91, lon: 10.75},
+		{name: "Zero longitude", flags: map[string]string{"lat": "51.4779", "lon": "0"}, lat: 51.4779, lon: 0},
+		{name: "Zero latitude", flags: map[string]string{"lat": "0", "lon": "-0.187"}, lat: 0, lon: -0.187},
+		{name: "Null Island", flags: map[string]string{"lat": "0", "lon": "0"}, lat: 0, lon: 0},
+		{name: "Coordinate flags take priority", args: []string{"home"}, flags: map[string]string{"lat": "5.6", "lon": "-0.19"}, lat: 5.6, lon: -0.19},
+		{name: "Only latitude", flags: map[string]string{"lat": "0"}, err: true},
+		{name: "Only longitude", flags: map[string]string{"lon": "10.75"}, err: true},
+		{name: "Latitude out of range", flags: map[string]string{"lat": "95", "lon": "10"}, err: true},
+
+		{name: "Coordinate argument", args: []string{"5.6037,-0.1870"}, lat: 5.6037, lon: -0.1870},
+		{name: "Coordinates in two arguments", args: []string{"59.91,", "10.75"}, lat: 59.91, lon: 10.75},
+		{name: "Coordinates from flag", flags: map[string]string{"location": "51.4779,0"}, lat: 51.4779, lon: 0},
+		{name: "Geo URI", args: []string{"geo:0,0?q=59.0469,10.0344(Stavern)"}, lat: 59.0469, lon: 10.0344, label: "Stavern"},
+		{name: "DMS", args: []string{"59°54'N 10°45'E"}, lat: 59.9, lon: 10.75},
+		{name: "Plus code", args: []string{"9FFGWQ7V+XV"}, lat: 59.9149, lon: 10.7947},
+		{name: "Geohash", args: []string{"geohash:u4xsu"}, lat: 59.9, lon: 10.7},
+		{name: "Invalid coordinates", args: []string{"59,200"}, err: true},
+		{name: "Unknown place", args: []string{"Atlantis"}, err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg = saved
+
+			cmd := &cobra.Command{}
+			addLocationFlags(cmd)
+			for name, value := range tt.flags {
+				if err := cmd.Flags().Set(name, value); err != nil {
+					t.Fatalf("Set(%s) failed: %v", name, err)
+				}
+			}
+
+			loc, err := resolveLocation(cmd, tt.args)
+			if tt.err {
+				if err == nil {
+					t.Errorf("resolveLocation() = %v; want error", loc)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveLocation() failed: %v", err)
+			}
+
+			if math.Abs(loc.Latitude-tt.lat) > 0.05 || math.Abs(loc.Longitude-tt.lon) > 0.05 {
+				t.Errorf("resolveLocation() = %.4f,%.4f; want %.4f,%.4f", loc.Latitude, loc.Longitude, tt.lat, tt.lon)
+			}
+			if tt.label != "" && loc.Name != tt.label {
+				t.Errorf("resolveLocation() name = %q; want %q", loc.Name, tt.label)
+			}
+		})
+	}
+}
+
+func TestSnapLocation(t *testing.T) {
+	cfg = &config.Config{
+		Geocoding: config.GeocodingConfig{SnapRadiusMeters: 500},
+		Locations: map[string]*models.Location{
+			"stavern": {Name: "Stavern", Latitude: 59.0, Longitude: 10.03},
+			"home":    {Latitude: 59.91, Longitude: 10.75},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		args  []string
+		flags map[string]string
+		label string
+		lat   float64
+	}{
+		{name: "Coordinate flags within radius", flags: map[string]string{"lat": "59.002", "lon": "10.031"}, label: "Stavern", lat: 59.0},
+		{name: "Coordinate argument within radius", args: []string{"59.913,10.752"}, label: "home", lat: 59.91},
+		{name: "Outside radius", args: []string{"59.02,10.03"}, lat: 59.02},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{}
+			addLocationFlags(cmd)
+			for name, value := range tt.flags {
+				if err := cmd.Flags().Set(name, value); err != nil {
+					t.Fatalf("Set(%s) failed: %v", name, err)
+				}
+			}
+
+			loc, err := resolveLocation(cmd, tt.args)
+			if err != nil {
+				t.Fatalf("resolveLocation() failed: %v", err)
+			}
+			if loc.Latitude != tt.lat {
+				t.Errorf("resolveLocation() latitude = %v; want %v", loc.Latitude, tt.lat)
+			}
+			if tt.label != "" && loc.Name != tt.label {
+				t.Errorf("resolveLocation() name = %q; want %q", loc.Name, tt.label)
+			}
+		})
+	}
+
+	cfg.Geocoding.SnapRadiusMeters = 0
+	if loc := snapLocation(&models.Location{Latitude: 59.0, Longitude: 10.03}); loc != nil {
+		t.Errorf("snapLocation() with snapping disabled = %v; want nil", loc)
+	}
+}
+
+func TestResolveLocationHere(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "position.json")
+	if err := os.WriteFile(path, []byte(`{"lat": 59.91, "lon": 10.75}`), 0644); err != nil {
+		t.Fatalf("failed to write location file: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		position config.PositionConfig
+		args     []string
+		lat, lon float64
+		err      bool
+	}{
+		{name: "Location file", position: config.PositionConfig{File: path}, lat: 59.91, lon: 10.75},
+		{name: "Missing file falls back to default", position: config.PositionConfig{File: path + ".missing"}, lat: 59.0, lon: 10.03},
+		{name: "No sources falls back to default", lat: 59.0, lon: 10.03},
+		{name: "With a location", position: config.PositionConfig{File: path}, args: []string{"home"}, err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg = &config.Config{
+				DefaultLocation: "stavern",
+				Position:        tt.position,
+				Locations: map[string]*models.Location{
+					"stavern": {Name: "Stavern", Latitude: 59.0, Longitude: 10.03},
+					"home":    {Name: "Home", Latitude: 59.91, Longitude: 10.75},
+				},
+			}
+
+			cmd := &cobra.Command{}
+			addLocationFlags(cmd)
+			if err := cmd.Flags().Set("here", "true"); err != nil {
+				t.Fatalf("Set(here) failed: %v", err)
+			}
+
+			loc, err := resolveLocation(cmd, tt.args)
+			if tt.err {
+				if err == nil {
+					t.Errorf("resolveLocation() = %v; want error", loc)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveLocation() failed: %v", err)
+			}
+			if loc.Latitude != tt.lat || loc.Longitude != tt.lon {
+				t.Errorf("resolveLocation() = %v,%v; want %v,%v", loc.Latitude, loc.Longitude, tt.lat, tt.lon)
+			}
+		})
+	}
+}
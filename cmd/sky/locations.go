@@ -3,11 +3,16 @@ package main
 import (
 	"fmt"
 	"os"
+	"slices"
 	"sort"
+	"strings"
 	"text/tabwriter"
 
+	"github.com/kristofferrisa/sky-cli/internal/api"
+	"github.com/kristofferrisa/sky-cli/internal/formatter"
 	"github.com/kristofferrisa/sky-cli/internal/models"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 // locationsCmd represents the locations command
@@ -44,6 +49,34 @@ var removeLocationCmd = &cobra.Command{
 	RunE:    runRemoveLocation,
 }
 
+// editLocationCmd changes a saved location
+var editLocationCmd = &cobra.Command{
+	Use:   "edit <name>",
+	Short: "Change a saved location",
+	Long: `Change the coordinates or details of a saved location. Only the
+given flags are changed; pass an empty value to clear a field.
+
+Examples:
+  sky locations edit cabin --lat 61.6364 --lon 8.3125
+  sky locations edit cabin --altitude 1100 --tag mountain --tag weekend
+  sky locations edit cabin --untag weekend --notes "Key under the mat"
+  sky locations edit office --format summary
+  sky locations edit office --notes ""`,
+	Args: cobra.ExactArgs(1),
+	RunE: runEditLocation,
+}
+
+// renameLocationCmd renames a saved location
+var renameLocationCmd = &cobra.Command{
+	Use:     "rename <old> <new>",
+	Aliases: []string{"mv"},
+	Short:   "Rename a saved location",
+	Long: `Rename a saved location. If it is the default location, or the default
+location of a profile, it stays the default under its new name.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runRenameLocation,
+}
+
 // setDefaultLocationCmd sets the default location
 var setDefaultLocationCmd = &cobra.Command{
 	Use:   "set-default <name>",
@@ -53,26 +86,87 @@ var setDefaultLocationCmd = &cobra.Command{
 	RunE:  runSetDefaultLocation,
 }
 
+// groupCmd manages location groups
+var groupCmd = &cobra.Command{
+	Use:   "group",
+	Short: "Manage location groups",
+	Long: `Manage named groups of saved locations. A group name can be used
+wherever several locations are accepted, for example 'sky current coast'.`,
+}
+
+// groupAddCmd adds locations to a group
+var groupAddCmd = &cobra.Command{
+	Use:   "add <group> <location>...",
+	Short: "Add saved locations to a group",
+	Long: `Add saved locations to a group, creating the group if it does not
+exist.
+
+Examples:
+  sky locations group add coast stavern larvik
+  sky current coast`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runGroupAdd,
+}
+
+// groupRemoveCmd removes locations from a group
+var groupRemoveCmd = &cobra.Command{
+	Use:     "remove <group> [location...]",
+	Aliases: []string{"rm"},
+	Short:   "Remove locations from a group, or the whole group",
+	Long: `Remove locations from a group. Without locations the whole group is
+removed; the locations themselves are kept.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runGroupRemove,
+}
+
+// groupListCmd lists the location groups
+var groupListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List location groups",
+	Args:  cobra.NoArgs,
+	RunE:  runGroupList,
+}
+
 var (
-	addLat      float64
-	addLon      float64
-	addTimezone string
+	addLat float64
+	addLon float64
+
+	// List command flags
+	listTags []string
+	listLong bool
 )
 
 func init() {
 	// Add subcommands
 	locationsCmd.AddCommand(listLocationsCmd)
 	locationsCmd.AddCommand(addLocationCmd)
+	locationsCmd.AddCommand(editLocationCmd)
+	locationsCmd.AddCommand(renameLocationCmd)
 	locationsCmd.AddCommand(removeLocationCmd)
 	locationsCmd.AddCommand(setDefaultLocationCmd)
+	locationsCmd.AddCommand(groupCmd)
+	groupCmd.AddCommand(groupAddCmd)
+	groupCmd.AddCommand(groupRemoveCmd)
+	groupCmd.AddCommand(groupListCmd)
+
+	// Add flags for list command
+	listLocationsCmd.Flags().StringSliceVarP(&listTags, "tag", "t", nil, "Only show locations with this tag (repeatable)")
+	listLocationsCmd.Flags().BoolVar(&listLong, "long", false, "Show altitude, timezone, provider, format and notes")
 
 	// Add flags for add command
 	addLocationCmd.Flags().Float64Var(&addLat, "lat", 0, "Latitude (required)")
 	addLocationCmd.Flags().Float64Var(&addLon, "lon", 0, "Longitude (required)")
-	addLocationCmd.Flags().StringVar(&addTimezone, "timezone", "", "Timezone (optional)")
+	addLocationDetailFlags(addLocationCmd)
 	addLocationCmd.MarkFlagRequired("lat")
 	addLocationCmd.MarkFlagRequired("lon")
 
+	// Add flags for edit command
+	editLocationCmd.Flags().String("name", "", "Display name")
+	editLocationCmd.Flags().Float64("lat", 0, "Latitude")
+	editLocationCmd.Flags().Float64("lon", 0, "Longitude")
+	addLocationDetailFlags(editLocationCmd)
+	editLocationCmd.Flags().StringSlice("untag", nil, "Remove a tag (repeatable)")
+
 	rootCmd.AddCommand(locationsCmd)
 }
 
@@ -82,17 +176,29 @@ func runListLocations(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	// Sort locations by name
+	// Sort locations by name, keeping those with all requested tags
 	names := make([]string, 0, len(cfg.Locations))
-	for name := range cfg.Locations {
-		names = append(names, name)
+	for name, loc := range cfg.Locations {
+		if hasTags(loc, listTags) {
+			names = append(names, name)
+		}
 	}
 	sort.Strings(names)
 
+	if len(names) == 0 {
+		fmt.Printf("No saved locations tagged %s\n", strings.Join(listTags, ", "))
+		return nil
+	}
+
 	// Create table writer
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
-	fmt.Fprintln(w, "NAME\tLOCATION\tCOORDINATES\tDEFAULT")
-	fmt.Fprintln(w, "────\t────────\t───────────\t───────")
+	if listLong {
+		fmt.Fprintln(w, "NAME\tLOCATION\tCOORDINATES\tALTITUDE\tTIMEZONE\tTAGS\tPROVIDER\tFORMAT\tDEFAULT\tNOTES")
+		fmt.Fprintln(w, "────\t────────\t───────────\t────────\t────────\t────\t────────\t──────\t───────\t─────")
+	} else {
+		fmt.Fprintln(w, "NAME\tLOCATION\tCOORDINATES\tTAGS\tDEFAULT")
+		fmt.Fprintln(w, "────\t────────\t───────────\t────\t───────")
+	}
 
 	for _, name := range names {
 		loc := cfg.Locations[name]
@@ -101,16 +207,34 @@ func runListLocations(cmd *cobra.Command, args []string) error {
 			isDefault = "✓"
 		}
 
-		locationName := loc.Name
-		if locationName == "" {
-			locationName = "-"
+		coordinates := fmt.Sprintf("%.4f°N, %.4f°E", loc.Latitude, loc.Longitude)
+
+		if listLong {
+			altitude := "-"
+			if loc.Elevation != 0 {
+				altitude = fmt.Sprintf("%.0f m", loc.Elevation)
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				name,
+				orDash(loc.Name),
+				coordinates,
+				altitude,
+				orDash(loc.Timezone),
+				orDash(strings.Join(loc.Tags, ", ")),
+				orDash(loc.Provider),
+				orDash(loc.Format),
+				isDefault,
+				loc.Notes,
+			)
+			continue
 		}
 
-		fmt.Fprintf(w, "%s\t%s\t%.4f°N, %.4f°E\t%s\n",
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
 			name,
-			locationName,
-			loc.Latitude,
-			loc.Longitude,
+			orDash(loc.Name),
+			coordinates,
+			orDash(strings.Join(loc.Tags, ", ")),
 			isDefault,
 		)
 	}
@@ -119,12 +243,30 @@ func runListLocations(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// hasTags reports whether loc has every one of tags
+func hasTags(loc *models.Location, tags []string) bool {
+	for _, tag := range tags {
+		if !loc.HasTag(tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// orDash returns s, or "-" when s is empty
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
 func runAddLocation(cmd *cobra.Command, args []string) error {
 	name := args[0]
 
 	// Check if location already exists
 	if _, exists := cfg.Locations[name]; exists {
-		return fmt.Errorf("location '%s' already exists (use 'locations remove' first to replace)", name)
+		return fmt.Errorf("location '%s' already exists (use 'locations edit' to change it)", name)
 	}
 
 	// Create location
@@ -132,7 +274,9 @@ func runAddLocation(cmd *cobra.Command, args []string) error {
 		Name:      name,
 		Latitude:  addLat,
 		Longitude: addLon,
-		Timezone:  addTimezone,
+	}
+	if err := applyLocationDetails(cmd, loc); err != nil {
+		return err
 	}
 
 	// Validate
@@ -161,6 +305,77 @@ func runAddLocation(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func runEditLocation(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	// Check if location exists
+	existing, exists := cfg.Locations[name]
+	if !exists {
+		return fmt.Errorf("location '%s' not found", name)
+	}
+	changed := false
+	cmd.LocalFlags().VisitAll(func(f *pflag.Flag) {
+		changed = changed || f.Changed
+	})
+	if !changed {
+		return fmt.Errorf("nothing to change (see 'sky locations edit --help')")
+	}
+
+	// Edit a copy so that the config is unchanged if validation fails
+	loc := *existing
+	loc.Tags = append([]string(nil), existing.Tags...)
+
+	flags := cmd.Flags()
+	if flags.Changed("name") {
+		loc.Name, _ = flags.GetString("name")
+	}
+	if flags.Changed("lat") {
+		loc.Latitude, _ = flags.GetFloat64("lat")
+	}
+	if flags.Changed("lon") {
+		loc.Longitude, _ = flags.GetFloat64("lon")
+	}
+	if err := applyLocationDetails(cmd, &loc); err != nil {
+		return err
+	}
+
+	// Update config
+	if err := cfg.AddLocation(name, &loc); err != nil {
+		return err
+	}
+
+	// Save config
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Printf("✓ Location '%s' updated successfully\n", name)
+	fmt.Printf("  %s\n", loc.String())
+
+	return nil
+}
+
+func runRenameLocation(cmd *cobra.Command, args []string) error {
+	oldName, newName := args[0], args[1]
+
+	// Rename in config
+	if err := cfg.RenameLocation(oldName, newName); err != nil {
+		return err
+	}
+
+	// Save config
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Printf("✓ Location '%s' renamed to '%s'\n", oldName, newName)
+	if cfg.DefaultLocation == newName {
+		fmt.Println("  It is still the default location")
+	}
+
+	return nil
+}
+
 func runRemoveLocation(cmd *cobra.Command, args []string) error {
 	name := args[0]
 
@@ -210,3 +425,112 @@ func runSetDefaultLocation(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+func runGroupAdd(cmd *cobra.Command, args []string) error {
+	group, names := strings.ToLower(args[0]), args[1:]
+
+	if err := cfg.AddToGroup(group, names...); err != nil {
+		return err
+	}
+
+	// Save config
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Printf("✓ Group '%s': %s\n", group, strings.Join(cfg.Groups[group], ", "))
+
+	return nil
+}
+
+func runGroupRemove(cmd *cobra.Command, args []string) error {
+	group, names := strings.ToLower(args[0]), args[1:]
+
+	if err := cfg.RemoveFromGroup(group, names...); err != nil {
+		return err
+	}
+
+	// Save config
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	if members, ok := cfg.Groups[group]; ok {
+		fmt.Printf("✓ Group '%s': %s\n", group, strings.Join(members, ", "))
+	} else {
+		fmt.Printf("✓ Group '%s' removed\n", group)
+	}
+
+	return nil
+}
+
+func runGroupList(cmd *cobra.Command, args []string) error {
+	names := cfg.GroupNames()
+	if len(names) == 0 {
+		fmt.Println("No location groups")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "GROUP\tLOCATIONS")
+	fmt.Fprintln(w, "─────\t─────────")
+	for _, name := range names {
+		fmt.Fprintf(w, "%s\t%s\n", name, strings.Join(cfg.Groups[name], ", "))
+	}
+	return w.Flush()
+}
+
+// addLocationDetailFlags adds the flags read by applyLocationDetails to cmd
+func addLocationDetailFlags(cmd *cobra.Command) {
+	cmd.Flags().String("timezone", "", "Timezone, for example Europe/Oslo")
+	cmd.Flags().Float64("altitude", 0, "Altitude in meters above sea level")
+	cmd.Flags().StringSlice("tag", nil, "Add a tag (repeatable)")
+	cmd.Flags().String("notes", "", "Notes")
+	cmd.Flags().String("provider", "", fmt.Sprintf("Preferred weather provider (%s)", strings.Join(api.Providers(), ", ")))
+	cmd.Flags().String("format", "", fmt.Sprintf("Preferred output format (%s)", strings.Join(formatter.AvailableFormatters(), ", ")))
+}
+
+// applyLocationDetails copies the detail flags that were given to loc.
+// Tags given with --tag are added and those given with --untag removed.
+func applyLocationDetails(cmd *cobra.Command, loc *models.Location) error {
+	flags := cmd.Flags()
+
+	if flags.Changed("timezone") {
+		loc.Timezone, _ = flags.GetString("timezone")
+	}
+	if flags.Changed("altitude") {
+		loc.Elevation, _ = flags.GetFloat64("altitude")
+	}
+	if flags.Changed("notes") {
+		loc.Notes, _ = flags.GetString("notes")
+	}
+
+	if flags.Changed("provider") {
+		loc.Provider, _ = flags.GetString("provider")
+	}
+	if flags.Changed("format") {
+		loc.Format, _ = flags.GetString("format")
+	}
+
+	if flags.Changed("tag") {
+		tags, _ := flags.GetStringSlice("tag")
+		for _, tag := range tags {
+			if !loc.HasTag(tag) {
+				loc.Tags = append(loc.Tags, tag)
+			}
+		}
+	}
+
+	if flags.Lookup("untag") != nil && flags.Changed("untag") {
+		untag, _ := flags.GetStringSlice("untag")
+		tags := loc.Tags[:0]
+		for _, tag := range loc.Tags {
+			if !slices.ContainsFunc(untag, func(u string) bool { return strings.EqualFold(u, tag) }) {
+				tags = append(tags, tag)
+			}
+		}
+		loc.Tags = tags
+	}
+
+	return checkPreferences(loc)
+}
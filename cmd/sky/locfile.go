@@ -0,0 +1,273 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kristofferrisa/sky-cli/internal/api"
+	"github.com/kristofferrisa/sky-cli/internal/formatter"
+	"github.com/kristofferrisa/sky-cli/internal/locfile"
+	"github.com/kristofferrisa/sky-cli/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// importLocationsCmd imports locations from a file
+var importLocationsCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import locations from a GeoJSON, GPX, KML or CSV file",
+	Long: `Import locations from a file. The format is detected from the file
+extension or content; use - to read from standard input.
+
+  GeoJSON  Point features; the "name" property is the location name
+  GPX      Waypoints (wpt)
+  KML      Point placemarks, also inside folders
+  CSV      A header row with name, latitude and longitude columns
+           (lat/lon/lng are accepted too); comma, semicolon or tab separated
+
+Locations are saved under a name made from the key or name in the file, such
+as "mo-i-rana". When a location with that name already exists, --on-duplicate
+decides what happens: skip keeps the existing location, overwrite replaces it
+and rename saves the imported location as "name-2".
+
+Examples:
+  sky locations import sites.geojson --dry-run
+  sky locations import waypoints.gpx --on-duplicate rename
+  cat sites.csv | sky locations import - --format csv`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImportLocations,
+}
+
+// exportLocationsCmd exports locations to a file
+var exportLocationsCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export locations as GeoJSON, GPX, KML or CSV",
+	Long: `Export saved locations to standard output or a file. Without --format,
+the format is taken from the extension of --output, or GeoJSON.
+
+Examples:
+  sky locations export > locations.geojson
+  sky locations export --format gpx --tag coast
+  sky locations export -o locations.kml`,
+	Args: cobra.NoArgs,
+	RunE: runExportLocations,
+}
+
+var (
+	// Import command flags
+	importFormat      string
+	importOnDuplicate string
+	importDryRun      bool
+
+	// Export command flags
+	exportFormat string
+	exportOutput string
+	exportTags   []string
+)
+
+func init() {
+	locationsCmd.AddCommand(importLocationsCmd)
+	locationsCmd.AddCommand(exportLocationsCmd)
+
+	importLocationsCmd.Flags().StringVarP(&importFormat, "format", "f", "", fmt.Sprintf("File format (%s; default: detect)", strings.Join(locfile.Formats(), ", ")))
+	importLocationsCmd.Flags().StringVar(&importOnDuplicate, "on-duplicate", locfile.StrategySkip, fmt.Sprintf("What to do with names that already exist (%s)", strings.Join(locfile.Strategies(), ", ")))
+	importLocationsCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "Show what would be imported without saving")
+
+	exportLocationsCmd.Flags().StringVarP(&exportFormat, "format", "f", "", fmt.Sprintf("File format (%s)", strings.Join(locfile.Formats(), ", ")))
+	exportLocationsCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Write to a file instead of standard output")
+	exportLocationsCmd.Flags().StringSliceVarP(&exportTags, "tag", "t", nil, "Only export locations with this tag (repeatable)")
+}
+
+func runImportLocations(cmd *cobra.Command, args []string) error {
+	filename := args[0]
+
+	var data []byte
+	var err error
+	if filename == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(filename)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+
+	format := importFormat
+	if format == "" {
+		if format, err = locfile.Detect(filename, data); err != nil {
+			return err
+		}
+	}
+
+	entries, err := locfile.Decode(format, data)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("no locations found in %s", filename)
+	}
+
+	// Preferences must be valid for the config to load again
+	for i := range entries {
+		if entries[i].Err == nil {
+			entries[i].Err = checkPreferences(entries[i].Location)
+		}
+	}
+
+	changes, err := locfile.Plan(cfg.Locations, entries, importOnDuplicate)
+	if err != nil {
+		return err
+	}
+
+	printChanges(os.Stdout, changes)
+
+	counts := make(map[locfile.Action]int)
+	for _, c := range changes {
+		counts[c.Action]++
+	}
+	imported := counts[locfile.ActionAdd] + counts[locfile.ActionOverwrite] + counts[locfile.ActionRename]
+
+	summary := fmt.Sprintf("%d of %d locations", imported, len(changes))
+	var details []string
+	for _, a := range []locfile.Action{locfile.ActionOverwrite, locfile.ActionRename, locfile.ActionSkip, locfile.ActionInvalid} {
+		if counts[a] > 0 {
+			details = append(details, fmt.Sprintf("%d %s", counts[a], pastTense(a)))
+		}
+	}
+	if len(details) > 0 {
+		summary += " (" + strings.Join(details, ", ") + ")"
+	}
+
+	if importDryRun {
+		fmt.Printf("\nDry run: would import %s\n", summary)
+		return nil
+	}
+	if imported == 0 {
+		fmt.Printf("\nNothing imported: %s\n", summary)
+		return nil
+	}
+
+	for _, c := range changes {
+		switch c.Action {
+		case locfile.ActionAdd, locfile.ActionOverwrite, locfile.ActionRename:
+			if err := cfg.AddLocation(c.Key, c.Location); err != nil {
+				return fmt.Errorf("%s: %w", c.Key, err)
+			}
+		}
+	}
+
+	// Save config
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Printf("\n✓ Imported %s\n", summary)
+
+	return nil
+}
+
+// printChanges lists what importing each entry does
+func printChanges(w io.Writer, changes []locfile.Change) {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "ACTION\tNAME\tLOCATION\tDETAILS")
+	fmt.Fprintln(tw, "──────\t────\t────────\t───────")
+
+	for _, c := range changes {
+		var details string
+		switch c.Action {
+		case locfile.ActionSkip:
+			details = "already exists"
+		case locfile.ActionOverwrite:
+			details = "replaces the existing location"
+		case locfile.ActionRename:
+			details = fmt.Sprintf("'%s' already exists", c.From)
+		case locfile.ActionInvalid:
+			details = fmt.Sprintf("%s: %v", c.Source, c.Err)
+		}
+
+		location := "-"
+		if c.Action != locfile.ActionInvalid {
+			location = c.Location.String()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", c.Action, orDash(c.Key), location, details)
+	}
+	tw.Flush()
+}
+
+// pastTense describes how many locations an action applied to, as in
+// "2 skipped"
+func pastTense(a locfile.Action) string {
+	switch a {
+	case locfile.ActionAdd:
+		return "added"
+	case locfile.ActionSkip:
+		return "skipped"
+	case locfile.ActionOverwrite:
+		return "overwritten"
+	case locfile.ActionRename:
+		return "renamed"
+	default:
+		return a.String()
+	}
+}
+
+// checkPreferences checks the preferred provider and output format of a
+// location
+func checkPreferences(loc *models.Location) error {
+	if loc.Provider != "" && !slices.Contains(api.Providers(), loc.Provider) {
+		return fmt.Errorf("unknown provider '%s' (available: %s)", loc.Provider, strings.Join(api.Providers(), ", "))
+	}
+	if loc.Format != "" && !slices.Contains(formatter.AvailableFormatters(), loc.Format) {
+		return fmt.Errorf("unknown format '%s' (available: %s)", loc.Format, strings.Join(formatter.AvailableFormatters(), ", "))
+	}
+	return nil
+}
+
+func runExportLocations(cmd *cobra.Command, args []string) error {
+	format := exportFormat
+	if format == "" && exportOutput != "" {
+		format, _ = locfile.Detect(exportOutput, nil)
+	}
+	if format == "" {
+		format = locfile.FormatGeoJSON
+	}
+	if !slices.Contains(locfile.Formats(), format) {
+		return fmt.Errorf("unknown format: %s (available: %s)", format, strings.Join(locfile.Formats(), ", "))
+	}
+
+	keys := make([]string, 0, len(cfg.Locations))
+	for key, loc := range cfg.Locations {
+		if hasTags(loc, exportTags) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	entries := make([]locfile.Entry, 0, len(keys))
+	for _, key := range keys {
+		entries = append(entries, locfile.Entry{Key: key, Location: cfg.Locations[key]})
+	}
+
+	if exportOutput == "" {
+		return locfile.Encode(os.Stdout, format, entries)
+	}
+
+	f, err := os.Create(exportOutput)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", exportOutput, err)
+	}
+	if err := locfile.Encode(f, format, entries); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "✓ Exported %d locations to %s\n", len(entries), exportOutput)
+	return nil
+}
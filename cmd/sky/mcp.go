@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/kristofferrisa/sky-cli/internal/mcp"
+	"github.com/spf13/cobra"
+)
+
+// mcpCmd represents the mcp command
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Serve weather tools to AI assistants over MCP",
+	Long: `Serve weather tools to AI assistants and other LLM clients over the Model
+Context Protocol (MCP), on stdin and stdout.
+
+The tools are:
+
+  get_current_weather   current weather at a location
+  get_hourly_forecast   hourly forecast, up to 240 hours
+  get_daily_forecast    daily forecast, up to 10 days
+  list_locations        the saved locations
+  plan_activity         best times for an activity, as with sky plan
+
+They take saved locations, place names or coordinates, use the default location
+without one, and return the same JSON as --format json. Forecasts come from the
+cache like other commands.
+
+Add sky to a client that starts MCP servers, for example:
+
+  {
+    "mcpServers": {
+      "sky": {"command": "sky", "args": ["mcp"]}
+    }
+  }`,
+	Args: cobra.NoArgs,
+	RunE: runMCP,
+}
+
+func init() {
+	rootCmd.AddCommand(mcpCmd)
+}
+
+func runMCP(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	s := &mcp.Server{
+		Name:    "sky",
+		Version: version,
+		Tools: &mcp.Tools{
+			Client:          getWeatherClient(),
+			Locate:          serverLocation,
+			Locations:       cfg.Locations,
+			DefaultLocation: cfg.DefaultLocation,
+			Activity:        cfg.GetActivity,
+			Activities:      cfg.ActivityNames(),
+		},
+		Log: os.Stderr,
+	}
+	return s.Serve(ctx, os.Stdin, os.Stdout)
+}
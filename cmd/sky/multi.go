@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/kristofferrisa/sky-cli/internal/formatter"
+	"github.com/kristofferrisa/sky-cli/internal/geocode"
+	"github.com/kristofferrisa/sky-cli/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// maxParallelRequests limits the API requests in flight when querying
+// several locations, to stay within the MET Norway terms of service
+const maxParallelRequests = 4
+
+// resolveLocations returns the saved locations selected by --all, a group
+// or several saved location names. It returns nil when the arguments
+// select a single location, which resolveLocation handles.
+func resolveLocations(cmd *cobra.Command, args []string) ([]*models.Location, error) {
+	flags := cmd.Flags()
+	name, _ := flags.GetString("location")
+
+	if all, _ := flags.GetBool("all"); all {
+		if here, _ := flags.GetBool("here"); here || len(args) > 0 || name != "" || flags.Changed("lat") || flags.Changed("lon") {
+			return nil, fmt.Errorf("--all cannot be combined with a location")
+		}
+		if len(cfg.Locations) == 0 {
+			return nil, fmt.Errorf("no saved locations (add one with 'sky locations add')")
+		}
+
+		keys := make([]string, 0, len(cfg.Locations))
+		for key := range cfg.Locations {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		return savedLocations(keys), nil
+	}
+
+	if here, _ := flags.GetBool("here"); here || flags.Changed("lat") || flags.Changed("lon") {
+		return nil, nil
+	}
+
+	names := args
+	if name != "" {
+		names = []string{name}
+	}
+
+	// A single name is a group unless a saved location has that name; two
+	// or more names are several locations only if all of them are saved
+	// locations or groups, so "Mo i Rana" is still one place name.
+	if len(names) == 1 {
+		if _, _, ok := findSavedLocation(names[0]); ok {
+			return nil, nil
+		}
+		if members, ok := cfg.Groups[strings.ToLower(names[0])]; ok {
+			return savedLocations(members), nil
+		}
+		return nil, nil
+	}
+
+	var keys []string
+	for _, n := range names {
+		if key, _, ok := findSavedLocation(n); ok {
+			keys = append(keys, key)
+			continue
+		}
+		members, ok := cfg.Groups[strings.ToLower(n)]
+		if !ok {
+			return nil, nil
+		}
+		keys = append(keys, members...)
+	}
+	return savedLocations(keys), nil
+}
+
+// findSavedLocation finds a saved location by its name, ignoring case and
+// diacritics, and returns its key. When several keys match, such as
+// "tromso" and "Tromsø", one that differs only in case wins, and then the
+// first in sorted order, so that the same one is always found.
+func findSavedLocation(name string) (string, *models.Location, bool) {
+	if loc, ok := cfg.Locations[name]; ok {
+		return name, loc, true
+	}
+
+	keys := make([]string, 0, len(cfg.Locations))
+	for key := range cfg.Locations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if strings.EqualFold(key, name) {
+			return key, cfg.Locations[key], true
+		}
+	}
+	for _, key := range keys {
+		if geocode.Normalize(key) == geocode.Normalize(name) {
+			return key, cfg.Locations[key], true
+		}
+	}
+	return "", nil, false
+}
+
+// savedLocations returns the saved locations with the given keys, without
+// duplicates. Locations without a display name are named after their key.
+func savedLocations(keys []string) []*models.Location {
+	seen := make(map[string]bool)
+	var locs []*models.Location
+	for _, key := range keys {
+		loc, ok := cfg.Locations[key]
+		if !ok || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if loc.Name == "" {
+			named := *loc
+			named.Name = key
+			loc = &named
+		}
+		locs = append(locs, loc)
+	}
+	return locs
+}
+
+// fetchAll calls fetch for every location, with at most limit calls in
+// flight, and returns the results in the order of locs. A failure is
+// recorded in its result rather than stopping the other requests.
+func fetchAll[T any](ctx context.Context, locs []*models.Location, limit int, fetch func(context.Context, *models.Location) (T, error)) []formatter.Result[T] {
+	results := make([]formatter.Result[T], len(locs))
+	sem := make(chan struct{}, limit)
+
+	var wg sync.WaitGroup
+	for i, loc := range locs {
+		wg.Add(1)
+		go func(i int, loc *models.Location) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			data, err := fetch(ctx, loc)
+			results[i] = formatter.Result[T]{Location: loc, Data: data, Err: err}
+		}(i, loc)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// checkResults returns an error when no location could be fetched, so
+// that a run where everything failed exits with a non-zero status
+func checkResults[T any](results []formatter.Result[T]) error {
+	for _, r := range results {
+		if r.Err == nil {
+			return nil
+		}
+	}
+	if len(results) == 1 {
+		return results[0].Err
+	}
+	return fmt.Errorf("failed to fetch weather for all %d locations", len(results))
+}
+
+// compareFormatter returns the formatter for comparing several locations.
+// Preferred formats of the individual locations are ignored.
+func compareFormatter(format string) (formatter.Formatter, error) {
+	if format == "" {
+		format = cfg.DefaultFormat
+	}
+	if format == "" {
+		format = "full"
+	}
+	return formatter.GetFormatter(format)
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/config"
+	"github.com/kristofferrisa/sky-cli/internal/models"
+	"github.com/spf13/cobra"
+)
+
+func TestResolveLocations(t *testing.T) {
+	saved := &config.Config{
+		DefaultLocation: "stavern",
+		Locations: map[string]*models.Location{
+			"stavern": {Name: "Stavern", Latitude: 59.0, Longitude: 10.03},
+			"larvik":  {Latitude: 59.05, Longitude: 10.03},
+			"home":    {Name: "Home", Latitude: 59.91, Longitude: 10.75},
+		},
+		Groups: map[string][]string{
+			"coast": {"stavern", "larvik"},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		args  []string
+		flags map[string]string
+		want  string // comma-separated location names, empty for a single location
+		err   bool
+	}{
+		{name: "No arguments", want: ""},
+		{name: "Saved location", args: []string{"home"}, want: ""},
+		{name: "Place name", args: []string{"Mo", "i", "Rana"}, want: ""},
+		{name: "Coordinates", args: []string{"59.91,", "10.75"}, want: ""},
+		{name: "Group", args: []string{"coast"}, want: "Stavern,larvik"},
+		{name: "Group ignoring case", args: []string{"Coast"}, want: "Stavern,larvik"},
+		{name: "Group from flag", flags: map[string]string{"location": "coast"}, want: "Stavern,larvik"},
+		{name: "Several locations", args: []string{"home", "stavern"}, want: "Home,Stavern"},
+		{name: "Locations and groups without duplicates", args: []string{"larvik", "coast", "home"}, want: "larvik,Stavern,Home"},
+		{name: "All locations", flags: map[string]string{"all": "true"}, want: "Home,larvik,Stavern"},
+		{name: "All with a location", args: []string{"home"}, flags: map[string]string{"all": "true"}, err: true},
+		{name: "Coordinate flags", args: []string{"coast"}, flags: map[string]string{"lat": "59", "lon": "10"}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg = saved
+
+			cmd := &cobra.Command{}
+			addLocationFlags(cmd)
+			for name, value := range tt.flags {
+				if err := cmd.Flags().Set(name, value); err != nil {
+					t.Fatalf("Set(%s) failed: %v", name, err)
+				}
+			}
+
+			locs, err := resolveLocations(cmd, tt.args)
+			if tt.err {
+				if err == nil {
+					t.Errorf("resolveLocations() = %v; want error", locs)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveLocations() failed: %v", err)
+			}
+
+			var names []string
+			for _, loc := range locs {
+				names = append(names, loc.Name)
+			}
+			if got := strings.Join(names, ","); got != tt.want {
+				t.Errorf("resolveLocations() = %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindSavedLocation(t *testing.T) {
+	cfg = &config.Config{
+		Locations: map[string]*models.Location{
+			"Tromsø": {Latitude: 69.65, Longitude: 18.96},
+			"tromso": {Latitude: 69.68, Longitude: 18.92},
+			"TROMSØ": {Latitude: 69.6, Longitude: 19.0},
+			"bodø":   {Latitude: 67.28, Longitude: 14.4},
+		},
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"tromso", "tromso"},
+		{"Tromso", "tromso"},
+		{"tromsø", "TROMSØ"},
+		{"TROMSO", "tromso"},
+		{"Bodo", "bodø"},
+	}
+	for _, tt := range tests {
+		// Map order varies between runs, so look every name up repeatedly
+		for i := 0; i < 20; i++ {
+			key, _, ok := findSavedLocation(tt.name)
+			if !ok || key != tt.want {
+				t.Fatalf("findSavedLocation(%q) = %q, %v; want %q", tt.name, key, ok, tt.want)
+			}
+		}
+	}
+	if _, _, ok := findSavedLocation("oslo"); ok {
+		t.Error("findSavedLocation(oslo) found a location; want none")
+	}
+}
+
+func TestFetchAll(t *testing.T) {
+	var locs []*models.Location
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g"} {
+		locs = append(locs, &models.Location{Name: name})
+	}
+
+	var mu sync.Mutex
+	running, peak := 0, 0
+
+	results := fetchAll(context.Background(), locs, 3, func(ctx context.Context, loc *models.Location) (string, error) {
+		mu.Lock()
+		running++
+		if running > peak {
+			peak = running
+		}
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+
+		if loc.Name == "c" {
+			return "", errors.New("unavailable")
+		}
+		return strings.ToUpper(loc.Name), nil
+	})
+
+	if peak > 3 {
+		t.Errorf("fetchAll() ran %d requests at once; want at most 3", peak)
+	}
+	if len(results) != len(locs) {
+		t.Fatalf("fetchAll() returned %d results; want %d", len(results), len(locs))
+	}
+	for i, r := range results {
+		if r.Location != locs[i] {
+			t.Errorf("results[%d].Location = %s; want %s", i, r.Location.Name, locs[i].Name)
+		}
+		if r.Location.Name == "c" {
+			if r.Err == nil {
+				t.Errorf("results[%d].Err = nil; want error", i)
+			}
+			continue
+		}
+		if r.Err != nil || r.Data != strings.ToUpper(r.Location.Name) {
+			t.Errorf("results[%d] = %q, %v; want %q", i, r.Data, r.Err, strings.ToUpper(r.Location.Name))
+		}
+	}
+
+	if err := checkResults(results); err != nil {
+		t.Errorf("checkResults() with partial failures = %v; want nil", err)
+	}
+	if err := checkResults(results[2:3]); err == nil {
+		t.Error("checkResults() with only failures expected error but got none")
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/notify"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Notify command flags
+	notifyMessage string
+	notifyList    bool
+)
+
+// notifyCmd represents the notify command
+var notifyCmd = &cobra.Command{
+	Use:   "notify [notifier...]",
+	Short: "Send a message to your notifiers",
+	Long: `Send a message to notifiers in the config file, to test them or from scripts.
+Without names, the message goes to every notifier.
+
+Notifiers deliver the alerts of 'sky check --to' and the events of
+'sky watch --to' to chat rooms and phones:
+
+  webhook   posts JSON to any URL; template renders the body from .Title,
+            .Text, .Markdown and .Data, with json to quote values
+  slack     Slack-compatible incoming webhooks (Slack, Mattermost, Rocket.Chat)
+  teams     Microsoft Teams webhooks, as an Adaptive Card
+  ntfy      push notifications through an ntfy topic URL
+  matrix    a Matrix room; url is the homeserver, with room and token
+
+Chat rooms get the markdown format and push notifications the summary format.
+Deliveries that fail with a network or server error are retried 3 times unless
+retries says otherwise.
+
+Add a notifier with:
+  sky config set notifiers.team.type slack
+  sky config set notifiers.team.url https://hooks.slack.com/services/...
+
+Examples:
+  sky notify team                                # Send a test message
+  sky notify --message "Ferry cancelled"         # To every notifier
+  sky notify --list                              # Show the notifiers`,
+	RunE: runNotify,
+}
+
+func init() {
+	notifyCmd.Flags().StringVarP(&notifyMessage, "message", "m", "", "Text to send (default: a test message)")
+	notifyCmd.Flags().BoolVar(&notifyList, "list", false, "List the notifiers in the config file")
+
+	rootCmd.AddCommand(notifyCmd)
+}
+
+func runNotify(cmd *cobra.Command, args []string) error {
+	if notifyList {
+		return listNotifiers()
+	}
+
+	names := args
+	if len(names) == 0 {
+		names = cfg.NotifierNames()
+	}
+	notifiers, err := selectNotifiers(names)
+	if err != nil {
+		return err
+	}
+	if len(notifiers) == 0 {
+		return fmt.Errorf("no notifiers configured (add one with 'sky config set notifiers.team.type slack' and 'sky config set notifiers.team.url <webhook URL>')")
+	}
+
+	text := notifyMessage
+	if text == "" {
+		text = "Test notification from sky"
+	}
+	msg := notify.Message{
+		Title:    "Sky",
+		Text:     text,
+		Markdown: text,
+		Data:     map[string]string{"text": text},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	if failed := sendNotification(ctx, notifiers, msg); failed > 0 {
+		return fmt.Errorf("%d of %d notifications failed", failed, len(notifiers))
+	}
+	fmt.Printf("✓ Sent to %s\n", strings.Join(names, ", "))
+	return nil
+}
+
+// namedNotifier is a notifier with the name it is configured under
+type namedNotifier struct {
+	name     string
+	notifier notify.Notifier
+}
+
+// selectNotifiers creates the configured notifiers with the given names
+func selectNotifiers(names []string) ([]namedNotifier, error) {
+	var selected []namedNotifier
+	for _, name := range names {
+		name = strings.ToLower(name)
+		n, ok := cfg.Notifiers[name]
+		if !ok || n == nil {
+			available := cfg.NotifierNames()
+			if len(available) == 0 {
+				return nil, fmt.Errorf("unknown notifier '%s' (none configured; add one with 'sky config set notifiers.%s.type slack')", name, name)
+			}
+			return nil, fmt.Errorf("unknown notifier '%s' (available: %s)", name, strings.Join(available, ", "))
+		}
+		notifier, err := notify.New(n)
+		if err != nil {
+			return nil, fmt.Errorf("notifier '%s': %w", name, err)
+		}
+		selected = append(selected, namedNotifier{name, notifier})
+	}
+	return selected, nil
+}
+
+// sendNotification sends msg to every notifier, reporting failures on
+// stderr, and returns the number of failures
+func sendNotification(ctx context.Context, notifiers []namedNotifier, msg notify.Message) int {
+	failed := 0
+	for _, n := range notifiers {
+		if err := n.notifier.Notify(ctx, msg); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  Could not notify %s: %v\n", n.name, err)
+			failed++
+		}
+	}
+	return failed
+}
+
+// listNotifiers prints the notifiers in the config file
+func listNotifiers() error {
+	names := cfg.NotifierNames()
+	if len(names) == 0 {
+		fmt.Println("No notifiers configured.")
+		fmt.Println("\nAdd one with: sky config set notifiers.team.type slack")
+		fmt.Println("              sky config set notifiers.team.url <webhook URL>")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NOTIFIER\tTYPE\tHOST")
+	fmt.Fprintln(w, "────────\t────\t────")
+	for _, name := range names {
+		n := cfg.Notifiers[name]
+		if n == nil {
+			fmt.Fprintf(w, "%s\t-\t-\n", name)
+			continue
+		}
+		// Webhook URLs are secrets, so only their host is shown
+		host := "-"
+		if u, err := url.Parse(n.URL); err == nil && u.Host != "" {
+			host = u.Host
+		}
+		if n.Room != "" {
+			host += " " + n.Room
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", name, n.Type, host)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/formatter"
+	"github.com/kristofferrisa/sky-cli/internal/models"
+	"github.com/kristofferrisa/sky-cli/internal/planner"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Plan command flags
+	planActivity string
+	planDuration time.Duration
+	planWithin   time.Duration
+	planTop      int
+	planFormat   string
+	planList     bool
+)
+
+// planCmd represents the plan command
+var planCmd = &cobra.Command{
+	Use:   "plan [location]",
+	Short: "Find the best time for an activity",
+	Long: `Find the best times for an outdoor activity in the hourly forecast.
+
+Every window of --duration that starts in the next --within is scored from 0 to
+100 against the activity's limits: a comfortable temperature range, maximum
+wind, maximum precipitation and humidity, a minimum wind for sailing and
+whether it needs daylight. The best windows that do not overlap are listed with
+the reasons for their score.
+
+Built-in activities are running, cycling, hiking, painting and sailing. Change
+their limits or add your own under activities in the config file, for example
+with 'sky config set activities.running.max_wind 6'. List them with --list.
+
+The hourly forecast reaches about two and a half days ahead; later windows are
+not considered.
+
+Examples:
+  sky plan --activity running                      # Best 1h runs in 48h
+  sky plan --activity running --duration 2h --within 24h
+  sky plan stavern --activity sailing --duration 3h
+  sky plan --activity painting --duration 6h --top 1
+  sky plan --list                                  # Show the activities`,
+	RunE: runPlan,
+}
+
+func init() {
+	addLocationFlags(planCmd)
+	planCmd.Flags().StringVarP(&planActivity, "activity", "a", "", "Activity to plan (see --list)")
+	planCmd.Flags().DurationVarP(&planDuration, "duration", "d", time.Hour, "Length of the activity")
+	planCmd.Flags().DurationVarP(&planWithin, "within", "w", 48*time.Hour, "How far ahead to look")
+	planCmd.Flags().IntVarP(&planTop, "top", "n", 3, "Number of windows to show")
+	planCmd.Flags().StringVarP(&planFormat, "format", "f", "", "Output format (full, json, summary, markdown)")
+	planCmd.Flags().BoolVar(&planList, "list", false, "List the activities and their limits")
+
+	rootCmd.AddCommand(planCmd)
+}
+
+func runPlan(cmd *cobra.Command, args []string) error {
+	if planList {
+		return listActivities()
+	}
+
+	if planActivity == "" {
+		return fmt.Errorf("--activity is required (available: %s)", strings.Join(cfg.ActivityNames(), ", "))
+	}
+	activity, err := cfg.GetActivity(strings.ToLower(planActivity))
+	if err != nil {
+		return err
+	}
+	if planDuration < time.Minute {
+		return fmt.Errorf("--duration must be at least 1m")
+	}
+	if planWithin < planDuration {
+		return fmt.Errorf("--within must be at least as long as --duration")
+	}
+	if planTop < 1 {
+		return fmt.Errorf("--top must be at least 1")
+	}
+
+	locs, err := resolveLocations(cmd, args)
+	if err != nil {
+		return err
+	}
+	if locs != nil {
+		return fmt.Errorf("plan works on one location at a time")
+	}
+	loc, err := resolveLocation(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	format := planFormat
+	if format == "" {
+		format = cfg.OutputFormat(loc.Format)
+	}
+	if format == "" {
+		format = "full"
+	}
+	fmtr, err := formatter.GetFormatter(format)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// The current hour is included, since the first window starts at the
+	// next full hour
+	hours := int(math.Ceil(planWithin.Hours())) + 1
+	forecast, err := getWeatherClient().GetHourlyForecast(ctx, loc, hours)
+	if err != nil {
+		return fmt.Errorf("failed to fetch forecast: %w", err)
+	}
+
+	now := time.Now()
+	plan := &models.Plan{
+		Location: loc,
+		Activity: strings.ToLower(planActivity),
+		Duration: planDuration,
+		Windows:  planner.Rank(forecast, activity, planDuration, now, now.Add(planWithin), planTop),
+	}
+
+	opts := formatter.Options{
+		NoColor:    cfg.NoColor,
+		NoEmoji:    cfg.NoEmoji,
+		TimeFormat: "2006-01-02 15:04:05",
+	}
+	return fmtr.FormatPlan(os.Stdout, plan, opts)
+}
+
+// listActivities prints the activities and their limits
+func listActivities() error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "ACTIVITY\tTEMP\tWIND\tPRECIP\tHUMIDITY\tDAYLIGHT")
+	fmt.Fprintln(w, "────────\t────\t────\t──────\t────────\t────────")
+	for _, name := range cfg.ActivityNames() {
+		a, err := cfg.GetActivity(name)
+		if err != nil {
+			return err
+		}
+
+		daylight := "no"
+		if a.NeedsDaylight() {
+			daylight = "yes"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			name,
+			activityRange(a.MinTemp, a.MaxTemp, "°C"),
+			activityRange(a.MinWind, a.MaxWind, " m/s"),
+			activityRange(nil, a.MaxPrecipitation, " mm/h"),
+			activityRange(nil, a.MaxHumidity, "%"),
+			daylight,
+		)
+	}
+	return w.Flush()
+}
+
+// activityRange formats the limits of an activity, such as "5-18°C",
+// "≥ 4 m/s" or "≤ 0.5 mm/h"
+func activityRange(low, high *float64, unit string) string {
+	switch {
+	case low != nil && high != nil:
+		return fmt.Sprintf("%g-%g%s", *low, *high, unit)
+	case low != nil:
+		return fmt.Sprintf("≥ %g%s", *low, unit)
+	case high != nil:
+		return fmt.Sprintf("≤ %g%s", *high, unit)
+	}
+	return "-"
+}
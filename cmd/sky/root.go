@@ -1,23 +1,29 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/kristofferrisa/sky-cli/internal/api"
 	"github.com/kristofferrisa/sky-cli/internal/api/met"
 	"github.com/kristofferrisa/sky-cli/internal/cache"
 	"github.com/kristofferrisa/sky-cli/internal/config"
+	"github.com/kristofferrisa/sky-cli/internal/history"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var (
 	cfg *config.Config
 
 	// Global flags
-	noColor bool
-	noEmoji bool
+	configFile  string
+	profileName string
+	noColor     bool
+	noEmoji     bool
 )
 
 // rootCmd represents the base command
@@ -27,20 +33,36 @@ var rootCmd = &cobra.Command{
 	Long: `Sky is a command-line weather tool that provides current conditions,
 forecasts, and weather data in a human-readable and LLM-friendly format.
 
-Powered by MET Norway (Meteorologisk institutt).`,
+Powered by MET Norway (Meteorologisk institutt).
+
+Configuration is resolved with the following precedence, highest first:
+  1. Command-line flags (--no-color, --no-emoji, --format, ...)
+  2. Environment variables (SKY_DEFAULT_LOCATION, SKY_FORMAT,
+     SKY_CACHE_ENABLED, NO_COLOR, ...)
+  3. The active profile (--profile, SKY_PROFILE or "profile" in the file)
+  4. The config file (--config, SKY_CONFIG, ~/.sky/config.yaml or
+     $XDG_CONFIG_HOME/sky/config.yaml)
+  5. Built-in defaults`,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		var err error
-		cfg, err = config.Load()
+		cfg, err = config.Load(config.LoadOptions{
+			File: configFile,
+			Flags: map[string]*pflag.Flag{
+				"profile":  cmd.Flags().Lookup("profile"),
+				"no_color": cmd.Flags().Lookup("no-color"),
+				"no_emoji": cmd.Flags().Lookup("no-emoji"),
+				// SKY_FORMAT and --format both set default_format, so that
+				// either wins over the format saved with a location
+				"default_format": formatFlag(cmd),
+			},
+			SkipMigration: cmd == migrateConfigCmd,
+		})
 		if err != nil {
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 
-		// Override config with flags if set
-		if noColor {
-			cfg.NoColor = true
-		}
-		if noEmoji {
-			cfg.NoEmoji = true
+		if m := cfg.Migration(); m != nil {
+			fmt.Fprintf(os.Stderr, "ℹ️  Config file is version %d; run 'sky config migrate' to upgrade it to version %d\n", m.From, m.To)
 		}
 
 		return nil
@@ -48,6 +70,8 @@ Powered by MET Norway (Meteorologisk institutt).`,
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "Config file (default: ~/.sky/config.yaml)")
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "Config profile to use")
 	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colored output")
 	rootCmd.PersistentFlags().BoolVar(&noEmoji, "no-emoji", false, "Disable emoji output")
 
@@ -55,6 +79,17 @@ func init() {
 	rootCmd.AddCommand(versionCmd)
 }
 
+// formatFlag returns the --format flag of commands whose output format
+// defaults to default_format, or nil. Other commands use --format for
+// file formats such as csv.
+func formatFlag(cmd *cobra.Command) *pflag.Flag {
+	switch cmd {
+	case currentCmd, forecastCmd, dailyCmd, diffCmd, planCmd, routeCmd:
+		return cmd.Flags().Lookup("format")
+	}
+	return nil
+}
+
 // versionCmd represents the version command
 var versionCmd = &cobra.Command{
 	Use:   "version",
@@ -73,38 +108,96 @@ var versionCmd = &cobra.Command{
 // Execute runs the root command
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
+		var exit *exitError
+		if errors.As(err, &exit) {
+			os.Exit(exit.code)
+		}
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 }
 
+// exitError ends sky with a status other than 1, for commands whose exit
+// status carries a result. The command has already printed its output.
+type exitError struct {
+	code int
+}
+
+func (e *exitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
 // getWeatherClient creates a weather client with optional caching
 func getWeatherClient() api.WeatherClient {
+	client := recordHistory(met.NewClient())
+
+	fileCache := getCache()
+	if fileCache == nil {
+		return client
+	}
+
+	// Return cached client
+	return met.NewCachedClientWithClient(client, fileCache, cacheTTL())
+}
+
+// recordHistory makes a client archive the forecasts it fetches when the
+// history is enabled
+func recordHistory(client *met.Client) *met.Client {
+	if !cfg.History.Enabled {
+		return client
+	}
+
+	var warned sync.Once
+	client.SetRecorder(&history.Recorder{
+		Path:      historyPath(),
+		Retention: time.Duration(cfg.History.RetentionDays) * 24 * time.Hour,
+		OnError: func(err error) {
+			warned.Do(func() {
+				fmt.Fprintf(os.Stderr, "⚠️  Failed to record the forecast in the history: %v\n", err)
+			})
+		},
+	})
+	return client
+}
+
+// historyPath returns the history archive
+func historyPath() string {
+	if cfg.History.Path != "" {
+		return cfg.History.Path
+	}
+	return config.DefaultHistoryPath()
+}
+
+// cacheTTL returns how long weather data is cached
+func cacheTTL() time.Duration {
+	ttl := time.Duration(cfg.Cache.TTLMinutes) * time.Minute
+	if ttl == 0 {
+		ttl = 10 * time.Minute
+	}
+	return ttl
+}
+
+// getCache returns the file cache, or nil if caching is disabled or the
+// cache cannot be created
+func getCache() cache.Cache {
 	// Check if cache is enabled
 	if !cfg.Cache.Enabled {
-		return met.NewClient()
+		return nil
 	}
 
 	// Create cache directory
 	cacheDir := cfg.Cache.Directory
 	if cacheDir == "" {
-		cacheDir = os.Getenv("HOME") + "/.sky/cache"
+		cacheDir = config.DefaultCacheDir()
 	}
 
 	// Create file cache
 	fileCache, err := cache.NewFileCache(cacheDir)
 	if err != nil {
-		// Fall back to no-op cache if creation fails
+		// Fall back to no cache if creation fails
 		fmt.Fprintf(os.Stderr, "Warning: Failed to create cache: %v\n", err)
-		return met.NewClient()
-	}
-
-	// Get TTL from config
-	ttl := time.Duration(cfg.Cache.TTLMinutes) * time.Minute
-	if ttl == 0 {
-		ttl = 10 * time.Minute
+		return nil
 	}
 
-	// Return cached client
-	return met.NewCachedClient(fileCache, ttl)
+	return fileCache
 }
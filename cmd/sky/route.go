@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/api"
+	"github.com/kristofferrisa/sky-cli/internal/formatter"
+	"github.com/kristofferrisa/sky-cli/internal/geocode"
+	"github.com/kristofferrisa/sky-cli/internal/models"
+	"github.com/kristofferrisa/sky-cli/internal/route"
+	"github.com/spf13/cobra"
+)
+
+// maxRoutePoints limits the points sampled along a route
+const maxRoutePoints = 200
+
+var (
+	// Route command flags
+	routeDepart string
+	routeSpeed  string
+	routeEvery  time.Duration
+	routeFormat string
+)
+
+// routeCmd represents the route command
+var routeCmd = &cobra.Command{
+	Use:   "route <file.gpx>",
+	Short: "Get the forecast along a GPX track",
+	Long: `Get the forecast along a track or route from a GPX file, for the time each
+point is reached when travelling at a steady speed.
+
+Points are sampled along the track for every --every of travel time, and the
+forecast hour in which each is reached is shown. The coldest, windiest and
+wettest points are highlighted.
+
+The departure time is "now", a time of day (today, or tomorrow if it was more
+than an hour ago) or a date and time. The speed takes km/h, m/s, mph or kn.
+
+Examples:
+  sky route trip.gpx --speed 15km/h --depart 08:00
+  sky route hike.gpx --speed 4 --every 30m
+  sky route sail.gpx --speed 6kn --depart "2026-06-01 09:30"
+  sky route trip.gpx --speed 80km/h --format json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRoute,
+}
+
+func init() {
+	routeCmd.Flags().StringVar(&routeDepart, "depart", "now", "Departure time (now, HH:MM or YYYY-MM-DD HH:MM)")
+	routeCmd.Flags().StringVar(&routeSpeed, "speed", "", "Travel speed, such as 15km/h, 4m/s, 30mph or 6kn (required)")
+	routeCmd.Flags().DurationVar(&routeEvery, "every", time.Hour, "Travel time between sampled points")
+	routeCmd.Flags().StringVarP(&routeFormat, "format", "f", "", "Output format (full, json, summary, markdown)")
+	_ = routeCmd.MarkFlagRequired("speed")
+
+	rootCmd.AddCommand(routeCmd)
+}
+
+func runRoute(cmd *cobra.Command, args []string) error {
+	speed, err := route.ParseSpeed(routeSpeed)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	depart, err := route.ParseDeparture(routeDepart, now)
+	if err != nil {
+		return err
+	}
+	if routeEvery < time.Minute {
+		return fmt.Errorf("--every must be at least 1m")
+	}
+
+	track, err := route.LoadGPX(args[0])
+	if err != nil {
+		return err
+	}
+	r := route.Plan(track, depart, speed, routeEvery)
+	if len(r.Points) > maxRoutePoints {
+		return fmt.Errorf("%d points to sample along %.1f km; use a longer --every (at most %d points)", len(r.Points), r.Length, maxRoutePoints)
+	}
+
+	format := routeFormat
+	if format == "" {
+		format = cfg.DefaultFormat
+	}
+	if format == "" {
+		format = "full"
+	}
+	fmtr, err := formatter.GetFormatter(format)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	if err := forecastRoute(ctx, getWeatherClient(), r, now); err != nil {
+		return err
+	}
+	nameRoutePoints(r)
+
+	opts := formatter.Options{
+		NoColor:    cfg.NoColor,
+		NoEmoji:    cfg.NoEmoji,
+		TimeFormat: "2006-01-02 15:04:05",
+	}
+	return fmtr.FormatRoute(os.Stdout, r, opts)
+}
+
+// forecastRoute sets the forecast of every point along r. Points in the
+// same grid cell of about a kilometer share one request, and every request
+// asks for the same whole number of days, so that repeated runs are served
+// from the cache. It fails only when no forecast could be fetched.
+func forecastRoute(ctx context.Context, client api.WeatherClient, r *models.Route, now time.Time) error {
+	if len(r.Points) == 0 {
+		return nil
+	}
+
+	last := r.Points[len(r.Points)-1].Arrival
+	days := max(1, int(math.Ceil(last.Sub(now).Hours()/24)))
+	hours := days * 24
+
+	cells := make(map[string]int)
+	var locs []*models.Location
+	cell := make([]int, len(r.Points))
+	for i, p := range r.Points {
+		lat, lon := math.Round(p.Location.Latitude*100)/100, math.Round(p.Location.Longitude*100)/100
+		key := fmt.Sprintf("%.2f,%.2f", lat, lon)
+		j, ok := cells[key]
+		if !ok {
+			j = len(locs)
+			cells[key] = j
+			locs = append(locs, &models.Location{Latitude: lat, Longitude: lon, Elevation: p.Location.Elevation})
+		}
+		cell[i] = j
+	}
+
+	results := fetchAll(ctx, locs, maxParallelRequests, func(ctx context.Context, loc *models.Location) (*models.Forecast, error) {
+		return client.GetHourlyForecast(ctx, loc, hours)
+	})
+	if err := checkResults(results); err != nil {
+		return fmt.Errorf("failed to fetch forecast: %w", err)
+	}
+
+	failed := 0
+	for i := range r.Points {
+		result := results[cell[i]]
+		if result.Err != nil {
+			failed++
+			continue
+		}
+		r.Points[i].Forecast = result.Data.At(r.Points[i].Arrival)
+	}
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "⚠️  Could not fetch the forecast for %d of %d points\n", failed, len(r.Points))
+	}
+	return nil
+}
+
+// nameRoutePoints names the points along r after saved locations within
+// the snap radius, or else the nearest place in the offline gazetteer
+func nameRoutePoints(r *models.Route) {
+	gazetteer, _ := geocode.NewGazetteer()
+	for _, p := range r.Points {
+		if saved := snapLocation(p.Location); saved != nil {
+			p.Location.Name = saved.Name
+			continue
+		}
+		if gazetteer == nil {
+			continue
+		}
+		if nearby, err := gazetteer.Reverse(context.Background(), p.Location.Latitude, p.Location.Longitude); err == nil {
+			p.Location.Name = nearby.Label()
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/api"
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+// fakeForecastClient serves hourly forecasts starting at start, where
+// the temperature is the latitude, and records the requests
+type fakeForecastClient struct {
+	api.WeatherClient
+
+	start time.Time
+	fail  float64 // latitude whose requests fail
+
+	mu       sync.Mutex
+	requests []*models.Location
+	hours    map[int]bool
+}
+
+func (c *fakeForecastClient) GetHourlyForecast(ctx context.Context, loc *models.Location, hours int) (*models.Forecast, error) {
+	c.mu.Lock()
+	c.requests = append(c.requests, loc)
+	c.hours[hours] = true
+	c.mu.Unlock()
+
+	if loc.Latitude == c.fail {
+		return nil, errors.New("unavailable")
+	}
+
+	forecast := &models.Forecast{Location: loc}
+	for i := 0; i < hours; i++ {
+		forecast.Hours = append(forecast.Hours, models.HourlyForecast{
+			Time:        c.start.Add(time.Duration(i) * time.Hour),
+			Temperature: loc.Latitude,
+		})
+	}
+	return forecast, nil
+}
+
+func TestForecastRoute(t *testing.T) {
+	now := time.Date(2026, 6, 1, 7, 40, 0, 0, time.UTC)
+	depart := time.Date(2026, 6, 1, 8, 0, 0, 0, time.UTC)
+
+	point := func(lat float64, minutes int) models.RoutePoint {
+		return models.RoutePoint{
+			Location: &models.Location{Latitude: lat, Longitude: 10},
+			Arrival:  depart.Add(time.Duration(minutes) * time.Minute),
+		}
+	}
+	r := &models.Route{Points: []models.RoutePoint{
+		point(59.001, 0),
+		point(59.003, 20), // same grid cell as the first point
+		point(59.05, 70),
+		point(59.2, 130), // its requests fail
+		point(59.3, 26*60),
+	}}
+
+	client := &fakeForecastClient{start: now.Truncate(time.Hour), fail: 59.2, hours: make(map[int]bool)}
+	if err := forecastRoute(context.Background(), client, r, now); err != nil {
+		t.Fatalf("forecastRoute() failed: %v", err)
+	}
+
+	if len(client.requests) != 4 {
+		t.Errorf("forecastRoute() made %d requests; want 4", len(client.requests))
+	}
+	if len(client.hours) != 1 || !client.hours[48] {
+		t.Errorf("forecastRoute() asked for %v hours; want 48 for every request", client.hours)
+	}
+
+	want := []struct {
+		temperature float64
+		hour        string
+	}{
+		{59.0, "08:00"},
+		{59.0, "08:00"},
+		{59.05, "09:00"},
+		{0, ""},
+		{59.3, "10:00"},
+	}
+	for i, p := range r.Points {
+		if want[i].hour == "" {
+			if p.Forecast != nil {
+				t.Errorf("point %d forecast = %+v; want none", i, p.Forecast)
+			}
+			continue
+		}
+		if p.Forecast == nil {
+			t.Errorf("point %d has no forecast", i)
+			continue
+		}
+		if p.Forecast.Temperature != want[i].temperature || p.Forecast.Time.Format("15:04") != want[i].hour {
+			t.Errorf("point %d forecast = %.2f°C at %s; want %.2f°C at %s",
+				i, p.Forecast.Temperature, p.Forecast.Time.Format("15:04"), want[i].temperature, want[i].hour)
+		}
+	}
+
+	// Only failures fail the route
+	client = &fakeForecastClient{start: now, fail: 59.2, hours: make(map[int]bool)}
+	failing := &models.Route{Points: []models.RoutePoint{point(59.2, 0)}}
+	if err := forecastRoute(context.Background(), client, failing, now); err == nil {
+		t.Error("forecastRoute() without any forecast expected error but got none")
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/api/met"
+	"github.com/kristofferrisa/sky-cli/internal/cache"
+	"github.com/kristofferrisa/sky-cli/internal/metrics"
+	"github.com/kristofferrisa/sky-cli/internal/models"
+	"github.com/kristofferrisa/sky-cli/internal/server"
+	"github.com/spf13/cobra"
+)
+
+// shutdownTimeout is how long requests in flight get to finish when the
+// server stops
+const shutdownTimeout = 10 * time.Second
+
+var (
+	// Serve command flags
+	serveAddr      string
+	serveOrigins   []string
+	serveQuiet     bool
+	serveNoMetrics bool
+	serveDrain     time.Duration
+)
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the weather as a JSON REST API",
+	Long: `Serve the weather as a JSON REST API, so that dashboards, phones and scripts
+on the network can share one cached sky instance.
+
+  GET /v1/current              current weather
+  GET /v1/forecast?hours=24    hourly forecast (default 12, at most 240)
+  GET /v1/daily?days=7         daily forecast (default 7, at most 10)
+  GET /healthz                 200 while sky runs
+  GET /readyz                  200 while sky accepts requests
+  GET /metrics                 Prometheus metrics
+
+The weather endpoints take ?location= with a saved location, a place name or
+coordinates, or ?lat= and ?lon=, and use the default location without either.
+They return the same JSON as --format json.
+
+Responses come from the cache, and concurrent requests for the same data make
+one request to MET Norway. Every response has an ETag, so clients that send
+If-None-Match get 304 Not Modified when nothing changed. Browsers on the
+origins given with --cors-origin may call the API.
+
+/metrics reports the weather at every saved location, labeled by location:
+temperature, feels-like, humidity, pressure, wind, cloud cover, precipitation
+and the forecast 1, 6 and 24 hours ahead. Scrapes read the cache, so MET
+Norway is asked at most once per cache TTL however often Prometheus scrapes.
+It also reports the latency and errors of requests to MET Norway and the cache
+hit ratio.
+
+Stop with Ctrl+C or SIGTERM; requests in flight get 10 seconds to finish. With
+--drain, /readyz reports not ready for that long first while requests are still
+served, so that load balancers stop sending new ones.
+
+Examples:
+  sky serve                                      # On port 8080
+  sky serve --addr 127.0.0.1:9000
+  sky serve --cors-origin https://dash.example.com
+  sky serve --drain 5s                           # Behind a load balancer
+  curl localhost:8080/metrics
+  curl 'localhost:8080/v1/current?location=oslo'`,
+	RunE: runServe,
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveAddr, "addr", ":8080", "Address to listen on")
+	serveCmd.Flags().StringArrayVar(&serveOrigins, "cors-origin", nil, "Origin allowed to call the API from a browser, or * for any (repeatable)")
+	serveCmd.Flags().BoolVarP(&serveQuiet, "quiet", "q", false, "Do not log requests")
+	serveCmd.Flags().BoolVar(&serveNoMetrics, "no-metrics", false, "Do not serve Prometheus metrics on /metrics")
+	serveCmd.Flags().DurationVar(&serveDrain, "drain", 0, "How long to report not ready before stopping, while still serving requests")
+
+	rootCmd.AddCommand(serveCmd)
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	if serveDrain < 0 {
+		return fmt.Errorf("--drain must not be negative")
+	}
+
+	// The server always caches, in memory if not on disk, so that
+	// requests and scrapes do not all reach MET Norway
+	weatherCache := getCache()
+	if weatherCache == nil {
+		fmt.Fprintln(os.Stderr, "ℹ️  The cache is disabled (cache.enabled), so weather data is cached in memory while sky serves")
+		weatherCache = cache.NewMemoryCache()
+	}
+	stats := metrics.New()
+	client := met.NewCachedClientWithClient(recordHistory(met.NewClientWithTransport(stats.Transport(nil))), stats.Cache(weatherCache), cacheTTL())
+
+	s := &server.Server{
+		Client:  client,
+		Locate:  serverLocation,
+		Origins: serveOrigins,
+		MaxAge:  cacheTTL(),
+		Log:     os.Stderr,
+	}
+	if serveQuiet {
+		s.Log = nil
+	}
+	if !serveNoMetrics {
+		s.Metrics = &metrics.Exporter{
+			Client:    client,
+			Locations: cfg.Locations,
+			Metrics:   stats,
+		}
+	}
+
+	listener, err := net.Listen("tcp", serveAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", serveAddr, err)
+	}
+
+	httpServer := &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	served := make(chan error, 1)
+	go func() { served <- httpServer.Serve(listener) }()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	s.SetReady(true)
+	fmt.Fprintf(os.Stderr, "🌐 Serving the weather on http://%s (Ctrl+C to stop)\n", listener.Addr())
+
+	select {
+	case err := <-served:
+		return fmt.Errorf("server stopped: %w", err)
+	case <-ctx.Done():
+	}
+	// A second Ctrl+C stops at once
+	stop()
+
+	// Report not ready first and keep serving while load balancers notice,
+	// so that they stop sending requests before the listener closes
+	s.SetReady(false)
+	if serveDrain > 0 {
+		fmt.Fprintf(os.Stderr, "⏳ Draining for %s\n", serveDrain)
+		time.Sleep(serveDrain)
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to stop the server: %w", err)
+	}
+	if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	fmt.Fprintln(os.Stderr, "✓ Stopped serving")
+	return nil
+}
+
+// serverLocation finds the location a request asks for, without asking
+// anyone to choose between places with the same name
+func serverLocation(name string) (*models.Location, error) {
+	if name == "" {
+		return cfg.GetDefaultLocation()
+	}
+	return findLocation(name, false, false)
+}
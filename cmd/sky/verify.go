@@ -0,0 +1,207 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/history"
+	"github.com/kristofferrisa/sky-cli/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// defaultVerifyLeads are the lead times verified without --lead
+var defaultVerifyLeads = []time.Duration{
+	time.Hour, 3 * time.Hour, 6 * time.Hour, 12 * time.Hour, 24 * time.Hour, 48 * time.Hour, 72 * time.Hour,
+}
+
+var (
+	// Verify command flags
+	verifyLeads     []time.Duration
+	verifyVariables []string
+	verifyFormat    string
+)
+
+// verifyCmd represents the verify command
+var verifyCmd = &cobra.Command{
+	Use:   "verify [location]",
+	Short: "Measure how accurate past forecasts were",
+	Long: `Measure how accurate the forecasts in the history were, to know how far
+ahead to trust them.
+
+Every hour a forecast in the history was made --lead hours ahead of is compared
+with the analysis of that hour: the first hour of a later forecast, the closest
+to an observation MET Norway publishes. The errors are reported per variable
+and lead time as:
+
+  BIAS   the mean error; positive when the forecasts were too high
+  MAE    the mean absolute error, how far off the forecasts were on average
+  RMSE   the root mean square error, which weighs large misses more
+
+Lead times are rounded to the hour. Precipitation is only verified where the
+forecast is hourly, up to about 2.5 days ahead, and gusts where they are
+forecast. The history must be enabled, and verification needs forecasts fetched
+both ahead of and at the hours verified:
+
+  sky config set history.enabled true
+
+Examples:
+  sky verify oslo --lead 24h
+  sky verify --lead 6h,24h,48h --variable temperature,wind
+  sky verify stavern --from 2026-06-01 --format json`,
+	RunE: runVerify,
+}
+
+func init() {
+	addLocationFlags(verifyCmd)
+	verifyCmd.Flags().DurationSliceVar(&verifyLeads, "lead", nil, "Lead times to verify, in whole hours (default 1h,3h,6h,12h,24h,48h,72h)")
+	verifyCmd.Flags().StringSliceVar(&verifyVariables, "variable", nil, fmt.Sprintf("Variables to verify (%s; default: all)", strings.Join(history.VariableNames(), ", ")))
+	verifyCmd.Flags().StringVar(&historyFrom, "from", "", "Start of the period verified (2006-01-02 or 2006-01-02T15:04)")
+	verifyCmd.Flags().StringVar(&historyTo, "to", "", "End of the period verified, included")
+	verifyCmd.Flags().StringVarP(&verifyFormat, "format", "f", "full", "Output format (full, json)")
+	rootCmd.AddCommand(verifyCmd)
+}
+
+func runVerify(cmd *cobra.Command, args []string) error {
+	if verifyFormat != "full" && verifyFormat != "json" {
+		return fmt.Errorf("unknown format '%s' (available: full, json)", verifyFormat)
+	}
+
+	leads := verifyLeads
+	if len(leads) == 0 {
+		leads = defaultVerifyLeads
+	}
+	for _, lead := range leads {
+		if lead <= 0 || lead%time.Hour != 0 {
+			return fmt.Errorf("invalid lead time %s (must be a positive number of whole hours, such as 24h)", lead)
+		}
+	}
+
+	selected := history.Variables()
+	if len(verifyVariables) > 0 {
+		selected = nil
+		for _, name := range verifyVariables {
+			variable, err := history.FindVariable(strings.TrimSpace(name))
+			if err != nil {
+				return err
+			}
+			selected = append(selected, variable)
+		}
+	}
+
+	filter, err := historyFilter()
+	if err != nil {
+		return err
+	}
+	locs, err := resolveLocations(cmd, args)
+	if err != nil {
+		return err
+	}
+	if locs != nil {
+		return fmt.Errorf("verify works on one location at a time")
+	}
+	if filter.Location, err = resolveLocation(cmd, args); err != nil {
+		return err
+	}
+
+	snapshots, err := readHistory(filter)
+	if err != nil {
+		return err
+	}
+	scores := history.Verify(snapshots, selected, leads)
+
+	samples := 0
+	for _, s := range scores {
+		samples += s.Samples
+	}
+	if samples == 0 {
+		return fmt.Errorf("no forecasts to verify for %s in the history%s; it needs forecasts fetched ahead of hours that were fetched again later", filter.Location, periodText(filter))
+	}
+
+	if verifyFormat == "json" {
+		return writeVerifyJSON(os.Stdout, filter.Location, scores)
+	}
+	return printVerify(os.Stdout, filter.Location, scores)
+}
+
+// formatLead formats a lead time in hours, such as "24h"
+func formatLead(lead time.Duration) string {
+	return fmt.Sprintf("%dh", int(lead.Hours()))
+}
+
+// printVerify prints the scores with samples as a table, grouped by
+// variable
+func printVerify(out io.Writer, loc *models.Location, scores []history.Score) error {
+	fmt.Fprintf(out, "%s\n\n", loc)
+
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "VARIABLE\tLEAD\tSAMPLES\tBIAS\tMAE\tRMSE")
+	fmt.Fprintln(w, "────────\t────\t───────\t────\t───\t────")
+	skipped := false
+	shown := ""
+	for _, s := range scores {
+		if s.Samples == 0 {
+			skipped = true
+			continue
+		}
+		name := s.Variable.Name
+		if name == shown {
+			name = ""
+		}
+		shown = s.Variable.Name
+		unit := s.Variable.Unit
+		fmt.Fprintf(w, "%s\t%s\t%d\t%+.1f %s\t%.1f %s\t%.1f %s\n",
+			name, formatLead(s.Lead), s.Samples, s.Bias, unit, s.MAE, unit, s.RMSE, unit)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(out, "\nBias is the forecast minus the analysis: positive when the forecasts were too high.")
+	if skipped {
+		fmt.Fprintln(out, "Variables and lead times without forecasts to compare are left out.")
+	}
+	return nil
+}
+
+// jsonScore is a score in the JSON output, without errors when there were
+// no samples
+type jsonScore struct {
+	Variable  string   `json:"variable"`
+	Unit      string   `json:"unit"`
+	LeadHours int      `json:"lead_hours"`
+	Samples   int      `json:"samples"`
+	Bias      *float64 `json:"bias"`
+	MAE       *float64 `json:"mae"`
+	RMSE      *float64 `json:"rmse"`
+}
+
+func writeVerifyJSON(out io.Writer, loc *models.Location, scores []history.Score) error {
+	result := struct {
+		Location *models.Location `json:"location"`
+		Scores   []jsonScore      `json:"scores"`
+	}{Location: loc, Scores: make([]jsonScore, len(scores))}
+
+	round := func(v float64) *float64 {
+		v = math.Round(v*100) / 100
+		return &v
+	}
+	for i, s := range scores {
+		result.Scores[i] = jsonScore{
+			Variable:  s.Variable.Name,
+			Unit:      s.Variable.Unit,
+			LeadHours: int(s.Lead.Hours()),
+			Samples:   s.Samples,
+		}
+		if s.Samples > 0 {
+			result.Scores[i].Bias = round(s.Bias)
+			result.Scores[i].MAE = round(s.MAE)
+			result.Scores[i].RMSE = round(s.RMSE)
+		}
+	}
+	return writeIndentedJSON(out, result)
+}
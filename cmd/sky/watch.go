@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/formatter"
+	"github.com/kristofferrisa/sky-cli/internal/models"
+	"github.com/kristofferrisa/sky-cli/internal/watch"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Watch command flags
+	watchInterval   time.Duration
+	watchRuleNames  []string
+	watchWhen       []string
+	watchThresholds []float64
+	watchNotify     string
+	watchWebhook    string
+	watchTo         []string
+)
+
+// watchCmd represents the watch command
+var watchCmd = &cobra.Command{
+	Use:   "watch [location]",
+	Short: "Keep watching the weather and report changes",
+	Long: `Keep polling the weather and report only meaningful changes:
+
+  rule_started         a rule starts matching the forecast (see sky check)
+  rule_stopped         a rule no longer matches
+  symbol_changed       the weather symbol changes, such as cloudy to light rain
+  temperature_crossed  the temperature crosses a --threshold
+
+Every event is written to stdout as a line of JSON (NDJSON). With --notify,
+each event also runs a local command with a title and a text as its last two
+arguments, such as notify-send for desktop notifications. With --webhook, each
+event is posted as JSON to a URL. With --to, each event is sent to a notifier
+from the config file, such as a chat room or a phone (see sky notify).
+
+Rules that match when the watch starts are reported right away; the symbol and
+temperature are reported from the first change. The temperature has to move
+0.5°C past a threshold to cross it again, so readings around it stay quiet.
+
+Weather data is cached, so the interval is at least the cache TTL: polling more
+often would only see the same data. Stop with Ctrl+C or SIGTERM.
+
+Examples:
+  sky watch                                      # Default location, every 15m
+  sky watch stavern --interval 30m
+  sky watch coast --threshold 0 --threshold 25   # Freezing and hot
+  sky watch --rule frost --notify notify-send    # Desktop notifications
+  sky watch --webhook https://example.com/hook
+  sky watch --rule frost --to team               # Post to a chat room
+  sky watch | jq -r .text`,
+	RunE: runWatch,
+}
+
+func init() {
+	addLocationFlags(watchCmd)
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", 15*time.Minute, "Time between polls")
+	watchCmd.Flags().StringArrayVarP(&watchRuleNames, "rule", "r", nil, "Rule to watch (repeatable; default: all rules)")
+	watchCmd.Flags().StringArrayVar(&watchWhen, "when", nil, "Watch a rule given on the command line (repeatable)")
+	watchCmd.Flags().Float64SliceVar(&watchThresholds, "threshold", []float64{0}, "Temperature in °C whose crossing is reported (repeatable)")
+	watchCmd.Flags().StringVar(&watchNotify, "notify", "", "Command to run for every event, such as notify-send")
+	watchCmd.Flags().StringVar(&watchWebhook, "webhook", "", "URL to post every event to as JSON")
+	watchCmd.Flags().StringArrayVar(&watchTo, "to", nil, "Notifier to send every event to (repeatable)")
+
+	rootCmd.AddCommand(watchCmd)
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	if watchInterval < time.Minute {
+		return fmt.Errorf("--interval must be at least 1m")
+	}
+
+	// Watching needs no rules; without any, only the symbol and the
+	// temperature are watched
+	var selected []namedRule
+	if len(watchRuleNames) > 0 || len(watchWhen) > 0 || len(cfg.Rules) > 0 {
+		var err error
+		if selected, err = selectRules(watchRuleNames, watchWhen); err != nil {
+			return err
+		}
+	}
+
+	locs, err := resolveLocations(cmd, args)
+	if err != nil {
+		return err
+	}
+	if locs == nil {
+		loc, err := resolveLocation(cmd, args)
+		if err != nil {
+			return err
+		}
+		locs = []*models.Location{loc}
+	}
+
+	sinks := []watch.Sink{watch.NewNDJSONSink(os.Stdout)}
+	if watchNotify != "" {
+		sink, err := watch.NewCommandSink(watchNotify)
+		if err != nil {
+			return err
+		}
+		sinks = append(sinks, sink)
+	}
+	if watchWebhook != "" {
+		sinks = append(sinks, watch.NewWebhookSink(watchWebhook))
+	}
+	notifiers, err := selectNotifiers(watchTo)
+	if err != nil {
+		return err
+	}
+	opts := formatter.Options{
+		NoColor:    cfg.NoColor,
+		NoEmoji:    cfg.NoEmoji,
+		TimeFormat: "2006-01-02 15:04:05",
+	}
+	for _, n := range notifiers {
+		sinks = append(sinks, watch.NewNotifierSink(n.notifier, opts))
+	}
+
+	interval := watchInterval
+	if ttl := cacheTTL(); cfg.Cache.Enabled && interval < ttl {
+		fmt.Fprintf(os.Stderr, "ℹ️  Polling every %s, the cache TTL (cache.ttl_minutes)\n", ttl)
+		interval = ttl
+	}
+
+	w := &watch.Watcher{
+		Client:     getWeatherClient(),
+		Locations:  locs,
+		Thresholds: watchThresholds,
+		Interval:   interval,
+		Sinks:      sinks,
+		Log:        os.Stderr,
+	}
+	for _, r := range selected {
+		w.Rules = append(w.Rules, watch.Rule{Name: r.name, Rule: r.rule})
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Fprintf(os.Stderr, "👀 Watching %d location(s) with %d rule(s) every %s (Ctrl+C to stop)\n", len(locs), len(w.Rules), interval)
+	if err := w.Run(ctx); err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "✓ Stopped watching")
+	return nil
+}
@@ -6,6 +6,15 @@ import (
 	"github.com/kristofferrisa/sky-cli/internal/models"
 )
 
+// ProviderMET is the Norwegian Meteorological Institute (api.met.no),
+// the default weather provider
+const ProviderMET = "met"
+
+// Providers returns the names of the weather providers sky can query
+func Providers() []string {
+	return []string{ProviderMET}
+}
+
 // WeatherClient is the interface for weather API clients
 type WeatherClient interface {
 	GetCurrentWeather(ctx context.Context, loc *models.Location) (*models.Weather, error)
@@ -10,7 +10,9 @@ import (
 	"github.com/kristofferrisa/sky-cli/internal/models"
 )
 
-// CachedClient wraps the MET client with caching
+// CachedClient wraps the MET client with caching. Cache keys include the
+// product, so entries cached from another variant, which may lack
+// fields, are fetched again.
 type CachedClient struct {
 	client *Client
 	cache  cache.Cache
@@ -19,8 +21,14 @@ type CachedClient struct {
 
 // NewCachedClient creates a new cached MET client
 func NewCachedClient(cache cache.Cache, ttl time.Duration) *CachedClient {
+	return NewCachedClientWithClient(NewClient(), cache, ttl)
+}
+
+// NewCachedClientWithClient creates a cached MET client that fetches
+// with client
+func NewCachedClientWithClient(client *Client, cache cache.Cache, ttl time.Duration) *CachedClient {
 	return &CachedClient{
-		client: NewClient(),
+		client: client,
 		cache:  cache,
 		ttl:    ttl,
 	}
@@ -28,7 +36,7 @@ func NewCachedClient(cache cache.Cache, ttl time.Duration) *CachedClient {
 
 // GetCurrentWeather fetches current weather with caching
 func (c *CachedClient) GetCurrentWeather(ctx context.Context, loc *models.Location) (*models.Weather, error) {
-	key := fmt.Sprintf("weather:current:%f:%f", loc.Latitude, loc.Longitude)
+	key := fmt.Sprintf("weather:%s:current:%f:%f", product, loc.Latitude, loc.Longitude)
 
 	// Try to get from cache
 	if data, err := c.cache.Get(key); err == nil {
@@ -57,7 +65,7 @@ func (c *CachedClient) GetCurrentWeather(ctx context.Context, loc *models.Locati
 
 // GetHourlyForecast fetches hourly forecast with caching
 func (c *CachedClient) GetHourlyForecast(ctx context.Context, loc *models.Location, hours int) (*models.Forecast, error) {
-	key := fmt.Sprintf("weather:forecast:%f:%f:%d", loc.Latitude, loc.Longitude, hours)
+	key := fmt.Sprintf("weather:%s:forecast:%f:%f:%d", product, loc.Latitude, loc.Longitude, hours)
 
 	// Try to get from cache
 	if data, err := c.cache.Get(key); err == nil {
@@ -85,7 +93,7 @@ func (c *CachedClient) GetHourlyForecast(ctx context.Context, loc *models.Locati
 
 // GetDailySummary fetches daily summary with caching
 func (c *CachedClient) GetDailySummary(ctx context.Context, loc *models.Location) (*models.DailySummary, error) {
-	key := fmt.Sprintf("weather:summary:%f:%f", loc.Latitude, loc.Longitude)
+	key := fmt.Sprintf("weather:%s:summary:%f:%f", product, loc.Latitude, loc.Longitude)
 
 	// Try to get from cache
 	if data, err := c.cache.Get(key); err == nil {
@@ -113,7 +121,7 @@ func (c *CachedClient) GetDailySummary(ctx context.Context, loc *models.Location
 
 // GetDailyForecast fetches daily forecast with caching
 func (c *CachedClient) GetDailyForecast(ctx context.Context, loc *models.Location, days int) (*models.DailyForecast, error) {
-	key := fmt.Sprintf("weather:daily:%f:%f:%d", loc.Latitude, loc.Longitude, days)
+	key := fmt.Sprintf("weather:%s:daily:%f:%f:%d", product, loc.Latitude, loc.Longitude, days)
 
 	// Try to get from cache
 	if data, err := c.cache.Get(key); err == nil {
@@ -11,8 +11,13 @@ import (
 	"github.com/kristofferrisa/sky-cli/internal/models"
 )
 
+// product is the Locationforecast variant sky requests. Unlike compact,
+// complete includes wind gusts and the probability of precipitation,
+// which forecasts and rules use.
+const product = "complete"
+
 const (
-	baseURL   = "https://api.met.no/weatherapi/locationforecast/2.0/compact"
+	baseURL   = "https://api.met.no/weatherapi/locationforecast/2.0/" + product
 	userAgent = "sky-cli/1.0 github.com/kristofferrisa/sky-cli"
 )
 
@@ -20,6 +25,13 @@ const (
 type Client struct {
 	httpClient *http.Client
 	userAgent  string
+	recorder   Recorder
+}
+
+// Recorder archives the forecasts a client fetches. Recording must not
+// fail a forecast, so recorders report their own errors.
+type Recorder interface {
+	Record(snapshot *models.Snapshot)
 }
 
 // NewClient creates a new MET Norway API client
@@ -32,6 +44,19 @@ func NewClient() *Client {
 	}
 }
 
+// NewClientWithTransport creates a MET Norway API client that sends its
+// requests through transport, such as one that records metrics
+func NewClientWithTransport(transport http.RoundTripper) *Client {
+	c := NewClient()
+	c.httpClient.Transport = transport
+	return c
+}
+
+// SetRecorder makes the client pass every forecast it fetches to r
+func (c *Client) SetRecorder(r Recorder) {
+	c.recorder = r
+}
+
 // GetForecast fetches weather forecast for the given coordinates
 func (c *Client) GetForecast(ctx context.Context, lat, lon float64) (*Response, error) {
 	url := fmt.Sprintf("%s?lat=%.4f&lon=%.4f", baseURL, lat, lon)
@@ -63,9 +88,21 @@ func (c *Client) GetForecast(ctx context.Context, lat, lon float64) (*Response,
 	return &result, nil
 }
 
+// fetch fetches the forecast for a location and records it
+func (c *Client) fetch(ctx context.Context, loc *models.Location) (*Response, error) {
+	resp, err := c.GetForecast(ctx, loc.Latitude, loc.Longitude)
+	if err != nil {
+		return nil, err
+	}
+	if c.recorder != nil && len(resp.Properties.Timeseries) > 0 {
+		c.recorder.Record(resp.Snapshot(loc, time.Now()))
+	}
+	return resp, nil
+}
+
 // GetCurrentWeather fetches current weather conditions
 func (c *Client) GetCurrentWeather(ctx context.Context, loc *models.Location) (*models.Weather, error) {
-	resp, err := c.GetForecast(ctx, loc.Latitude, loc.Longitude)
+	resp, err := c.fetch(ctx, loc)
 	if err != nil {
 		return nil, err
 	}
@@ -107,7 +144,7 @@ func (c *Client) GetCurrentWeather(ctx context.Context, loc *models.Location) (*
 
 // GetHourlyForecast fetches hourly forecast for the specified number of hours
 func (c *Client) GetHourlyForecast(ctx context.Context, loc *models.Location, hours int) (*models.Forecast, error) {
-	resp, err := c.GetForecast(ctx, loc.Latitude, loc.Longitude)
+	resp, err := c.fetch(ctx, loc)
 	if err != nil {
 		return nil, err
 	}
@@ -132,21 +169,30 @@ func (c *Client) GetHourlyForecast(ctx context.Context, loc *models.Location, ho
 
 		symbol := ""
 		precipitation := 0.0
+		var probability *float64
 		if ts.Data.Next1Hours != nil {
 			symbol = ts.Data.Next1Hours.Summary.SymbolCode
 			precipitation = ts.Data.Next1Hours.Details.PrecipitationAmount
+			probability = ts.Data.Next1Hours.Details.ProbabilityOfPrecipitation
 		} else if ts.Data.Next6Hours != nil {
 			symbol = ts.Data.Next6Hours.Summary.SymbolCode
 			precipitation = ts.Data.Next6Hours.Details.PrecipitationAmount
+			probability = ts.Data.Next6Hours.Details.ProbabilityOfPrecipitation
 		}
 
+		gust := ts.Data.Instant.Details.WindSpeedOfGust
 		hourly := models.HourlyForecast{
-			Time:          ts.Time,
-			Temperature:   ts.Data.Instant.Details.AirTemperature,
-			Humidity:      ts.Data.Instant.Details.RelativeHumidity,
-			WindSpeed:     ts.Data.Instant.Details.WindSpeed,
-			Precipitation: precipitation,
-			Symbol:        symbol,
+			Time:                        ts.Time,
+			Temperature:                 ts.Data.Instant.Details.AirTemperature,
+			Humidity:                    ts.Data.Instant.Details.RelativeHumidity,
+			WindSpeed:                   ts.Data.Instant.Details.WindSpeed,
+			WindGust:                    valueOf(gust),
+			HasWindGust:                 gust != nil,
+			WindDir:                     ts.Data.Instant.Details.WindFromDirection,
+			Precipitation:               precipitation,
+			PrecipitationProbability:    valueOf(probability),
+			HasPrecipitationProbability: probability != nil,
+			Symbol:                      symbol,
 		}
 
 		forecast.Hours = append(forecast.Hours, hourly)
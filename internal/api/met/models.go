@@ -1,6 +1,10 @@
 package met
 
-import "time"
+import (
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
 
 // Response represents the root structure of MET Norway API response
 type Response struct {
@@ -59,14 +63,15 @@ type Instant struct {
 
 // InstantDetails contains the actual instant weather values
 type InstantDetails struct {
-	AirPressureAtSeaLevel    float64 `json:"air_pressure_at_sea_level"`
-	AirTemperature           float64 `json:"air_temperature"`
-	CloudAreaFraction        float64 `json:"cloud_area_fraction"`
-	RelativeHumidity         float64 `json:"relative_humidity"`
-	WindFromDirection        float64 `json:"wind_from_direction"`
-	WindSpeed                float64 `json:"wind_speed"`
-	FogAreaFraction          float64 `json:"fog_area_fraction,omitempty"`
-	UltravioletIndexClearSky float64 `json:"ultraviolet_index_clear_sky,omitempty"`
+	AirPressureAtSeaLevel    float64  `json:"air_pressure_at_sea_level"`
+	AirTemperature           float64  `json:"air_temperature"`
+	CloudAreaFraction        float64  `json:"cloud_area_fraction"`
+	RelativeHumidity         float64  `json:"relative_humidity"`
+	WindFromDirection        float64  `json:"wind_from_direction"`
+	WindSpeed                float64  `json:"wind_speed"`
+	WindSpeedOfGust          *float64 `json:"wind_speed_of_gust,omitempty"`
+	FogAreaFraction          float64  `json:"fog_area_fraction,omitempty"`
+	UltravioletIndexClearSky float64  `json:"ultraviolet_index_clear_sky,omitempty"`
 }
 
 // NextNHours contains forecast for the next N hours
@@ -82,9 +87,52 @@ type Summary struct {
 
 // ForecastDetails contains forecast-specific details
 type ForecastDetails struct {
-	PrecipitationAmount        float64 `json:"precipitation_amount,omitempty"`
-	PrecipitationAmountMax     float64 `json:"precipitation_amount_max,omitempty"`
-	PrecipitationAmountMin     float64 `json:"precipitation_amount_min,omitempty"`
-	ProbabilityOfPrecipitation float64 `json:"probability_of_precipitation,omitempty"`
-	ProbabilityOfThunder       float64 `json:"probability_of_thunder,omitempty"`
+	PrecipitationAmount        float64  `json:"precipitation_amount,omitempty"`
+	PrecipitationAmountMax     float64  `json:"precipitation_amount_max,omitempty"`
+	PrecipitationAmountMin     float64  `json:"precipitation_amount_min,omitempty"`
+	ProbabilityOfPrecipitation *float64 `json:"probability_of_precipitation,omitempty"`
+	ProbabilityOfThunder       float64  `json:"probability_of_thunder,omitempty"`
+}
+
+// Snapshot converts the response to a snapshot of the forecast for loc
+func (r *Response) Snapshot(loc *models.Location, fetchedAt time.Time) *models.Snapshot {
+	snapshot := &models.Snapshot{
+		Location:  loc,
+		IssuedAt:  r.Properties.Meta.UpdatedAt,
+		FetchedAt: fetchedAt,
+		Hours:     make([]models.SnapshotHour, len(r.Properties.Timeseries)),
+	}
+	for i, ts := range r.Properties.Timeseries {
+		details := ts.Data.Instant.Details
+		hour := models.SnapshotHour{
+			Time:        ts.Time,
+			Temperature: details.AirTemperature,
+			Humidity:    details.RelativeHumidity,
+			Pressure:    details.AirPressureAtSeaLevel,
+			CloudCover:  details.CloudAreaFraction,
+			WindSpeed:   details.WindSpeed,
+			WindGust:    valueOf(details.WindSpeedOfGust),
+			WindDir:     details.WindFromDirection,
+		}
+		next := ts.Data.Next1Hours
+		if next == nil {
+			next = ts.Data.Next6Hours
+		}
+		if next != nil {
+			hour.Precipitation = next.Details.PrecipitationAmount
+			hour.PrecipitationProbability = valueOf(next.Details.ProbabilityOfPrecipitation)
+			hour.Symbol = next.Summary.SymbolCode
+		}
+		snapshot.Hours[i] = hour
+	}
+	return snapshot
+}
+
+// valueOf returns the value of a field that MET may leave out, or 0 when
+// it is missing
+func valueOf(v *float64) float64 {
+	if v == nil {
+		return 0
+	}
+	return *v
 }
@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// MemoryCache implements in-memory caching for long-running commands,
+// such as sky serve when the file cache is disabled
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+// NewMemoryCache creates a new in-memory cache
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{
+		entries: make(map[string]cacheEntry),
+	}
+}
+
+// Get retrieves a value from cache
+func (c *MemoryCache) Get(key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, ErrCacheMiss
+	}
+	if time.Now().After(entry.ExpiresAt) {
+		delete(c.entries, key)
+		return nil, ErrCacheExpired
+	}
+	return entry.Value, nil
+}
+
+// Set stores a value in cache with a TTL. Expired entries are dropped
+// along the way, so the cache does not grow without bound.
+func (c *MemoryCache) Set(key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for k, entry := range c.entries {
+		if now.After(entry.ExpiresAt) {
+			delete(c.entries, k)
+		}
+	}
+
+	c.entries[key] = cacheEntry{
+		Value:     append([]byte(nil), value...),
+		ExpiresAt: now.Add(ttl),
+	}
+	return nil
+}
+
+// Delete removes a value from cache
+func (c *MemoryCache) Delete(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+	return nil
+}
+
+// Clear removes all cached values
+func (c *MemoryCache) Clear() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]cacheEntry)
+	return nil
+}
+
+// Has checks if a key exists and is not expired
+func (c *MemoryCache) Has(key string) bool {
+	_, err := c.Get(key)
+	return err == nil
+}
@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryCache(t *testing.T) {
+	cache := NewMemoryCache()
+
+	t.Run("Set and Get", func(t *testing.T) {
+		value := []byte("test-value")
+		if err := cache.Set("test-key", value, time.Hour); err != nil {
+			t.Fatalf("Set() failed: %v", err)
+		}
+
+		// The cache keeps its own copy
+		value[0] = 'X'
+		retrieved, err := cache.Get("test-key")
+		if err != nil {
+			t.Fatalf("Get() failed: %v", err)
+		}
+		if string(retrieved) != "test-value" {
+			t.Errorf("Get() = %s; want test-value", retrieved)
+		}
+	})
+
+	t.Run("Get non-existent key", func(t *testing.T) {
+		if _, err := cache.Get("non-existent-key"); err != ErrCacheMiss {
+			t.Errorf("Get() error = %v; want %v", err, ErrCacheMiss)
+		}
+	})
+
+	t.Run("Expired entry", func(t *testing.T) {
+		cache.Set("expired-key", []byte("expired-value"), time.Millisecond)
+		time.Sleep(10 * time.Millisecond)
+
+		if _, err := cache.Get("expired-key"); err != ErrCacheExpired {
+			t.Errorf("Get() error = %v; want %v", err, ErrCacheExpired)
+		}
+		if cache.Has("expired-key") {
+			t.Error("Has() = true for an expired key; want false")
+		}
+	})
+
+	t.Run("Expired entries are dropped", func(t *testing.T) {
+		cache.Set("short-key", []byte("value"), time.Millisecond)
+		time.Sleep(10 * time.Millisecond)
+		cache.Set("other-key", []byte("value"), time.Hour)
+
+		if _, ok := cache.entries["short-key"]; ok {
+			t.Error("Set() kept an expired entry")
+		}
+	})
+
+	t.Run("Delete and Clear", func(t *testing.T) {
+		cache.Set("delete-key", []byte("value"), time.Hour)
+		cache.Delete("delete-key")
+		if cache.Has("delete-key") {
+			t.Error("Has() = true after delete; want false")
+		}
+
+		cache.Set("clear-key", []byte("value"), time.Hour)
+		cache.Clear()
+		if cache.Has("clear-key") || cache.Has("test-key") {
+			t.Error("Has() = true after clear; want false")
+		}
+	})
+}
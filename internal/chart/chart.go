@@ -0,0 +1,251 @@
+// Package chart draws small charts for the terminal: line charts on a
+// canvas of braille dots, bars of eighth blocks, sparklines and wind
+// arrows. Every chart has a plain-ASCII form for terminals without
+// Unicode.
+package chart
+
+import (
+	"math"
+	"strings"
+)
+
+// Canvas is a grid of terminal cells to plot dots on. In braille, a cell
+// holds 2×4 dots; in ASCII, it holds one.
+type Canvas struct {
+	cols, rows int
+	ascii      bool
+
+	// dots are the braille dots set in each cell, as the bits of the
+	// braille pattern
+	dots []uint8
+
+	// series are the series with a dot in each cell, as bits
+	series []uint8
+}
+
+// brailleDots are the bits of the braille pattern of each dot in a cell,
+// by column and by row from the top
+var brailleDots = [2][4]uint8{
+	{0x01, 0x02, 0x04, 0x40},
+	{0x08, 0x10, 0x20, 0x80},
+}
+
+// asciiMarks are the marks of the series in ASCII, by series
+const asciiMarks = "*.o+"
+
+// NewCanvas returns an empty canvas of cols×rows cells
+func NewCanvas(cols, rows int, ascii bool) *Canvas {
+	return &Canvas{
+		cols:   cols,
+		rows:   rows,
+		ascii:  ascii,
+		dots:   make([]uint8, cols*rows),
+		series: make([]uint8, cols*rows),
+	}
+}
+
+// Size returns the width and the height of the canvas in dots
+func (c *Canvas) Size() (width, height int) {
+	if c.ascii {
+		return c.cols, c.rows
+	}
+	return 2 * c.cols, 4 * c.rows
+}
+
+// Set sets the dot at x, counted from the left, and y, counted from the
+// bottom, for a series from 0 to 3. Dots outside the canvas are ignored.
+func (c *Canvas) Set(x, y, series int) {
+	width, height := c.Size()
+	if x < 0 || x >= width || y < 0 || y >= height {
+		return
+	}
+	y = height - 1 - y
+	cell := x + y*c.cols
+	if !c.ascii {
+		cell = x/2 + y/4*c.cols
+		c.dots[cell] |= brailleDots[x%2][y%4]
+	}
+	c.series[cell] |= 1 << series
+}
+
+// Plot draws a series as a line through a value, scaled from 0 at the
+// bottom to 1 at the top, at every x. NaN values leave a gap. When every
+// is more than 1, only every so many x get a dot, for a dotted line.
+func (c *Canvas) Plot(values []float64, series, every int) {
+	_, height := c.Size()
+	top := float64(height - 1)
+	prev := -1
+	for x, v := range values {
+		if math.IsNaN(v) {
+			prev = -1
+			continue
+		}
+		y := int(math.Round(math.Max(0, math.Min(1, v)) * top))
+		if every > 1 {
+			if x%every == 0 {
+				c.Set(x, y, series)
+			}
+			continue
+		}
+
+		// Join steep steps with a vertical run of dots up or down from
+		// the previous one
+		c.Set(x, y, series)
+		if prev >= 0 && prev != y {
+			step := 1
+			if y < prev {
+				step = -1
+			}
+			for yy := prev + step; yy != y; yy += step {
+				c.Set(x, yy, series)
+			}
+		}
+		prev = y
+	}
+}
+
+// Lines returns the rows of the canvas from the top. paint styles a cell
+// given the series with a dot in it, as bits, and may be nil.
+func (c *Canvas) Lines(paint func(cell string, series uint8) string) []string {
+	lines := make([]string, c.rows)
+	for row := range lines {
+		var b strings.Builder
+		for col := 0; col < c.cols; col++ {
+			i := col + row*c.cols
+			cell := " "
+			switch {
+			case c.series[i] == 0:
+			case c.ascii:
+				cell = string(asciiMarks[firstSeries(c.series[i])])
+			default:
+				cell = string(rune(0x2800 + int(c.dots[i])))
+			}
+			if c.series[i] != 0 && paint != nil {
+				cell = paint(cell, c.series[i])
+			}
+			b.WriteString(cell)
+		}
+		lines[row] = b.String()
+	}
+	return lines
+}
+
+// firstSeries returns the lowest series set in bits
+func firstSeries(bits uint8) int {
+	for s := 0; s < 8; s++ {
+		if bits&(1<<s) != 0 {
+			return s
+		}
+	}
+	return 0
+}
+
+// blocks are the eighth blocks from empty to full
+var blocks = []rune(" ▁▂▃▄▅▆▇█")
+
+// Bars draws a bar per value, rows high, scaled so that top fills them.
+// It returns the rows from the top. In ASCII, a bar is drawn with # and
+// ends in a . when it fills less than half of its top row; any value
+// above zero shows.
+func Bars(values []float64, top float64, rows int, ascii bool) []string {
+	lines := make([]strings.Builder, rows)
+	for _, v := range values {
+		fill := 0.0
+		if top > 0 && v > 0 {
+			fill = math.Min(1, v/top) * float64(rows)
+		}
+		for row := 0; row < rows; row++ {
+			// How much of this row, counted from the bottom, is filled
+			part := math.Max(0, math.Min(1, fill-float64(rows-1-row)))
+			lines[row].WriteString(barCell(part, v > 0 && row == rows-1, ascii))
+		}
+	}
+
+	out := make([]string, rows)
+	for i := range lines {
+		out[i] = lines[i].String()
+	}
+	return out
+}
+
+// barCell returns a cell of a bar filled by part, from 0 to 1. A bottom
+// cell of a value above zero is never empty.
+func barCell(part float64, bottom bool, ascii bool) string {
+	if ascii {
+		switch {
+		case part >= 0.5:
+			return "#"
+		case part > 0 || bottom:
+			return "."
+		}
+		return " "
+	}
+	eighths := int(math.Round(part * 8))
+	if eighths == 0 && (part > 0 || bottom) {
+		eighths = 1
+	}
+	return string(blocks[eighths])
+}
+
+// asciiRamp are the levels of an ASCII sparkline, from low to high
+const asciiRamp = "_.-~^"
+
+// Sparkline draws a value per character, scaled between the lowest and the
+// highest of them, such as ▁▂▄▇█▆▃. NaN values leave a space.
+func Sparkline(values []float64, ascii bool) string {
+	levels := blocks[1:]
+	if ascii {
+		levels = []rune(asciiRamp)
+	}
+
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			lo, hi = math.Min(lo, v), math.Max(hi, v)
+		}
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		if math.IsNaN(v) {
+			b.WriteRune(' ')
+			continue
+		}
+		level := len(levels) / 2
+		if hi > lo {
+			level = int(math.Round((v - lo) / (hi - lo) * float64(len(levels)-1)))
+		}
+		b.WriteRune(levels[level])
+	}
+	return b.String()
+}
+
+// Resample averages values down to at most n, each the mean of an equal
+// share of them. Fewer values are returned as they are.
+func Resample(values []float64, n int) []float64 {
+	if len(values) <= n || n <= 0 {
+		return values
+	}
+	out := make([]float64, n)
+	for i := range out {
+		from, to := i*len(values)/n, (i+1)*len(values)/n
+		sum := 0.0
+		for _, v := range values[from:to] {
+			sum += v
+		}
+		out[i] = sum / float64(to-from)
+	}
+	return out
+}
+
+// Arrow returns an arrow pointing where a wind from a direction, in
+// degrees clockwise from north, blows to: a north wind points down. In
+// ASCII, it is one of v < ^ > and / \ for the diagonals.
+func Arrow(from float64, ascii bool) string {
+	arrows := []string{"↓", "↙", "←", "↖", "↑", "↗", "→", "↘"}
+	if ascii {
+		arrows = []string{"v", "/", "<", "\\", "^", "/", ">", "\\"}
+	}
+	sector := int(math.Round(math.Mod(math.Mod(from, 360)+360, 360)/45)) % 8
+	return arrows[sector]
+}
@@ -0,0 +1,133 @@
+package chart
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestCanvas(t *testing.T) {
+	t.Run("Braille", func(t *testing.T) {
+		c := NewCanvas(2, 1, false)
+		if w, h := c.Size(); w != 4 || h != 4 {
+			t.Fatalf("Size() = %d×%d; want 4×4", w, h)
+		}
+		c.Set(0, 0, 0) // bottom left
+		c.Set(1, 3, 0) // top right of the first cell
+		c.Set(3, 1, 1)
+		c.Set(4, 0, 0) // outside
+		got := c.Lines(nil)
+		if want := []string{"⡈⠠"}; strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Errorf("Lines() = %q; want %q", got, want)
+		}
+	})
+
+	t.Run("Plot", func(t *testing.T) {
+		c := NewCanvas(4, 4, true)
+		c.Plot([]float64{0, 1, math.NaN(), 0.5}, 0, 1)
+		got := strings.Join(c.Lines(nil), "\n")
+		want := " *  \n" +
+			" * *\n" +
+			" *  \n" +
+			"*   "
+		if got != want {
+			t.Errorf("Lines() =\n%s\nwant\n%s", got, want)
+		}
+	})
+
+	t.Run("Dotted", func(t *testing.T) {
+		c := NewCanvas(4, 2, true)
+		c.Plot([]float64{1, 1, 1, 1}, 1, 2)
+		c.Plot([]float64{0, 0, 1, 0}, 0, 1)
+		got := strings.Join(c.Lines(func(cell string, series uint8) string {
+			if series == 0b11 {
+				return "!"
+			}
+			return cell
+		}), "\n")
+		want := ". ! \n" +
+			"** *"
+		if got != want {
+			t.Errorf("Lines() =\n%s\nwant\n%s", got, want)
+		}
+	})
+}
+
+func TestBars(t *testing.T) {
+	tests := []struct {
+		name  string
+		ascii bool
+		want  []string
+	}{
+		{"Blocks", false, []string{"  ▄█", "▁▁██"}},
+		{"ASCII", true, []string{"  ##", "..##"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Bars([]float64{0.01, 0.2, 3, 8}, 4, 2, tt.ascii)
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("Bars() = %q; want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got := Bars([]float64{0, 0}, 0, 1, false); got[0] != "  " {
+		t.Errorf("Bars() = %q; want empty bars", got)
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	values := []float64{2, 4, 6, 9, math.NaN(), 2}
+	if got, want := Sparkline(values, false), "▁▃▅█ ▁"; got != want {
+		t.Errorf("Sparkline() = %q; want %q", got, want)
+	}
+	if got, want := Sparkline(values, true), "_.-^ _"; got != want {
+		t.Errorf("Sparkline(ascii) = %q; want %q", got, want)
+	}
+	if got, want := Sparkline([]float64{3, 3}, false), "▅▅"; got != want {
+		t.Errorf("Sparkline() of a flat line = %q; want %q", got, want)
+	}
+}
+
+func TestResample(t *testing.T) {
+	values := []float64{1, 3, 5, 7, 9, 11, 2}
+	if got := Resample(values, 10); len(got) != len(values) {
+		t.Errorf("Resample() = %v; want the values as they are", got)
+	}
+	got := Resample(values, 3)
+	want := []float64{2, 6, 22.0 / 3}
+	if len(got) != len(want) {
+		t.Fatalf("Resample() = %v; want %v", got, want)
+	}
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > 1e-9 {
+			t.Errorf("Resample()[%d] = %v; want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestArrow(t *testing.T) {
+	tests := []struct {
+		from  float64
+		want  string
+		ascii string
+	}{
+		{0, "↓", "v"},
+		{350, "↓", "v"},
+		{45, "↙", "/"},
+		{90, "←", "<"},
+		{180, "↑", "^"},
+		{225, "↗", "/"},
+		{270, "→", ">"},
+		{315, "↘", "\\"},
+		{-90, "→", ">"},
+	}
+	for _, tt := range tests {
+		if got := Arrow(tt.from, false); got != tt.want {
+			t.Errorf("Arrow(%v) = %q; want %q", tt.from, got, tt.want)
+		}
+		if got := Arrow(tt.from, true); got != tt.ascii {
+			t.Errorf("Arrow(%v, ascii) = %q; want %q", tt.from, got, tt.ascii)
+		}
+	}
+}
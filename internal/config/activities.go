@@ -0,0 +1,133 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+// builtinActivities returns the activities known without any
+// configuration. Entries under activities in the config file override
+// their limits one by one.
+func builtinActivities() map[string]*models.Activity {
+	limit := func(v float64) *float64 { return &v }
+	daylight := func() *bool { v := true; return &v }
+
+	return map[string]*models.Activity{
+		"running": {
+			MinTemp:          limit(5),
+			MaxTemp:          limit(18),
+			MaxWind:          limit(8),
+			MaxPrecipitation: limit(0.5),
+			Daylight:         daylight(),
+		},
+		"cycling": {
+			MinTemp:          limit(10),
+			MaxTemp:          limit(25),
+			MaxWind:          limit(7),
+			MaxPrecipitation: limit(0.2),
+			Daylight:         daylight(),
+		},
+		"hiking": {
+			MinTemp:          limit(5),
+			MaxTemp:          limit(22),
+			MaxWind:          limit(10),
+			MaxPrecipitation: limit(1),
+			Daylight:         daylight(),
+		},
+		"painting": {
+			MinTemp:          limit(10),
+			MaxTemp:          limit(30),
+			MaxWind:          limit(6),
+			MaxPrecipitation: limit(0),
+			MaxHumidity:      limit(85),
+			Daylight:         daylight(),
+		},
+		"sailing": {
+			MinTemp:          limit(10),
+			MaxTemp:          limit(28),
+			MinWind:          limit(4),
+			MaxWind:          limit(10),
+			MaxPrecipitation: limit(1),
+			Daylight:         daylight(),
+		},
+	}
+}
+
+// ActivityNames returns the names of the built-in and configured
+// activities, sorted
+func (c *Config) ActivityNames() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, activities := range []map[string]*models.Activity{builtinActivities(), c.Activities} {
+		for name := range activities {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetActivity returns an activity by name: a built-in activity with the
+// limits set in the config file applied, or an activity defined only in
+// the config file
+func (c *Config) GetActivity(name string) (*models.Activity, error) {
+	builtin, isBuiltin := builtinActivities()[name]
+	configured, isConfigured := c.Activities[name]
+	if !isBuiltin && !isConfigured {
+		return nil, fmt.Errorf("unknown activity '%s' (available: %s)", name, strings.Join(c.ActivityNames(), ", "))
+	}
+
+	if builtin == nil {
+		builtin = &models.Activity{}
+	}
+	return builtin.Merge(configured), nil
+}
+
+// validateActivities checks that the limits of configured activities make
+// sense
+func (c *Config) validateActivities() []error {
+	var problems []error
+
+	names := make([]string, 0, len(c.Activities))
+	for name := range c.Activities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		a, err := c.GetActivity(name)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("activities.%s: %w", name, err))
+			continue
+		}
+
+		if a.MinTemp != nil && a.MaxTemp != nil && *a.MinTemp > *a.MaxTemp {
+			problems = append(problems, fmt.Errorf("activities.%s: min_temp (%g) is above max_temp (%g)", name, *a.MinTemp, *a.MaxTemp))
+		}
+		if a.MinWind != nil && a.MaxWind != nil && *a.MinWind > *a.MaxWind {
+			problems = append(problems, fmt.Errorf("activities.%s: min_wind (%g) is above max_wind (%g)", name, *a.MinWind, *a.MaxWind))
+		}
+		limits := []struct {
+			key   string
+			value *float64
+		}{
+			{"min_wind", a.MinWind},
+			{"max_wind", a.MaxWind},
+			{"max_precipitation", a.MaxPrecipitation},
+			{"max_humidity", a.MaxHumidity},
+		}
+		for _, l := range limits {
+			if l.value != nil && *l.value < 0 {
+				problems = append(problems, fmt.Errorf("activities.%s.%s: must not be negative (got %g)", name, l.key, *l.value))
+			}
+		}
+	}
+
+	return problems
+}
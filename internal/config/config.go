@@ -1,11 +1,16 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
 	"os"
-	"path/filepath"
+	"sort"
+	"strings"
 
+	"github.com/kristofferrisa/sky-cli/internal/api"
+	"github.com/kristofferrisa/sky-cli/internal/formatter"
 	"github.com/kristofferrisa/sky-cli/internal/models"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
@@ -16,82 +21,558 @@ type CacheConfig struct {
 	TTLMinutes int    `yaml:"ttl_minutes" mapstructure:"ttl_minutes"`
 }
 
+// GeocodingConfig represents place name lookup configuration
+type GeocodingConfig struct {
+	// ReverseURL is a Nominatim-compatible server used to name
+	// coordinates. When empty, the built-in gazetteer is used.
+	ReverseURL string `yaml:"reverse_url" mapstructure:"reverse_url"`
+
+	// SnapRadiusMeters makes coordinates within this distance of a saved
+	// location use that location, so that its name and cached forecasts
+	// are reused. Zero disables snapping.
+	SnapRadiusMeters int `yaml:"snap_radius_meters" mapstructure:"snap_radius_meters"`
+}
+
+// PositionConfig represents the sources of the current position used by
+// --here. Sources with an empty address or path are not used.
+type PositionConfig struct {
+	GPSD           string `yaml:"gpsd" mapstructure:"gpsd"`
+	NMEA           string `yaml:"nmea" mapstructure:"nmea"`
+	File           string `yaml:"file" mapstructure:"file"`
+	TimeoutSeconds int    `yaml:"timeout_seconds" mapstructure:"timeout_seconds"`
+	MaxAgeMinutes  int    `yaml:"max_age_minutes" mapstructure:"max_age_minutes"`
+}
+
+// HistoryConfig represents the archive of fetched forecasts used by
+// sky history
+type HistoryConfig struct {
+	Enabled       bool   `yaml:"enabled" mapstructure:"enabled"`
+	Path          string `yaml:"path" mapstructure:"path"`
+	RetentionDays int    `yaml:"retention_days" mapstructure:"retention_days"`
+}
+
+// DiffConfig represents the smallest forecast changes sky diff reports
+type DiffConfig struct {
+	Temperature              float64 `yaml:"temperature" mapstructure:"temperature"`
+	Precipitation            float64 `yaml:"precipitation" mapstructure:"precipitation"`
+	PrecipitationProbability float64 `yaml:"precipitation_probability" mapstructure:"precipitation_probability"`
+	Wind                     float64 `yaml:"wind" mapstructure:"wind"`
+	Gust                     float64 `yaml:"gust" mapstructure:"gust"`
+}
+
 // Config represents the application configuration
 type Config struct {
+	Version         int                         `yaml:"version,omitempty" mapstructure:"version"`
 	DefaultLocation string                      `yaml:"default_location" mapstructure:"default_location"`
 	DefaultFormat   string                      `yaml:"default_format" mapstructure:"default_format"`
 	NoColor         bool                        `yaml:"no_color" mapstructure:"no_color"`
 	NoEmoji         bool                        `yaml:"no_emoji" mapstructure:"no_emoji"`
 	Cache           CacheConfig                 `yaml:"cache" mapstructure:"cache"`
+	Geocoding       GeocodingConfig             `yaml:"geocoding" mapstructure:"geocoding"`
+	Position        PositionConfig              `yaml:"position" mapstructure:"position"`
+	History         HistoryConfig               `yaml:"history" mapstructure:"history"`
+	Diff            DiffConfig                  `yaml:"diff" mapstructure:"diff"`
 	Locations       map[string]*models.Location `yaml:"locations" mapstructure:"locations"`
+	Groups          map[string][]string         `yaml:"groups,omitempty" mapstructure:"groups"`
+	Activities      map[string]*models.Activity `yaml:"activities,omitempty" mapstructure:"activities"`
+	Rules           map[string]*models.Rule     `yaml:"rules,omitempty" mapstructure:"rules"`
+	Notifiers       map[string]*models.Notifier `yaml:"notifiers,omitempty" mapstructure:"notifiers"`
+	Profile         string                      `yaml:"profile,omitempty" mapstructure:"profile"`
+	Profiles        map[string]Profile          `yaml:"profiles,omitempty" mapstructure:"profiles"`
+
+	// path is the file the configuration was loaded from, if any
+	path string
+
+	// v holds the file and default layers the configuration was read from
+	v *viper.Viper
+
+	// sources records keys whose value comes from a profile, an
+	// environment variable or a flag
+	sources map[string]Source
+
+	// file holds the values stored in the config file, without defaults,
+	// and loaded the effective values right after loading. Save applies
+	// everything that changed since then, plus keys set explicitly
+	// (dirty), to file and removes keys that were unset from it.
+	file   *Config
+	loaded map[string]string
+	dirty  map[string]bool
+	unset  map[string]bool
+
+	// migration records an automatic upgrade of the config file
+	migration *Migration
+}
+
+// LoadOptions controls where configuration is read from
+type LoadOptions struct {
+	// File is an explicit config file (--config). When empty, SKY_CONFIG
+	// and then the default search paths are used.
+	File string
+
+	// Flags maps config keys to command-line flags. Flags that were set
+	// on the command line override every other source. The "profile"
+	// key selects the profile to apply.
+	Flags map[string]*pflag.Flag
+
+	// SkipMigration loads an outdated config file as it is instead of
+	// upgrading it to CurrentVersion first
+	SkipMigration bool
+}
+
+// defaults returns the built-in defaults for scalar settings
+func defaults() map[string]interface{} {
+	return map[string]interface{}{
+		"default_location":               "stavern",
+		"default_format":                 "full",
+		"no_color":                       false,
+		"no_emoji":                       false,
+		"cache.enabled":                  true,
+		"cache.directory":                DefaultCacheDir(),
+		"cache.ttl_minutes":              10,
+		"geocoding.reverse_url":          "",
+		"geocoding.snap_radius_meters":   0,
+		"position.gpsd":                  "localhost:2947",
+		"position.nmea":                  "",
+		"position.file":                  "",
+		"position.timeout_seconds":       5,
+		"position.max_age_minutes":       10,
+		"history.enabled":                false,
+		"history.path":                   DefaultHistoryPath(),
+		"history.retention_days":         365,
+		"diff.temperature":               2.0,
+		"diff.precipitation":             1.0,
+		"diff.precipitation_probability": 20.0,
+		"diff.wind":                      3.0,
+		"diff.gust":                      5.0,
+		"profile":                        "",
+	}
 }
 
-// Load loads configuration from file or creates default config
-func Load() (*Config, error) {
-	// Set config file name and locations
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
-
-	// Add config paths
-	viper.AddConfigPath("$HOME/.sky")
-	viper.AddConfigPath("$HOME/.config/sky")
-	viper.AddConfigPath(".")
-
-	// Set defaults
-	viper.SetDefault("default_format", "full")
-	viper.SetDefault("no_color", false)
-	viper.SetDefault("no_emoji", false)
-	viper.SetDefault("cache.enabled", true)
-	viper.SetDefault("cache.directory", filepath.Join(os.Getenv("HOME"), ".sky", "cache"))
-	viper.SetDefault("cache.ttl_minutes", 10)
-	viper.SetDefault("locations", map[string]*models.Location{
-		"stavern": {
-			Name:      "Stavern, Norway",
-			Latitude:  59.0,
-			Longitude: 10.0,
-			Timezone:  "Europe/Oslo",
-		},
-	})
-	viper.SetDefault("default_location", "stavern")
+// Load loads the configuration. Values are resolved with the following
+// precedence, highest first:
+//
+//  1. command-line flags (opts.Flags)
+//  2. environment variables (SKY_*, NO_COLOR)
+//  3. the active profile and the profiles it inherits from
+//  4. the config file
+//  5. built-in defaults
+//
+// The active profile is chosen by the "profile" flag, SKY_PROFILE or the
+// profile key in the config file, in that order.
+func Load(opts LoadOptions) (*Config, error) {
+	v := viper.New()
+	v.SetConfigType("yaml")
+
+	file := opts.File
+	if file == "" {
+		file = os.Getenv("SKY_CONFIG")
+	}
+	if file != "" {
+		v.SetConfigFile(file)
+	} else {
+		v.SetConfigName("config")
+		for _, dir := range searchPaths() {
+			v.AddConfigPath(dir)
+		}
+	}
+
+	sources := make(map[string]Source)
 
 	// Try to read config file
-	if err := viper.ReadInConfig(); err != nil {
-		// Config file not found, use defaults
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+	var migration *Migration
+	if err := v.ReadInConfig(); err != nil {
+		// Config file not found, use defaults. An explicitly requested
+		// file must exist.
+		if _, ok := err.(viper.ConfigFileNotFoundError); !ok || file != "" {
 			return nil, fmt.Errorf("error reading config: %w", err)
 		}
+
+		// Start like a new file would, with the built-in locations
+		starter := newFileValues()
+		if err := v.MergeConfigMap(starter); err != nil {
+			return nil, fmt.Errorf("failed to apply defaults: %w", err)
+		}
+		for _, key := range flattenKeys(starter, "") {
+			sources[key] = SourceDefault
+		}
+	} else if !opts.SkipMigration {
+		if migration, err = migrateFile(v); err != nil {
+			return nil, err
+		}
+	}
+
+	// Keep the file layer on its own, read before the defaults are set,
+	// so Save never writes defaults, profile, environment or flag values
+	// back to the file
+	var stored Config
+	if err := v.Unmarshal(&stored); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+
+	for key, value := range defaults() {
+		v.SetDefault(key, value)
+	}
+
+	profile := stored.Profile
+	if value, ok := lookupEnv("profile"); ok {
+		profile = value
+	}
+	if flag := opts.Flags["profile"]; flag != nil && flag.Changed {
+		profile = flag.Value.String()
+	}
+	if profile != "" {
+		chain, err := stored.profileChain(profile)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range chain {
+			values := p.values()
+			if err := v.MergeConfigMap(values); err != nil {
+				return nil, fmt.Errorf("failed to apply profile: %w", err)
+			}
+			for _, key := range flattenKeys(values, "") {
+				sources[key] = SourceProfile
+			}
+		}
 	}
 
 	var cfg Config
-	if err := viper.Unmarshal(&cfg); err != nil {
+	if err := v.Unmarshal(&cfg); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
+	cfg.path = v.ConfigFileUsed()
+	cfg.v = v
+	cfg.sources = sources
+	cfg.file = &stored
+	cfg.migration = migration
+
+	// Environment overrides the file, flags override the environment
+	for _, key := range overridableKeys() {
+		if value, ok := lookupEnv(key); ok {
+			if err := cfg.set(key, value); err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", EnvNames(key)[0], err)
+			}
+			sources[key] = SourceEnv
+		}
+	}
+
+	for key, flag := range opts.Flags {
+		if flag != nil && flag.Changed {
+			if err := cfg.set(key, flag.Value.String()); err != nil {
+				return nil, fmt.Errorf("invalid --%s: %w", flag.Name, err)
+			}
+			sources[key] = SourceFlag
+		}
+	}
 
+	cfg.loaded = cfg.snapshot()
 	return &cfg, nil
 }
 
-// Save saves the configuration to file
+// newFileValues returns the values a config file starts with
+func newFileValues() map[string]interface{} {
+	locations := make(map[string]interface{})
+	for name, loc := range builtinLocations() {
+		locations[name] = map[string]interface{}{
+			"name":      loc.Name,
+			"latitude":  loc.Latitude,
+			"longitude": loc.Longitude,
+			"timezone":  loc.Timezone,
+		}
+	}
+
+	return map[string]interface{}{
+		"version":   CurrentVersion,
+		"locations": locations,
+	}
+}
+
+// migrateFile upgrades the config file read by v to CurrentVersion in
+// memory and reloads it. The file itself is only rewritten by Save or
+// Migrate, so commands that just read the config leave it alone.
+func migrateFile(v *viper.Viper) (*Migration, error) {
+	path := v.ConfigFileUsed()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config: %w", err)
+	}
+
+	m, err := migrateData(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to migrate %s: %w", path, err)
+	}
+	if !m.Changed() {
+		return nil, nil
+	}
+	m.Path = path
+
+	if err := v.ReadConfig(bytes.NewReader(m.Data)); err != nil {
+		return nil, fmt.Errorf("error reading config: %w", err)
+	}
+	return m, nil
+}
+
+// Migration returns the upgrade applied to the config file in memory
+// while loading, or nil if the file was up to date. Save writes it to the
+// file, after which its Backup is set.
+func (c *Config) Migration() *Migration {
+	return c.migration
+}
+
+// ActiveProfile returns the name of the profile in effect, if any
+func (c *Config) ActiveProfile() string {
+	return c.Profile
+}
+
+// Path returns the config file in use, or the default location where
+// the configuration would be saved if no file exists yet
+func (c *Config) Path() string {
+	if c.path != "" {
+		return c.path
+	}
+	return defaultConfigFile()
+}
+
+// Exists reports whether the configuration was loaded from a file
+func (c *Config) Exists() bool {
+	return c.path != ""
+}
+
+// OutputFormat returns the format to write output in for a location whose
+// saved format is saved. default_format wins when it was set by
+// SKY_FORMAT, --format or the active profile; the saved format only
+// overrides a value from the config file or the built-in default.
+func (c *Config) OutputFormat(saved string) string {
+	if saved != "" {
+		switch c.source("default_format") {
+		case SourceFile, SourceDefault:
+			return saved
+		}
+	}
+	return c.DefaultFormat
+}
+
+// source reports where the value of a dotted key came from
+func (c *Config) source(key string) Source {
+	if source, ok := c.sources[key]; ok {
+		return source
+	}
+	if c.v != nil && c.v.InConfig(key) {
+		return SourceFile
+	}
+	return SourceDefault
+}
+
+// Validate checks the configuration for problems and returns all of them
+func (c *Config) Validate() []error {
+	var problems []error
+
+	if c.DefaultFormat != "" && !isAvailableFormat(c.DefaultFormat) {
+		problems = append(problems, fmt.Errorf("default_format: unknown format '%s' (available: %s)",
+			c.DefaultFormat, strings.Join(formatter.AvailableFormatters(), ", ")))
+	}
+
+	if c.DefaultLocation != "" {
+		if _, ok := c.Locations[c.DefaultLocation]; !ok {
+			problems = append(problems, fmt.Errorf("default_location: location '%s' is not defined", c.DefaultLocation))
+		}
+	}
+
+	if c.Version > CurrentVersion {
+		problems = append(problems, fmt.Errorf("version: %d is newer than this version of sky supports (%d)", c.Version, CurrentVersion))
+	}
+
+	if c.Cache.TTLMinutes < 0 {
+		problems = append(problems, fmt.Errorf("cache.ttl_minutes: must not be negative (got %d)", c.Cache.TTLMinutes))
+	}
+	if c.Geocoding.SnapRadiusMeters < 0 {
+		problems = append(problems, fmt.Errorf("geocoding.snap_radius_meters: must not be negative (got %d)", c.Geocoding.SnapRadiusMeters))
+	}
+	if c.Position.TimeoutSeconds < 0 {
+		problems = append(problems, fmt.Errorf("position.timeout_seconds: must not be negative (got %d)", c.Position.TimeoutSeconds))
+	}
+	if c.Position.MaxAgeMinutes < 0 {
+		problems = append(problems, fmt.Errorf("position.max_age_minutes: must not be negative (got %d)", c.Position.MaxAgeMinutes))
+	}
+
+	if c.History.RetentionDays < 0 {
+		problems = append(problems, fmt.Errorf("history.retention_days: must not be negative (got %d)", c.History.RetentionDays))
+	}
+
+	diffThresholds := []struct {
+		key   string
+		value float64
+	}{
+		{"diff.temperature", c.Diff.Temperature},
+		{"diff.precipitation", c.Diff.Precipitation},
+		{"diff.precipitation_probability", c.Diff.PrecipitationProbability},
+		{"diff.wind", c.Diff.Wind},
+		{"diff.gust", c.Diff.Gust},
+	}
+	for _, t := range diffThresholds {
+		if t.value < 0 {
+			problems = append(problems, fmt.Errorf("%s: must not be negative (got %g)", t.key, t.value))
+		}
+	}
+
+	problems = append(problems, c.validateProfiles()...)
+
+	names := make([]string, 0, len(c.Locations))
+	for name := range c.Locations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		loc := c.Locations[name]
+		if loc == nil {
+			problems = append(problems, fmt.Errorf("locations.%s: empty location", name))
+			continue
+		}
+		if err := loc.Validate(); err != nil {
+			problems = append(problems, fmt.Errorf("locations.%s: %w", name, err))
+		}
+		if loc.Format != "" && !isAvailableFormat(loc.Format) {
+			problems = append(problems, fmt.Errorf("locations.%s.format: unknown format '%s' (available: %s)",
+				name, loc.Format, strings.Join(formatter.AvailableFormatters(), ", ")))
+		}
+		if loc.Provider != "" && !isProvider(loc.Provider) {
+			problems = append(problems, fmt.Errorf("locations.%s.provider: unknown provider '%s' (available: %s)",
+				name, loc.Provider, strings.Join(api.Providers(), ", ")))
+		}
+	}
+
+	for _, group := range c.GroupNames() {
+		for _, name := range c.Groups[group] {
+			if _, ok := c.Locations[name]; !ok {
+				problems = append(problems, fmt.Errorf("groups.%s: location '%s' is not defined", group, name))
+			}
+		}
+	}
+
+	problems = append(problems, c.validateActivities()...)
+	problems = append(problems, c.validateRules()...)
+	problems = append(problems, c.validateNotifiers()...)
+
+	return problems
+}
+
+// isAvailableFormat reports whether name is a known formatter
+func isAvailableFormat(name string) bool {
+	for _, f := range formatter.AvailableFormatters() {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isProvider reports whether name is a known weather provider
+func isProvider(name string) bool {
+	for _, p := range api.Providers() {
+		if p == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Save writes the configuration back to the file it was loaded from,
+// or to the default config file if there is none. Only keys already in the
+// file and values that were changed are written, so settings left at their
+// defaults keep following the defaults of sky. Comments and key order in
+// the existing file are preserved and the previous version is kept as
+// a .bak file next to it.
 func (c *Config) Save() error {
-	configDir := filepath.Join(os.Getenv("HOME"), ".sky")
-	if err := os.MkdirAll(configDir, 0755); err != nil {
-		return fmt.Errorf("failed to create config directory: %w", err)
+	saved := c
+	var keys map[string]bool
+	if c.file != nil {
+		saved = c.file
+		var err error
+		if keys, err = c.applyChanges(saved); err != nil {
+			return err
+		}
+
+		// A new file starts with the version and the built-in locations
+		if !c.Exists() {
+			for _, key := range flattenKeys(newFileValues(), "") {
+				if _, ok := keys[key]; !ok {
+					keys[key] = true
+				}
+			}
+		}
 	}
 
-	configFile := filepath.Join(configDir, "config.yaml")
+	path := c.Path()
 
-	viper.Set("default_location", c.DefaultLocation)
-	viper.Set("default_format", c.DefaultFormat)
-	viper.Set("no_color", c.NoColor)
-	viper.Set("no_emoji", c.NoEmoji)
-	viper.Set("locations", c.Locations)
+	// A file upgraded in memory by Load gets its upgrade written first,
+	// keeping the original as a backup like Migrate does
+	if m := c.migration; m != nil && m.Backup == "" {
+		original, err := os.ReadFile(m.Path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", m.Path, err)
+		}
+		if err := m.write(original); err != nil {
+			return err
+		}
+	}
 
-	if err := viper.WriteConfigAs(configFile); err != nil {
-		return fmt.Errorf("failed to write config: %w", err)
+	if err := writeFile(path, saved, keys); err != nil {
+		return err
 	}
 
+	c.path = path
+	c.loaded = c.snapshot()
+	c.dirty = nil
+	c.unset = nil
 	return nil
 }
 
+// applyChanges copies every value that changed since loading, or was set
+// explicitly, to dst and removes map entries that were deleted. It returns
+// the keys to write to the file, and the keys that were unset as false.
+func (c *Config) applyChanges(dst *Config) (map[string]bool, error) {
+	current := c.snapshot()
+
+	keys := make([]string, 0, len(current))
+	for key := range current {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	written := make(map[string]bool)
+	for key := range c.unset {
+		written[key] = false
+	}
+	for _, key := range keys {
+		if previous, ok := c.loaded[key]; ok && previous == current[key] && !c.dirty[key] {
+			continue
+		}
+		if err := dst.set(key, current[key]); err != nil {
+			return nil, err
+		}
+		written[key] = !c.unset[key]
+	}
+
+	for key := range c.loaded {
+		if _, ok := current[key]; ok {
+			continue
+		}
+
+		// Find the map entry that was removed, e.g. locations.oslo
+		path := splitKey(key)
+		for i := 1; i < len(path); i++ {
+			entry := strings.Join(path[:i+1], ".")
+			if _, err := c.Get(entry); err != nil {
+				dst.Unset(entry)
+				break
+			}
+		}
+	}
+
+	return written, nil
+}
+
 // GetLocation retrieves a location by name
 func (c *Config) GetLocation(name string) (*models.Location, error) {
 	loc, ok := c.Locations[name]
@@ -123,12 +604,141 @@ func (c *Config) AddLocation(name string, loc *models.Location) error {
 	return nil
 }
 
-// RemoveLocation removes a location by name
+// RenameLocation renames a saved location. The default location, groups
+// and profiles that select it follow the new name, as does the display
+// name when it was the old name.
+func (c *Config) RenameLocation(oldName, newName string) error {
+	loc, ok := c.Locations[oldName]
+	if !ok {
+		return fmt.Errorf("location '%s' not found", oldName)
+	}
+	if _, exists := c.Locations[newName]; exists {
+		return fmt.Errorf("location '%s' already exists", newName)
+	}
+
+	if loc.Name == oldName {
+		loc.Name = newName
+	}
+	delete(c.Locations, oldName)
+	c.Locations[newName] = loc
+
+	if c.DefaultLocation == oldName {
+		c.DefaultLocation = newName
+	}
+	for _, members := range c.Groups {
+		for i, name := range members {
+			if name == oldName {
+				members[i] = newName
+			}
+		}
+	}
+	for _, p := range c.Profiles {
+		if p["default_location"] == oldName {
+			p["default_location"] = newName
+		}
+	}
+	return nil
+}
+
+// RemoveLocation removes a location by name, including from groups
 func (c *Config) RemoveLocation(name string) error {
 	if _, ok := c.Locations[name]; !ok {
 		return fmt.Errorf("location '%s' not found", name)
 	}
 
 	delete(c.Locations, name)
+	for group := range c.Groups {
+		c.removeFromGroup(group, name)
+	}
+	return nil
+}
+
+// GroupNames returns the names of all location groups, sorted
+func (c *Config) GroupNames() []string {
+	names := make([]string, 0, len(c.Groups))
+	for name := range c.Groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AddToGroup adds saved locations to a group, creating the group if
+// needed. Locations already in the group are skipped.
+func (c *Config) AddToGroup(group string, names ...string) error {
+	if group == "" {
+		return fmt.Errorf("group name must not be empty")
+	}
+	if _, ok := c.Locations[group]; ok {
+		return fmt.Errorf("group name '%s' is already used by a location", group)
+	}
+	for _, name := range names {
+		if _, ok := c.Locations[name]; !ok {
+			return fmt.Errorf("location '%s' not found", name)
+		}
+	}
+
+	if c.Groups == nil {
+		c.Groups = make(map[string][]string)
+	}
+
+	members := c.Groups[group]
+	for _, name := range names {
+		if !contains(members, name) {
+			members = append(members, name)
+		}
+	}
+	c.Groups[group] = members
+	return nil
+}
+
+// RemoveFromGroup removes locations from a group, or the whole group
+// when no names are given. A group left empty is removed as well.
+func (c *Config) RemoveFromGroup(group string, names ...string) error {
+	members, ok := c.Groups[group]
+	if !ok {
+		return fmt.Errorf("group '%s' not found", group)
+	}
+
+	if len(names) == 0 {
+		delete(c.Groups, group)
+		return nil
+	}
+
+	for _, name := range names {
+		if !contains(members, name) {
+			return fmt.Errorf("location '%s' is not in group '%s'", name, group)
+		}
+	}
+	for _, name := range names {
+		c.removeFromGroup(group, name)
+	}
 	return nil
 }
+
+// removeFromGroup removes a location from a group, deleting the group
+// when it becomes empty
+func (c *Config) removeFromGroup(group, name string) {
+	var members []string
+	for _, member := range c.Groups[group] {
+		if member != name {
+			members = append(members, member)
+		}
+	}
+
+	if len(members) == 0 {
+		delete(c.Groups, group)
+		return
+	}
+	c.Groups[group] = members
+}
+
+// contains reports whether list contains s
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
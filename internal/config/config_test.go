@@ -0,0 +1,811 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+	"github.com/spf13/pflag"
+	"go.yaml.in/yaml/v3"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files")
+
+func TestConfigSave(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(t *testing.T, c *Config)
+
+		// written are the default keys set by modify, and so written to
+		// the file even when not in it before
+		written []string
+	}{
+		{
+			name: "comments",
+			modify: func(t *testing.T, c *Config) {
+				mustSet(t, c, "cache.ttl_minutes", "5")
+				mustAdd(t, c, "bergen", &models.Location{
+					Name:      "Bergen, Norway",
+					Latitude:  60.3913,
+					Longitude: 5.3221,
+					Timezone:  "Europe/Oslo",
+				})
+			},
+		},
+		{
+			name: "remove",
+			modify: func(t *testing.T, c *Config) {
+				delete(c.Locations, "bergen")
+				mustSet(t, c, "default_location", "oslo")
+			},
+		},
+		{
+			name: "rename",
+			modify: func(t *testing.T, c *Config) {
+				if err := c.AddToGroup("norway", "bergen", "oslo"); err != nil {
+					t.Fatal(err)
+				}
+				if err := c.RenameLocation("bergen", "west"); err != nil {
+					t.Fatal(err)
+				}
+				c.Locations["oslo"].Tags = []string{"capital", "east"}
+				c.Locations["oslo"].Notes = "Office"
+				c.Locations["oslo"].Format = "summary"
+			},
+		},
+		{
+			name: "partial",
+			modify: func(t *testing.T, c *Config) {
+				mustSet(t, c, "default_format", "summary")
+				mustSet(t, c, "cache.directory", "/tmp/sky")
+				mustAdd(t, c, "home", &models.Location{Name: "Home", Latitude: 59, Longitude: 10})
+			},
+			written: []string{"default_format", "cache.directory"},
+		},
+		{
+			name: "new",
+			modify: func(t *testing.T, c *Config) {
+				mustSet(t, c, "default_location", "oslo")
+				mustAdd(t, c, "oslo", &models.Location{Name: "Oslo", Latitude: 59.9139, Longitude: 10.7522, Timezone: "Europe/Oslo"})
+			},
+			written: []string{"default_location"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			golden, err := filepath.Abs(filepath.Join("testdata", "save", tt.name+".golden.yaml"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			input, err := os.ReadFile(filepath.Join("testdata", "save", tt.name+".input.yaml"))
+			if err != nil && !os.IsNotExist(err) {
+				t.Fatalf("failed to read input: %v", err)
+			}
+
+			home := isolateEnv(t)
+			path := filepath.Join(home, "config.yaml")
+			if input != nil {
+				if err := os.WriteFile(path, input, 0600); err != nil {
+					t.Fatalf("failed to write input: %v", err)
+				}
+			}
+
+			opts := LoadOptions{SkipMigration: true}
+			if input != nil {
+				opts.File = path
+			}
+			cfg, err := Load(opts)
+			if err != nil {
+				t.Fatalf("Load() failed: %v", err)
+			}
+			if input == nil {
+				path = cfg.Path()
+			}
+
+			tt.modify(t, cfg)
+			if err := cfg.Save(); err != nil {
+				t.Fatalf("Save() failed: %v", err)
+			}
+
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read saved config: %v", err)
+			}
+
+			if *updateGolden {
+				if err := os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatalf("failed to update golden file: %v", err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read golden file: %v", err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("Save() output mismatch\n--- got ---\n%s\n--- want ---\n%s", got, want)
+			}
+
+			// Defaults that were neither in the file nor set must not be
+			// written, so they keep following the defaults after reloading
+			before, after := fileKeys(t, input), fileKeys(t, got)
+			for _, key := range tt.written {
+				before[key] = true
+			}
+			reloaded, err := Load(LoadOptions{File: path, SkipMigration: true})
+			if err != nil {
+				t.Fatalf("Load() of the saved config failed: %v", err)
+			}
+			for key, value := range defaults() {
+				if before[key] {
+					continue
+				}
+				if after[key] {
+					t.Errorf("Save() wrote the default of %s", key)
+				}
+				if got, _ := reloaded.Get(key); got != fmt.Sprint(value) {
+					t.Errorf("%s = %q after reloading; want the default %q", key, got, fmt.Sprint(value))
+				}
+			}
+
+			// The previous file must be kept as a backup
+			backup, err := os.ReadFile(path + ".bak")
+			if input == nil {
+				if !os.IsNotExist(err) {
+					t.Errorf("Save() created a backup for a new file")
+				}
+			} else if string(backup) != string(input) {
+				t.Errorf("Save() backup does not match the previous file")
+			}
+
+			// Saving again without changes must be stable
+			if err := cfg.Save(); err != nil {
+				t.Fatalf("second Save() failed: %v", err)
+			}
+			again, _ := os.ReadFile(path)
+			if string(again) != string(got) {
+				t.Errorf("Save() is not idempotent\n--- first ---\n%s\n--- second ---\n%s", got, again)
+			}
+		})
+	}
+}
+
+// mustSet sets a config key or fails the test
+func mustSet(t *testing.T, c *Config, key, value string) {
+	t.Helper()
+	if err := c.Set(key, value); err != nil {
+		t.Fatalf("Set(%s) failed: %v", key, err)
+	}
+}
+
+// mustAdd adds a location or fails the test
+func mustAdd(t *testing.T, c *Config, name string, loc *models.Location) {
+	t.Helper()
+	if err := c.AddLocation(name, loc); err != nil {
+		t.Fatalf("AddLocation(%s) failed: %v", name, err)
+	}
+}
+
+// fileKeys returns the dotted keys in a config file
+func fileKeys(t *testing.T, data []byte) map[string]bool {
+	t.Helper()
+	values := make(map[string]interface{})
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+	keys := make(map[string]bool)
+	for _, key := range flattenKeys(values, "") {
+		keys[key] = true
+	}
+	return keys
+}
+
+func TestConfigSaveRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	cfg := &Config{
+		DefaultLocation: "oslo",
+		DefaultFormat:   "markdown",
+		NoEmoji:         true,
+		Cache:           CacheConfig{Enabled: false, Directory: "/tmp/sky", TTLMinutes: 42},
+		Locations: map[string]*models.Location{
+			"oslo": {Name: "Oslo", Latitude: 59.9139, Longitude: 10.7522, Timezone: "Europe/Oslo"},
+		},
+		path: path,
+	}
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read saved config: %v", err)
+	}
+
+	var loaded Config
+	if err := yaml.Unmarshal(data, &loaded); err != nil {
+		t.Fatalf("failed to parse saved config: %v", err)
+	}
+	loaded.path = path
+
+	for _, s := range cfg.Settings() {
+		got, err := loaded.Get(s.Key)
+		if err != nil {
+			t.Errorf("Get(%s) after round trip: %v", s.Key, err)
+			continue
+		}
+		if got != s.Value {
+			t.Errorf("%s = %s after round trip; want %s", s.Key, got, s.Value)
+		}
+	}
+}
+
+// isolateEnv points HOME at an empty directory and clears every variable
+// that Load reads, so tests do not pick up the developer's setup
+func isolateEnv(t *testing.T) string {
+	t.Helper()
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_CACHE_HOME", "")
+	t.Setenv("SKY_CONFIG", "")
+	for _, key := range overridableKeys() {
+		for _, name := range EnvNames(key) {
+			t.Setenv(name, "")
+		}
+	}
+
+	// Load also searches the working directory
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get working directory: %v", err)
+	}
+	if err := os.Chdir(home); err != nil {
+		t.Fatalf("failed to change directory: %v", err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	return home
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		env      map[string]string
+		flag     string
+		expected string
+		source   Source
+	}{
+		{
+			name:     "Default",
+			expected: "full",
+			source:   SourceDefault,
+		},
+		{
+			name:     "File over default",
+			file:     "default_format: summary\n",
+			expected: "summary",
+			source:   SourceFile,
+		},
+		{
+			name:     "Env over file",
+			file:     "default_format: summary\n",
+			env:      map[string]string{"SKY_DEFAULT_FORMAT": "json"},
+			expected: "json",
+			source:   SourceEnv,
+		},
+		{
+			name:     "Env alias",
+			file:     "default_format: summary\n",
+			env:      map[string]string{"SKY_FORMAT": "markdown"},
+			expected: "markdown",
+			source:   SourceEnv,
+		},
+		{
+			name:     "Prefixed env over alias",
+			env:      map[string]string{"SKY_DEFAULT_FORMAT": "json", "SKY_FORMAT": "markdown"},
+			expected: "json",
+			source:   SourceEnv,
+		},
+		{
+			name:     "Flag over env",
+			file:     "default_format: summary\n",
+			env:      map[string]string{"SKY_DEFAULT_FORMAT": "json"},
+			flag:     "markdown",
+			expected: "markdown",
+			source:   SourceFlag,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := isolateEnv(t)
+
+			var opts LoadOptions
+			if tt.file != "" {
+				opts.File = filepath.Join(home, "sky.yaml")
+				if err := os.WriteFile(opts.File, []byte(tt.file), 0600); err != nil {
+					t.Fatalf("failed to write config: %v", err)
+				}
+			}
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			if tt.flag != "" {
+				flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+				flags.String("format", "", "")
+				if err := flags.Parse([]string{"--format", tt.flag}); err != nil {
+					t.Fatalf("failed to parse flags: %v", err)
+				}
+				opts.Flags = map[string]*pflag.Flag{"default_format": flags.Lookup("format")}
+			}
+
+			cfg, err := Load(opts)
+			if err != nil {
+				t.Fatalf("Load() failed: %v", err)
+			}
+
+			if cfg.DefaultFormat != tt.expected {
+				t.Errorf("DefaultFormat = %s; want %s", cfg.DefaultFormat, tt.expected)
+			}
+			if got := cfg.source("default_format"); got != tt.source {
+				t.Errorf("source(default_format) = %s; want %s", got, tt.source)
+			}
+		})
+	}
+}
+
+func TestOutputFormat(t *testing.T) {
+	const file = `default_format: summary
+profiles:
+  work:
+    default_format: markdown
+locations:
+  home:
+    latitude: 59.0
+    longitude: 10.0
+    format: chart
+`
+	tests := []struct {
+		name     string
+		env      map[string]string
+		flag     string
+		expected string
+	}{
+		{name: "Saved format over file", expected: "chart"},
+		{name: "Env over saved format", env: map[string]string{"SKY_FORMAT": "json"}, expected: "json"},
+		{name: "Flag over saved format", flag: "json", expected: "json"},
+		{name: "Profile over saved format", env: map[string]string{"SKY_PROFILE": "work"}, expected: "markdown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := isolateEnv(t)
+			opts := LoadOptions{File: filepath.Join(home, "sky.yaml")}
+			if err := os.WriteFile(opts.File, []byte(file), 0600); err != nil {
+				t.Fatalf("failed to write config: %v", err)
+			}
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			if tt.flag != "" {
+				flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+				flags.String("format", "", "")
+				if err := flags.Parse([]string{"--format", tt.flag}); err != nil {
+					t.Fatalf("failed to parse flags: %v", err)
+				}
+				opts.Flags = map[string]*pflag.Flag{"default_format": flags.Lookup("format")}
+			}
+
+			cfg, err := Load(opts)
+			if err != nil {
+				t.Fatalf("Load() failed: %v", err)
+			}
+
+			loc := cfg.Locations["home"]
+			if got := cfg.OutputFormat(loc.Format); got != tt.expected {
+				t.Errorf("OutputFormat(%s) = %s; want %s", loc.Format, got, tt.expected)
+			}
+			if got := cfg.OutputFormat(""); got != cfg.DefaultFormat {
+				t.Errorf("OutputFormat(\"\") = %s; want %s", got, cfg.DefaultFormat)
+			}
+		})
+	}
+}
+
+func TestLoadEnv(t *testing.T) {
+	home := isolateEnv(t)
+	t.Setenv("SKY_DEFAULT_LOCATION", "oslo")
+	t.Setenv("SKY_CACHE_ENABLED", "false")
+	t.Setenv("SKY_CACHE_TTL_MINUTES", "3")
+	t.Setenv("NO_COLOR", "1")
+
+	file := filepath.Join(home, "config.yaml")
+	input := "default_location: stavern\nno_color: false\nlocations:\n  oslo:\n    latitude: 59.9\n    longitude: 10.7\n"
+	if err := os.WriteFile(file, []byte(input), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := Load(LoadOptions{File: file})
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	if cfg.DefaultLocation != "oslo" {
+		t.Errorf("DefaultLocation = %s; want oslo", cfg.DefaultLocation)
+	}
+	if cfg.Cache.Enabled {
+		t.Error("Cache.Enabled = true; want false")
+	}
+	if cfg.Cache.TTLMinutes != 3 {
+		t.Errorf("Cache.TTLMinutes = %d; want 3", cfg.Cache.TTLMinutes)
+	}
+	if !cfg.NoColor {
+		t.Error("NoColor = false; want true when NO_COLOR is set")
+	}
+
+	// Overrides must not be written back to the file
+	cfg.Locations["bergen"] = &models.Location{Latitude: 60.39, Longitude: 5.32}
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	var saved Config
+	data, _ := os.ReadFile(file)
+	if err := yaml.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("failed to parse saved config: %v", err)
+	}
+	if saved.DefaultLocation != "stavern" || saved.NoColor || strings.Contains(string(data), "cache:") {
+		t.Errorf("Save() persisted environment overrides:\n%s", data)
+	}
+	if _, ok := saved.Locations["bergen"]; !ok {
+		t.Errorf("Save() did not persist the new location:\n%s", data)
+	}
+
+	// Invalid values are reported with the variable name
+	t.Setenv("SKY_CACHE_ENABLED", "maybe")
+	if _, err := Load(LoadOptions{File: file}); err == nil {
+		t.Error("Load() expected error for invalid SKY_CACHE_ENABLED")
+	}
+}
+
+func TestLoadPaths(t *testing.T) {
+	t.Run("XDG config and cache", func(t *testing.T) {
+		isolateEnv(t)
+		xdg := t.TempDir()
+		t.Setenv("HOME", "")
+		t.Setenv("XDG_CONFIG_HOME", filepath.Join(xdg, "config"))
+		t.Setenv("XDG_CACHE_HOME", filepath.Join(xdg, "cache"))
+
+		file := filepath.Join(xdg, "config", "sky", "config.yaml")
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatalf("failed to create config dir: %v", err)
+		}
+		if err := os.WriteFile(file, []byte("default_format: json\n"), 0600); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+
+		cfg, err := Load(LoadOptions{})
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if cfg.Path() != file {
+			t.Errorf("Path() = %s; want %s", cfg.Path(), file)
+		}
+		if cfg.DefaultFormat != "json" {
+			t.Errorf("DefaultFormat = %s; want json", cfg.DefaultFormat)
+		}
+		if want := filepath.Join(xdg, "cache", "sky"); cfg.Cache.Directory != want {
+			t.Errorf("Cache.Directory = %s; want %s", cfg.Cache.Directory, want)
+		}
+	})
+
+	t.Run("New file under XDG_CONFIG_HOME", func(t *testing.T) {
+		isolateEnv(t)
+		xdg := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", xdg)
+
+		cfg, err := Load(LoadOptions{})
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if want := filepath.Join(xdg, "sky", "config.yaml"); cfg.Path() != want {
+			t.Errorf("Path() = %s; want %s", cfg.Path(), want)
+		}
+	})
+
+	t.Run("SKY_CONFIG", func(t *testing.T) {
+		home := isolateEnv(t)
+		file := filepath.Join(home, "custom.yaml")
+		if err := os.WriteFile(file, []byte("no_emoji: true\n"), 0600); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		t.Setenv("SKY_CONFIG", file)
+
+		cfg, err := Load(LoadOptions{})
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if !cfg.NoEmoji {
+			t.Error("NoEmoji = false; want true from SKY_CONFIG file")
+		}
+	})
+
+	t.Run("Missing explicit file", func(t *testing.T) {
+		home := isolateEnv(t)
+		if _, err := Load(LoadOptions{File: filepath.Join(home, "missing.yaml")}); err == nil {
+			t.Error("Load() expected error for a missing --config file")
+		}
+	})
+}
+
+func TestRenameLocation(t *testing.T) {
+	cfg := &Config{
+		DefaultLocation: "home",
+		Locations: map[string]*models.Location{
+			"home":  {Name: "home", Latitude: 59, Longitude: 10},
+			"cabin": {Name: "Cabin", Latitude: 61, Longitude: 8},
+		},
+		Profiles: map[string]Profile{
+			"travel": {"default_location": "home"},
+			"work":   {"default_location": "cabin"},
+		},
+	}
+
+	if err := cfg.RenameLocation("home", "house"); err != nil {
+		t.Fatalf("RenameLocation() failed: %v", err)
+	}
+	if _, ok := cfg.Locations["home"]; ok {
+		t.Error("RenameLocation() kept the old name")
+	}
+	if loc := cfg.Locations["house"]; loc == nil || loc.Name != "house" || loc.Latitude != 59 {
+		t.Errorf("Locations[house] = %v; want the renamed location", loc)
+	}
+	if cfg.DefaultLocation != "house" {
+		t.Errorf("DefaultLocation = %s; want house", cfg.DefaultLocation)
+	}
+	if got := cfg.Profiles["travel"]["default_location"]; got != "house" {
+		t.Errorf("travel profile default_location = %v; want house", got)
+	}
+	if got := cfg.Profiles["work"]["default_location"]; got != "cabin" {
+		t.Errorf("work profile default_location = %v; want cabin", got)
+	}
+
+	if err := cfg.RenameLocation("cabin", "house"); err == nil {
+		t.Error("RenameLocation() onto an existing name expected error but got none")
+	}
+	if err := cfg.RenameLocation("office", "work"); err == nil {
+		t.Error("RenameLocation() of a missing location expected error but got none")
+	}
+}
+
+func TestLocationGroups(t *testing.T) {
+	cfg := &Config{
+		Locations: map[string]*models.Location{
+			"stavern": {Latitude: 59.0, Longitude: 10.03},
+			"larvik":  {Latitude: 59.05, Longitude: 10.03},
+			"oslo":    {Latitude: 59.91, Longitude: 10.75},
+		},
+	}
+
+	if err := cfg.AddToGroup("coast", "stavern", "larvik"); err != nil {
+		t.Fatalf("AddToGroup() failed: %v", err)
+	}
+	if err := cfg.AddToGroup("coast", "larvik", "oslo"); err != nil {
+		t.Fatalf("AddToGroup() failed: %v", err)
+	}
+	if got := strings.Join(cfg.Groups["coast"], ","); got != "stavern,larvik,oslo" {
+		t.Errorf("Groups[coast] = %s; want stavern,larvik,oslo", got)
+	}
+	if err := cfg.AddToGroup("coast", "bergen"); err == nil {
+		t.Error("AddToGroup() of an unknown location expected error but got none")
+	}
+	if err := cfg.AddToGroup("oslo", "stavern"); err == nil {
+		t.Error("AddToGroup() with the name of a location expected error but got none")
+	}
+
+	if err := cfg.RemoveFromGroup("coast", "oslo"); err != nil {
+		t.Fatalf("RemoveFromGroup() failed: %v", err)
+	}
+	if err := cfg.RemoveFromGroup("coast", "oslo"); err == nil {
+		t.Error("RemoveFromGroup() of a non-member expected error but got none")
+	}
+
+	if err := cfg.RenameLocation("larvik", "larvik-havn"); err != nil {
+		t.Fatalf("RenameLocation() failed: %v", err)
+	}
+	if got := strings.Join(cfg.Groups["coast"], ","); got != "stavern,larvik-havn" {
+		t.Errorf("Groups[coast] after rename = %s; want stavern,larvik-havn", got)
+	}
+
+	if err := cfg.RemoveLocation("stavern"); err != nil {
+		t.Fatalf("RemoveLocation() failed: %v", err)
+	}
+	if err := cfg.RemoveLocation("larvik-havn"); err != nil {
+		t.Fatalf("RemoveLocation() failed: %v", err)
+	}
+	if _, ok := cfg.Groups["coast"]; ok {
+		t.Errorf("RemoveLocation() left an empty group: %v", cfg.Groups)
+	}
+
+	cfg.Groups = map[string][]string{"east": {"oslo", "drammen"}}
+	if problems := cfg.Validate(); len(problems) != 1 {
+		t.Errorf("Validate() returned %d problems; want 1: %v", len(problems), problems)
+	}
+	if err := cfg.RemoveFromGroup("east"); err != nil {
+		t.Fatalf("RemoveFromGroup() of the whole group failed: %v", err)
+	}
+	if len(cfg.GroupNames()) != 0 {
+		t.Errorf("GroupNames() = %v; want none", cfg.GroupNames())
+	}
+}
+
+func TestActivities(t *testing.T) {
+	home := isolateEnv(t)
+	path := filepath.Join(home, ".sky", "config.yaml")
+	content := `activities:
+  running:
+    max_wind: 6
+    daylight: false
+  kayaking:
+    min_temp: 12
+    max_wind: 5
+`
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create config directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := Load(LoadOptions{})
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	want := []string{"cycling", "hiking", "kayaking", "painting", "running", "sailing"}
+	if got := cfg.ActivityNames(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("ActivityNames() = %v; want %v", got, want)
+	}
+
+	// Configured limits override the built-in ones one by one
+	running, err := cfg.GetActivity("running")
+	if err != nil {
+		t.Fatalf("GetActivity(running) failed: %v", err)
+	}
+	if *running.MaxWind != 6 || *running.MinTemp != 5 || running.NeedsDaylight() {
+		t.Errorf("GetActivity(running) = max_wind %v, min_temp %v, daylight %v; want 6, 5, false",
+			*running.MaxWind, *running.MinTemp, running.NeedsDaylight())
+	}
+
+	kayaking, err := cfg.GetActivity("kayaking")
+	if err != nil {
+		t.Fatalf("GetActivity(kayaking) failed: %v", err)
+	}
+	if *kayaking.MinTemp != 12 || kayaking.MaxTemp != nil || kayaking.NeedsDaylight() {
+		t.Errorf("GetActivity(kayaking) = %+v; want only min_temp and max_wind", kayaking)
+	}
+
+	if _, err := cfg.GetActivity("curling"); err == nil {
+		t.Error("GetActivity() of an unknown activity expected error but got none")
+	}
+
+	// The built-in activities are not changed by overrides
+	if cycling, _ := cfg.GetActivity("cycling"); !cycling.NeedsDaylight() {
+		t.Error("GetActivity(cycling) lost its daylight limit")
+	}
+
+	if err := cfg.Set("activities.kayaking.max_temp", "8"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+	if err := cfg.Set("activities.running.max_precipitation", "-1"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+	problems := cfg.Validate()
+	if len(problems) != 2 {
+		t.Errorf("Validate() returned %d problems; want 2: %v", len(problems), problems)
+	}
+}
+
+func TestRules(t *testing.T) {
+	home := isolateEnv(t)
+	path := filepath.Join(home, ".sky", "config.yaml")
+	content := `rules:
+  frost:
+    when: temp < 0 within 12h
+    message: Cover the plants
+  gusts:
+    when: gust > 20
+`
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create config directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := Load(LoadOptions{})
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	if got := cfg.RuleNames(); strings.Join(got, ",") != "frost,gusts" {
+		t.Errorf("RuleNames() = %v; want [frost gusts]", got)
+	}
+	if frost := cfg.Rules["frost"]; frost.When != "temp < 0 within 12h" || frost.Message != "Cover the plants" {
+		t.Errorf("Rules[frost] = %+v; want the condition and message", frost)
+	}
+	if problems := cfg.Validate(); len(problems) != 0 {
+		t.Errorf("Validate() = %v; want no problems", problems)
+	}
+
+	if err := cfg.Set("rules.rain.when", "precip_prob > 70 and hour in 7..9"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+	if err := cfg.Set("rules.gusts.when", "gusts > 20"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+	if err := cfg.Set("rules.empty.message", "No condition"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+	problems := cfg.Validate()
+	if len(problems) != 2 {
+		t.Errorf("Validate() returned %d problems; want 2: %v", len(problems), problems)
+	}
+}
+
+func TestNotifiers(t *testing.T) {
+	home := isolateEnv(t)
+	path := filepath.Join(home, ".sky", "config.yaml")
+	content := `notifiers:
+  team:
+    type: slack
+    url: https://hooks.slack.com/services/T000/B000/XXXX
+  phone:
+    type: ntfy
+    url: https://ntfy.sh/my-weather
+    retries: -1
+`
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create config directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := Load(LoadOptions{})
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	if got := cfg.NotifierNames(); strings.Join(got, ",") != "phone,team" {
+		t.Errorf("NotifierNames() = %v; want [phone team]", got)
+	}
+	if phone := cfg.Notifiers["phone"]; phone.Type != "ntfy" || phone.Retries != -1 {
+		t.Errorf("Notifiers[phone] = %+v; want ntfy without retries", phone)
+	}
+	if problems := cfg.Validate(); len(problems) != 0 {
+		t.Errorf("Validate() = %v; want no problems", problems)
+	}
+
+	if err := cfg.Set("notifiers.room.type", "matrix"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+	if err := cfg.Set("notifiers.room.url", "https://matrix.org"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+	if err := cfg.Set("notifiers.team.type", "irc"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+	problems := cfg.Validate()
+	if len(problems) != 2 {
+		t.Errorf("Validate() returned %d problems; want 2: %v", len(problems), problems)
+	}
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// envPrefix is the prefix of all environment variables read by sky
+const envPrefix = "SKY_"
+
+// envAliases lists extra environment variables for a config key, checked
+// after the SKY_* variable derived from the key itself
+var envAliases = map[string][]string{
+	"default_format": {"SKY_FORMAT"},
+	"no_color":       {"NO_COLOR"},
+}
+
+// overridableKeys returns the config keys that can be set through
+// environment variables
+func overridableKeys() []string {
+	keys := make([]string, 0, len(defaults()))
+	for key := range defaults() {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// EnvNames returns the environment variables that set a config key,
+// in the order they are checked
+func EnvNames(key string) []string {
+	name := envPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+	return append([]string{name}, envAliases[key]...)
+}
+
+// lookupEnv returns the value for key from the environment, if any
+func lookupEnv(key string) (string, bool) {
+	for _, name := range EnvNames(key) {
+		value, ok := os.LookupEnv(name)
+		if !ok || value == "" {
+			continue
+		}
+
+		// NO_COLOR disables color whenever it is set to any value
+		// (https://no-color.org)
+		if name == "NO_COLOR" {
+			return "true", true
+		}
+		return value, true
+	}
+	return "", false
+}
+
+// searchPaths returns the directories searched for config.yaml
+func searchPaths() []string {
+	var dirs []string
+	if home := os.Getenv("HOME"); home != "" {
+		dirs = append(dirs, filepath.Join(home, ".sky"))
+	}
+	if dir := configHome(); dir != "" {
+		dirs = append(dirs, filepath.Join(dir, "sky"))
+	}
+	return append(dirs, ".")
+}
+
+// configHome returns $XDG_CONFIG_HOME, falling back to ~/.config
+func configHome() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return dir
+	}
+	if home := os.Getenv("HOME"); home != "" {
+		return filepath.Join(home, ".config")
+	}
+	return ""
+}
+
+// defaultConfigFile returns where a new config file is created: under
+// $XDG_CONFIG_HOME when it is set, otherwise ~/.sky/config.yaml
+func defaultConfigFile() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "sky", "config.yaml")
+	}
+	if home := os.Getenv("HOME"); home != "" {
+		return filepath.Join(home, ".sky", "config.yaml")
+	}
+	return "config.yaml"
+}
+
+// DefaultCacheDir returns the default cache directory: $XDG_CACHE_HOME/sky
+// when it is set, otherwise ~/.sky/cache
+func DefaultCacheDir() string {
+	if dir := os.Getenv("XDG_CACHE_HOME"); dir != "" {
+		return filepath.Join(dir, "sky")
+	}
+	if home := os.Getenv("HOME"); home != "" {
+		return filepath.Join(home, ".sky", "cache")
+	}
+	return filepath.Join(os.TempDir(), "sky-cache")
+}
+
+// DefaultHistoryPath returns the default history archive:
+// $XDG_DATA_HOME/sky/history.db when it is set, otherwise
+// ~/.sky/history.db
+func DefaultHistoryPath() string {
+	return filepath.Join(DefaultDataDir(), "history.db")
+}
+
+// DefaultDataDir returns the directory for data sky keeps, unlike the
+// cache: $XDG_DATA_HOME/sky when it is set, otherwise ~/.sky
+func DefaultDataDir() string {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "sky")
+	}
+	if home := os.Getenv("HOME"); home != "" {
+		return filepath.Join(home, ".sky")
+	}
+	return filepath.Join(os.TempDir(), "sky")
+}
@@ -0,0 +1,422 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Source describes where an effective configuration value came from
+type Source string
+
+const (
+	// SourceDefault means the value is a built-in default
+	SourceDefault Source = "default"
+
+	// SourceFile means the value was read from the config file
+	SourceFile Source = "file"
+
+	// SourceProfile means the value was set by the active profile
+	SourceProfile Source = "profile"
+
+	// SourceEnv means the value was set by an environment variable
+	SourceEnv Source = "env"
+
+	// SourceFlag means the value was set by a command-line flag
+	SourceFlag Source = "flag"
+)
+
+// Setting is a single effective configuration value
+type Setting struct {
+	Key    string
+	Value  string
+	Source Source
+}
+
+// Settings returns every leaf value of the configuration as dotted keys,
+// sorted by key, together with the source of each value
+func (c *Config) Settings() []Setting {
+	var settings []Setting
+	walk(reflect.ValueOf(c).Elem(), "", func(key string, v reflect.Value) {
+		settings = append(settings, Setting{
+			Key:    key,
+			Value:  formatValue(v),
+			Source: c.source(key),
+		})
+	})
+
+	sort.Slice(settings, func(i, j int) bool {
+		return settings[i].Key < settings[j].Key
+	})
+	return settings
+}
+
+// Get returns the value of a dotted key such as "cache.ttl_minutes"
+func (c *Config) Get(key string) (string, error) {
+	v, err := lookup(reflect.ValueOf(c).Elem(), splitKey(key))
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", err, key)
+	}
+	return formatValue(v), nil
+}
+
+// Set parses value and assigns it to a dotted key. Map entries such as
+// "locations.oslo.latitude" are created when they do not exist yet.
+func (c *Config) Set(key, value string) error {
+	if err := c.set(key, value); err != nil {
+		return err
+	}
+
+	c.markDirty(key)
+	return nil
+}
+
+// set assigns value to key without marking it for saving
+func (c *Config) set(key, value string) error {
+	err := update(reflect.ValueOf(c).Elem(), splitKey(key), func(v reflect.Value) error {
+		return parseValue(v, value)
+	})
+	if err != nil {
+		return fmt.Errorf("cannot set %s: %w", key, err)
+	}
+	return nil
+}
+
+// Unset resets a dotted key to its built-in default, and Save removes it
+// from the file. Unsetting a map entry such as "locations.oslo" removes the
+// entry.
+func (c *Config) Unset(key string) error {
+	path := splitKey(key)
+	root := reflect.ValueOf(c).Elem()
+
+	// Removing a whole map entry
+	if len(path) > 1 {
+		parent, err := lookup(root, path[:len(path)-1])
+		if err == nil && parent.Kind() == reflect.Map {
+			k := reflect.ValueOf(path[len(path)-1])
+			if !parent.MapIndex(k).IsValid() {
+				return fmt.Errorf("%w: %s", errUnknownKey, key)
+			}
+			parent.SetMapIndex(k, reflect.Value{})
+			return nil
+		}
+	}
+
+	if _, err := lookup(root, path); err != nil {
+		return fmt.Errorf("%w: %s", err, key)
+	}
+
+	c.markUnset(key)
+	return update(root, path, func(v reflect.Value) error {
+		if def, ok := defaults()[strings.Join(path, ".")]; ok {
+			v.Set(reflect.ValueOf(def).Convert(v.Type()))
+			return nil
+		}
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	})
+}
+
+var errUnknownKey = errors.New("unknown config key")
+
+// markDirty records that key was set explicitly, so Save writes it to
+// the file even when it has the same value as a profile, environment
+// variable or flag provided
+func (c *Config) markDirty(key string) {
+	key = strings.Join(splitKey(key), ".")
+	if c.dirty == nil {
+		c.dirty = make(map[string]bool)
+	}
+	c.dirty[key] = true
+	delete(c.unset, key)
+	delete(c.sources, key)
+}
+
+// markUnset records that key was unset, so Save removes it from the file
+// and it follows the built-in default from then on
+func (c *Config) markUnset(key string) {
+	key = strings.Join(splitKey(key), ".")
+	if c.unset == nil {
+		c.unset = make(map[string]bool)
+	}
+	c.unset[key] = true
+	delete(c.dirty, key)
+	delete(c.sources, key)
+}
+
+// snapshot returns the current value of every leaf key
+func (c *Config) snapshot() map[string]string {
+	values := make(map[string]string)
+	walk(reflect.ValueOf(c).Elem(), "", func(key string, v reflect.Value) {
+		values[key] = formatValue(v)
+	})
+	return values
+}
+
+// flattenKeys returns the dotted leaf keys of a nested map
+func flattenKeys(values map[string]interface{}, prefix string) []string {
+	var keys []string
+	walk(reflect.ValueOf(values), prefix, func(key string, v reflect.Value) {
+		keys = append(keys, key)
+	})
+	sort.Strings(keys)
+	return keys
+}
+
+// splitKey splits a dotted key into its path segments
+func splitKey(key string) []string {
+	return strings.Split(strings.ToLower(strings.TrimSpace(key)), ".")
+}
+
+// fieldByTag returns the struct field whose mapstructure tag matches name
+func fieldByTag(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if tagName(t.Field(i)) == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// tagName returns the config key of a struct field, preferring the
+// mapstructure tag and falling back to the yaml tag (as used by models)
+func tagName(f reflect.StructField) string {
+	if !f.IsExported() {
+		return ""
+	}
+	for _, key := range []string{"mapstructure", "yaml"} {
+		tag := f.Tag.Get(key)
+		if tag == "-" {
+			return ""
+		}
+		if name := strings.Split(tag, ",")[0]; name != "" {
+			return name
+		}
+	}
+	return strings.ToLower(f.Name)
+}
+
+// lookup follows path from v and returns the value it points at
+func lookup(v reflect.Value, path []string) (reflect.Value, error) {
+	for _, segment := range path {
+		if v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return reflect.Value{}, errUnknownKey
+			}
+			v = v.Elem()
+		}
+
+		switch v.Kind() {
+		case reflect.Struct:
+			field, ok := fieldByTag(v, segment)
+			if !ok {
+				return reflect.Value{}, errUnknownKey
+			}
+			v = field
+		case reflect.Map:
+			elem := v.MapIndex(reflect.ValueOf(segment))
+			if !elem.IsValid() {
+				return reflect.Value{}, errUnknownKey
+			}
+			v = elem
+		default:
+			return reflect.Value{}, errUnknownKey
+		}
+	}
+
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v, nil
+}
+
+// update follows path from v, creating map entries on the way, and calls
+// fn with the settable leaf value
+func update(v reflect.Value, path []string, fn func(reflect.Value) error) error {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	if len(path) == 0 {
+		if isLeaf(v) || (v.Kind() == reflect.Interface && (v.IsNil() || isLeaf(v.Elem()))) {
+			return fn(v)
+		}
+		return fmt.Errorf("key is a section, not a value")
+	}
+
+	// Free-form sections such as profiles hold nested maps
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() || v.Elem().Kind() != reflect.Map {
+			v.Set(reflect.ValueOf(map[string]interface{}{}))
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		field, ok := fieldByTag(v, path[0])
+		if !ok {
+			return errUnknownKey
+		}
+		return update(field, path[1:], fn)
+	case reflect.Map:
+		elemType := v.Type().Elem()
+		if len(path) == 1 && elemType.Kind() != reflect.Interface && !isLeaf(reflect.Zero(elemType)) {
+			return fmt.Errorf("key is a section, not a value")
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		key := reflect.ValueOf(path[0])
+
+		// Map elements are not addressable, so work on a copy and
+		// store it back afterwards
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if existing := v.MapIndex(key); existing.IsValid() {
+			elem.Set(existing)
+		}
+		if err := update(elem, path[1:], fn); err != nil {
+			return err
+		}
+		v.SetMapIndex(key, elem)
+		return nil
+	default:
+		return errUnknownKey
+	}
+}
+
+// walk calls fn for every leaf value below v
+func walk(v reflect.Value, prefix string, fn func(key string, v reflect.Value)) {
+	if v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	join := func(name string) string {
+		if prefix == "" {
+			return name
+		}
+		return prefix + "." + name
+	}
+
+	switch {
+	case isLeaf(v):
+		fn(prefix, v)
+	case v.Kind() == reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if name := tagName(t.Field(i)); name != "" {
+				walk(v.Field(i), join(name), fn)
+			}
+		}
+	case v.Kind() == reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return keys[i].String() < keys[j].String()
+		})
+		for _, k := range keys {
+			walk(v.MapIndex(k), join(k.String()), fn)
+		}
+	}
+}
+
+// isLeaf reports whether v holds a plain value rather than a section
+func isLeaf(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return v.Type().Elem().Kind() == reflect.String
+	}
+	return false
+}
+
+// formatValue renders a leaf value as a string
+func formatValue(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case reflect.Slice:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = v.Index(i).String()
+		}
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// parseValue parses s according to the kind of v and assigns it
+func parseValue(v reflect.Value, s string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("invalid boolean: %s", s)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid integer: %s", s)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid integer: %s", s)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number: %s", s)
+		}
+		v.SetFloat(f)
+	case reflect.Interface:
+		v.Set(reflect.ValueOf(inferValue(s)))
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items).Convert(v.Type()))
+	default:
+		return fmt.Errorf("unsupported value type %s", v.Type())
+	}
+	return nil
+}
+
+// inferValue converts s to a bool or number when it looks like one, for
+// free-form sections that have no fixed type
+func inferValue(s string) interface{} {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return int(n)
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	switch s {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	return s
+}
@@ -0,0 +1,157 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+func TestConfigSetGet(t *testing.T) {
+	tests := []struct {
+		name      string
+		key       string
+		value     string
+		expected  string
+		shouldErr bool
+	}{
+		{"String value", "default_format", "json", "json", false},
+		{"Bool value", "no_color", "true", "true", false},
+		{"Nested int value", "cache.ttl_minutes", "30", "30", false},
+		{"Existing location field", "locations.oslo.latitude", "59.5", "59.5", false},
+		{"New location field", "locations.bergen.longitude", "5.32", "5.32", false},
+		{"Location tags", "locations.oslo.tags", "coast, capital", "coast,capital", false},
+		{"Case-insensitive key", "Cache.TTL_Minutes", "15", "15", false},
+		{"Invalid bool", "no_emoji", "maybe", "", true},
+		{"Invalid int", "cache.ttl_minutes", "ten", "", true},
+		{"Unknown key", "cache.size", "1", "", true},
+		{"Section instead of value", "cache", "1", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Locations: map[string]*models.Location{
+					"oslo": {Name: "Oslo", Latitude: 59.9, Longitude: 10.7},
+				},
+			}
+
+			err := cfg.Set(tt.key, tt.value)
+			if tt.shouldErr {
+				if err == nil {
+					t.Errorf("Set(%s, %s) expected error but got none", tt.key, tt.value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Set(%s, %s) unexpected error: %v", tt.key, tt.value, err)
+			}
+
+			got, err := cfg.Get(tt.key)
+			if err != nil {
+				t.Fatalf("Get(%s) unexpected error: %v", tt.key, err)
+			}
+			if got != tt.expected {
+				t.Errorf("Get(%s) = %s; want %s", tt.key, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConfigUnset(t *testing.T) {
+	cfg := &Config{
+		DefaultFormat: "json",
+		Cache:         CacheConfig{TTLMinutes: 60},
+		Locations: map[string]*models.Location{
+			"oslo": {Name: "Oslo", Latitude: 59.9, Longitude: 10.7, Timezone: "Europe/Oslo"},
+		},
+	}
+
+	if err := cfg.Unset("default_format"); err != nil {
+		t.Fatalf("Unset(default_format) unexpected error: %v", err)
+	}
+	if cfg.DefaultFormat != "full" {
+		t.Errorf("DefaultFormat = %s; want full", cfg.DefaultFormat)
+	}
+
+	if err := cfg.Unset("cache.ttl_minutes"); err != nil {
+		t.Fatalf("Unset(cache.ttl_minutes) unexpected error: %v", err)
+	}
+	if cfg.Cache.TTLMinutes != 10 {
+		t.Errorf("Cache.TTLMinutes = %d; want 10", cfg.Cache.TTLMinutes)
+	}
+
+	if err := cfg.Unset("locations.oslo.timezone"); err != nil {
+		t.Fatalf("Unset(locations.oslo.timezone) unexpected error: %v", err)
+	}
+	if cfg.Locations["oslo"].Timezone != "" {
+		t.Errorf("Timezone = %s; want empty", cfg.Locations["oslo"].Timezone)
+	}
+
+	if err := cfg.Unset("locations.oslo"); err != nil {
+		t.Fatalf("Unset(locations.oslo) unexpected error: %v", err)
+	}
+	if _, ok := cfg.Locations["oslo"]; ok {
+		t.Error("Unset(locations.oslo) did not remove the location")
+	}
+
+	if err := cfg.Unset("locations.oslo"); err == nil {
+		t.Error("Unset() of missing location expected error but got none")
+	}
+}
+
+func TestConfigUnsetSave(t *testing.T) {
+	home := isolateEnv(t)
+	path := filepath.Join(home, "config.yaml")
+	input := "default_format: json\ncache:\n  enabled: false\n  ttl_minutes: 60\n"
+	if err := os.WriteFile(path, []byte(input), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := Load(LoadOptions{File: path, SkipMigration: true})
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	for _, key := range []string{"default_format", "cache.ttl_minutes"} {
+		if err := cfg.Unset(key); err != nil {
+			t.Fatalf("Unset(%s) unexpected error: %v", key, err)
+		}
+	}
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	// Unset keys are removed from the file rather than set to the
+	// defaults of this version
+	data, _ := os.ReadFile(path)
+	if want := "cache:\n  enabled: false\n"; string(data) != want {
+		t.Errorf("Save() after Unset() wrote\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	cfg := &Config{
+		DefaultLocation: "home",
+		DefaultFormat:   "xml",
+		Locations: map[string]*models.Location{
+			"oslo":  {Latitude: 59.9, Longitude: 10.7},
+			"north": {Latitude: 95, Longitude: 10},
+			"cabin": {Latitude: 61, Longitude: 8, Format: "xml", Provider: "yr"},
+		},
+	}
+
+	problems := cfg.Validate()
+	if len(problems) != 5 {
+		t.Errorf("Validate() returned %d problems; want 5: %v", len(problems), problems)
+	}
+
+	cfg.DefaultLocation = "oslo"
+	cfg.DefaultFormat = "json"
+	cfg.Locations["cabin"].Format = "summary"
+	cfg.Locations["cabin"].Provider = "met"
+	delete(cfg.Locations, "north")
+	if problems := cfg.Validate(); len(problems) != 0 {
+		t.Errorf("Validate() unexpected problems: %v", problems)
+	}
+}
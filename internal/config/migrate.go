@@ -0,0 +1,228 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+	"go.yaml.in/yaml/v3"
+)
+
+// CurrentVersion is the config file version written by this build of sky.
+// Files without a version key are version 0.
+const CurrentVersion = 1
+
+// migration upgrades a config document by a single version
+type migration struct {
+	// description explains the change to the user
+	description string
+
+	// apply edits the root mapping of the document in place
+	apply func(root *yaml.Node) error
+}
+
+// migrations[i] upgrades a file from version i to version i+1. Add new
+// steps at the end and bump CurrentVersion; never edit a released step.
+var migrations = []migration{
+	{
+		description: "save the built-in 'stavern' location in the file",
+		apply:       saveBuiltinLocations,
+	},
+}
+
+// Migration describes the upgrade of a config file to CurrentVersion
+type Migration struct {
+	// Path is the migrated file
+	Path string
+
+	// From and To are the file versions before and after the upgrade
+	From int
+	To   int
+
+	// Steps describes each migration that was applied, in order
+	Steps []string
+
+	// Data holds the migrated file contents
+	Data []byte
+
+	// Backup is where the original file was saved, empty when nothing
+	// was written
+	Backup string
+}
+
+// Changed reports whether the file needed an upgrade
+func (m *Migration) Changed() bool {
+	return m.From != m.To
+}
+
+// Migrate upgrades the config file at path to CurrentVersion, one version
+// at a time. The original file is kept as path + ".v<version>.bak". With
+// dryRun the result is returned without writing anything.
+func Migrate(path string, dryRun bool) (*Migration, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	m, err := migrateData(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to migrate %s: %w", path, err)
+	}
+	m.Path = path
+
+	if dryRun || !m.Changed() {
+		return m, nil
+	}
+
+	if err := m.write(data); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// write replaces the file with the migrated contents, keeping the
+// original contents as a backup
+func (m *Migration) write(original []byte) error {
+	backup := fmt.Sprintf("%s.v%d.bak", m.Path, m.From)
+	if err := replaceFile(m.Path, m.Data, original, backup); err != nil {
+		return err
+	}
+	m.Backup = backup
+	return nil
+}
+
+// migrateData applies every pending migration to a config document
+func migrateData(data []byte) (*Migration, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	keepBlankLines(&doc, strings.Split(string(data), "\n"))
+
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		head := doc.HeadComment
+		doc = yaml.Node{Kind: yaml.DocumentNode, HeadComment: head}
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("config file must be a mapping")
+	}
+
+	version, err := fileVersion(root)
+	if err != nil {
+		return nil, err
+	}
+	if version > CurrentVersion {
+		return nil, fmt.Errorf("config file version %d is newer than this version of sky supports (%d)", version, CurrentVersion)
+	}
+
+	m := &Migration{From: version, To: CurrentVersion, Data: data}
+	if !m.Changed() {
+		return m, nil
+	}
+
+	for i := version; i < CurrentVersion; i++ {
+		if err := migrations[i].apply(root); err != nil {
+			return nil, fmt.Errorf("migration to version %d: %w", i+1, err)
+		}
+		m.Steps = append(m.Steps, fmt.Sprintf("%d → %d: %s", i, i+1, migrations[i].description))
+	}
+	setVersion(root, CurrentVersion)
+
+	if m.Data, err = encodeDocument(&doc); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// fileVersion returns the version key of a config document, or 0
+func fileVersion(root *yaml.Node) (int, error) {
+	i := findKey(root, "version")
+	if i < 0 {
+		return 0, nil
+	}
+
+	var version int
+	if err := root.Content[i+1].Decode(&version); err != nil || version < 0 {
+		return 0, fmt.Errorf("invalid config version: %s", root.Content[i+1].Value)
+	}
+	return version, nil
+}
+
+// setVersion sets the version key, adding it as the first key of the
+// document if it is missing
+func setVersion(root *yaml.Node, version int) {
+	value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(version)}
+
+	if i := findKey(root, "version"); i >= 0 {
+		root.Content[i+1] = value
+		return
+	}
+
+	key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "version"}
+
+	// A comment above the first key describes the file, so keep it on top
+	if len(root.Content) > 0 {
+		key.HeadComment, root.Content[0].HeadComment = root.Content[0].HeadComment, ""
+	}
+	root.Content = append([]*yaml.Node{key, value}, root.Content...)
+}
+
+// builtinLocations returns the locations a new configuration starts with
+func builtinLocations() map[string]*models.Location {
+	return map[string]*models.Location{
+		"stavern": {
+			Name:      "Stavern, Norway",
+			Latitude:  59.0,
+			Longitude: 10.0,
+			Timezone:  "Europe/Oslo",
+		},
+	}
+}
+
+// saveBuiltinLocations (version 0 → 1) writes the built-in locations to
+// the file. Older versions merged them into every file, so removing them
+// from the file had no effect; from version 1 on, the file's locations are
+// used as they are, and the built-in ones stay available by being saved.
+func saveBuiltinLocations(root *yaml.Node) error {
+	i := findKey(root, "locations")
+	if i < 0 {
+		root.Content = append(root.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "locations"},
+			&yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"})
+		i = len(root.Content) - 2
+	}
+
+	locations := root.Content[i+1]
+	if locations.Kind == yaml.ScalarNode && locations.Tag == "!!null" {
+		*locations = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", LineComment: locations.LineComment}
+	}
+	if locations.Kind != yaml.MappingNode {
+		return fmt.Errorf("locations must be a mapping")
+	}
+
+	builtin := builtinLocations()
+	names := make([]string, 0, len(builtin))
+	for name := range builtin {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if findKey(locations, name) >= 0 {
+			continue
+		}
+
+		var value yaml.Node
+		if err := value.Encode(builtin[name]); err != nil {
+			return err
+		}
+		locations.Content = append(locations.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name}, &value)
+	}
+	locations.Style &^= yaml.FlowStyle
+	return nil
+}
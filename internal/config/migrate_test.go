@@ -0,0 +1,190 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMigrate(t *testing.T) {
+	tests := []struct {
+		name  string
+		from  int
+		steps int
+	}{
+		{name: "v0-default", from: 0, steps: 1},
+		{name: "v0-custom", from: 0, steps: 1},
+		{name: "v0-empty", from: 0, steps: 1},
+		{name: "v1", from: 1, steps: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input, err := os.ReadFile(filepath.Join("testdata", "migrate", tt.name+".input.yaml"))
+			if err != nil {
+				t.Fatalf("failed to read input: %v", err)
+			}
+
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, input, 0600); err != nil {
+				t.Fatalf("failed to write input: %v", err)
+			}
+
+			// A dry run must not touch the file
+			dry, err := Migrate(path, true)
+			if err != nil {
+				t.Fatalf("Migrate(dry run) failed: %v", err)
+			}
+			if data, _ := os.ReadFile(path); string(data) != string(input) {
+				t.Error("Migrate(dry run) changed the file")
+			}
+
+			m, err := Migrate(path, false)
+			if err != nil {
+				t.Fatalf("Migrate() failed: %v", err)
+			}
+			if m.From != tt.from || m.To != CurrentVersion || len(m.Steps) != tt.steps {
+				t.Errorf("Migrate() = from %d to %d in %d steps; want from %d to %d in %d steps",
+					m.From, m.To, len(m.Steps), tt.from, CurrentVersion, tt.steps)
+			}
+			if string(dry.Data) != string(m.Data) {
+				t.Error("Migrate(dry run) output differs from the migrated file")
+			}
+
+			got, _ := os.ReadFile(path)
+			golden := filepath.Join("testdata", "migrate", tt.name+".golden.yaml")
+			if !m.Changed() {
+				if string(got) != string(input) {
+					t.Errorf("Migrate() rewrote an up-to-date file:\n%s", got)
+				}
+				if m.Backup != "" {
+					t.Errorf("Migrate() wrote a backup for an up-to-date file")
+				}
+				return
+			}
+
+			if *updateGolden {
+				if err := os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatalf("failed to update golden file: %v", err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read golden file: %v", err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("Migrate() output mismatch\n--- got ---\n%s\n--- want ---\n%s", got, want)
+			}
+
+			backup, err := os.ReadFile(path + ".v0.bak")
+			if err != nil || string(backup) != string(input) || m.Backup != path+".v0.bak" {
+				t.Errorf("Migrate() backup = %q (%v); want the original file", backup, err)
+			}
+
+			// Migrating again is a no-op
+			again, err := Migrate(path, false)
+			if err != nil || again.Changed() {
+				t.Errorf("second Migrate() = %+v, %v; want no change", again, err)
+			}
+		})
+	}
+}
+
+func TestMigrateNewerVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("version: 99\n"), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	if _, err := Migrate(path, true); err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Errorf("Migrate() error = %v; want newer version error", err)
+	}
+	if _, err := Load(LoadOptions{File: path}); err == nil {
+		t.Error("Load() expected error for a file from a newer version")
+	}
+}
+
+func TestLoadMigrates(t *testing.T) {
+	home := isolateEnv(t)
+
+	file := filepath.Join(home, "config.yaml")
+	input := "default_location: oslo\nlocations:\n  oslo:\n    latitude: 59.9\n    longitude: 10.7\n"
+	if err := os.WriteFile(file, []byte(input), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	// Loading upgrades the file in memory only
+	cfg, err := Load(LoadOptions{File: file})
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if m := cfg.Migration(); m == nil || !m.Changed() || m.Backup != "" {
+		t.Fatalf("Migration() = %+v; want an upgrade in memory", m)
+	}
+	if cfg.Version != CurrentVersion {
+		t.Errorf("Version = %d; want %d", cfg.Version, CurrentVersion)
+	}
+	if _, ok := cfg.Locations["stavern"]; !ok {
+		t.Error("Load() dropped the built-in location of the old file")
+	}
+	if data, _ := os.ReadFile(file); string(data) != input {
+		t.Errorf("Load() rewrote the file:\n%s", data)
+	}
+	if _, err := os.Stat(file + ".v0.bak"); !os.IsNotExist(err) {
+		t.Error("Load() wrote a backup")
+	}
+
+	// Saving writes the upgrade, keeping the original as a backup
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	if m := cfg.Migration(); m.Backup != file+".v0.bak" {
+		t.Errorf("Migration().Backup = %q; want %q", m.Backup, file+".v0.bak")
+	}
+	if backup, _ := os.ReadFile(file + ".v0.bak"); string(backup) != input {
+		t.Errorf("Save() backup = %q; want the original file", backup)
+	}
+
+	// Removing the built-in location must stick once the file is current
+	cfg, err = Load(LoadOptions{File: file})
+	if err != nil {
+		t.Fatalf("second Load() failed: %v", err)
+	}
+	if cfg.Migration() != nil {
+		t.Error("second Load() migrated the file again")
+	}
+	if err := cfg.Unset("locations.stavern"); err != nil {
+		t.Fatalf("Unset(locations.stavern) failed: %v", err)
+	}
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	cfg, err = Load(LoadOptions{File: file})
+	if err != nil {
+		t.Fatalf("third Load() failed: %v", err)
+	}
+	if _, ok := cfg.Locations["stavern"]; ok {
+		t.Error("Load() merged the removed built-in location back in")
+	}
+
+	// Without a file the built-in location is available and saved
+	isolateEnv(t)
+	cfg, err = Load(LoadOptions{})
+	if err != nil {
+		t.Fatalf("Load() without a file failed: %v", err)
+	}
+	if _, err := cfg.GetDefaultLocation(); err != nil {
+		t.Errorf("GetDefaultLocation() without a file failed: %v", err)
+	}
+	if got := cfg.source("locations.stavern.latitude"); got != SourceDefault {
+		t.Errorf("source(locations.stavern.latitude) = %s; want %s", got, SourceDefault)
+	}
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	data, _ := os.ReadFile(cfg.Path())
+	if !strings.HasPrefix(string(data), "version: 1\n") || !strings.Contains(string(data), "stavern:") {
+		t.Errorf("Save() of a new file =\n%s\nwant version and built-in location", data)
+	}
+}
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/kristofferrisa/sky-cli/internal/notify"
+)
+
+// NotifierNames returns the names of the configured notifiers, sorted
+func (c *Config) NotifierNames() []string {
+	names := make([]string, 0, len(c.Notifiers))
+	for name := range c.Notifiers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validateNotifiers checks that every configured notifier can be created
+func (c *Config) validateNotifiers() []error {
+	var problems []error
+	for _, name := range c.NotifierNames() {
+		if _, err := notify.New(c.Notifiers[name]); err != nil {
+			problems = append(problems, fmt.Errorf("notifiers.%s: %w", name, err))
+		}
+	}
+	return problems
+}
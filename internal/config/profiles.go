@@ -0,0 +1,120 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Profile is a named set of overrides for top-level config keys. The
+// special key "inherits" names another profile whose values are applied
+// first.
+type Profile map[string]interface{}
+
+// Inherits returns the name of the parent profile, if any
+func (p Profile) Inherits() string {
+	parent, _ := p["inherits"].(string)
+	return parent
+}
+
+// Keys returns the top-level keys the profile overrides, sorted and
+// without inherited ones
+func (p Profile) Keys() []string {
+	keys := make([]string, 0, len(p))
+	for key := range p.values() {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// values returns the overrides of the profile without the inherits key.
+// Profiles cannot select or define other profiles, so those keys are
+// dropped as well (and reported by Validate).
+func (p Profile) values() map[string]interface{} {
+	values := make(map[string]interface{}, len(p))
+	for key, value := range p {
+		switch key {
+		case "inherits", "profile", "profiles":
+			continue
+		}
+		values[key] = value
+	}
+	return values
+}
+
+// ProfileNames returns the names of all profiles, sorted
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// profileChain returns the named profile and its ancestors, the most
+// distant ancestor first, so that applying them in order lets each
+// profile override the ones it inherits from
+func (c *Config) profileChain(name string) ([]Profile, error) {
+	var chain []Profile
+	seen := make(map[string]bool)
+
+	for name != "" {
+		if seen[name] {
+			return nil, fmt.Errorf("profile '%s' inherits from itself", name)
+		}
+		seen[name] = true
+
+		p, ok := c.Profiles[name]
+		if !ok {
+			return nil, fmt.Errorf("profile '%s' not found in config", name)
+		}
+		chain = append([]Profile{p}, chain...)
+		name = p.Inherits()
+	}
+
+	return chain, nil
+}
+
+// validateProfiles checks inheritance and that profiles only override
+// known top-level keys
+func (c *Config) validateProfiles() []error {
+	var problems []error
+
+	for _, name := range c.ProfileNames() {
+		p := c.Profiles[name]
+
+		if _, err := c.profileChain(name); err != nil {
+			problems = append(problems, fmt.Errorf("profiles.%s: %w", name, err))
+		}
+
+		keys := make([]string, 0, len(p))
+		for key := range p {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if key == "inherits" {
+				continue
+			}
+			if key == "profile" || key == "profiles" {
+				problems = append(problems, fmt.Errorf("profiles.%s: a profile cannot set '%s'", name, key))
+				continue
+			}
+			if _, ok := fieldByTag(reflect.ValueOf(c).Elem(), strings.ToLower(key)); !ok {
+				problems = append(problems, fmt.Errorf("profiles.%s: unknown key '%s'", name, key))
+			}
+		}
+	}
+
+	if c.Profile != "" {
+		if _, ok := c.Profiles[c.Profile]; !ok {
+			problems = append(problems, fmt.Errorf("profile: profile '%s' is not defined", c.Profile))
+		}
+	}
+
+	return problems
+}
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"go.yaml.in/yaml/v3"
+)
+
+const profilesInput = `default_format: full
+profile: base
+profiles:
+  base:
+    default_format: summary
+    cache:
+      ttl_minutes: 2
+  work:
+    inherits: base
+    default_format: json
+    no_emoji: true
+  loop-a:
+    inherits: loop-b
+  loop-b:
+    inherits: loop-a
+`
+
+func TestLoadProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     string
+		flag    string
+		format  string
+		ttl     int
+		noEmoji bool
+	}{
+		{name: "Profile from file", format: "summary", ttl: 2},
+		{name: "SKY_PROFILE over file", env: "work", format: "json", ttl: 2, noEmoji: true},
+		{name: "Flag over SKY_PROFILE", env: "work", flag: "base", format: "summary", ttl: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := isolateEnv(t)
+			t.Setenv("SKY_PROFILE", tt.env)
+
+			file := filepath.Join(home, "config.yaml")
+			if err := os.WriteFile(file, []byte(profilesInput), 0600); err != nil {
+				t.Fatalf("failed to write config: %v", err)
+			}
+
+			opts := LoadOptions{File: file}
+			if tt.flag != "" {
+				flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+				flags.String("profile", "", "")
+				if err := flags.Parse([]string{"--profile", tt.flag}); err != nil {
+					t.Fatalf("failed to parse flags: %v", err)
+				}
+				opts.Flags = map[string]*pflag.Flag{"profile": flags.Lookup("profile")}
+			}
+
+			cfg, err := Load(opts)
+			if err != nil {
+				t.Fatalf("Load() failed: %v", err)
+			}
+
+			if cfg.DefaultFormat != tt.format {
+				t.Errorf("DefaultFormat = %s; want %s", cfg.DefaultFormat, tt.format)
+			}
+			if cfg.Cache.TTLMinutes != tt.ttl {
+				t.Errorf("Cache.TTLMinutes = %d; want %d", cfg.Cache.TTLMinutes, tt.ttl)
+			}
+			if cfg.NoEmoji != tt.noEmoji {
+				t.Errorf("NoEmoji = %v; want %v", cfg.NoEmoji, tt.noEmoji)
+			}
+			if got := cfg.source("default_format"); got != SourceProfile {
+				t.Errorf("source(default_format) = %s; want %s", got, SourceProfile)
+			}
+		})
+	}
+}
+
+func TestLoadProfileErrors(t *testing.T) {
+	tests := []struct {
+		profile string
+		err     string
+	}{
+		{profile: "missing", err: "not found"},
+		{profile: "loop-a", err: "inherits from itself"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.profile, func(t *testing.T) {
+			home := isolateEnv(t)
+			t.Setenv("SKY_PROFILE", tt.profile)
+
+			file := filepath.Join(home, "config.yaml")
+			if err := os.WriteFile(file, []byte(profilesInput), 0600); err != nil {
+				t.Fatalf("failed to write config: %v", err)
+			}
+
+			_, err := Load(LoadOptions{File: file})
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Load() error = %v; want error containing %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestSaveWithProfile(t *testing.T) {
+	home := isolateEnv(t)
+	t.Setenv("SKY_PROFILE", "work")
+
+	file := filepath.Join(home, "config.yaml")
+	if err := os.WriteFile(file, []byte(profilesInput), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := Load(LoadOptions{File: file})
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if err := cfg.Set("default_location", "oslo"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	var saved Config
+	data, _ := os.ReadFile(file)
+	if err := yaml.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("failed to parse saved config: %v", err)
+	}
+
+	// Profile values stay in the profile, explicit changes are saved
+	if saved.DefaultFormat != "full" || saved.NoEmoji || saved.Profile != "base" {
+		t.Errorf("Save() persisted profile values:\n%s", data)
+	}
+	if saved.DefaultLocation != "oslo" {
+		t.Errorf("Save() did not persist default_location:\n%s", data)
+	}
+	if saved.Profiles["work"].Inherits() != "base" {
+		t.Errorf("Save() changed the profiles section:\n%s", data)
+	}
+}
+
+func TestValidateProfiles(t *testing.T) {
+	cfg := &Config{
+		Profile: "missing",
+		Profiles: map[string]Profile{
+			"a":   {"inherits": "b"},
+			"b":   {"inherits": "a"},
+			"bad": {"colour": true, "profile": "a"},
+		},
+	}
+
+	var got []string
+	for _, err := range cfg.validateProfiles() {
+		got = append(got, err.Error())
+	}
+
+	want := []string{
+		"profiles.a: profile 'a' inherits from itself",
+		"profiles.b: profile 'b' inherits from itself",
+		"profiles.bad: unknown key 'colour'",
+		"profiles.bad: a profile cannot set 'profile'",
+		"profile: profile 'missing' is not defined",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("validateProfiles() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/kristofferrisa/sky-cli/internal/rules"
+)
+
+// RuleNames returns the names of the configured rules, sorted
+func (c *Config) RuleNames() []string {
+	names := make([]string, 0, len(c.Rules))
+	for name := range c.Rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validateRules checks that every configured rule compiles
+func (c *Config) validateRules() []error {
+	var problems []error
+	for _, name := range c.RuleNames() {
+		rule := c.Rules[name]
+		if rule == nil || rule.When == "" {
+			problems = append(problems, fmt.Errorf("rules.%s.when: missing condition", name))
+			continue
+		}
+		if _, err := rules.Compile(rule.When); err != nil {
+			problems = append(problems, fmt.Errorf("rules.%s.when: %w", name, err))
+		}
+	}
+	return problems
+}
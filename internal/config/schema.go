@@ -0,0 +1,14 @@
+package config
+
+import _ "embed"
+
+// schema is the JSON Schema of the config file. Editors that support the
+// yaml-language-server modeline use it for completion and validation.
+//
+//go:embed schema.json
+var schema []byte
+
+// Schema returns the JSON Schema describing the config file
+func Schema() []byte {
+	return schema
+}
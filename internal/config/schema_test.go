@@ -0,0 +1,84 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kristofferrisa/sky-cli/internal/formatter"
+)
+
+// jsonSchema is the subset of JSON Schema used by schema.json
+type jsonSchema struct {
+	Ref                  string                 `json:"$ref"`
+	Type                 string                 `json:"type"`
+	Enum                 []string               `json:"enum"`
+	Maximum              *int                   `json:"maximum"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	AdditionalProperties json.RawMessage        `json:"additionalProperties"`
+	Defs                 map[string]*jsonSchema `json:"$defs"`
+}
+
+func TestSchema(t *testing.T) {
+	var root jsonSchema
+	if err := json.Unmarshal(Schema(), &root); err != nil {
+		t.Fatalf("schema.json is not valid JSON: %v", err)
+	}
+
+	resolve := func(s *jsonSchema) *jsonSchema {
+		for s != nil && s.Ref != "" {
+			s = root.Defs[strings.TrimPrefix(s.Ref, "#/$defs/")]
+		}
+		return s
+	}
+
+	// Every config key must be described, so the schema cannot fall
+	// behind the Config struct
+	var check func(t reflect.Type, s *jsonSchema, prefix string)
+	check = func(typ reflect.Type, s *jsonSchema, prefix string) {
+		s = resolve(s)
+		if s == nil {
+			t.Errorf("schema.json does not describe %s", prefix)
+			return
+		}
+
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+
+		switch typ.Kind() {
+		case reflect.Struct:
+			for i := 0; i < typ.NumField(); i++ {
+				name := tagName(typ.Field(i))
+				if name == "" {
+					continue
+				}
+				check(typ.Field(i).Type, s.Properties[name], strings.TrimPrefix(prefix+"."+name, "."))
+			}
+		case reflect.Map:
+			// Free-form sections such as profiles are checked by Validate
+			if typ.Elem().Kind() == reflect.Interface {
+				return
+			}
+
+			var elem jsonSchema
+			if err := json.Unmarshal(s.AdditionalProperties, &elem); err != nil {
+				t.Errorf("schema.json does not describe the entries of %s", prefix)
+				return
+			}
+			check(typ.Elem(), &elem, prefix+".*")
+		}
+	}
+	check(reflect.TypeOf(Config{}), &root, "")
+
+	format := resolve(root.Properties["default_format"])
+	if !reflect.DeepEqual(format.Enum, formatter.AvailableFormatters()) {
+		t.Errorf("schema formats = %v; want %v", format.Enum, formatter.AvailableFormatters())
+	}
+
+	version := root.Properties["version"]
+	if version.Maximum == nil || *version.Maximum != CurrentVersion {
+		t.Errorf("schema version maximum does not match CurrentVersion %d", CurrentVersion)
+	}
+}
@@ -0,0 +1,288 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// writeFile saves the configuration to path, editing the existing YAML
+// document in place so that comments, key order and formatting survive.
+// When keys is not nil, only keys already in the file and keys listed as
+// true are written, and keys listed as false are removed; see pruneNode.
+// The previous file is kept as path + ".bak".
+func writeFile(path string, c *Config, keys map[string]bool) error {
+	var doc yaml.Node
+
+	existing, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := yaml.Unmarshal(existing, &doc); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		keepBlankLines(&doc, strings.Split(string(existing), "\n"))
+	case !os.IsNotExist(err):
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var updated yaml.Node
+	if err := updated.Encode(c); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	if keys != nil {
+		var current *yaml.Node
+		if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+			current = doc.Content[0]
+		}
+		pruneNode(&updated, current, reflect.TypeOf(*c), "", keys)
+	}
+
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		head := doc.HeadComment
+		doc = yaml.Node{Kind: yaml.DocumentNode, HeadComment: head}
+		doc.Content = []*yaml.Node{&updated}
+	} else {
+		mergeNode(doc.Content[0], &updated)
+	}
+
+	out, err := encodeDocument(&doc)
+	if err != nil {
+		return err
+	}
+
+	backup := ""
+	if existing != nil {
+		backup = path + ".bak"
+	}
+	return replaceFile(path, out, existing, backup)
+}
+
+// encodeDocument renders a YAML document the way sky writes config files
+func encodeDocument(doc *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	// Blank lines inside nested mappings are written with indentation
+	lines := bytes.Split(buf.Bytes(), []byte("\n"))
+	for i, line := range lines {
+		if len(bytes.TrimSpace(line)) == 0 {
+			lines[i] = nil
+		}
+	}
+	return bytes.Join(lines, []byte("\n")), nil
+}
+
+// replaceFile writes data to path. When backup is not empty the previous
+// contents are written there first, with the same permissions.
+func replaceFile(path string, data, previous []byte, backup string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if backup != "" {
+		if err := os.WriteFile(backup, previous, mode); err != nil {
+			return fmt.Errorf("failed to write backup: %w", err)
+		}
+	}
+
+	// Write to a temporary file first so a failed write never leaves a
+	// truncated config behind
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".config-*.yaml")
+	if err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+
+	return nil
+}
+
+// mergeNode updates dst in place with the values of src. Mapping keys that
+// exist in both are merged recursively, new keys are appended and keys
+// missing from src are removed. Nodes whose value did not change are left
+// untouched so their original formatting is kept.
+func mergeNode(dst, src *yaml.Node) {
+	if dst.Kind == yaml.MappingNode && src.Kind == yaml.MappingNode {
+		keep := make(map[string]bool, len(src.Content)/2)
+		for i := 0; i+1 < len(src.Content); i += 2 {
+			key, value := src.Content[i], src.Content[i+1]
+			keep[key.Value] = true
+
+			if j := findKey(dst, key.Value); j >= 0 {
+				mergeNode(dst.Content[j+1], value)
+			} else {
+				dst.Content = append(dst.Content, key, value)
+			}
+		}
+
+		content := dst.Content[:0]
+		for i := 0; i+1 < len(dst.Content); i += 2 {
+			if keep[dst.Content[i].Value] {
+				content = append(content, dst.Content[i], dst.Content[i+1])
+			}
+		}
+		dst.Content = content
+
+		// An empty mapping written as "{}" must switch to block style
+		// once it has entries
+		if len(dst.Content) > 0 {
+			dst.Style &^= yaml.FlowStyle
+		}
+		return
+	}
+
+	if sameValue(dst, src) {
+		return
+	}
+
+	head, line, foot := dst.HeadComment, dst.LineComment, dst.FootComment
+	*dst = *src
+	dst.HeadComment, dst.LineComment, dst.FootComment = head, line, foot
+}
+
+// pruneNode removes the keys of src that are neither in the file (dst) nor
+// listed as true in keys, so that values left at their defaults stay out of
+// the file and follow the defaults of later versions. Keys listed as false
+// are removed even when they are in the file. Sections with fixed keys,
+// such as cache, are pruned key by key following t; entries of maps such
+// as locations are kept whole.
+func pruneNode(src, dst *yaml.Node, t reflect.Type, prefix string, keys map[string]bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if src.Kind != yaml.MappingNode || t.Kind() != reflect.Struct {
+		return
+	}
+
+	content := src.Content[:0]
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+		name := key.Value
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		var current *yaml.Node
+		if dst != nil && dst.Kind == yaml.MappingNode {
+			if j := findKey(dst, key.Value); j >= 0 {
+				current = dst.Content[j+1]
+			}
+		}
+
+		write, listed := keys[name]
+		if listed && !write {
+			continue
+		}
+		if current == nil && !write && !writesBelow(keys, name) {
+			continue
+		}
+
+		if field, ok := fieldType(t, key.Value); ok {
+			pruneNode(value, current, field, name, keys)
+		}
+		content = append(content, key, value)
+	}
+	src.Content = content
+}
+
+// writesBelow reports whether keys lists a key below prefix to be written
+func writesBelow(keys map[string]bool, prefix string) bool {
+	for key, write := range keys {
+		if write && strings.HasPrefix(key, prefix+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldType returns the type of the struct field of t whose config key
+// matches name
+func fieldType(t reflect.Type, name string) (reflect.Type, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		if tagName(t.Field(i)) == name {
+			return t.Field(i).Type, true
+		}
+	}
+	return nil, false
+}
+
+// keepBlankLines records blank lines that separate mapping entries in the
+// original file. The YAML parser drops them, but a head comment starting
+// with an empty line makes the encoder write them back.
+func keepBlankLines(node *yaml.Node, lines []string) {
+	if node.Kind == yaml.MappingNode {
+		for i := 2; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+
+			// Skip over the key's own comment lines
+			above := key.Line - 2
+			for above >= 0 && strings.HasPrefix(strings.TrimSpace(lines[above]), "#") {
+				above--
+			}
+			if above >= 0 && strings.TrimSpace(lines[above]) == "" {
+				key.HeadComment = "\n" + key.HeadComment
+			}
+		}
+	}
+
+	for _, child := range node.Content {
+		keepBlankLines(child, lines)
+	}
+}
+
+// findKey returns the index of key in a mapping node, or -1
+func findKey(mapping *yaml.Node, key string) int {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// sameValue reports whether two nodes decode to the same value, so that
+// for example "59.0" and "59" are treated as equal
+func sameValue(a, b *yaml.Node) bool {
+	if a.Kind != b.Kind {
+		return false
+	}
+
+	var av, bv interface{}
+	if err := a.Decode(&av); err != nil {
+		return false
+	}
+	if err := b.Decode(&bv); err != nil {
+		return false
+	}
+	return fmt.Sprint(av) == fmt.Sprint(bv)
+}
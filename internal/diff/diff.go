@@ -0,0 +1,107 @@
+// Package diff compares a forecast with the one seen before it for the
+// same location, and keeps the forecasts seen
+package diff
+
+import (
+	"math"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+// Thresholds are the smallest changes reported per variable. A threshold
+// of zero reports every change.
+type Thresholds struct {
+	Temperature              float64 // °C
+	Precipitation            float64 // mm
+	PrecipitationProbability float64 // percentage points
+	Wind                     float64 // m/s
+	Gust                     float64 // m/s
+}
+
+// variable is a forecast variable that is compared
+type variable struct {
+	name      string
+	unit      string
+	threshold func(t Thresholds) float64
+
+	// perPeriod is set for amounts over the period of an hour, which
+	// cannot be compared between periods of different lengths
+	perPeriod bool
+
+	// value returns the value in hour i of a forecast, and false when it
+	// has none to compare
+	value func(f *models.Forecast, i int) (float64, bool)
+}
+
+var variables = []variable{
+	{"temp", "°C", func(t Thresholds) float64 { return t.Temperature }, false,
+		func(f *models.Forecast, i int) (float64, bool) { return f.Hours[i].Temperature, true }},
+	{"rain", "mm", func(t Thresholds) float64 { return t.Precipitation }, true,
+		func(f *models.Forecast, i int) (float64, bool) { return f.Hours[i].Precipitation, true }},
+	{"rain chance", "%", func(t Thresholds) float64 { return t.PrecipitationProbability }, true,
+		func(f *models.Forecast, i int) (float64, bool) { return f.Hours[i].PrecipitationProbability, true }},
+	{"wind", "m/s", func(t Thresholds) float64 { return t.Wind }, false,
+		func(f *models.Forecast, i int) (float64, bool) { return f.Hours[i].WindSpeed, true }},
+	{"gust", "m/s", func(t Thresholds) float64 { return t.Gust }, false,
+		func(f *models.Forecast, i int) (float64, bool) { return f.Hours[i].WindGust, f.Hours[i].WindGust > 0 }},
+}
+
+// Compare returns the hours from now on whose forecast changed by at least
+// a threshold between the previous and the current forecast. Hours only
+// one of them covers are skipped, as are the amounts of hours whose
+// period differs between them: further ahead MET Norway forecasts six
+// hours at a time, and an hour of a later forecast cannot be compared with
+// six hours of an earlier one.
+func Compare(previous, current *models.Forecast, thresholds Thresholds, now time.Time) []models.HourChange {
+	before := make(map[time.Time]int, len(previous.Hours))
+	for i, h := range previous.Hours {
+		before[h.Time.UTC()] = i
+	}
+
+	start := now.Truncate(time.Hour)
+	var hours []models.HourChange
+	for i, h := range current.Hours {
+		if h.Time.Before(start) {
+			continue
+		}
+		j, ok := before[h.Time.UTC()]
+		if !ok {
+			continue
+		}
+		samePeriod := period(previous, j) == period(current, i)
+
+		var changes []models.Change
+		for _, v := range variables {
+			if v.perPeriod && !samePeriod {
+				continue
+			}
+			was, ok := v.value(previous, j)
+			if !ok {
+				continue
+			}
+			is, ok := v.value(current, i)
+			if !ok {
+				continue
+			}
+			delta := math.Abs(is - was)
+			if delta == 0 || delta < v.threshold(thresholds) {
+				continue
+			}
+			changes = append(changes, models.Change{Name: v.name, Unit: v.unit, Previous: was, Current: is})
+		}
+		if len(changes) > 0 {
+			hours = append(hours, models.HourChange{Time: h.Time.In(now.Location()), Changes: changes})
+		}
+	}
+	return hours
+}
+
+// period returns how long hour i of a forecast lasts, until the next one
+// starts. The last one lasts an hour.
+func period(f *models.Forecast, i int) time.Duration {
+	if i+1 < len(f.Hours) {
+		return f.Hours[i+1].Time.Sub(f.Hours[i].Time)
+	}
+	return time.Hour
+}
@@ -0,0 +1,128 @@
+package diff
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+var oslo = &models.Location{Name: "Oslo", Latitude: 59.9139, Longitude: 10.7522}
+
+// forecast returns a forecast with an hour at each offset from start
+func forecast(start time.Time, offsets []int, hour func(i int) models.HourlyForecast) *models.Forecast {
+	f := &models.Forecast{Location: oslo}
+	for i, offset := range offsets {
+		h := hour(i)
+		h.Time = start.Add(time.Duration(offset) * time.Hour)
+		f.Hours = append(f.Hours, h)
+	}
+	return f
+}
+
+func TestCompare(t *testing.T) {
+	start := time.Date(2026, 1, 9, 12, 0, 0, 0, time.UTC)
+	thresholds := Thresholds{Temperature: 2, Precipitation: 1, PrecipitationProbability: 20, Wind: 3, Gust: 5}
+
+	previous := forecast(start, []int{0, 1, 2, 3, 4, 10}, func(i int) models.HourlyForecast {
+		return models.HourlyForecast{Temperature: 5, Precipitation: 0.4, WindSpeed: 4, WindGust: 8}
+	})
+	current := forecast(start, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, func(i int) models.HourlyForecast {
+		return models.HourlyForecast{Temperature: 5, Precipitation: 0.4, WindSpeed: 4, WindGust: 8}
+	})
+	// 13:00: too small a change to report
+	current.Hours[0].Temperature = 6.5
+	// 14:00: rain and temperature
+	current.Hours[1].Precipitation = 3.2
+	current.Hours[1].Temperature = 3
+	// 15:00: a gust that is no longer forecast is not a change
+	current.Hours[2].WindGust = 0
+	current.Hours[2].WindSpeed = 8
+	// 16:00: an hour where the previous forecast had six hours of rain
+	current.Hours[3].Precipitation = 5
+	// 22:00: the last hour of both
+	current.Hours[9].Precipitation = 2
+
+	now := start.Add(90 * time.Minute).In(time.FixedZone("CET", 3600))
+	hours := Compare(previous, current, thresholds, now)
+
+	want := []struct {
+		time    time.Time
+		changes []models.Change
+	}{
+		{start.Add(2 * time.Hour), []models.Change{{Name: "temp", Unit: "°C", Previous: 5, Current: 3}, {Name: "rain", Unit: "mm", Previous: 0.4, Current: 3.2}}},
+		{start.Add(3 * time.Hour), []models.Change{{Name: "wind", Unit: "m/s", Previous: 4, Current: 8}}},
+		{start.Add(10 * time.Hour), []models.Change{{Name: "rain", Unit: "mm", Previous: 0.4, Current: 2}}},
+	}
+	if len(hours) != len(want) {
+		t.Fatalf("Compare() = %d hours; want %d: %+v", len(hours), len(want), hours)
+	}
+	for i, w := range want {
+		got := hours[i]
+		if !got.Time.Equal(w.time) || got.Time.Location() != now.Location() {
+			t.Errorf("Compare()[%d].Time = %v; want %v in local time", i, got.Time, w.time)
+		}
+		if len(got.Changes) != len(w.changes) {
+			t.Errorf("Compare()[%d].Changes = %+v; want %+v", i, got.Changes, w.changes)
+			continue
+		}
+		for j, c := range w.changes {
+			if got.Changes[j] != c {
+				t.Errorf("Compare()[%d].Changes[%d] = %+v; want %+v", i, j, got.Changes[j], c)
+			}
+		}
+	}
+
+	t.Run("Past hours", func(t *testing.T) {
+		if hours := Compare(previous, current, thresholds, start.Add(12*time.Hour)); len(hours) != 0 {
+			t.Errorf("Compare() = %+v; want no hours in the past", hours)
+		}
+	})
+
+	t.Run("Zero thresholds", func(t *testing.T) {
+		hours := Compare(previous, current, Thresholds{}, now)
+		if len(hours) != 4 || hours[0].Changes[0].Current != 6.5 {
+			t.Errorf("Compare() = %d hours; want every change", len(hours))
+		}
+	})
+}
+
+func TestStore(t *testing.T) {
+	store := &Store{Dir: filepath.Join(t.TempDir(), "forecasts")}
+
+	seen, err := store.Load(oslo)
+	if err != nil || seen != nil {
+		t.Fatalf("Load() = %v, %v; want nothing seen", seen, err)
+	}
+
+	start := time.Date(2026, 1, 9, 12, 0, 0, 0, time.UTC)
+	f := forecast(start, []int{0, 1}, func(i int) models.HourlyForecast {
+		return models.HourlyForecast{Temperature: float64(i), Symbol: "rain"}
+	})
+	if err := store.Save(&Seen{SeenAt: start, Forecast: f}); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	// Coordinates with more decimals share the file
+	nearby := &models.Location{Latitude: 59.91391, Longitude: 10.75221}
+	seen, err = store.Load(nearby)
+	if err != nil || seen == nil {
+		t.Fatalf("Load() = %v, %v; want the forecast saved", seen, err)
+	}
+	if !seen.SeenAt.Equal(start) || len(seen.Forecast.Hours) != 2 || seen.Forecast.Hours[1].Temperature != 1 ||
+		!seen.Forecast.Hours[1].Time.Equal(start.Add(time.Hour)) || seen.Forecast.Location.Name != "Oslo" {
+		t.Errorf("Load() = %+v; want the forecast saved", seen)
+	}
+
+	files, _ := os.ReadDir(store.Dir)
+	if len(files) != 1 {
+		t.Errorf("Save() left %d files; want 1", len(files))
+	}
+
+	os.WriteFile(store.path(oslo), []byte("{"), 0644)
+	if _, err := store.Load(oslo); err == nil {
+		t.Error("Load() of a corrupt file succeeded; want an error")
+	}
+}
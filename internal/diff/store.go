@@ -0,0 +1,77 @@
+package diff
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+// Seen is a forecast as it was seen
+type Seen struct {
+	SeenAt   time.Time        `json:"seen_at"`
+	Forecast *models.Forecast `json:"forecast"`
+}
+
+// Store keeps the last forecast seen for every location as a JSON file in
+// Dir. Unlike the cache, its forecasts do not expire.
+type Store struct {
+	Dir string
+}
+
+// Load returns the last forecast seen for a location, or nil when none
+// has been
+func (s *Store) Load(loc *models.Location) (*Seen, error) {
+	data, err := os.ReadFile(s.path(loc))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the last forecast seen: %w", err)
+	}
+
+	var seen Seen
+	if err := json.Unmarshal(data, &seen); err != nil || seen.Forecast == nil {
+		return nil, fmt.Errorf("failed to read the last forecast seen from %s: it is corrupt", s.path(loc))
+	}
+	return &seen, nil
+}
+
+// Save keeps a forecast as the last one seen for its location
+func (s *Store) Save(seen *Seen) error {
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", s.Dir, err)
+	}
+	data, err := json.Marshal(seen)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first, so that an interrupted save keeps
+	// the previous forecast
+	path := s.path(seen.Forecast.Location)
+	tmp, err := os.CreateTemp(s.Dir, ".forecast-*")
+	if err != nil {
+		return fmt.Errorf("failed to save the forecast seen: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to save the forecast seen: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to save the forecast seen: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// path returns the file of a location, named after its coordinates with
+// the four decimals MET Norway uses
+func (s *Store) path(loc *models.Location) string {
+	return filepath.Join(s.Dir, fmt.Sprintf("%.4f_%.4f.json", loc.Latitude, loc.Longitude))
+}
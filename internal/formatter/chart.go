@@ -0,0 +1,348 @@
+package formatter
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kristofferrisa/sky-cli/internal/chart"
+	"github.com/kristofferrisa/sky-cli/internal/models"
+	"github.com/kristofferrisa/sky-cli/internal/ui"
+)
+
+const (
+	// chartRows is the height of the temperature chart
+	chartRows = 8
+
+	// rainRows is the height of the precipitation bars
+	rainRows = 3
+
+	// chartLabelWidth is the width of the labels left of the axis
+	chartLabelWidth = 6
+
+	// windSpacing is the columns between the wind arrows
+	windSpacing = 3
+
+	// minChartColumns is the narrowest the charts get, however narrow the
+	// terminal is
+	minChartColumns = 24
+)
+
+// ChartFormatter draws the hourly forecast as charts as wide as the
+// terminal: temperature and feels like as lines, precipitation as bars and
+// the wind as arrows. Everything else is formatted like the full formatter.
+type ChartFormatter struct {
+	*FullFormatter
+}
+
+// NewChartFormatter creates a new chart formatter
+func NewChartFormatter() *ChartFormatter {
+	return &ChartFormatter{FullFormatter: NewFullFormatter()}
+}
+
+// Name returns the formatter name
+func (f *ChartFormatter) Name() string {
+	return "chart"
+}
+
+// FormatForecast draws hourly forecast data as charts
+func (f *ChartFormatter) FormatForecast(w io.Writer, forecast *models.Forecast, opts Options) error {
+	if opts.NoColor {
+		ui.DisableColors()
+	}
+
+	fmt.Fprintln(w, ui.Header(fmt.Sprintf("HOURLY FORECAST (Next %d Hours)", len(forecast.Hours))))
+	f.drawForecast(w, forecast, opts)
+	fmt.Fprintln(w)
+	return nil
+}
+
+// CompareForecast draws the hourly forecasts of several locations one
+// below the other
+func (f *ChartFormatter) CompareForecast(w io.Writer, results []Result[*models.Forecast], opts Options) error {
+	if opts.NoColor {
+		ui.DisableColors()
+	}
+
+	fmt.Fprintln(w, ui.Header(fmt.Sprintf("HOURLY FORECAST - %d Locations", len(results))))
+	for _, r := range succeeded(results) {
+		fmt.Fprintln(w, ui.Bold(label(r.Location)))
+		f.drawForecast(w, r.Data, opts)
+		fmt.Fprintln(w)
+	}
+
+	f.formatFailures(w, failures(results))
+	return nil
+}
+
+// drawForecast draws the charts of a forecast, with times in local time
+func (f *ChartFormatter) drawForecast(w io.Writer, forecast *models.Forecast, opts Options) {
+	if len(forecast.Hours) == 0 {
+		fmt.Fprintln(w, "No forecast to chart")
+		return
+	}
+
+	ascii := opts.NoEmoji || !ui.Unicode()
+	cols := max(minChartColumns, ui.TerminalWidth()-chartLabelWidth-2)
+	tl := newTimeline(forecast.Hours, cols)
+
+	var lines []string
+	lines = append(lines, temperatureChart(forecast.Hours, tl, ascii)...)
+	lines = append(lines, rainChart(forecast.Hours, tl, ascii)...)
+	lines = append(lines, windChart(forecast.Hours, tl, ascii)...)
+	lines = append(lines, timeAxis(tl, ascii)...)
+	lines = append(lines, chartLegend(ascii))
+	for _, line := range lines {
+		fmt.Fprintln(w, strings.TrimRight(line, " "))
+	}
+}
+
+// timeline maps the time of a forecast to the columns of a chart. The
+// hours need not be evenly spaced: further ahead MET Norway forecasts six
+// hours at a time, and those hours get six times the room.
+type timeline struct {
+	start, end time.Time
+	cols       int
+}
+
+// newTimeline returns the timeline of hours across cols columns, from the
+// first hour to the end of the last one
+func newTimeline(hours []models.HourlyForecast, cols int) timeline {
+	last := len(hours) - 1
+	step := time.Hour
+	if last > 0 {
+		step = hours[last].Time.Sub(hours[last-1].Time)
+	}
+	return timeline{start: hours[0].Time, end: hours[last].Time.Add(step), cols: cols}
+}
+
+// at returns the time at a fraction of the timeline, from 0 to 1
+func (tl timeline) at(fraction float64) time.Time {
+	return tl.start.Add(time.Duration(fraction * float64(tl.end.Sub(tl.start))))
+}
+
+// column returns the column of a time
+func (tl timeline) column(t time.Time) int {
+	return int(float64(t.Sub(tl.start)) / float64(tl.end.Sub(tl.start)) * float64(tl.cols))
+}
+
+// periodAt returns the index of the hour whose period holds t: the last
+// one starting at or before it
+func periodAt(hours []models.HourlyForecast, t time.Time) int {
+	i := 0
+	for i+1 < len(hours) && !hours[i+1].Time.After(t) {
+		i++
+	}
+	return i
+}
+
+// interpolate returns a value of the hours at t, along a straight line
+// between the hours around it
+func interpolate(hours []models.HourlyForecast, t time.Time, value func(h *models.HourlyForecast) float64) float64 {
+	i := periodAt(hours, t)
+	if i+1 == len(hours) || t.Before(hours[i].Time) {
+		return value(&hours[i])
+	}
+	fraction := float64(t.Sub(hours[i].Time)) / float64(hours[i+1].Time.Sub(hours[i].Time))
+	return value(&hours[i]) + fraction*(value(&hours[i+1])-value(&hours[i]))
+}
+
+// axisLabel pads a label to the width of the labels and adds an axis
+func axisLabel(text, axis string) string {
+	return strings.Repeat(" ", max(0, chartLabelWidth-utf8.RuneCountInString(text))) + text + axis
+}
+
+// degrees formats a temperature label, without a degree sign in ASCII
+func degrees(v float64, ascii bool) string {
+	if ascii {
+		return fmt.Sprintf("%.0fC", v)
+	}
+	return fmt.Sprintf("%.0f°C", v)
+}
+
+// temperatureChart draws the temperature as a line and feels like as a
+// dotted line, between whole degrees below and above them
+func temperatureChart(hours []models.HourlyForecast, tl timeline, ascii bool) []string {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for i := range hours {
+		for _, v := range []float64{hours[i].Temperature, hours[i].FeelsLike()} {
+			lo, hi = math.Min(lo, v), math.Max(hi, v)
+		}
+	}
+	lo, hi = math.Floor(lo), math.Ceil(hi)
+	if hi-lo < 2 {
+		lo, hi = lo-1, hi+1
+	}
+
+	canvas := chart.NewCanvas(tl.cols, chartRows, ascii)
+	width, _ := canvas.Size()
+	temps := make([]float64, width)
+	feels := make([]float64, width)
+	for x := range temps {
+		t := tl.at((float64(x) + 0.5) / float64(width))
+		temps[x] = (interpolate(hours, t, func(h *models.HourlyForecast) float64 { return h.Temperature }) - lo) / (hi - lo)
+		feels[x] = (interpolate(hours, t, (*models.HourlyForecast).FeelsLike) - lo) / (hi - lo)
+	}
+	every := 3
+	if ascii {
+		every = 2
+	}
+	canvas.Plot(feels, 1, every)
+	canvas.Plot(temps, 0, 1)
+
+	lines := canvas.Lines(func(cell string, series uint8) string {
+		if series&1 != 0 {
+			return ui.Cyan(cell)
+		}
+		return ui.Yellow(cell)
+	})
+	tick, axis := "┤", "│"
+	if ascii {
+		tick, axis = "+", "|"
+	}
+	for i := range lines {
+		switch i {
+		case 0:
+			lines[i] = axisLabel(degrees(hi, ascii), tick) + lines[i]
+		case len(lines) - 1:
+			lines[i] = axisLabel(degrees(lo, ascii), tick) + lines[i]
+		default:
+			lines[i] = axisLabel("", axis) + lines[i]
+		}
+	}
+	return lines
+}
+
+// rainChart draws the precipitation per hour as bars, up to at least 1 mm
+// an hour, or a line saying there is none
+func rainChart(hours []models.HourlyForecast, tl timeline, ascii bool) []string {
+	axis := "│"
+	if ascii {
+		axis = "|"
+	}
+
+	// Amounts over six hours are spread over them, to compare with the
+	// amounts of single hours
+	rates := make([]float64, tl.cols)
+	top := 0.0
+	for col := range rates {
+		i := periodAt(hours, tl.at((float64(col)+0.5)/float64(tl.cols)))
+		period := time.Hour
+		if i+1 < len(hours) {
+			period = hours[i+1].Time.Sub(hours[i].Time)
+		}
+		rates[col] = hours[i].Precipitation / period.Hours()
+		top = math.Max(top, rates[col])
+	}
+	if top == 0 {
+		return []string{axisLabel("Rain", axis) + " none"}
+	}
+	top = math.Max(top, 1)
+
+	lines := chart.Bars(rates, top, rainRows, ascii)
+	for i := range lines {
+		text := ""
+		switch i {
+		case 0:
+			text = fmt.Sprintf("%.1fmm", top)
+			if top >= 10 {
+				text = fmt.Sprintf("%.0fmm", top)
+			}
+		case len(lines) - 1:
+			text = "Rain"
+		}
+		lines[i] = axisLabel(text, axis) + ui.Blue(lines[i])
+	}
+	return lines
+}
+
+// windChart draws an arrow every few columns, with the wind speed in m/s
+// below it
+func windChart(hours []models.HourlyForecast, tl timeline, ascii bool) []string {
+	axis := "│"
+	if ascii {
+		axis = "|"
+	}
+
+	var arrows, speeds strings.Builder
+	for col := 0; col+windSpacing <= tl.cols; col += windSpacing {
+		hour := hours[periodAt(hours, tl.at((float64(col)+0.5)/float64(tl.cols)))]
+		arrows.WriteString(chart.Arrow(hour.WindDir, ascii) + strings.Repeat(" ", windSpacing-1))
+		speeds.WriteString(fmt.Sprintf("%-*.0f", windSpacing, hour.WindSpeed))
+	}
+	return []string{
+		axisLabel("Wind", axis) + arrows.String(),
+		axisLabel("m/s", axis) + speeds.String(),
+	}
+}
+
+// tickSteps are the hours between the ticks of the time axis to choose
+// from
+var tickSteps = []int{1, 2, 3, 6, 12, 24}
+
+// timeAxis draws the time axis with ticks at whole hours in local time,
+// as far apart as their labels need. Midnight is labelled with the day.
+func timeAxis(tl timeline, ascii bool) []string {
+	corner, rule, tick := "└", "─", "┬"
+	if ascii {
+		corner, rule, tick = "+", "-", "+"
+	}
+
+	// Labels are five characters wide and need a gap between them
+	hours := tl.end.Sub(tl.start).Hours()
+	step := tickSteps[len(tickSteps)-1]
+	for _, s := range tickSteps {
+		if float64(s)/hours*float64(tl.cols) >= 7 {
+			step = s
+			break
+		}
+	}
+
+	axis := make([]string, tl.cols)
+	for col := range axis {
+		axis[col] = rule
+	}
+	labels := []rune(strings.Repeat(" ", chartLabelWidth+1+tl.cols))
+	free := 0
+	start := tl.start.Local()
+	t := time.Date(start.Year(), start.Month(), start.Day(), start.Hour(), 0, 0, 0, time.Local)
+	for ; t.Before(tl.end); t = t.Add(time.Hour) {
+		if t.Before(tl.start) || t.Hour()%step != 0 {
+			continue
+		}
+		col := tl.column(t)
+		if col >= tl.cols {
+			break
+		}
+		axis[col] = tick
+
+		text := t.Format("15:04")
+		if t.Hour() == 0 {
+			text = t.Format("Mon")
+		}
+		at := chartLabelWidth + 1 + col - utf8.RuneCountInString(text)/2
+		if at < free || at+utf8.RuneCountInString(text) > len(labels) {
+			continue
+		}
+		copy(labels[at:], []rune(text))
+		free = at + utf8.RuneCountInString(text) + 1
+	}
+
+	return []string{
+		strings.Repeat(" ", chartLabelWidth) + corner + strings.Join(axis, ""),
+		string(labels),
+	}
+}
+
+// chartLegend explains the lines of the temperature chart
+func chartLegend(ascii bool) string {
+	if ascii {
+		return fmt.Sprintf("%s %s temperature  %s feels like",
+			strings.Repeat(" ", chartLabelWidth), ui.Cyan("**"), ui.Yellow(". ."))
+	}
+	return fmt.Sprintf("%s %s temperature  %s feels like",
+		strings.Repeat(" ", chartLabelWidth), ui.Cyan("⠒⠒"), ui.Yellow("⠂⠂"))
+}
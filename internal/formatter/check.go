@@ -0,0 +1,36 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+// alertValues formats the values of an alert's variables, such as
+// "temp -1.5°C, gust 21.3 m/s"
+func alertValues(a models.Alert) string {
+	values := make([]string, len(a.Values))
+	for i, v := range a.Values {
+		values[i] = fmt.Sprintf("%s %g%s", v.Name, v.Value, v.Unit)
+	}
+	return strings.Join(values, ", ")
+}
+
+// checkLocations names the locations of a check, or counts them when
+// there are several
+func checkLocations(check *models.Check) string {
+	if len(check.Locations) == 1 {
+		return label(check.Locations[0])
+	}
+	return fmt.Sprintf("%d locations", len(check.Locations))
+}
+
+// matchedRules returns the number of rules that matched at any location
+func matchedRules(check *models.Check) int {
+	seen := make(map[string]bool)
+	for _, a := range check.Alerts {
+		seen[a.Rule] = true
+	}
+	return len(seen)
+}
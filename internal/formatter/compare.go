@@ -0,0 +1,103 @@
+package formatter
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+// failure is a location whose data could not be fetched
+type failure struct {
+	Location *models.Location
+	Err      error
+}
+
+// failures returns the results whose data could not be fetched
+func failures[T any](results []Result[T]) []failure {
+	var failed []failure
+	for _, r := range results {
+		if r.Err != nil {
+			failed = append(failed, failure{r.Location, r.Err})
+		}
+	}
+	return failed
+}
+
+// succeeded returns the results that have data
+func succeeded[T any](results []Result[T]) []Result[T] {
+	var ok []Result[T]
+	for _, r := range results {
+		if r.Err == nil {
+			ok = append(ok, r)
+		}
+	}
+	return ok
+}
+
+// shortDirection turns a wind direction such as "NE (Northeast)" into
+// "NE"
+func shortDirection(direction string) string {
+	if fields := strings.Fields(direction); len(fields) > 0 {
+		return fields[0]
+	}
+	return direction
+}
+
+// forecastTimes returns every hour covered by at least one forecast, in
+// order
+func forecastTimes(results []Result[*models.Forecast]) []time.Time {
+	seen := make(map[time.Time]bool)
+	var times []time.Time
+	for _, r := range succeeded(results) {
+		for _, hour := range r.Data.Hours {
+			if !seen[hour.Time] {
+				seen[hour.Time] = true
+				times = append(times, hour.Time)
+			}
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	return times
+}
+
+// hourAt returns the forecast for the hour t, or nil if the forecast does
+// not cover it
+func hourAt(forecast *models.Forecast, t time.Time) *models.HourlyForecast {
+	for i := range forecast.Hours {
+		if forecast.Hours[i].Time.Equal(t) {
+			return &forecast.Hours[i]
+		}
+	}
+	return nil
+}
+
+// forecastDates returns every day covered by at least one daily forecast,
+// in order
+func forecastDates(results []Result[*models.DailyForecast]) []string {
+	seen := make(map[string]bool)
+	var dates []string
+	for _, r := range succeeded(results) {
+		for _, day := range r.Data.Days {
+			date := day.Date.Format("2006-01-02")
+			if !seen[date] {
+				seen[date] = true
+				dates = append(dates, date)
+			}
+		}
+	}
+	sort.Strings(dates)
+	return dates
+}
+
+// dayAt returns the summary for the date (YYYY-MM-DD), or nil if the
+// forecast does not cover it
+func dayAt(forecast *models.DailyForecast, date string) *models.DailySummary {
+	for i := range forecast.Days {
+		if forecast.Days[i].Date.Format("2006-01-02") == date {
+			return &forecast.Days[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+// dayTime formats a time relative to now, such as "Today 14:00",
+// "Tomorrow 14:00", "Sat 14:00" within a week or "Mon 19 Jan 14:00"
+func dayTime(t, now time.Time) string {
+	t = t.In(now.Location())
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, now.Location())
+
+	switch days := int(day.Sub(today).Hours()/24 + 0.5); {
+	case days == 0:
+		return "Today " + t.Format("15:04")
+	case days == 1:
+		return "Tomorrow " + t.Format("15:04")
+	case days == -1:
+		return "Yesterday " + t.Format("15:04")
+	case days > 1 && days < 7:
+		return t.Format("Mon 15:04")
+	}
+	return t.Format("Mon 2 Jan 15:04")
+}
+
+// changeText describes a change, such as "rain now 3.2 mm (was 0.4)" or,
+// for temperatures, "temp -2.0°C (now 3.0)"
+func changeText(c models.Change) string {
+	if c.Unit == "°C" {
+		return fmt.Sprintf("%s %+.1f%s (now %.1f)", c.Name, c.Delta(), c.Unit, c.Current)
+	}
+	unit := " " + c.Unit
+	if c.Unit == "%" {
+		unit = c.Unit
+	}
+	return fmt.Sprintf("%s now %.1f%s (was %.1f)", c.Name, c.Current, unit, c.Previous)
+}
+
+// hourChanges describes the changes in an hour, separated by commas
+func hourChanges(hour models.HourChange) string {
+	texts := make([]string, len(hour.Changes))
+	for i, c := range hour.Changes {
+		texts[i] = changeText(c)
+	}
+	return strings.Join(texts, ", ")
+}
+
+// diffSince describes when the previous forecast was seen, such as
+// "since today 09:12" or "since Sat 18:40"
+func diffSince(diff *models.ForecastDiff) string {
+	when := dayTime(diff.PreviousSeenAt, diff.SeenAt)
+	for _, day := range []string{"Today", "Yesterday"} {
+		if strings.HasPrefix(when, day) {
+			when = strings.ToLower(day) + when[len(day):]
+		}
+	}
+	return "since " + when
+}
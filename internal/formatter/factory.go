@@ -13,12 +13,14 @@ func GetFormatter(name string) (Formatter, error) {
 		return NewSummaryFormatter(), nil
 	case "markdown", "md":
 		return NewMarkdownFormatter(), nil
+	case "chart":
+		return NewChartFormatter(), nil
 	default:
-		return nil, fmt.Errorf("unknown formatter: %s (available: full, json, summary, markdown)", name)
+		return nil, fmt.Errorf("unknown formatter: %s (available: full, json, summary, markdown, chart)", name)
 	}
 }
 
 // AvailableFormatters returns a list of available formatter names
 func AvailableFormatters() []string {
-	return []string{"full", "json", "summary", "markdown"}
+	return []string{"full", "json", "summary", "markdown", "chart"}
 }
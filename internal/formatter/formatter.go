@@ -27,10 +27,47 @@ type Formatter interface {
 	// FormatDailyForecast formats multi-day forecast data
 	FormatDailyForecast(w io.Writer, dailyForecast *models.DailyForecast, opts Options) error
 
+	// CompareCurrent formats current weather for several locations
+	CompareCurrent(w io.Writer, results []Result[*models.Weather], opts Options) error
+
+	// CompareForecast formats hourly forecasts for several locations
+	CompareForecast(w io.Writer, results []Result[*models.Forecast], opts Options) error
+
+	// CompareDailyForecast formats multi-day forecasts for several locations
+	CompareDailyForecast(w io.Writer, results []Result[*models.DailyForecast], opts Options) error
+
+	// FormatRoute formats the forecast along a route
+	FormatRoute(w io.Writer, route *models.Route, opts Options) error
+
+	// FormatPlan formats the best times for an activity
+	FormatPlan(w io.Writer, plan *models.Plan, opts Options) error
+
+	// FormatCheck formats the rules that matched the forecast
+	FormatCheck(w io.Writer, check *models.Check, opts Options) error
+
+	// FormatDiff formats the changes in a forecast since it was last seen
+	FormatDiff(w io.Writer, diff *models.ForecastDiff, opts Options) error
+
 	// Name returns the formatter name
 	Name() string
 }
 
+// Result holds the data for one of several locations being compared.
+// Err is set instead of Data when the data could not be fetched.
+type Result[T any] struct {
+	Location *models.Location
+	Data     T
+	Err      error
+}
+
+// label returns the name used for a location in comparison tables
+func label(loc *models.Location) string {
+	if loc.Name != "" {
+		return loc.Name
+	}
+	return loc.String()
+}
+
 // DefaultOptions returns default formatting options
 func DefaultOptions() Options {
 	return Options{
@@ -3,6 +3,8 @@ package formatter
 import (
 	"fmt"
 	"io"
+	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/kristofferrisa/sky-cli/internal/models"
@@ -274,3 +276,310 @@ func (f *FullFormatter) FormatDailyForecast(w io.Writer, dailyForecast *models.D
 	fmt.Fprintln(w)
 	return nil
 }
+
+// CompareCurrent formats current weather for several locations as a table
+func (f *FullFormatter) CompareCurrent(w io.Writer, results []Result[*models.Weather], opts Options) error {
+	if opts.NoColor {
+		ui.DisableColors()
+	}
+
+	fmt.Fprintln(w, ui.Header(fmt.Sprintf("CURRENT WEATHER - %d Locations", len(results))))
+
+	// Conditions come last so that emoji widths do not shift the columns
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Location\tTemp\tFeels\tWind\tPrecip\tHumidity\tConditions")
+	for _, r := range succeeded(results) {
+		weather := r.Data
+		emoji, description := ui.WeatherSymbol(weather.Symbol)
+		if opts.NoEmoji {
+			emoji = ""
+			description = stripEmoji(description)
+		}
+
+		fmt.Fprintf(tw, "%s\t%.1f°C\t%.1f°C\t%.1fm/s %s\t%.1fmm\t%.0f%%\t%s\n",
+			label(r.Location),
+			weather.Temperature,
+			weather.FeelsLike(),
+			weather.WindSpeed,
+			shortDirection(weather.WindDirection()),
+			weather.Precipitation,
+			weather.Humidity,
+			strings.TrimSpace(emoji+" "+description),
+		)
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+
+	f.formatFailures(w, failures(results))
+	return nil
+}
+
+// CompareForecast formats hourly forecasts for several locations as a
+// table with one column per location
+func (f *FullFormatter) CompareForecast(w io.Writer, results []Result[*models.Forecast], opts Options) error {
+	if opts.NoColor {
+		ui.DisableColors()
+	}
+
+	ok := succeeded(results)
+	times := forecastTimes(results)
+	fmt.Fprintln(w, ui.Header(fmt.Sprintf("HOURLY FORECAST (Next %d Hours) - %d Locations", len(times), len(results))))
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprint(tw, "Time")
+	for _, r := range ok {
+		fmt.Fprintf(tw, "\t%s", label(r.Location))
+	}
+	fmt.Fprintln(tw)
+
+	for _, t := range times {
+		fmt.Fprint(tw, t.Format("Mon 15:04"))
+		for _, r := range ok {
+			cell := "-"
+			if hour := hourAt(r.Data, t); hour != nil {
+				cell = fmt.Sprintf("%.1f°C %.1fmm", hour.Temperature, hour.Precipitation)
+			}
+			fmt.Fprintf(tw, "\t%s", cell)
+		}
+		fmt.Fprintln(tw)
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+
+	f.formatFailures(w, failures(results))
+	return nil
+}
+
+// CompareDailyForecast formats multi-day forecasts for several locations
+// as a table with one column per location
+func (f *FullFormatter) CompareDailyForecast(w io.Writer, results []Result[*models.DailyForecast], opts Options) error {
+	if opts.NoColor {
+		ui.DisableColors()
+	}
+
+	ok := succeeded(results)
+	dates := forecastDates(results)
+	fmt.Fprintln(w, ui.Header(fmt.Sprintf("DAILY FORECAST (%d Days) - %d Locations", len(dates), len(results))))
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprint(tw, "Date")
+	for _, r := range ok {
+		fmt.Fprintf(tw, "\t%s", label(r.Location))
+	}
+	fmt.Fprintln(tw)
+
+	for _, date := range dates {
+		t, _ := time.Parse("2006-01-02", date)
+		fmt.Fprint(tw, t.Format("Mon Jan 2"))
+		for _, r := range ok {
+			cell := "-"
+			if day := dayAt(r.Data, date); day != nil {
+				cell = fmt.Sprintf("%.1f-%.1f°C %.1fmm", day.TemperatureMin, day.TemperatureMax, day.PrecipitationTotal)
+			}
+			fmt.Fprintf(tw, "\t%s", cell)
+		}
+		fmt.Fprintln(tw)
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+
+	f.formatFailures(w, failures(results))
+	return nil
+}
+
+// formatFailures lists the locations whose data could not be fetched
+func (f *FullFormatter) formatFailures(w io.Writer, failed []failure) {
+	if len(failed) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w, ui.YellowBold("⚠️  Failed Locations:"))
+	for _, r := range failed {
+		fmt.Fprintf(w, "  • %s: %v\n", label(r.Location), r.Err)
+	}
+	fmt.Fprintln(w)
+}
+
+// FormatRoute formats the forecast along a route as a table, one row per
+// sampled point, followed by the worst conditions
+func (f *FullFormatter) FormatRoute(w io.Writer, route *models.Route, opts Options) error {
+	if opts.NoColor {
+		ui.DisableColors()
+	}
+
+	fmt.Fprintln(w, ui.Header(fmt.Sprintf("ROUTE FORECAST - %s (%.1f km, %s)", route.Name, route.Length, formatDuration(route.Arrival().Sub(route.Departure)))))
+	fmt.Fprintf(w, "%s %s at %.1f km/h, arriving %s\n\n",
+		ui.Bold("Departure:"),
+		route.Departure.Format("Mon Jan 2 15:04"),
+		route.Speed,
+		arrivalTime(route, route.Arrival()),
+	)
+
+	extremes := route.Extremes()
+
+	// Conditions and highlights come last so that emoji widths do not
+	// shift the columns
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Arrival\tDistance\tLocation\tTemp\tFeels\tWind\tPrecip\tConditions")
+	for i := range route.Points {
+		p := &route.Points[i]
+		fmt.Fprintf(tw, "%s\t%.1f km\t%s\t", arrivalTime(route, p.Arrival), p.Distance, label(p.Location))
+
+		if p.Forecast == nil {
+			fmt.Fprintln(tw, "-\t-\t-\t-\tno forecast")
+			continue
+		}
+
+		hour := p.Forecast
+		emoji, description := ui.WeatherSymbol(hour.Symbol)
+		if opts.NoEmoji {
+			emoji = ""
+			description = stripEmoji(description)
+		}
+		conditions := strings.TrimSpace(emoji + " " + description)
+		if names := extremeNames(extremes, p); names != "" {
+			marker := "⚠️ "
+			if opts.NoEmoji {
+				marker = "!"
+			}
+			conditions += "  " + ui.YellowBold(marker+" "+names)
+		}
+
+		fmt.Fprintf(tw, "%.1f°C\t%.1f°C\t%.1fm/s\t%.1fmm\t%s\n",
+			hour.Temperature,
+			hour.FeelsLike(),
+			hour.WindSpeed,
+			hour.Precipitation,
+			conditions,
+		)
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+
+	if extremes.Coldest == nil {
+		return nil
+	}
+
+	fmt.Fprintln(w, ui.YellowBold("⚠️  Worst Conditions:"))
+	fmt.Fprintf(w, "  • Coldest:  %.1f°C feels like, %s\n", extremes.Coldest.Forecast.FeelsLike(), f.routePlace(route, extremes.Coldest))
+	fmt.Fprintf(w, "  • Windiest: %.1fm/s, %s\n", extremes.Windiest.Forecast.WindSpeed, f.routePlace(route, extremes.Windiest))
+	if extremes.Wettest != nil {
+		fmt.Fprintf(w, "  • Wettest:  %.1fmm, %s\n", extremes.Wettest.Forecast.Precipitation, f.routePlace(route, extremes.Wettest))
+	} else {
+		fmt.Fprintln(w, "  • No precipitation expected")
+	}
+	fmt.Fprintln(w)
+	return nil
+}
+
+// routePlace describes where and when a route point is reached
+func (f *FullFormatter) routePlace(route *models.Route, p *models.RoutePoint) string {
+	return fmt.Sprintf("%s at km %.1f (%s)", arrivalTime(route, p.Arrival), p.Distance, label(p.Location))
+}
+
+// FormatPlan formats the best times for an activity as a ranked table
+func (f *FullFormatter) FormatPlan(w io.Writer, plan *models.Plan, opts Options) error {
+	if opts.NoColor {
+		ui.DisableColors()
+	}
+
+	fmt.Fprintln(w, ui.Header(fmt.Sprintf("BEST TIMES FOR %s - %s (%s)",
+		strings.ToUpper(plan.Activity), label(plan.Location), formatDuration(plan.Duration))))
+
+	if len(plan.Windows) == 0 {
+		fmt.Fprintf(w, "No %s window found in the forecast period.\n\n", formatDuration(plan.Duration))
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "#\tWhen\tScore\tConditions")
+	for i, window := range plan.Windows {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n",
+			i+1,
+			windowTime(window),
+			scoreColor(window.Score),
+			strings.Join(window.Reasons, ", "),
+		)
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+	return nil
+}
+
+// FormatCheck formats the rules that matched the forecast as a table
+func (f *FullFormatter) FormatCheck(w io.Writer, check *models.Check, opts Options) error {
+	if opts.NoColor {
+		ui.DisableColors()
+	}
+
+	fmt.Fprintln(w, ui.Header(fmt.Sprintf("RULE CHECK - %s", checkLocations(check))))
+
+	if len(check.Alerts) == 0 {
+		mark := "✅ "
+		if opts.NoEmoji {
+			mark = ""
+		}
+		fmt.Fprintf(w, "%sNo rule matched (%d checked).\n\n", mark, check.Rules)
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "Rule\tLocation\tWhen\tValues\tMessage")
+	for _, alert := range check.Alerts {
+		message := alert.Message
+		if message == "" {
+			message = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+			ui.YellowBold(alert.Rule),
+			label(alert.Location),
+			timeSpan(alert.Start, alert.End),
+			alertValues(alert),
+			message,
+		)
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+
+	marker := "⚠️  "
+	if opts.NoEmoji {
+		marker = "! "
+	}
+	fmt.Fprintln(w, ui.YellowBold(fmt.Sprintf("%s%d of %d rules matched", marker, matchedRules(check), check.Rules)))
+	fmt.Fprintln(w)
+	return nil
+}
+
+// FormatDiff formats the changes in a forecast since it was last seen as
+// a table
+func (f *FullFormatter) FormatDiff(w io.Writer, diff *models.ForecastDiff, opts Options) error {
+	if opts.NoColor {
+		ui.DisableColors()
+	}
+
+	if diff.First() {
+		fmt.Fprintln(w, ui.Header(fmt.Sprintf("FORECAST CHANGES - %s", label(diff.Location))))
+		fmt.Fprintln(w, "First look at this forecast; changes are shown from the next time.")
+		fmt.Fprintln(w)
+		return nil
+	}
+
+	fmt.Fprintln(w, ui.Header(fmt.Sprintf("FORECAST CHANGES - %s (%s)", label(diff.Location), diffSince(diff))))
+	if len(diff.Hours) == 0 {
+		mark := "✅ "
+		if opts.NoEmoji {
+			mark = ""
+		}
+		fmt.Fprintf(w, "%sNo changes above the thresholds.\n\n", mark)
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "When\tChanges")
+	for _, hour := range diff.Hours {
+		fmt.Fprintf(tw, "%s\t%s\n", ui.Cyan(dayTime(hour.Time, diff.SeenAt)), hourChanges(hour))
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+	return nil
+}
@@ -3,6 +3,8 @@ package formatter
 import (
 	"encoding/json"
 	"io"
+	"math"
+	"time"
 
 	"github.com/kristofferrisa/sky-cli/internal/models"
 )
@@ -56,14 +58,16 @@ type JSONForecast struct {
 
 // JSONHourlyForecast is a single hour in the forecast
 type JSONHourlyForecast struct {
-	Time          string  `json:"time"`
-	Temperature   float64 `json:"temperature"`
-	FeelsLike     float64 `json:"feels_like"`
-	Humidity      float64 `json:"humidity"`
-	WindSpeed     float64 `json:"wind_speed"`
-	Precipitation float64 `json:"precipitation"`
-	Symbol        string  `json:"symbol"`
-	Description   string  `json:"description"`
+	Time                     string  `json:"time"`
+	Temperature              float64 `json:"temperature"`
+	FeelsLike                float64 `json:"feels_like"`
+	Humidity                 float64 `json:"humidity"`
+	WindSpeed                float64 `json:"wind_speed"`
+	WindGust                 float64 `json:"wind_gust"`
+	Precipitation            float64 `json:"precipitation"`
+	PrecipitationProbability float64 `json:"precipitation_probability"`
+	Symbol                   string  `json:"symbol"`
+	Description              string  `json:"description"`
 }
 
 // JSONDailySummary is the JSON representation of daily summary
@@ -77,9 +81,131 @@ type JSONDailySummary struct {
 	Units              JSONUnits        `json:"units"`
 }
 
+// JSONDailyForecast is the JSON representation of a multi-day forecast
+type JSONDailyForecast struct {
+	Location *models.Location       `json:"location"`
+	Days     []JSONDailyForecastDay `json:"days"`
+	Units    JSONUnits              `json:"units"`
+}
+
+// JSONDailyForecastDay is a single day in the daily forecast
+type JSONDailyForecastDay struct {
+	Date               string  `json:"date"`
+	TemperatureMin     float64 `json:"temperature_min"`
+	TemperatureMax     float64 `json:"temperature_max"`
+	TemperatureAvg     float64 `json:"temperature_avg"`
+	PrecipitationTotal float64 `json:"precipitation_total"`
+	Symbol             string  `json:"symbol"`
+	WindSpeedMax       float64 `json:"wind_speed_max"`
+}
+
+// JSONLocationError reports a location whose data could not be fetched
+type JSONLocationError struct {
+	Location *models.Location `json:"location"`
+	Error    string           `json:"error"`
+}
+
+// JSONRoute is the JSON representation of the forecast along a route
+type JSONRoute struct {
+	Name      string           `json:"name"`
+	Departure string           `json:"departure"`
+	Arrival   string           `json:"arrival"`
+	Speed     float64          `json:"speed"`
+	Length    float64          `json:"length"`
+	Points    []JSONRoutePoint `json:"points"`
+	Worst     JSONRouteWorst   `json:"worst"`
+	Units     JSONUnits        `json:"units"`
+}
+
+// JSONRoutePoint is a point along a route. Forecast is null when the
+// forecast does not reach the arrival time.
+type JSONRoutePoint struct {
+	Location *models.Location    `json:"location"`
+	Distance float64             `json:"distance"`
+	Arrival  string              `json:"arrival"`
+	Forecast *JSONHourlyForecast `json:"forecast"`
+}
+
+// JSONRouteWorst holds the indexes in points of the worst conditions, or
+// null when there are none
+type JSONRouteWorst struct {
+	Coldest  *int `json:"coldest"`
+	Windiest *int `json:"windiest"`
+	Wettest  *int `json:"wettest"`
+}
+
+// JSONPlan is the JSON representation of the best times for an activity
+type JSONPlan struct {
+	Location        *models.Location `json:"location"`
+	Activity        string           `json:"activity"`
+	DurationMinutes int              `json:"duration_minutes"`
+	Windows         []JSONWindow     `json:"windows"`
+	Units           JSONUnits        `json:"units"`
+}
+
+// JSONWindow is a ranked window, with a score from 0 to 100
+type JSONWindow struct {
+	Start   string               `json:"start"`
+	End     string               `json:"end"`
+	Score   float64              `json:"score"`
+	Reasons []string             `json:"reasons"`
+	Hours   []JSONHourlyForecast `json:"hours"`
+}
+
+// JSONCheck is the JSON representation of a rule check
+type JSONCheck struct {
+	CheckedAt string             `json:"checked_at"`
+	Rules     int                `json:"rules"`
+	Matched   int                `json:"matched"`
+	Locations []*models.Location `json:"locations"`
+	Alerts    []JSONAlert        `json:"alerts"`
+}
+
+// JSONAlert is a rule that matched the forecast for a location. Values
+// are the rule's variables in the first matching period.
+type JSONAlert struct {
+	Rule      string             `json:"rule"`
+	Condition string             `json:"condition"`
+	Message   string             `json:"message,omitempty"`
+	Location  *models.Location   `json:"location"`
+	Start     string             `json:"start"`
+	End       string             `json:"end"`
+	Periods   int                `json:"periods"`
+	Values    map[string]float64 `json:"values"`
+}
+
+// JSONDiff is the JSON representation of the changes in a forecast since
+// it was last seen. PreviousSeenAt is null the first time it is seen.
+type JSONDiff struct {
+	Location       *models.Location `json:"location"`
+	SeenAt         string           `json:"seen_at"`
+	PreviousSeenAt *string          `json:"previous_seen_at"`
+	Hours          []JSONHourChange `json:"hours"`
+}
+
+// JSONHourChange is an hour whose forecast changed
+type JSONHourChange struct {
+	Time    string       `json:"time"`
+	Changes []JSONChange `json:"changes"`
+}
+
+// JSONChange is a variable whose forecast changed
+type JSONChange struct {
+	Variable string  `json:"variable"`
+	Unit     string  `json:"unit"`
+	Previous float64 `json:"previous"`
+	Current  float64 `json:"current"`
+	Delta    float64 `json:"delta"`
+}
+
 // FormatCurrent formats current weather as JSON
 func (f *JSONFormatter) FormatCurrent(w io.Writer, weather *models.Weather, opts Options) error {
-	jw := JSONWeather{
+	return writeJSON(w, NewJSONWeather(weather))
+}
+
+// NewJSONWeather converts current weather to its JSON representation
+func NewJSONWeather(weather *models.Weather) JSONWeather {
+	return JSONWeather{
 		Location:      weather.Location,
 		Timestamp:     weather.Timestamp.Format("2006-01-02T15:04:05Z"),
 		UpdatedAt:     weather.UpdatedAt.Format("2006-01-02T15:04:05Z"),
@@ -94,96 +220,70 @@ func (f *JSONFormatter) FormatCurrent(w io.Writer, weather *models.Weather, opts
 		Precipitation: weather.Precipitation,
 		Symbol:        weather.Symbol,
 		Description:   weather.Description,
-		Units: JSONUnits{
-			Temperature:   "celsius",
-			WindSpeed:     "meters_per_second",
-			Pressure:      "hectopascal",
-			Precipitation: "millimeters",
-			Humidity:      "percent",
-		},
+		Units:         metricUnits(),
 	}
-
-	encoder := json.NewEncoder(w)
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(jw)
 }
 
 // FormatForecast formats forecast as JSON
 func (f *JSONFormatter) FormatForecast(w io.Writer, forecast *models.Forecast, opts Options) error {
+	return writeJSON(w, NewJSONForecast(forecast))
+}
+
+// NewJSONForecast converts an hourly forecast to its JSON representation
+func NewJSONForecast(forecast *models.Forecast) JSONForecast {
 	jf := JSONForecast{
 		Location: forecast.Location,
 		Hours:    make([]JSONHourlyForecast, len(forecast.Hours)),
 	}
 
-	for i, hour := range forecast.Hours {
-		jf.Hours[i] = JSONHourlyForecast{
-			Time:          hour.Time.Format("2006-01-02T15:04:05Z"),
-			Temperature:   hour.Temperature,
-			FeelsLike:     hour.FeelsLike(),
-			Humidity:      hour.Humidity,
-			WindSpeed:     hour.WindSpeed,
-			Precipitation: hour.Precipitation,
-			Symbol:        hour.Symbol,
-			Description:   hour.Description,
-		}
+	for i := range forecast.Hours {
+		jf.Hours[i] = newJSONHourlyForecast(&forecast.Hours[i])
 	}
 
-	encoder := json.NewEncoder(w)
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(jf)
+	return jf
+}
+
+// newJSONHourlyForecast converts a forecast hour to its JSON
+// representation
+func newJSONHourlyForecast(hour *models.HourlyForecast) JSONHourlyForecast {
+	return JSONHourlyForecast{
+		Time:                     hour.Time.Format("2006-01-02T15:04:05Z"),
+		Temperature:              hour.Temperature,
+		FeelsLike:                hour.FeelsLike(),
+		Humidity:                 hour.Humidity,
+		WindSpeed:                hour.WindSpeed,
+		WindGust:                 hour.WindGust,
+		Precipitation:            hour.Precipitation,
+		PrecipitationProbability: hour.PrecipitationProbability,
+		Symbol:                   hour.Symbol,
+		Description:              hour.Description,
+	}
 }
 
 // FormatDailySummary formats daily summary as JSON
 func (f *JSONFormatter) FormatDailySummary(w io.Writer, summary *models.DailySummary, opts Options) error {
-	js := JSONDailySummary{
+	return writeJSON(w, JSONDailySummary{
 		Location:           summary.Location,
 		Date:               summary.Date.Format("2006-01-02"),
 		TemperatureMin:     summary.TemperatureMin,
 		TemperatureMax:     summary.TemperatureMax,
 		TemperatureAvg:     summary.TemperatureAvg,
 		PrecipitationTotal: summary.PrecipitationTotal,
-		Units: JSONUnits{
-			Temperature:   "celsius",
-			WindSpeed:     "meters_per_second",
-			Pressure:      "hectopascal",
-			Precipitation: "millimeters",
-			Humidity:      "percent",
-		},
-	}
-
-	encoder := json.NewEncoder(w)
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(js)
+		Units:              metricUnits(),
+	})
 }
 
 // FormatDailyForecast formats daily forecast as JSON
 func (f *JSONFormatter) FormatDailyForecast(w io.Writer, dailyForecast *models.DailyForecast, opts Options) error {
-	type JSONDailyForecastDay struct {
-		Date               string  `json:"date"`
-		TemperatureMin     float64 `json:"temperature_min"`
-		TemperatureMax     float64 `json:"temperature_max"`
-		TemperatureAvg     float64 `json:"temperature_avg"`
-		PrecipitationTotal float64 `json:"precipitation_total"`
-		Symbol             string  `json:"symbol"`
-		WindSpeedMax       float64 `json:"wind_speed_max"`
-	}
-
-	type JSONDailyForecastOutput struct {
-		Location *models.Location       `json:"location"`
-		Days     []JSONDailyForecastDay `json:"days"`
-		Units    JSONUnits              `json:"units"`
-	}
+	return writeJSON(w, NewJSONDailyForecast(dailyForecast))
+}
 
-	output := JSONDailyForecastOutput{
+// NewJSONDailyForecast converts a daily forecast to its JSON representation
+func NewJSONDailyForecast(dailyForecast *models.DailyForecast) JSONDailyForecast {
+	output := JSONDailyForecast{
 		Location: dailyForecast.Location,
 		Days:     make([]JSONDailyForecastDay, len(dailyForecast.Days)),
-		Units: JSONUnits{
-			Temperature:   "celsius",
-			WindSpeed:     "meters_per_second",
-			Pressure:      "hectopascal",
-			Precipitation: "millimeters",
-			Humidity:      "percent",
-		},
+		Units:    metricUnits(),
 	}
 
 	for i, day := range dailyForecast.Days {
@@ -198,7 +298,192 @@ func (f *JSONFormatter) FormatDailyForecast(w io.Writer, dailyForecast *models.D
 		}
 	}
 
+	return output
+}
+
+// CompareCurrent formats current weather for several locations as a JSON
+// array. Locations that failed have an error instead of weather data.
+func (f *JSONFormatter) CompareCurrent(w io.Writer, results []Result[*models.Weather], opts Options) error {
+	return writeJSON(w, compareJSON(results, NewJSONWeather))
+}
+
+// CompareForecast formats hourly forecasts for several locations as a
+// JSON array
+func (f *JSONFormatter) CompareForecast(w io.Writer, results []Result[*models.Forecast], opts Options) error {
+	return writeJSON(w, compareJSON(results, NewJSONForecast))
+}
+
+// CompareDailyForecast formats daily forecasts for several locations as a
+// JSON array
+func (f *JSONFormatter) CompareDailyForecast(w io.Writer, results []Result[*models.DailyForecast], opts Options) error {
+	return writeJSON(w, compareJSON(results, NewJSONDailyForecast))
+}
+
+// FormatRoute formats the forecast along a route as JSON. Distances are in
+// km and the speed in km/h.
+func (f *JSONFormatter) FormatRoute(w io.Writer, route *models.Route, opts Options) error {
+	jr := JSONRoute{
+		Name:      route.Name,
+		Departure: route.Departure.Format(time.RFC3339),
+		Arrival:   route.Arrival().Format(time.RFC3339),
+		Speed:     route.Speed,
+		Length:    route.Length,
+		Points:    make([]JSONRoutePoint, len(route.Points)),
+		Units:     metricUnits(),
+	}
+
+	extremes := route.Extremes()
+	for i := range route.Points {
+		p := &route.Points[i]
+		jr.Points[i] = JSONRoutePoint{
+			Location: p.Location,
+			Distance: p.Distance,
+			Arrival:  p.Arrival.Format(time.RFC3339),
+		}
+		if p.Forecast != nil {
+			hour := newJSONHourlyForecast(p.Forecast)
+			jr.Points[i].Forecast = &hour
+		}
+
+		index := i
+		if p == extremes.Coldest {
+			jr.Worst.Coldest = &index
+		}
+		if p == extremes.Windiest {
+			jr.Worst.Windiest = &index
+		}
+		if p == extremes.Wettest {
+			jr.Worst.Wettest = &index
+		}
+	}
+
+	return writeJSON(w, jr)
+}
+
+// FormatPlan formats the best times for an activity as JSON
+func (f *JSONFormatter) FormatPlan(w io.Writer, plan *models.Plan, opts Options) error {
+	return writeJSON(w, NewJSONPlan(plan))
+}
+
+// NewJSONPlan converts a plan to its JSON representation
+func NewJSONPlan(plan *models.Plan) JSONPlan {
+	jp := JSONPlan{
+		Location:        plan.Location,
+		Activity:        plan.Activity,
+		DurationMinutes: int(plan.Duration.Minutes()),
+		Windows:         make([]JSONWindow, len(plan.Windows)),
+		Units:           metricUnits(),
+	}
+
+	for i, window := range plan.Windows {
+		jw := JSONWindow{
+			Start:   window.Start.Format(time.RFC3339),
+			End:     window.End.Format(time.RFC3339),
+			Score:   window.Score,
+			Reasons: window.Reasons,
+			Hours:   make([]JSONHourlyForecast, len(window.Hours)),
+		}
+		for j := range window.Hours {
+			jw.Hours[j] = newJSONHourlyForecast(&window.Hours[j])
+		}
+		jp.Windows[i] = jw
+	}
+	return jp
+}
+
+// FormatCheck formats the rules that matched the forecast as JSON
+func (f *JSONFormatter) FormatCheck(w io.Writer, check *models.Check, opts Options) error {
+	jc := JSONCheck{
+		CheckedAt: check.Time.Format(time.RFC3339),
+		Rules:     check.Rules,
+		Matched:   matchedRules(check),
+		Locations: check.Locations,
+		Alerts:    make([]JSONAlert, len(check.Alerts)),
+	}
+
+	for i, alert := range check.Alerts {
+		jc.Alerts[i] = NewJSONAlert(alert)
+	}
+
+	return writeJSON(w, jc)
+}
+
+// FormatDiff formats the changes in a forecast as JSON
+func (f *JSONFormatter) FormatDiff(w io.Writer, diff *models.ForecastDiff, opts Options) error {
+	jd := JSONDiff{
+		Location: diff.Location,
+		SeenAt:   diff.SeenAt.Format(time.RFC3339),
+		Hours:    make([]JSONHourChange, len(diff.Hours)),
+	}
+	if !diff.First() {
+		previous := diff.PreviousSeenAt.Format(time.RFC3339)
+		jd.PreviousSeenAt = &previous
+	}
+
+	for i, hour := range diff.Hours {
+		jh := JSONHourChange{Time: hour.Time.Format(time.RFC3339), Changes: make([]JSONChange, len(hour.Changes))}
+		for j, c := range hour.Changes {
+			jh.Changes[j] = JSONChange{
+				Variable: c.Name,
+				Unit:     c.Unit,
+				Previous: c.Previous,
+				Current:  c.Current,
+				Delta:    math.Round(c.Delta()*100) / 100,
+			}
+		}
+		jd.Hours[i] = jh
+	}
+
+	return writeJSON(w, jd)
+}
+
+// NewJSONAlert converts an alert to its JSON representation
+func NewJSONAlert(alert models.Alert) JSONAlert {
+	ja := JSONAlert{
+		Rule:      alert.Rule,
+		Condition: alert.When,
+		Message:   alert.Message,
+		Location:  alert.Location,
+		Start:     alert.Start.Format(time.RFC3339),
+		End:       alert.End.Format(time.RFC3339),
+		Periods:   alert.Periods,
+		Values:    make(map[string]float64, len(alert.Values)),
+	}
+	for _, v := range alert.Values {
+		ja.Values[v.Name] = v.Value
+	}
+	return ja
+}
+
+// compareJSON converts results to a list of JSON values, using
+// JSONLocationError for locations that failed
+func compareJSON[T any, J any](results []Result[T], convert func(T) J) []interface{} {
+	items := make([]interface{}, len(results))
+	for i, r := range results {
+		if r.Err != nil {
+			items[i] = JSONLocationError{Location: r.Location, Error: r.Err.Error()}
+			continue
+		}
+		items[i] = convert(r.Data)
+	}
+	return items
+}
+
+// metricUnits describes the units sky reports in
+func metricUnits() JSONUnits {
+	return JSONUnits{
+		Temperature:   "celsius",
+		WindSpeed:     "meters_per_second",
+		Pressure:      "hectopascal",
+		Precipitation: "millimeters",
+		Humidity:      "percent",
+	}
+}
+
+// writeJSON writes v as indented JSON
+func writeJSON(w io.Writer, v interface{}) error {
 	encoder := json.NewEncoder(w)
 	encoder.SetIndent("", "  ")
-	return encoder.Encode(output)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(v)
 }
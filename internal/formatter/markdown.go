@@ -3,6 +3,8 @@ package formatter
 import (
 	"fmt"
 	"io"
+	"strings"
+	"time"
 
 	"github.com/kristofferrisa/sky-cli/internal/models"
 	"github.com/kristofferrisa/sky-cli/internal/ui"
@@ -123,3 +125,243 @@ func (f *MarkdownFormatter) FormatDailyForecast(w io.Writer, dailyForecast *mode
 	fmt.Fprintln(w)
 	return nil
 }
+
+// CompareCurrent formats current weather for several locations as a
+// markdown table
+func (f *MarkdownFormatter) CompareCurrent(w io.Writer, results []Result[*models.Weather], opts Options) error {
+	fmt.Fprintf(w, "# Current Weather (%d locations)\n\n", len(results))
+
+	fmt.Fprintln(w, "| Location | Conditions | Temp | Feels Like | Wind | Precip | Humidity |")
+	fmt.Fprintln(w, "|----------|-----------|------|------------|------|--------|----------|")
+
+	for _, r := range succeeded(results) {
+		weather := r.Data
+		emoji, description := ui.WeatherSymbol(weather.Symbol)
+		if opts.NoEmoji {
+			emoji = ""
+		}
+
+		fmt.Fprintf(w, "| %s | %s %s | %.1f°C | %.1f°C | %.1fm/s %s | %.1fmm | %.0f%% |\n",
+			label(r.Location),
+			emoji,
+			description,
+			weather.Temperature,
+			weather.FeelsLike(),
+			weather.WindSpeed,
+			shortDirection(weather.WindDirection()),
+			weather.Precipitation,
+			weather.Humidity,
+		)
+	}
+
+	fmt.Fprintln(w)
+	f.formatFailures(w, failures(results))
+	return nil
+}
+
+// CompareForecast formats hourly forecasts for several locations as a
+// markdown table with one column per location
+func (f *MarkdownFormatter) CompareForecast(w io.Writer, results []Result[*models.Forecast], opts Options) error {
+	ok := succeeded(results)
+	times := forecastTimes(results)
+	fmt.Fprintf(w, "## Hourly Forecast (%d hours, %d locations)\n\n", len(times), len(results))
+
+	fmt.Fprint(w, "| Time |")
+	for _, r := range ok {
+		fmt.Fprintf(w, " %s |", label(r.Location))
+	}
+	fmt.Fprint(w, "\n|------|")
+	for range ok {
+		fmt.Fprint(w, "------|")
+	}
+	fmt.Fprintln(w)
+
+	for _, t := range times {
+		fmt.Fprintf(w, "| %s |", t.Format("Mon 15:04"))
+		for _, r := range ok {
+			cell := "-"
+			if hour := hourAt(r.Data, t); hour != nil {
+				emoji, _ := ui.WeatherSymbol(hour.Symbol)
+				if opts.NoEmoji {
+					emoji = ""
+				}
+				cell = strings.TrimSpace(fmt.Sprintf("%s %.1f°C %.1fmm", emoji, hour.Temperature, hour.Precipitation))
+			}
+			fmt.Fprintf(w, " %s |", cell)
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintln(w)
+	f.formatFailures(w, failures(results))
+	return nil
+}
+
+// CompareDailyForecast formats multi-day forecasts for several locations
+// as a markdown table with one column per location
+func (f *MarkdownFormatter) CompareDailyForecast(w io.Writer, results []Result[*models.DailyForecast], opts Options) error {
+	ok := succeeded(results)
+	dates := forecastDates(results)
+	fmt.Fprintf(w, "## Daily Forecast (%d days, %d locations)\n\n", len(dates), len(results))
+
+	fmt.Fprint(w, "| Date |")
+	for _, r := range ok {
+		fmt.Fprintf(w, " %s |", label(r.Location))
+	}
+	fmt.Fprint(w, "\n|------|")
+	for range ok {
+		fmt.Fprint(w, "------|")
+	}
+	fmt.Fprintln(w)
+
+	for _, date := range dates {
+		t, _ := time.Parse("2006-01-02", date)
+		fmt.Fprintf(w, "| %s |", t.Format("Mon Jan 2"))
+		for _, r := range ok {
+			cell := "-"
+			if day := dayAt(r.Data, date); day != nil {
+				emoji, _ := ui.WeatherSymbol(day.Symbol)
+				if opts.NoEmoji {
+					emoji = ""
+				}
+				cell = strings.TrimSpace(fmt.Sprintf("%s %.1f-%.1f°C %.1fmm", emoji, day.TemperatureMin, day.TemperatureMax, day.PrecipitationTotal))
+			}
+			fmt.Fprintf(w, " %s |", cell)
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintln(w)
+	f.formatFailures(w, failures(results))
+	return nil
+}
+
+// formatFailures lists the locations whose data could not be fetched
+func (f *MarkdownFormatter) formatFailures(w io.Writer, failed []failure) {
+	if len(failed) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w, "### Failed Locations")
+	fmt.Fprintln(w)
+	for _, r := range failed {
+		fmt.Fprintf(w, "- **%s:** %v\n", label(r.Location), r.Err)
+	}
+	fmt.Fprintln(w)
+}
+
+// FormatRoute formats the forecast along a route as a markdown table,
+// with the worst conditions in bold
+func (f *MarkdownFormatter) FormatRoute(w io.Writer, route *models.Route, opts Options) error {
+	fmt.Fprintf(w, "## Route Forecast: %s\n\n", route.Name)
+	fmt.Fprintf(w, "**Departure:** %s at %.1f km/h  \n", route.Departure.Format("Mon Jan 2 15:04"), route.Speed)
+	fmt.Fprintf(w, "**Length:** %.1f km, arriving %s\n\n", route.Length, arrivalTime(route, route.Arrival()))
+
+	fmt.Fprintln(w, "| Arrival | Distance | Location | Conditions | Temp | Feels Like | Wind | Precip | |")
+	fmt.Fprintln(w, "|---------|----------|----------|------------|------|------------|------|--------|-|")
+
+	extremes := route.Extremes()
+	for i := range route.Points {
+		p := &route.Points[i]
+		fmt.Fprintf(w, "| %s | %.1f km | %s |", arrivalTime(route, p.Arrival), p.Distance, label(p.Location))
+
+		if p.Forecast == nil {
+			fmt.Fprintln(w, " no forecast | - | - | - | - | |")
+			continue
+		}
+
+		hour := p.Forecast
+		emoji, description := ui.WeatherSymbol(hour.Symbol)
+		if opts.NoEmoji {
+			emoji = ""
+		}
+		names := extremeNames(extremes, p)
+		if names != "" {
+			names = "**" + names + "**"
+		}
+
+		fmt.Fprintf(w, " %s | %.1f°C | %.1f°C | %.1fm/s | %.1fmm | %s |\n",
+			strings.TrimSpace(emoji+" "+description),
+			hour.Temperature,
+			hour.FeelsLike(),
+			hour.WindSpeed,
+			hour.Precipitation,
+			names,
+		)
+	}
+
+	fmt.Fprintln(w)
+	return nil
+}
+
+// FormatPlan formats the best times for an activity as a markdown table
+func (f *MarkdownFormatter) FormatPlan(w io.Writer, plan *models.Plan, opts Options) error {
+	fmt.Fprintf(w, "## Best Times for %s: %s (%s)\n\n", plan.Activity, label(plan.Location), formatDuration(plan.Duration))
+
+	if len(plan.Windows) == 0 {
+		fmt.Fprintf(w, "No %s window found in the forecast period.\n\n", formatDuration(plan.Duration))
+		return nil
+	}
+
+	fmt.Fprintln(w, "| # | When | Score | Conditions |")
+	fmt.Fprintln(w, "|---|------|-------|------------|")
+	for i, window := range plan.Windows {
+		fmt.Fprintf(w, "| %d | %s | %.0f | %s |\n", i+1, windowTime(window), window.Score, strings.Join(window.Reasons, ", "))
+	}
+
+	fmt.Fprintln(w)
+	return nil
+}
+
+// FormatCheck formats the rules that matched the forecast as a markdown
+// table
+func (f *MarkdownFormatter) FormatCheck(w io.Writer, check *models.Check, opts Options) error {
+	fmt.Fprintf(w, "## Rule Check: %s\n\n", checkLocations(check))
+
+	if len(check.Alerts) == 0 {
+		fmt.Fprintf(w, "No rule matched (%d checked).\n\n", check.Rules)
+		return nil
+	}
+
+	fmt.Fprintln(w, "| Rule | Location | When | Condition | Values | Message |")
+	fmt.Fprintln(w, "|------|----------|------|-----------|--------|---------|")
+	for _, alert := range check.Alerts {
+		fmt.Fprintf(w, "| %s | %s | %s | `%s` | %s | %s |\n",
+			alert.Rule,
+			label(alert.Location),
+			timeSpan(alert.Start, alert.End),
+			alert.When,
+			alertValues(alert),
+			alert.Message,
+		)
+	}
+
+	fmt.Fprintf(w, "\n**%d of %d rules matched**\n\n", matchedRules(check), check.Rules)
+	return nil
+}
+
+// FormatDiff formats the changes in a forecast since it was last seen as
+// a markdown table
+func (f *MarkdownFormatter) FormatDiff(w io.Writer, diff *models.ForecastDiff, opts Options) error {
+	fmt.Fprintf(w, "## Forecast Changes: %s\n\n", label(diff.Location))
+
+	if diff.First() {
+		fmt.Fprintln(w, "First look at this forecast; changes are shown from the next time.")
+		fmt.Fprintln(w)
+		return nil
+	}
+	if len(diff.Hours) == 0 {
+		fmt.Fprintf(w, "No changes above the thresholds %s.\n\n", diffSince(diff))
+		return nil
+	}
+
+	fmt.Fprintf(w, "Changes %s:\n\n", diffSince(diff))
+	fmt.Fprintln(w, "| When | Changes |")
+	fmt.Fprintln(w, "|------|---------|")
+	for _, hour := range diff.Hours {
+		fmt.Fprintf(w, "| %s | %s |\n", dayTime(hour.Time, diff.SeenAt), hourChanges(hour))
+	}
+
+	fmt.Fprintln(w)
+	return nil
+}
@@ -0,0 +1,37 @@
+package formatter
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+	"github.com/kristofferrisa/sky-cli/internal/ui"
+)
+
+// windowTime formats when a window is, such as "Tue 09:00-11:00"
+func windowTime(w models.Window) string {
+	return timeSpan(w.Start, w.End)
+}
+
+// timeSpan formats a period such as "Tue 09:00-11:00", with the day of
+// the end when it is another day
+func timeSpan(start, end time.Time) string {
+	if end.YearDay() != start.YearDay() {
+		return start.Format("Mon 15:04") + "-" + end.Format("Mon 15:04")
+	}
+	return start.Format("Mon 15:04") + "-" + end.Format("15:04")
+}
+
+// scoreColor colors a score green when conditions are good, yellow when
+// they are fair and red when they are poor
+func scoreColor(score float64) string {
+	s := fmt.Sprintf("%.0f", score)
+	switch {
+	case score >= 80:
+		return ui.Green(s)
+	case score >= 50:
+		return ui.Yellow(s)
+	default:
+		return ui.Red(s)
+	}
+}
@@ -0,0 +1,50 @@
+package formatter
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+// extremeNames returns what makes a route point stand out, such as
+// "coldest, windiest", or "" for an ordinary point
+func extremeNames(e models.RouteExtremes, p *models.RoutePoint) string {
+	var names string
+	add := func(worst *models.RoutePoint, name string) {
+		if worst != p {
+			return
+		}
+		if names != "" {
+			names += ", "
+		}
+		names += name
+	}
+	add(e.Coldest, "coldest")
+	add(e.Windiest, "windiest")
+	add(e.Wettest, "wettest")
+	return names
+}
+
+// formatDuration formats a duration in hours and minutes, such as
+// "8h 14m", "2h" or "45m"
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	hours, minutes := int(d.Hours()), int(d.Minutes())%60
+	switch {
+	case hours == 0:
+		return fmt.Sprintf("%dm", minutes)
+	case minutes == 0:
+		return fmt.Sprintf("%dh", hours)
+	}
+	return fmt.Sprintf("%dh %02dm", hours, minutes)
+}
+
+// arrivalTime formats the time a route point is reached, with the day
+// when the route lasts into another day
+func arrivalTime(r *models.Route, t time.Time) string {
+	if r.Arrival().YearDay() != r.Departure.YearDay() {
+		return t.Format("Mon 15:04")
+	}
+	return t.Format("15:04")
+}
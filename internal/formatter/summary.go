@@ -3,7 +3,10 @@ package formatter
 import (
 	"fmt"
 	"io"
+	"math"
+	"strings"
 
+	"github.com/kristofferrisa/sky-cli/internal/chart"
 	"github.com/kristofferrisa/sky-cli/internal/models"
 	"github.com/kristofferrisa/sky-cli/internal/ui"
 )
@@ -59,7 +62,7 @@ func (f *SummaryFormatter) FormatForecast(w io.Writer, forecast *models.Forecast
 	}
 
 	fmt.Fprintln(w, ui.Bold(forecast.Location.String()))
-	fmt.Fprintln(w, ui.Bold("Hourly Forecast:"))
+	fmt.Fprintf(w, "%s %s\n", ui.Bold("Hourly Forecast:"), temperatureSparkline(forecast, opts))
 
 	for _, hour := range forecast.Hours {
 		emoji, description := ui.WeatherSymbol(hour.Symbol)
@@ -85,6 +88,24 @@ func (f *SummaryFormatter) FormatForecast(w io.Writer, forecast *models.Forecast
 	return nil
 }
 
+// temperatureSparkline draws the temperature of every hour as a sparkline,
+// followed by the range, such as "▁▂▄▇█▆▃ 4.1-9.8°C". Long forecasts are
+// averaged down to fit the terminal.
+func temperatureSparkline(forecast *models.Forecast, opts Options) string {
+	if len(forecast.Hours) == 0 {
+		return ""
+	}
+
+	temps := make([]float64, len(forecast.Hours))
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for i, hour := range forecast.Hours {
+		temps[i] = hour.Temperature
+		lo, hi = math.Min(lo, hour.Temperature), math.Max(hi, hour.Temperature)
+	}
+	temps = chart.Resample(temps, max(12, ui.TerminalWidth()-40))
+	return fmt.Sprintf("%s %.1f-%.1f°C", ui.Cyan(chart.Sparkline(temps, opts.NoEmoji)), lo, hi)
+}
+
 // FormatDailySummary formats daily summary as brief text
 func (f *SummaryFormatter) FormatDailySummary(w io.Writer, summary *models.DailySummary, opts Options) error {
 	if opts.NoColor {
@@ -139,3 +160,176 @@ func (f *SummaryFormatter) FormatDailyForecast(w io.Writer, dailyForecast *model
 
 	return nil
 }
+
+// CompareCurrent formats current weather for several locations, one line
+// per location
+func (f *SummaryFormatter) CompareCurrent(w io.Writer, results []Result[*models.Weather], opts Options) error {
+	for _, r := range results {
+		if r.Err != nil {
+			f.formatFailure(w, r.Location, r.Err, opts)
+			continue
+		}
+		if err := f.FormatCurrent(w, r.Data, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CompareForecast formats hourly forecasts for several locations, one
+// after the other
+func (f *SummaryFormatter) CompareForecast(w io.Writer, results []Result[*models.Forecast], opts Options) error {
+	for _, r := range results {
+		if r.Err != nil {
+			f.formatFailure(w, r.Location, r.Err, opts)
+			continue
+		}
+		if err := f.FormatForecast(w, r.Data, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CompareDailyForecast formats daily forecasts for several locations, one
+// after the other
+func (f *SummaryFormatter) CompareDailyForecast(w io.Writer, results []Result[*models.DailyForecast], opts Options) error {
+	for _, r := range results {
+		if r.Err != nil {
+			f.formatFailure(w, r.Location, r.Err, opts)
+			continue
+		}
+		if err := f.FormatDailyForecast(w, r.Data, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatFailure reports a location whose data could not be fetched
+func (f *SummaryFormatter) formatFailure(w io.Writer, loc *models.Location, err error, opts Options) {
+	if opts.NoColor {
+		ui.DisableColors()
+	}
+
+	marker := "⚠️ "
+	if opts.NoEmoji {
+		marker = "Error:"
+	}
+	fmt.Fprintf(w, "%s %s %v\n", ui.Bold(label(loc)), marker, err)
+}
+
+// FormatRoute formats the forecast along a route as a brief summary of
+// the range of conditions and the worst of them
+func (f *SummaryFormatter) FormatRoute(w io.Writer, route *models.Route, opts Options) error {
+	if opts.NoColor {
+		ui.DisableColors()
+	}
+
+	fmt.Fprintf(w, "%s %s-%s (%.1f km)",
+		ui.Bold(route.Name),
+		ui.Cyan(route.Departure.Format("15:04")),
+		ui.Cyan(arrivalTime(route, route.Arrival())),
+		route.Length,
+	)
+
+	extremes := route.Extremes()
+	if extremes.Coldest == nil {
+		fmt.Fprintln(w, ": no forecast")
+		return nil
+	}
+
+	warmest := extremes.Coldest.Forecast.Temperature
+	coldest := warmest
+	for _, p := range route.Points {
+		if p.Forecast != nil {
+			warmest = max(warmest, p.Forecast.Temperature)
+			coldest = min(coldest, p.Forecast.Temperature)
+		}
+	}
+
+	fmt.Fprintf(w, ": %.1f-%.1f°C, coldest %.1f°C feels like at %s, max wind %.1f m/s at %s",
+		coldest,
+		warmest,
+		extremes.Coldest.Forecast.FeelsLike(),
+		arrivalTime(route, extremes.Coldest.Arrival),
+		extremes.Windiest.Forecast.WindSpeed,
+		arrivalTime(route, extremes.Windiest.Arrival),
+	)
+	if extremes.Wettest != nil {
+		fmt.Fprintf(w, ", Rain: %.1fmm at %s", extremes.Wettest.Forecast.Precipitation, arrivalTime(route, extremes.Wettest.Arrival))
+	}
+
+	fmt.Fprintln(w)
+	return nil
+}
+
+// FormatPlan formats the best times for an activity on one line
+func (f *SummaryFormatter) FormatPlan(w io.Writer, plan *models.Plan, opts Options) error {
+	if opts.NoColor {
+		ui.DisableColors()
+	}
+
+	fmt.Fprintf(w, "%s %s (%s): ", ui.Bold(label(plan.Location)), plan.Activity, formatDuration(plan.Duration))
+	if len(plan.Windows) == 0 {
+		fmt.Fprintln(w, "no window found")
+		return nil
+	}
+
+	best := plan.Windows[0]
+	fmt.Fprintf(w, "best %s (score %.0f: %s)", ui.Cyan(windowTime(best)), best.Score, strings.Join(best.Reasons, ", "))
+	for _, window := range plan.Windows[1:] {
+		fmt.Fprintf(w, ", then %s (%.0f)", ui.Cyan(windowTime(window)), window.Score)
+	}
+	fmt.Fprintln(w)
+	return nil
+}
+
+// FormatCheck formats the rules that matched the forecast on one line
+func (f *SummaryFormatter) FormatCheck(w io.Writer, check *models.Check, opts Options) error {
+	if opts.NoColor {
+		ui.DisableColors()
+	}
+
+	if len(check.Alerts) == 0 {
+		fmt.Fprintf(w, "%s: no rule matched (%d checked)\n", ui.Bold(checkLocations(check)), check.Rules)
+		return nil
+	}
+
+	alerts := make([]string, len(check.Alerts))
+	for i, alert := range check.Alerts {
+		alerts[i] = fmt.Sprintf("%s (%s, %s)", ui.YellowBold(alert.Rule), label(alert.Location), ui.Cyan(alert.Start.Format("Mon 15:04")))
+	}
+	fmt.Fprintf(w, "%s: %d of %d rules matched: %s\n", ui.Bold(checkLocations(check)), matchedRules(check), check.Rules, strings.Join(alerts, ", "))
+	return nil
+}
+
+// FormatDiff formats the changes in a forecast since it was last seen on
+// one line, with the first few hours that changed
+func (f *SummaryFormatter) FormatDiff(w io.Writer, diff *models.ForecastDiff, opts Options) error {
+	if opts.NoColor {
+		ui.DisableColors()
+	}
+
+	place := ui.Bold(label(diff.Location))
+	switch {
+	case diff.First():
+		fmt.Fprintf(w, "%s: first look at this forecast\n", place)
+		return nil
+	case len(diff.Hours) == 0:
+		fmt.Fprintf(w, "%s: no changes %s\n", place, diffSince(diff))
+		return nil
+	}
+
+	const shown = 3
+	hours := make([]string, 0, shown+1)
+	for i, hour := range diff.Hours {
+		if i == shown {
+			hours = append(hours, fmt.Sprintf("and %d more", len(diff.Hours)-shown))
+			break
+		}
+		hours = append(hours, fmt.Sprintf("%s %s", ui.Cyan(dayTime(hour.Time, diff.SeenAt)), hourChanges(hour)))
+	}
+	fmt.Fprintf(w, "%s: %d hours changed %s: %s\n", place, len(diff.Hours), diffSince(diff), strings.Join(hours, "; "))
+	return nil
+}
@@ -0,0 +1,56 @@
+package geocode
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/cache"
+)
+
+// CachedReverseGeocoder wraps a reverse geocoder with caching
+type CachedReverseGeocoder struct {
+	geocoder  ReverseGeocoder
+	cache     cache.Cache
+	ttl       time.Duration
+	namespace string
+}
+
+// NewCachedReverseGeocoder creates a cached reverse geocoder. The
+// namespace, such as the server URL, keeps results from different
+// geocoders apart.
+func NewCachedReverseGeocoder(geocoder ReverseGeocoder, cache cache.Cache, ttl time.Duration, namespace string) *CachedReverseGeocoder {
+	return &CachedReverseGeocoder{
+		geocoder:  geocoder,
+		cache:     cache,
+		ttl:       ttl,
+		namespace: namespace,
+	}
+}
+
+// Reverse returns the place nearest to lat, lon with caching
+func (c *CachedReverseGeocoder) Reverse(ctx context.Context, lat, lon float64) (*Nearby, error) {
+	key := fmt.Sprintf("geocode:reverse:%s:%.4f:%.4f", c.namespace, lat, lon)
+
+	// Try to get from cache
+	if data, err := c.cache.Get(key); err == nil {
+		var nearby Nearby
+		if err := json.Unmarshal(data, &nearby); err == nil {
+			return &nearby, nil
+		}
+	}
+
+	// Look up
+	nearby, err := c.geocoder.Reverse(ctx, lat, lon)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cache the result
+	if data, err := json.Marshal(nearby); err == nil {
+		c.cache.Set(key, data, c.ttl)
+	}
+
+	return nearby, nil
+}
@@ -0,0 +1,225 @@
+package geocode
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+// ErrNotCoordinates is returned by ParseCoordinates for text that is not
+// written in any of the supported coordinate forms, such as a place name
+var ErrNotCoordinates = errors.New("not coordinates")
+
+// ErrInvalidCoordinates is wrapped by lookups of text written as
+// coordinates that are not valid, such as a latitude of 91
+var ErrInvalidCoordinates = errors.New("invalid coordinates")
+
+var (
+	// decimalPair matches "59.91,10.75", "59.91, 10.75" and "59.91 10.75"
+	decimalPair = regexp.MustCompile(`^([-+]?\d+(?:\.\d+)?)\s*(?:[,;]\s*|\s+)([-+]?\d+(?:\.\d+)?)$`)
+
+	// dmsNumber matches the degrees, minutes and seconds of a coordinate
+	// such as 59°54'30", 59°54.5' or 10.75°
+	dmsNumber = `(\d+(?:\.\d+)?)\s*[°º]?\s*(?:(\d+(?:\.\d+)?)\s*['′’]\s*)?(?:(\d+(?:\.\d+)?)\s*(?:"|″|”|''|′′)\s*)?`
+
+	// dmsSuffixPair matches "59°54'N 10°45'E" and dmsPrefixPair matches
+	// "N59°54' E10°45'"
+	dmsSuffixPair = regexp.MustCompile(`(?i)^` + dmsNumber + `([NSEW])?\s*[,;]?\s*` + dmsNumber + `([NSEW])?$`)
+	dmsPrefixPair = regexp.MustCompile(`(?i)^([NSEW])\s*` + dmsNumber + `[,;]?\s*([NSEW])\s*` + dmsNumber + `$`)
+
+	// looksLikeGeohash matches bare geohashes; one digit is required so
+	// that place names are not taken for geohashes
+	looksLikeGeohash = regexp.MustCompile(`^[0-9bcdefghjkmnpqrstuvwxyz]{5,12}$`)
+)
+
+// ParseCoordinates parses a location written as coordinates. Supported
+// forms are:
+//
+//	59.9139,10.7522                decimal latitude and longitude
+//	geo:59.9139,10.7522            geo: URI (RFC 5870), with optional altitude
+//	59°54'50"N 10°45'08"E          degrees, minutes and seconds
+//	9FFGWQJ3+H3                    Open Location Code (plus code)
+//	WQJ3+H3 Oslo                   short plus code near a known place
+//	geohash:u4xsu / u4xsu          geohash (bare geohashes need a digit)
+//
+// Text in none of these forms returns ErrNotCoordinates.
+func ParseCoordinates(s string) (*models.Location, error) {
+	s = strings.TrimSpace(s)
+	lower := strings.ToLower(s)
+
+	var loc *models.Location
+	var err error
+
+	switch {
+	case strings.HasPrefix(lower, "geo:"):
+		loc, err = parseGeoURI(s)
+	case strings.HasPrefix(lower, "geohash:"):
+		loc, err = decodeGeohash(strings.TrimSpace(s[len("geohash:"):]))
+	case isPlusCode(s):
+		loc, err = parsePlusCode(s)
+	case decimalPair.MatchString(s):
+		m := decimalPair.FindStringSubmatch(s)
+		loc, err = newCoordinates(m[1], m[2])
+	case dmsPrefixPair.MatchString(s):
+		m := dmsPrefixPair.FindStringSubmatch(s)
+		loc, err = parseDMS(s, m[1:5], m[5:9])
+	case dmsSuffixPair.MatchString(s) && strings.ContainsAny(lower, "°º'′’nsew"):
+		m := dmsSuffixPair.FindStringSubmatch(s)
+		loc, err = parseDMS(s, append([]string{m[4]}, m[1:4]...), append([]string{m[8]}, m[5:8]...))
+	case looksLikeGeohash.MatchString(lower) && strings.ContainsAny(lower, "0123456789"):
+		loc, err = decodeGeohash(lower)
+	default:
+		return nil, ErrNotCoordinates
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	if err := loc.Validate(); err != nil {
+		return nil, err
+	}
+	return loc, nil
+}
+
+// newCoordinates parses a decimal latitude and longitude
+func newCoordinates(lat, lon string) (*models.Location, error) {
+	latitude, err := strconv.ParseFloat(lat, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid latitude: %s", lat)
+	}
+	longitude, err := strconv.ParseFloat(lon, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid longitude: %s", lon)
+	}
+	return &models.Location{Latitude: latitude, Longitude: longitude}, nil
+}
+
+// parseGeoURI parses a geo: URI such as "geo:59.91,10.75,23;u=10". The
+// Android form "geo:0,0?q=59.91,10.75(Label)" is accepted as well.
+func parseGeoURI(s string) (*models.Location, error) {
+	rest := s[len("geo:"):]
+
+	var query string
+	if i := strings.Index(rest, "?"); i >= 0 {
+		rest, query = rest[:i], rest[i+1:]
+	}
+
+	params := strings.Split(rest, ";")
+	for _, param := range params[1:] {
+		key, value, _ := strings.Cut(param, "=")
+		if strings.EqualFold(key, "crs") && !strings.EqualFold(value, "wgs84") {
+			return nil, fmt.Errorf("unsupported coordinate reference system in geo URI: %s", value)
+		}
+	}
+
+	coords := strings.Split(params[0], ",")
+	if len(coords) < 2 || len(coords) > 3 {
+		return nil, fmt.Errorf("invalid geo URI: %s", s)
+	}
+
+	loc, err := newCoordinates(coords[0], coords[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid geo URI: %w", err)
+	}
+	if len(coords) == 3 {
+		if loc.Elevation, err = strconv.ParseFloat(coords[2], 64); err != nil {
+			return nil, fmt.Errorf("invalid geo URI altitude: %s", coords[2])
+		}
+	}
+
+	if query != "" {
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			return nil, fmt.Errorf("invalid geo URI query: %w", err)
+		}
+		if q := values.Get("q"); q != "" && loc.Latitude == 0 && loc.Longitude == 0 {
+			return parseGeoQuery(q)
+		}
+	}
+
+	return loc, nil
+}
+
+// parseGeoQuery parses the q parameter of an Android geo URI, such as
+// "59.91,10.75(Oslo)"
+func parseGeoQuery(q string) (*models.Location, error) {
+	label := ""
+	if i := strings.Index(q, "("); i >= 0 && strings.HasSuffix(q, ")") {
+		q, label = q[:i], q[i+1:len(q)-1]
+	}
+
+	m := decimalPair.FindStringSubmatch(strings.TrimSpace(q))
+	if m == nil {
+		return nil, fmt.Errorf("invalid geo URI query: %s", q)
+	}
+
+	loc, err := newCoordinates(m[1], m[2])
+	if err != nil {
+		return nil, err
+	}
+	loc.Name = strings.TrimSpace(label)
+	return loc, nil
+}
+
+// parseDMS converts a pair of degrees-minutes-seconds coordinates, each
+// given as hemisphere, degrees, minutes and seconds. The hemisphere
+// letters decide which one is the latitude; without them the latitude
+// comes first.
+func parseDMS(s string, a, b []string) (*models.Location, error) {
+	first, firstAxis, err := dmsValue(a)
+	if err != nil {
+		return nil, err
+	}
+	second, secondAxis, err := dmsValue(b)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case firstAxis == 'E' || secondAxis == 'N':
+		first, second = second, first
+		if firstAxis == secondAxis {
+			return nil, fmt.Errorf("invalid coordinates: %s", s)
+		}
+	case firstAxis != 0 && firstAxis == secondAxis:
+		return nil, fmt.Errorf("invalid coordinates: %s", s)
+	}
+
+	return &models.Location{Latitude: first, Longitude: second}, nil
+}
+
+// dmsValue converts a hemisphere, degrees, minutes and seconds to
+// decimal degrees. The axis is 'N' for a latitude, 'E' for a longitude
+// and 0 when unknown.
+func dmsValue(groups []string) (float64, byte, error) {
+	hemisphere, deg, min, sec := strings.ToUpper(groups[0]), groups[1], groups[2], groups[3]
+
+	value, _ := strconv.ParseFloat(deg, 64)
+	for i, part := range []string{min, sec} {
+		if part == "" {
+			continue
+		}
+		n, _ := strconv.ParseFloat(part, 64)
+		if n >= 60 {
+			return 0, 0, fmt.Errorf("invalid coordinate: %s must be below 60", []string{"minutes", "seconds"}[i])
+		}
+		value += n / []float64{60, 3600}[i]
+	}
+
+	switch hemisphere {
+	case "N":
+		return value, 'N', nil
+	case "S":
+		return -value, 'N', nil
+	case "E":
+		return value, 'E', nil
+	case "W":
+		return -value, 'E', nil
+	}
+	return value, 0, nil
+}
@@ -0,0 +1,128 @@
+package geocode
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParseCoordinates(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		lat, lon  float64
+		elevation float64
+		label     string
+		err       bool
+	}{
+		// Decimal degrees
+		{name: "Comma", input: "59.9139,10.7522", lat: 59.9139, lon: 10.7522},
+		{name: "Comma and space", input: "59.9139, 10.7522", lat: 59.9139, lon: 10.7522},
+		{name: "Space", input: "  59.9139 10.7522 ", lat: 59.9139, lon: 10.7522},
+		{name: "Southern and western", input: "-33.8688,-70.6693", lat: -33.8688, lon: -70.6693},
+		{name: "Greenwich", input: "51.4779,0", lat: 51.4779, lon: 0},
+		{name: "Null Island", input: "0,0", lat: 0, lon: 0},
+		{name: "Integers", input: "60,5", lat: 60, lon: 5},
+		{name: "Latitude out of range", input: "91,10", err: true},
+		{name: "Longitude out of range", input: "59,181", err: true},
+
+		// geo: URIs
+		{name: "Geo URI", input: "geo:59.9139,10.7522", lat: 59.9139, lon: 10.7522},
+		{name: "Geo URI with altitude", input: "geo:61.6364,8.3125,2469", lat: 61.6364, lon: 8.3125, elevation: 2469},
+		{name: "Geo URI with parameters", input: "geo:5.6037,-0.1870;crs=wgs84;u=35", lat: 5.6037, lon: -0.1870},
+		{name: "Geo URI upper case", input: "GEO:5.6037,-0.1870", lat: 5.6037, lon: -0.1870},
+		{name: "Geo URI with zoom", input: "geo:59.9139,10.7522?z=12", lat: 59.9139, lon: 10.7522},
+		{name: "Android geo URI", input: "geo:0,0?q=59.0469,10.0344(Stavern)", lat: 59.0469, lon: 10.0344, label: "Stavern"},
+		{name: "Geo URI other CRS", input: "geo:59.9,10.7;crs=utm", err: true},
+		{name: "Geo URI missing longitude", input: "geo:59.9", err: true},
+		{name: "Geo URI bad altitude", input: "geo:59.9,10.7,high", err: true},
+
+		// Degrees, minutes and seconds
+		{name: "DMS minutes", input: "59°54'N 10°45'E", lat: 59.9, lon: 10.75},
+		{name: "DMS seconds", input: `59°54'36"N, 10°45'18"E`, lat: 59.91, lon: 10.755},
+		{name: "DMS prime symbols", input: "59°54′36″N 10°45′18″E", lat: 59.91, lon: 10.755},
+		{name: "DMS decimal minutes", input: "59°54.6'N 10°45.3'E", lat: 59.91, lon: 10.755},
+		{name: "DMS decimal degrees", input: "59.91°N 10.755°E", lat: 59.91, lon: 10.755},
+		{name: "DMS hemisphere prefix", input: "N59°54' E10°45'", lat: 59.9, lon: 10.75},
+		{name: "DMS southern and western", input: "33°52'S 70°40'W", lat: -33.8667, lon: -70.6667},
+		{name: "DMS longitude first", input: "10°45'E 59°54'N", lat: 59.9, lon: 10.75},
+		{name: "DMS lower case", input: "59°54'n 10°45'e", lat: 59.9, lon: 10.75},
+		{name: "DMS without hemispheres", input: "59°54' 10°45'", lat: 59.9, lon: 10.75},
+		{name: "DMS two latitudes", input: "59°54'N 10°45'N", err: true},
+		{name: "DMS minutes out of range", input: "59°64'N 10°45'E", err: true},
+
+		// Plus codes
+		{name: "Plus code", input: "7FG49QCJ+2V", lat: 20.3700625, lon: 2.7821875},
+		{name: "Plus code lower case", input: "7fg49qcj+2v", lat: 20.3700625, lon: 2.7821875},
+		{name: "Plus code with grid", input: "7FG49QCJ+2VX", lat: 20.3701125, lon: 2.782234375},
+		{name: "Plus code padded", input: "7FG49Q00+", lat: 20.375, lon: 2.775},
+		{name: "Short plus code", input: "WQ7V+XV Oslo", lat: 59.9149375, lon: 10.7946875},
+		{name: "Short plus code with comma", input: "WQ7V+XV, Oslo", lat: 59.9149375, lon: 10.7946875},
+		{name: "Full plus code of short one", input: "9FFGWQ7V+XV", lat: 59.9149375, lon: 10.7946875},
+		{name: "Short plus code without locality", input: "WQ7V+XV", err: true},
+		{name: "Short plus code unknown locality", input: "WQ7V+XV Atlantis", err: true},
+		{name: "Plus code bad padding", input: "7FG40Q00+", err: true},
+
+		// Geohashes
+		{name: "Geohash", input: "u4pruydqqvj", lat: 57.64911, lon: 10.40744},
+		{name: "Geohash prefix", input: "geohash:u4pruydqqvj", lat: 57.64911, lon: 10.40744},
+		{name: "Geohash invalid character", input: "geohash:u4pa", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc, err := ParseCoordinates(tt.input)
+			if tt.err {
+				if err == nil {
+					t.Errorf("ParseCoordinates(%q) = %v; want error", tt.input, loc)
+				} else if errors.Is(err, ErrNotCoordinates) {
+					t.Errorf("ParseCoordinates(%q) error = %v; want a parse error", tt.input, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseCoordinates(%q) failed: %v", tt.input, err)
+			}
+
+			if math.Abs(loc.Latitude-tt.lat) > 1e-4 || math.Abs(loc.Longitude-tt.lon) > 1e-4 {
+				t.Errorf("ParseCoordinates(%q) = %.6f,%.6f; want %.6f,%.6f", tt.input, loc.Latitude, loc.Longitude, tt.lat, tt.lon)
+			}
+			if loc.Elevation != tt.elevation {
+				t.Errorf("ParseCoordinates(%q) elevation = %v; want %v", tt.input, loc.Elevation, tt.elevation)
+			}
+			if loc.Name != tt.label {
+				t.Errorf("ParseCoordinates(%q) name = %q; want %q", tt.input, loc.Name, tt.label)
+			}
+		})
+	}
+}
+
+func TestParseCoordinatesPlaceNames(t *testing.T) {
+	names := []string{"Oslo", "Bergen", "Tromsø", "Mo i Rana", "Frankfurt (Oder)", "New York, US", "bergen", "home", "Sankt Petersburg"}
+
+	for _, name := range names {
+		if loc, err := ParseCoordinates(name); !errors.Is(err, ErrNotCoordinates) {
+			t.Errorf("ParseCoordinates(%q) = %v, %v; want ErrNotCoordinates", name, loc, err)
+		}
+	}
+}
+
+func TestRecoverPlusCode(t *testing.T) {
+	// From the Open Location Code test data
+	lat, lon, err := recoverPlusCode("CJ+2VX", 51.3708675, -1.217765625)
+	if err != nil {
+		t.Fatalf("recoverPlusCode() failed: %v", err)
+	}
+	if math.Abs(lat-51.3701125) > 1e-6 || math.Abs(lon+1.217765625) > 1e-6 {
+		t.Errorf("recoverPlusCode() = %.7f,%.9f; want 51.3701125,-1.217765625", lat, lon)
+	}
+
+	// Across the antimeridian
+	lat, lon, err = recoverPlusCode("2222+22", 0.5, 179.9)
+	if err != nil {
+		t.Fatalf("recoverPlusCode() failed: %v", err)
+	}
+	if lon < 179 && lon > -179 {
+		t.Errorf("recoverPlusCode() = %f,%f; want a longitude near the antimeridian", lat, lon)
+	}
+}
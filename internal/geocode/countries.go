@@ -0,0 +1,65 @@
+package geocode
+
+// countries maps the ISO 3166-1 alpha-2 codes used in the gazetteer to
+// English country names
+var countries = map[string]string{
+	"AE": "United Arab Emirates",
+	"AR": "Argentina",
+	"AT": "Austria",
+	"AU": "Australia",
+	"BE": "Belgium",
+	"BR": "Brazil",
+	"CA": "Canada",
+	"CH": "Switzerland",
+	"CL": "Chile",
+	"CN": "China",
+	"CZ": "Czechia",
+	"DE": "Germany",
+	"DK": "Denmark",
+	"EE": "Estonia",
+	"EG": "Egypt",
+	"ES": "Spain",
+	"FI": "Finland",
+	"FO": "Faroe Islands",
+	"FR": "France",
+	"GB": "United Kingdom",
+	"GL": "Greenland",
+	"GR": "Greece",
+	"HK": "Hong Kong",
+	"HU": "Hungary",
+	"IE": "Ireland",
+	"IL": "Israel",
+	"IN": "India",
+	"IS": "Iceland",
+	"IT": "Italy",
+	"JP": "Japan",
+	"KE": "Kenya",
+	"KR": "South Korea",
+	"LT": "Lithuania",
+	"LV": "Latvia",
+	"MA": "Morocco",
+	"MX": "Mexico",
+	"NG": "Nigeria",
+	"NL": "Netherlands",
+	"NO": "Norway",
+	"NZ": "New Zealand",
+	"PE": "Peru",
+	"PL": "Poland",
+	"PT": "Portugal",
+	"RU": "Russia",
+	"SE": "Sweden",
+	"SG": "Singapore",
+	"TH": "Thailand",
+	"TR": "Türkiye",
+	"US": "United States",
+	"ZA": "South Africa",
+}
+
+// CountryName returns the English name of a country code, or the code
+// itself when it is unknown
+func CountryName(code string) string {
+	if name, ok := countries[code]; ok {
+		return name
+	}
+	return code
+}
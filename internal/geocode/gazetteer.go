@@ -0,0 +1,218 @@
+package geocode
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	_ "embed"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:generate go run gen.go -dump cities5000 -min-population 5000 -o cities.tsv
+
+//go:embed cities.tsv
+var cities []byte
+
+// maxResults limits the number of places returned by Search
+const maxResults = 10
+
+// entry is a gazetteer place with its normalized names
+type entry struct {
+	place Place
+	names []string
+}
+
+// Gazetteer is an offline geocoder backed by a list of places
+type Gazetteer struct {
+	entries []entry
+}
+
+// NewGazetteer returns a gazetteer with the places embedded in sky
+func NewGazetteer() (*Gazetteer, error) {
+	return ReadGazetteer(bytes.NewReader(cities))
+}
+
+// ReadGazetteer reads a gazetteer in the tab-separated format of
+// cities.tsv
+func ReadGazetteer(r io.Reader) (*Gazetteer, error) {
+	g := &Gazetteer{}
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		e, err := parseEntry(text)
+		if err != nil {
+			return nil, fmt.Errorf("gazetteer line %d: %w", line, err)
+		}
+		g.entries = append(g.entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read gazetteer: %w", err)
+	}
+
+	return g, nil
+}
+
+// parseEntry parses a single gazetteer line
+func parseEntry(line string) (entry, error) {
+	fields := strings.Split(line, "\t")
+	if len(fields) != 10 {
+		return entry{}, fmt.Errorf("expected 10 fields, got %d", len(fields))
+	}
+
+	lat, err := strconv.ParseFloat(fields[3], 64)
+	if err != nil {
+		return entry{}, fmt.Errorf("invalid latitude: %s", fields[3])
+	}
+	lon, err := strconv.ParseFloat(fields[4], 64)
+	if err != nil {
+		return entry{}, fmt.Errorf("invalid longitude: %s", fields[4])
+	}
+	population, err := strconv.Atoi(fields[7])
+	if err != nil {
+		return entry{}, fmt.Errorf("invalid population: %s", fields[7])
+	}
+
+	var elevation float64
+	if fields[8] != "" {
+		if elevation, err = strconv.ParseFloat(fields[8], 64); err != nil {
+			return entry{}, fmt.Errorf("invalid elevation: %s", fields[8])
+		}
+	}
+
+	e := entry{
+		place: Place{
+			Name:       fields[0],
+			Latitude:   lat,
+			Longitude:  lon,
+			Country:    fields[5],
+			Region:     fields[6],
+			Population: population,
+			Elevation:  elevation,
+			Timezone:   fields[9],
+		},
+	}
+
+	seen := make(map[string]bool)
+	for _, name := range append([]string{fields[0], fields[1]}, strings.Split(fields[2], ",")...) {
+		if name = Normalize(name); name != "" && !seen[name] {
+			seen[name] = true
+			e.names = append(e.names, name)
+		}
+	}
+
+	return e, nil
+}
+
+// Search returns the places matching query, best match first and the
+// most populous first among equally good matches
+func (g *Gazetteer) Search(ctx context.Context, query string) ([]Place, error) {
+	name, qualifier, _ := strings.Cut(query, ",")
+	name = Normalize(name)
+	qualifier = Normalize(qualifier)
+	if name == "" {
+		return nil, fmt.Errorf("empty place name")
+	}
+
+	var places []Place
+	for _, e := range g.entries {
+		if qualifier != "" && !e.inRegion(qualifier) {
+			continue
+		}
+
+		if match := e.match(name); match != 0 {
+			p := e.place
+			p.Match = match
+			places = append(places, p)
+		}
+	}
+
+	sort.SliceStable(places, func(i, j int) bool {
+		if places[i].Match != places[j].Match {
+			return places[i].Match > places[j].Match
+		}
+		return places[i].Population > places[j].Population
+	})
+
+	if len(places) > maxResults {
+		places = places[:maxResults]
+	}
+	return places, nil
+}
+
+// match returns how well name matches the entry, or 0
+func (e entry) match(name string) Quality {
+	best := Quality(0)
+	for _, n := range e.names {
+		switch {
+		case n == name:
+			return MatchExact
+		case strings.HasPrefix(n, name+" "):
+			best = MatchPrefix
+		case best == 0 && distance(n, name) <= maxDistance(name):
+			best = MatchFuzzy
+		}
+	}
+	return best
+}
+
+// inRegion reports whether the entry lies in the region or country named
+// by qualifier
+func (e entry) inRegion(qualifier string) bool {
+	for _, name := range []string{e.place.Country, CountryName(e.place.Country), e.place.Region} {
+		if name = Normalize(name); name != "" && strings.HasPrefix(name, qualifier) {
+			return true
+		}
+	}
+	return false
+}
+
+// maxDistance returns the number of typos tolerated in a query
+func maxDistance(query string) int {
+	switch n := len([]rune(query)); {
+	case n <= 3:
+		return 0
+	case n <= 6:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// distance returns the Damerau-Levenshtein (optimal string alignment)
+// distance between a and b
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
@@ -0,0 +1,225 @@
+//go:build ignore
+
+// gen converts a GeoNames cities dump into the gazetteer format of
+// cities.tsv. Without arguments it downloads the dump and the region names
+// from https://download.geonames.org/export/dump/ (about 10 MB for
+// cities5000), which is what go generate runs:
+//
+//	go run gen.go -dump cities5000 -min-population 5000 -o cities.tsv
+//
+// Files downloaded before can be given instead, unzipped:
+//
+//	go run gen.go -o cities.tsv cities5000.txt admin1CodesASCII.txt
+package main
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// maxAlternates limits the alternate names kept per place
+const maxAlternates = 4
+
+// regionsFile lists the names of the first-level regions of each country
+const regionsFile = "admin1CodesASCII.txt"
+
+func main() {
+	minPopulation := flag.Int("min-population", 5000, "Skip places with fewer inhabitants")
+	dump := flag.String("dump", "cities5000", "GeoNames dump to download when no files are given")
+	url := flag.String("url", "https://download.geonames.org/export/dump/", "Where to download the dump from")
+	output := flag.String("o", "", "Output file (default: stdout)")
+	flag.Parse()
+
+	var name string
+	var places, regionNames []byte
+	var err error
+	switch flag.NArg() {
+	case 0:
+		name = *dump
+		log.Printf("Downloading %s.zip and %s from %s", *dump, regionsFile, *url)
+		if places, err = downloadZipped(*url, *dump); err != nil {
+			log.Fatal(err)
+		}
+		if regionNames, err = download(*url + regionsFile); err != nil {
+			log.Fatal(err)
+		}
+	case 2:
+		name = strings.TrimSuffix(filepath.Base(flag.Arg(0)), ".txt")
+		if places, err = os.ReadFile(flag.Arg(0)); err != nil {
+			log.Fatal(err)
+		}
+		if regionNames, err = os.ReadFile(flag.Arg(1)); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		log.Fatal("usage: go run gen.go [-dump citiesNNNN] [-min-population N] [-o file] [citiesNNNN.txt admin1CodesASCII.txt]")
+	}
+
+	regions, err := readRegions(bytes.NewReader(regionNames))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	out := io.Writer(os.Stdout)
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	if err := convert(bytes.NewReader(places), name, regions, *minPopulation, out); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// download returns the contents of a URL
+func download(url string) ([]byte, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// downloadZipped downloads the dump name.zip and returns name.txt from it
+func downloadZipped(url, name string) ([]byte, error) {
+	data, err := download(url + name + ".zip")
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%s.zip: %w", name, err)
+	}
+	f, err := archive.Open(name + ".txt")
+	if err != nil {
+		return nil, fmt.Errorf("%s.zip: %w", name, err)
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// readRegions reads admin1 codes such as "NO.46" and their names
+func readRegions(r io.Reader) (map[string]string, error) {
+	regions := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) >= 2 {
+			regions[fields[0]] = fields[1]
+		}
+	}
+	return regions, scanner.Err()
+}
+
+// convert writes the places in the GeoNames cities dump name with at
+// least minPopulation inhabitants, most populous first
+func convert(r io.Reader, name string, regions map[string]string, minPopulation int, out io.Writer) error {
+	type row struct {
+		population int
+		line       string
+	}
+	var rows []row
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) < 19 {
+			continue
+		}
+
+		population, _ := strconv.Atoi(fields[14])
+		if population < minPopulation {
+			continue
+		}
+
+		// Prefer the measured elevation, then the digital elevation model
+		elevation := fields[15]
+		if elevation == "" && fields[16] != "-9999" {
+			elevation = fields[16]
+		}
+
+		line := strings.Join([]string{
+			fields[1],
+			fields[2],
+			strings.Join(alternates(fields[1], fields[2], fields[3]), ","),
+			fields[4],
+			fields[5],
+			fields[8],
+			regions[fields[8]+"."+fields[10]],
+			fields[14],
+			elevation,
+			fields[17],
+		}, "\t")
+		rows = append(rows, row{population, line})
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].population > rows[j].population
+	})
+
+	w := bufio.NewWriter(out)
+	fmt.Fprintf(w, "# Places from the GeoNames %s dump with at least %d inhabitants\n", name, minPopulation)
+	fmt.Fprintln(w, "# (https://download.geonames.org/export/dump/, CC BY 4.0), generated by gen.go.")
+	fmt.Fprintln(w, "# Columns: name, ascii name, alternate names, latitude, longitude, country,")
+	fmt.Fprintln(w, "# region, population, elevation (m), timezone")
+	for _, r := range rows {
+		fmt.Fprintln(w, r.line)
+	}
+	return w.Flush()
+}
+
+// alternates returns a few alternate names written in the Latin script,
+// which is what users type on the command line
+func alternates(name, ascii, list string) []string {
+	seen := map[string]bool{strings.ToLower(name): true, strings.ToLower(ascii): true}
+
+	var names []string
+	for _, alt := range strings.Split(list, ",") {
+		key := strings.ToLower(alt)
+		if alt == "" || seen[key] || !isLatin(alt) {
+			continue
+		}
+		seen[key] = true
+		names = append(names, alt)
+		if len(names) == maxAlternates {
+			break
+		}
+	}
+	return names
+}
+
+// isLatin reports whether every letter in s is in the Latin script
+func isLatin(s string) bool {
+	for _, r := range s {
+		if unicode.IsLetter(r) && !unicode.Is(unicode.Latin, r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,131 @@
+// Package geocode turns place names into coordinates
+package geocode
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Quality describes how well a place name matched a query
+type Quality int
+
+const (
+	// MatchFuzzy means the name is within a small edit distance of the query
+	MatchFuzzy Quality = iota + 1
+
+	// MatchPrefix means the name starts with the query
+	MatchPrefix
+
+	// MatchExact means the name or one of its alternate names equals the query
+	MatchExact
+)
+
+// Place is a named populated place
+type Place struct {
+	Name       string
+	Region     string
+	Country    string // ISO 3166-1 alpha-2 code
+	Latitude   float64
+	Longitude  float64
+	Elevation  float64 // meters above sea level
+	Timezone   string
+	Population int
+
+	// Match is how well the place matched the query it was found for
+	Match Quality
+}
+
+// String returns the place name with its region and country
+func (p Place) String() string {
+	parts := []string{p.Name}
+	if p.Region != "" && p.Region != p.Name {
+		parts = append(parts, p.Region)
+	}
+	if p.Country != "" {
+		parts = append(parts, CountryName(p.Country))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Location converts the place to a location that can be saved in the config
+func (p Place) Location() *models.Location {
+	name := p.Name
+	if p.Country != "" {
+		name = fmt.Sprintf("%s, %s", p.Name, CountryName(p.Country))
+	}
+
+	return &models.Location{
+		Name:      name,
+		Latitude:  p.Latitude,
+		Longitude: p.Longitude,
+		Elevation: p.Elevation,
+		Timezone:  p.Timezone,
+	}
+}
+
+// Geocoder looks up places by name
+type Geocoder interface {
+	// Search returns the places matching query, best match first. A query
+	// may narrow the search with a region or country after a comma, as
+	// in "Springfield, Illinois" or "Bergen, NL".
+	Search(ctx context.Context, query string) ([]Place, error)
+}
+
+// Ambiguous returns the places the user has to choose between, or nil
+// when the first place is clearly the one meant. A place is clear when no
+// other place matched as well, or when it is at least ten times as
+// populous as the runner-up.
+func Ambiguous(places []Place) []Place {
+	if len(places) < 2 {
+		return nil
+	}
+
+	best := places[0]
+	var candidates []Place
+	for _, p := range places {
+		if p.Match == best.Match {
+			candidates = append(candidates, p)
+		}
+	}
+
+	if len(candidates) < 2 || best.Population >= 10*candidates[1].Population {
+		return nil
+	}
+	return candidates
+}
+
+// folded maps letters that do not decompose into a base letter and a
+// diacritic to their usual ASCII spelling
+var folded = strings.NewReplacer(
+	"ø", "o", "æ", "ae", "œ", "oe", "ß", "ss",
+	"ł", "l", "đ", "d", "ð", "d", "þ", "th", "ı", "i",
+)
+
+// Normalize folds s for comparison: lower case, without diacritics and
+// with punctuation collapsed to single spaces, so "Tromsø" and "tromso"
+// compare equal
+func Normalize(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	stripped, _, err := transform.String(t, strings.ToLower(s))
+	if err != nil {
+		stripped = strings.ToLower(s)
+	}
+	stripped = folded.Replace(stripped)
+
+	fields := strings.FieldsFunc(stripped, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(fields, " ")
+}
+
+// Slug returns a config-friendly key for a place name, such as "mo-i-rana"
+func Slug(name string) string {
+	return strings.ReplaceAll(Normalize(name), " ", "-")
+}
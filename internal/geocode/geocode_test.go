@@ -0,0 +1,157 @@
+package geocode
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Tromsø", "tromso"},
+		{"ÅLESUND", "alesund"},
+		{"Málaga", "malaga"},
+		{"Frankfurt (Oder)", "frankfurt oder"},
+		{"  Mo i  Rana ", "mo i rana"},
+		{"Łódź", "lodz"},
+		{"Chamonix-Mont-Blanc", "chamonix mont blanc"},
+	}
+
+	for _, tt := range tests {
+		if got := Normalize(tt.input); got != tt.expected {
+			t.Errorf("Normalize(%q) = %q; want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"bergen", "bergen", 0},
+		{"bergen", "bergn", 1},
+		{"tromso", "tromos", 1},
+		{"oslo", "olso", 1},
+		{"kristiansand", "kristiansund", 1},
+		{"", "abc", 3},
+	}
+
+	for _, tt := range tests {
+		if got := distance(tt.a, tt.b); got != tt.expected {
+			t.Errorf("distance(%q, %q) = %d; want %d", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
+
+func TestGazetteerSearch(t *testing.T) {
+	g, err := NewGazetteer()
+	if err != nil {
+		t.Fatalf("NewGazetteer() failed: %v", err)
+	}
+
+	tests := []struct {
+		query     string
+		expected  string
+		match     Quality
+		ambiguous bool
+	}{
+		{query: "Tromsø", expected: "Tromsø, Troms, Norway", match: MatchExact},
+		{query: "tromso", expected: "Tromsø, Troms, Norway", match: MatchExact},
+		{query: "Tromsoe", expected: "Tromsø, Troms, Norway", match: MatchExact},
+		{query: "Trondhiem", expected: "Trondheim, Trøndelag, Norway", match: MatchFuzzy},
+		{query: "Munich", expected: "München, Bavaria, Germany", match: MatchExact},
+		{query: "Bergen", expected: "Bergen, Vestland, Norway", match: MatchExact},
+		{query: "Bergen, NL", expected: "Bergen, North Holland, Netherlands", match: MatchExact},
+		{query: "bergen, netherlands", expected: "Bergen, North Holland, Netherlands", match: MatchExact},
+		{query: "Frankfurt", expected: "Frankfurt am Main, Hesse, Germany", match: MatchExact},
+		{query: "Springfield", expected: "Springfield, Missouri, United States", match: MatchExact, ambiguous: true},
+		{query: "Springfield, Illinois", expected: "Springfield, Illinois, United States", match: MatchExact},
+		{query: "Mo", expected: "Mo i Rana, Nordland, Norway", match: MatchExact},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			places, err := g.Search(context.Background(), tt.query)
+			if err != nil {
+				t.Fatalf("Search() failed: %v", err)
+			}
+			if len(places) == 0 {
+				t.Fatal("Search() found nothing")
+			}
+
+			if got := places[0].String(); got != tt.expected {
+				t.Errorf("Search()[0] = %s; want %s", got, tt.expected)
+			}
+			if places[0].Match != tt.match {
+				t.Errorf("Search()[0].Match = %d; want %d", places[0].Match, tt.match)
+			}
+			if got := Ambiguous(places) != nil; got != tt.ambiguous {
+				t.Errorf("Ambiguous() = %v; want %v", got, tt.ambiguous)
+			}
+		})
+	}
+
+	places, err := g.Search(context.Background(), "Qwxyzzy")
+	if err != nil || len(places) != 0 {
+		t.Errorf("Search(unknown) = %v, %v; want no places", places, err)
+	}
+}
+
+func TestGazetteerTowns(t *testing.T) {
+	// The sample in the repository only has a few places; release builds
+	// and CI generate the full list first
+	if bytes.Contains(cities, []byte("hand-picked sample")) {
+		t.Skip("cities.tsv is the hand-picked sample; run go generate ./internal/geocode")
+	}
+
+	g, err := NewGazetteer()
+	if err != nil {
+		t.Fatalf("NewGazetteer() failed: %v", err)
+	}
+	for _, town := range []string{"Orkanger", "Brumunddal"} {
+		places, err := g.Search(context.Background(), town+", NO")
+		if err != nil || len(places) == 0 {
+			t.Errorf("Search(%s) = %v, %v; want the town", town, places, err)
+		}
+	}
+}
+
+func TestPlaceLocation(t *testing.T) {
+	g, err := ReadGazetteer(strings.NewReader("# test\nTromsø\tTromso\t\t69.6496\t18.956\tNO\tTroms\t77544\t10\tEurope/Oslo\n"))
+	if err != nil {
+		t.Fatalf("ReadGazetteer() failed: %v", err)
+	}
+
+	places, err := g.Search(context.Background(), "tromso")
+	if err != nil || len(places) != 1 {
+		t.Fatalf("Search() = %v, %v; want one place", places, err)
+	}
+
+	loc := places[0].Location()
+	if loc.Name != "Tromsø, Norway" || loc.Latitude != 69.6496 || loc.Longitude != 18.956 ||
+		loc.Elevation != 10 || loc.Timezone != "Europe/Oslo" {
+		t.Errorf("Location() = %+v", loc)
+	}
+	if err := loc.Validate(); err != nil {
+		t.Errorf("Location() is not valid: %v", err)
+	}
+}
+
+func TestReadGazetteerErrors(t *testing.T) {
+	inputs := []string{
+		"Oslo\tOslo\n",
+		"Oslo\tOslo\t\tnorth\t10.7\tNO\tOslo\t1\t\tEurope/Oslo\n",
+		"Oslo\tOslo\t\t59.9\t10.7\tNO\tOslo\tmany\t\tEurope/Oslo\n",
+	}
+
+	for _, input := range inputs {
+		if _, err := ReadGazetteer(strings.NewReader(input)); err == nil {
+			t.Errorf("ReadGazetteer(%q) expected error", input)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package geocode
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+// geohashAlphabet is the base 32 alphabet used by geohashes
+const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// decodeGeohash returns the centre of the cell described by a geohash
+func decodeGeohash(hash string) (*models.Location, error) {
+	hash = strings.ToLower(hash)
+	if hash == "" {
+		return nil, fmt.Errorf("empty geohash")
+	}
+
+	latLow, latHigh := -90.0, 90.0
+	lonLow, lonHigh := -180.0, 180.0
+	even := true
+	for _, r := range hash {
+		value := strings.IndexRune(geohashAlphabet, r)
+		if value < 0 {
+			return nil, fmt.Errorf("invalid geohash character %q: %s", r, hash)
+		}
+
+		// Bits alternate between longitude and latitude, longitude first
+		for bit := 4; bit >= 0; bit-- {
+			set := value&(1<<bit) != 0
+			if even {
+				mid := (lonLow + lonHigh) / 2
+				if set {
+					lonLow = mid
+				} else {
+					lonHigh = mid
+				}
+			} else {
+				mid := (latLow + latHigh) / 2
+				if set {
+					latLow = mid
+				} else {
+					latHigh = mid
+				}
+			}
+			even = !even
+		}
+	}
+
+	return &models.Location{
+		Latitude:  (latLow + latHigh) / 2,
+		Longitude: (lonLow + lonHigh) / 2,
+	}, nil
+}
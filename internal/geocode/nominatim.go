@@ -0,0 +1,116 @@
+package geocode
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const userAgent = "sky-cli/1.0 github.com/kristofferrisa/sky-cli"
+
+// Nominatim is a reverse geocoder for servers speaking the Nominatim API,
+// such as nominatim.openstreetmap.org or a self-hosted instance
+type Nominatim struct {
+	baseURL    string
+	httpClient *http.Client
+	userAgent  string
+}
+
+// NewNominatim creates a client for the Nominatim server at baseURL
+func NewNominatim(baseURL string) *Nominatim {
+	return &Nominatim{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		userAgent: userAgent,
+	}
+}
+
+// nominatimResult is the subset of a /reverse response used by sky
+type nominatimResult struct {
+	Error   string `json:"error"`
+	Lat     string `json:"lat"`
+	Lon     string `json:"lon"`
+	Name    string `json:"name"`
+	Address struct {
+		City        string `json:"city"`
+		Town        string `json:"town"`
+		Village     string `json:"village"`
+		Hamlet      string `json:"hamlet"`
+		State       string `json:"state"`
+		County      string `json:"county"`
+		CountryCode string `json:"country_code"`
+	} `json:"address"`
+}
+
+// Reverse returns the populated place nearest to lat, lon
+func (n *Nominatim) Reverse(ctx context.Context, lat, lon float64) (*Nearby, error) {
+	query := url.Values{}
+	query.Set("format", "jsonv2")
+	query.Set("lat", strconv.FormatFloat(lat, 'f', 5, 64))
+	query.Set("lon", strconv.FormatFloat(lon, 'f', 5, 64))
+	query.Set("zoom", "10") // city level
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, n.baseURL+"/reverse?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", n.userAgent)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reverse geocode: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("geocoding server returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result nominatimResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if result.Error != "" {
+		return nil, ErrNoPlace
+	}
+
+	place := Place{
+		Name:    firstNonEmpty(result.Address.City, result.Address.Town, result.Address.Village, result.Address.Hamlet, result.Name),
+		Region:  firstNonEmpty(result.Address.State, result.Address.County),
+		Country: strings.ToUpper(result.Address.CountryCode),
+	}
+	if place.Name == "" {
+		return nil, ErrNoPlace
+	}
+
+	// Measure from the place found, not from the query
+	if place.Latitude, err = strconv.ParseFloat(result.Lat, 64); err != nil {
+		return nil, fmt.Errorf("invalid latitude in response: %s", result.Lat)
+	}
+	if place.Longitude, err = strconv.ParseFloat(result.Lon, 64); err != nil {
+		return nil, fmt.Errorf("invalid longitude in response: %s", result.Lon)
+	}
+
+	return newNearby(place, lat, lon), nil
+}
+
+// firstNonEmpty returns the first argument that is not empty
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
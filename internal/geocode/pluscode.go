@@ -0,0 +1,194 @@
+package geocode
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+// Open Location Code (plus code) constants, see
+// https://github.com/google/open-location-code/blob/main/docs/specification.md
+const (
+	plusAlphabet     = "23456789CFGHJMPQRVWX"
+	plusSeparator    = '+'
+	plusSeparatorPos = 8
+	plusPadding      = '0'
+	plusPairLength   = 10
+	plusGridRows     = 5
+	plusGridColumns  = 4
+)
+
+// isPlusCode reports whether s starts with something shaped like a plus
+// code, optionally followed by a locality
+func isPlusCode(s string) bool {
+	code, _, _ := strings.Cut(s, " ")
+	code = strings.TrimSuffix(code, ",")
+	sep := strings.IndexByte(code, plusSeparator)
+	if sep < 2 || sep > plusSeparatorPos || sep%2 != 0 {
+		return false
+	}
+	for i, r := range strings.ToUpper(code) {
+		if i == sep || r == plusPadding && i < sep {
+			continue
+		}
+		if !strings.ContainsRune(plusAlphabet, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// parsePlusCode decodes a full plus code such as "9FFGWQJ3+H3", or a
+// short one followed by a locality, such as "WQJ3+H3 Oslo". The locality
+// is looked up in the embedded gazetteer.
+func parsePlusCode(s string) (*models.Location, error) {
+	code, locality, _ := strings.Cut(s, " ")
+	code = strings.ToUpper(strings.TrimSuffix(code, ","))
+	locality = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(locality), ","))
+
+	sep := strings.IndexByte(code, plusSeparator)
+	if sep == plusSeparatorPos {
+		if locality != "" {
+			return nil, fmt.Errorf("invalid plus code: a full code cannot have a locality: %s", s)
+		}
+		lat, lon, err := decodePlusCode(code)
+		if err != nil {
+			return nil, err
+		}
+		return &models.Location{Latitude: lat, Longitude: lon}, nil
+	}
+
+	if locality == "" {
+		return nil, fmt.Errorf("short plus code %s needs a locality, for example '%s Oslo'", code, code)
+	}
+
+	gazetteer, err := NewGazetteer()
+	if err != nil {
+		return nil, err
+	}
+	places, err := gazetteer.Search(context.Background(), locality)
+	if err != nil {
+		return nil, err
+	}
+	if len(places) == 0 {
+		return nil, fmt.Errorf("locality '%s' of plus code %s not found", locality, code)
+	}
+
+	lat, lon, err := recoverPlusCode(code, places[0].Latitude, places[0].Longitude)
+	if err != nil {
+		return nil, err
+	}
+	return &models.Location{Latitude: lat, Longitude: lon}, nil
+}
+
+// decodePlusCode returns the centre of the area described by a full plus
+// code
+func decodePlusCode(code string) (float64, float64, error) {
+	if strings.IndexByte(code, plusSeparator) != plusSeparatorPos {
+		return 0, 0, fmt.Errorf("invalid plus code: %s", code)
+	}
+
+	digits := strings.Replace(code, string(plusSeparator), "", 1)
+	if i := strings.IndexByte(digits, plusPadding); i >= 0 {
+		if i%2 != 0 || strings.Trim(digits[i:], string(plusPadding)) != "" || !strings.HasSuffix(code, "+") {
+			return 0, 0, fmt.Errorf("invalid plus code padding: %s", code)
+		}
+		digits = digits[:i]
+	}
+	if len(digits) < 2 || len(digits) == plusSeparatorPos+1 {
+		return 0, 0, fmt.Errorf("invalid plus code: %s", code)
+	}
+
+	lat, lon := -90.0, -180.0
+	latSize, lonSize := 20.0*20, 20.0*20
+	for i, r := range digits {
+		value := strings.IndexRune(plusAlphabet, r)
+		if value < 0 {
+			return 0, 0, fmt.Errorf("invalid plus code character %q: %s", r, code)
+		}
+
+		if i < plusPairLength {
+			if i%2 == 0 {
+				latSize /= 20
+				lonSize /= 20
+				lat += float64(value) * latSize
+			} else {
+				lon += float64(value) * lonSize
+			}
+			continue
+		}
+
+		latSize /= plusGridRows
+		lonSize /= plusGridColumns
+		lat += float64(value/plusGridColumns) * latSize
+		lon += float64(value%plusGridColumns) * lonSize
+	}
+
+	if lat >= 90 || lon >= 180 {
+		return 0, 0, fmt.Errorf("invalid plus code: %s is outside the globe", code)
+	}
+	return lat + latSize/2, lon + lonSize/2, nil
+}
+
+// recoverPlusCode decodes a short plus code relative to a reference
+// point, picking the matching area nearest to the reference
+func recoverPlusCode(code string, refLat, refLon float64) (float64, float64, error) {
+	sep := strings.IndexByte(code, plusSeparator)
+	padding := plusSeparatorPos - sep
+	resolution := math.Pow(20, 2-float64(padding/2))
+
+	prefix := encodePlusPairs(refLat, refLon)[:padding]
+	lat, lon, err := decodePlusCode(prefix + code)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	half := resolution / 2
+	switch {
+	case refLat+half < lat && lat-resolution >= -90:
+		lat -= resolution
+	case refLat-half > lat && lat+resolution <= 90:
+		lat += resolution
+	}
+	switch {
+	case refLon+half < lon:
+		lon -= resolution
+	case refLon-half > lon:
+		lon += resolution
+	}
+	return lat, normalizeLongitude(lon), nil
+}
+
+// encodePlusPairs returns the first eight digits of the plus code of a
+// point, which is all recoverPlusCode needs
+func encodePlusPairs(lat, lon float64) string {
+	lat = math.Min(math.Max(lat, -90), 90-1e-9) + 90
+	lon = normalizeLongitude(lon) + 180
+
+	var b strings.Builder
+	size := 20.0
+	for i := 0; i < plusSeparatorPos/2; i++ {
+		latDigit := int(lat / size)
+		lonDigit := int(lon / size)
+		b.WriteByte(plusAlphabet[latDigit])
+		b.WriteByte(plusAlphabet[lonDigit])
+		lat -= float64(latDigit) * size
+		lon -= float64(lonDigit) * size
+		size /= 20
+	}
+	return b.String()
+}
+
+// normalizeLongitude wraps a longitude into [-180, 180)
+func normalizeLongitude(lon float64) float64 {
+	for lon < -180 {
+		lon += 360
+	}
+	for lon >= 180 {
+		lon -= 360
+	}
+	return lon
+}
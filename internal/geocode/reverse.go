@@ -0,0 +1,77 @@
+package geocode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+// ErrNoPlace is returned when no place is close enough to a coordinate
+var ErrNoPlace = errors.New("no place nearby")
+
+// ErrNotFound and ErrAmbiguous are wrapped by lookups of names that match
+// no place, or several places without saying which
+var (
+	ErrNotFound  = errors.New("location not found")
+	ErrAmbiguous = errors.New("ambiguous location")
+)
+
+// maxNearbyDistance is how far away, in kilometers, a place may be and
+// still be used to describe a coordinate
+const maxNearbyDistance = 100.0
+
+// ReverseGeocoder finds the place nearest to a coordinate
+type ReverseGeocoder interface {
+	Reverse(ctx context.Context, lat, lon float64) (*Nearby, error)
+}
+
+// Nearby is the place nearest to a coordinate
+type Nearby struct {
+	Place Place `json:"place"`
+
+	// Distance is how far the coordinate is from the place, in kilometers
+	Distance float64 `json:"distance"`
+
+	// Bearing is the direction from the place to the coordinate, in
+	// degrees clockwise from north
+	Bearing float64 `json:"bearing"`
+}
+
+// newNearby describes the coordinate lat, lon relative to place
+func newNearby(place Place, lat, lon float64) *Nearby {
+	from := &models.Location{Latitude: place.Latitude, Longitude: place.Longitude}
+	to := &models.Location{Latitude: lat, Longitude: lon}
+
+	return &Nearby{
+		Place:    place,
+		Distance: from.DistanceTo(to),
+		Bearing:  from.BearingTo(to),
+	}
+}
+
+// Label describes the coordinate relative to the place, such as
+// "4.2 km NE of Larvik", or just the place name within a kilometer
+func (n *Nearby) Label() string {
+	if n.Distance < 1 {
+		return n.Place.Name
+	}
+	return fmt.Sprintf("%.1f km %s of %s", n.Distance, models.CompassPoint(n.Bearing), n.Place.Name)
+}
+
+// Reverse returns the gazetteer place nearest to lat, lon
+func (g *Gazetteer) Reverse(ctx context.Context, lat, lon float64) (*Nearby, error) {
+	var nearest *Nearby
+	for _, e := range g.entries {
+		n := newNearby(e.place, lat, lon)
+		if nearest == nil || n.Distance < nearest.Distance {
+			nearest = n
+		}
+	}
+
+	if nearest == nil || nearest.Distance > maxNearbyDistance {
+		return nil, ErrNoPlace
+	}
+	return nearest, nil
+}
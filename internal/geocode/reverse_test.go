@@ -0,0 +1,125 @@
+package geocode
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/cache"
+)
+
+func TestGazetteerReverse(t *testing.T) {
+	g, err := NewGazetteer()
+	if err != nil {
+		t.Fatalf("NewGazetteer() failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		lat, lon float64
+		expected string
+		err      error
+	}{
+		{name: "Northeast of Larvik", lat: 59.0800, lon: 10.0800, expected: "4.2 km NE of Larvik"},
+		{name: "In Tromsø", lat: 69.6496, lon: 18.9560, expected: "Tromsø"},
+		{name: "West of Accra", lat: 5.6, lon: -0.5, err: ErrNoPlace},
+		{name: "North Atlantic", lat: 50, lon: -30, err: ErrNoPlace},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nearby, err := g.Reverse(context.Background(), tt.lat, tt.lon)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("Reverse() error = %v; want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Reverse() failed: %v", err)
+			}
+			if got := nearby.Label(); got != tt.expected {
+				t.Errorf("Label() = %s; want %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNominatimReverse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/reverse" || r.URL.Query().Get("format") != "jsonv2" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		if r.Header.Get("User-Agent") == "" {
+			t.Error("request has no User-Agent")
+		}
+
+		if r.URL.Query().Get("lat") == "0.00000" {
+			w.Write([]byte(`{"error":"Unable to geocode"}`))
+			return
+		}
+		w.Write([]byte(`{"lat":"59.0533","lon":"10.0287","name":"Larvik",
+			"address":{"town":"Larvik","county":"Vestfold","country_code":"no"}}`))
+	}))
+	defer server.Close()
+
+	n := NewNominatim(server.URL + "/")
+
+	nearby, err := n.Reverse(context.Background(), 59.08, 10.08)
+	if err != nil {
+		t.Fatalf("Reverse() failed: %v", err)
+	}
+	if got := nearby.Label(); got != "4.2 km NE of Larvik" {
+		t.Errorf("Label() = %s; want 4.2 km NE of Larvik", got)
+	}
+	if nearby.Place.Region != "Vestfold" || nearby.Place.Country != "NO" {
+		t.Errorf("Place = %+v", nearby.Place)
+	}
+
+	if _, err := n.Reverse(context.Background(), 0, 0); !errors.Is(err, ErrNoPlace) {
+		t.Errorf("Reverse(0, 0) error = %v; want %v", err, ErrNoPlace)
+	}
+}
+
+// countingGeocoder counts lookups and always returns the same place
+type countingGeocoder struct {
+	calls int
+}
+
+func (c *countingGeocoder) Reverse(ctx context.Context, lat, lon float64) (*Nearby, error) {
+	c.calls++
+	return newNearby(Place{Name: "Larvik", Latitude: 59.0533, Longitude: 10.0287}, lat, lon), nil
+}
+
+func TestCachedReverseGeocoder(t *testing.T) {
+	fileCache, err := cache.NewFileCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileCache() failed: %v", err)
+	}
+
+	counting := &countingGeocoder{}
+	cached := NewCachedReverseGeocoder(counting, fileCache, time.Hour, "test")
+
+	for i := 0; i < 3; i++ {
+		nearby, err := cached.Reverse(context.Background(), 59.08, 10.08)
+		if err != nil {
+			t.Fatalf("Reverse() failed: %v", err)
+		}
+		if got := nearby.Label(); got != "4.2 km NE of Larvik" {
+			t.Errorf("Label() = %s; want 4.2 km NE of Larvik", got)
+		}
+	}
+	if counting.calls != 1 {
+		t.Errorf("geocoder called %d times; want 1", counting.calls)
+	}
+
+	// Other coordinates and namespaces are looked up separately
+	cached.Reverse(context.Background(), 59.1, 10.1)
+	NewCachedReverseGeocoder(counting, fileCache, time.Hour, "other").Reverse(context.Background(), 59.08, 10.08)
+	if counting.calls != 3 {
+		t.Errorf("geocoder called %d times; want 3", counting.calls)
+	}
+}
@@ -0,0 +1,209 @@
+// Package history archives the forecasts sky fetches in SQLite, so that
+// they can be queried and compared with what happened after the cache has
+// expired
+package history
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+	_ "modernc.org/sqlite" // registers the sqlite driver, in pure Go
+)
+
+// schema creates the tables of the archive. A snapshot is a forecast as
+// MET Norway issued it for a location; its hours are numbered by step,
+// with step 0 the analysis.
+const schema = `
+CREATE TABLE IF NOT EXISTS snapshots (
+	id         INTEGER PRIMARY KEY,
+	location   TEXT    NOT NULL,
+	latitude   REAL    NOT NULL,
+	longitude  REAL    NOT NULL,
+	issued_at  INTEGER NOT NULL,
+	fetched_at INTEGER NOT NULL,
+	UNIQUE (latitude, longitude, issued_at)
+);
+CREATE INDEX IF NOT EXISTS snapshots_issued_at ON snapshots (issued_at);
+
+CREATE TABLE IF NOT EXISTS hours (
+	snapshot_id               INTEGER NOT NULL REFERENCES snapshots (id) ON DELETE CASCADE,
+	step                      INTEGER NOT NULL,
+	time                      INTEGER NOT NULL,
+	temperature               REAL    NOT NULL,
+	humidity                  REAL    NOT NULL,
+	pressure                  REAL    NOT NULL,
+	cloud_cover               REAL    NOT NULL,
+	wind_speed                REAL    NOT NULL,
+	wind_gust                 REAL    NOT NULL,
+	wind_direction            REAL    NOT NULL,
+	precipitation             REAL    NOT NULL,
+	precipitation_probability REAL    NOT NULL,
+	symbol                    TEXT    NOT NULL,
+	PRIMARY KEY (snapshot_id, step)
+);
+CREATE INDEX IF NOT EXISTS hours_time ON hours (time);
+`
+
+// coordinateTolerance is how far apart coordinates of the same location
+// may be. MET Norway uses four decimals.
+const coordinateTolerance = 0.00005
+
+// Archive is a SQLite archive of forecast snapshots
+type Archive struct {
+	db *sql.DB
+}
+
+// Open opens the archive at path, creating it if it does not exist
+func Open(path string) (*Archive, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	dsn := "file:" + path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history archive: %w", err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open history archive %s: %w", path, err)
+	}
+	return &Archive{db: db}, nil
+}
+
+// Close closes the archive
+func (a *Archive) Close() error {
+	return a.db.Close()
+}
+
+// Record adds a snapshot to the archive. A snapshot of a forecast that is
+// already archived is skipped, so fetching the same forecast again does
+// not grow the archive.
+func (a *Archive) Record(snapshot *models.Snapshot) (bool, error) {
+	tx, err := a.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	loc := snapshot.Location
+	result, err := tx.Exec(`INSERT OR IGNORE INTO snapshots (location, latitude, longitude, issued_at, fetched_at) VALUES (?, ?, ?, ?, ?)`,
+		loc.Name, round(loc.Latitude), round(loc.Longitude), snapshot.IssuedAt.Unix(), snapshot.FetchedAt.Unix())
+	if err != nil {
+		return false, fmt.Errorf("failed to record snapshot: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return false, nil
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return false, err
+	}
+
+	stmt, err := tx.Prepare(`INSERT INTO hours (snapshot_id, step, time, temperature, humidity, pressure, cloud_cover,
+		wind_speed, wind_gust, wind_direction, precipitation, precipitation_probability, symbol)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return false, err
+	}
+	defer stmt.Close()
+	for step, h := range snapshot.Hours {
+		if _, err := stmt.Exec(id, step, h.Time.Unix(), h.Temperature, h.Humidity, h.Pressure, h.CloudCover,
+			h.WindSpeed, h.WindGust, h.WindDir, h.Precipitation, h.PrecipitationProbability, h.Symbol); err != nil {
+			return false, fmt.Errorf("failed to record snapshot: %w", err)
+		}
+	}
+	return true, tx.Commit()
+}
+
+// Prune deletes the snapshots issued before t and returns how many there
+// were
+func (a *Archive) Prune(t time.Time) (int64, error) {
+	result, err := a.db.Exec(`DELETE FROM snapshots WHERE issued_at < ?`, t.Unix())
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune history: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// Filter selects snapshots from the archive. Zero fields select all.
+type Filter struct {
+	// Location selects the snapshots for its coordinates
+	Location *models.Location
+
+	// From and To select the hours forecast from From until To
+	From, To time.Time
+
+	// Analyses selects only the first hour of every snapshot
+	Analyses bool
+}
+
+// Snapshots returns the snapshots that match f, oldest first, with the
+// hours that match it
+func (a *Archive) Snapshots(f Filter) ([]*models.Snapshot, error) {
+	var where []string
+	var args []interface{}
+	if f.Location != nil {
+		where = append(where, "ABS(s.latitude - ?) < ? AND ABS(s.longitude - ?) < ?")
+		args = append(args, round(f.Location.Latitude), coordinateTolerance, round(f.Location.Longitude), coordinateTolerance)
+	}
+	if !f.From.IsZero() {
+		where = append(where, "h.time >= ?")
+		args = append(args, f.From.Unix())
+	}
+	if !f.To.IsZero() {
+		where = append(where, "h.time < ?")
+		args = append(args, f.To.Unix())
+	}
+	if f.Analyses {
+		where = append(where, "h.step = 0")
+	}
+
+	query := `SELECT s.id, s.location, s.latitude, s.longitude, s.issued_at, s.fetched_at,
+		h.time, h.temperature, h.humidity, h.pressure, h.cloud_cover, h.wind_speed, h.wind_gust,
+		h.wind_direction, h.precipitation, h.precipitation_probability, h.symbol
+		FROM snapshots s JOIN hours h ON h.snapshot_id = s.id`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY s.issued_at, s.id, h.step"
+
+	rows, err := a.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	defer rows.Close()
+
+	var snapshots []*models.Snapshot
+	var current *models.Snapshot
+	lastID := int64(-1)
+	for rows.Next() {
+		var id, issued, fetched, t int64
+		loc := &models.Location{}
+		var h models.SnapshotHour
+		if err := rows.Scan(&id, &loc.Name, &loc.Latitude, &loc.Longitude, &issued, &fetched,
+			&t, &h.Temperature, &h.Humidity, &h.Pressure, &h.CloudCover, &h.WindSpeed, &h.WindGust,
+			&h.WindDir, &h.Precipitation, &h.PrecipitationProbability, &h.Symbol); err != nil {
+			return nil, fmt.Errorf("failed to read history: %w", err)
+		}
+		if id != lastID {
+			current = &models.Snapshot{Location: loc, IssuedAt: time.Unix(issued, 0).UTC(), FetchedAt: time.Unix(fetched, 0).UTC()}
+			snapshots = append(snapshots, current)
+			lastID = id
+		}
+		h.Time = time.Unix(t, 0).UTC()
+		current.Hours = append(current.Hours, h)
+	}
+	return snapshots, rows.Err()
+}
+
+// round rounds a coordinate to the four decimals MET Norway uses
+func round(coordinate float64) float64 {
+	return math.Round(coordinate*10000) / 10000
+}
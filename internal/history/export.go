@@ -0,0 +1,95 @@
+package history
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+// ExportFormats are the formats the archive can be exported to
+var ExportFormats = []string{"csv", "parquet"}
+
+// columnKind is the type of an exported column
+type columnKind int
+
+const (
+	kindString columnKind = iota
+	kindFloat
+	kindTime
+)
+
+// column is a column of an export, with one row per hour of a snapshot
+type column struct {
+	name  string
+	kind  columnKind
+	value func(s *models.Snapshot, h *models.SnapshotHour) interface{}
+}
+
+var columns = []column{
+	{"location", kindString, func(s *models.Snapshot, h *models.SnapshotHour) interface{} { return s.Location.Name }},
+	{"latitude", kindFloat, func(s *models.Snapshot, h *models.SnapshotHour) interface{} { return s.Location.Latitude }},
+	{"longitude", kindFloat, func(s *models.Snapshot, h *models.SnapshotHour) interface{} { return s.Location.Longitude }},
+	{"issued_at", kindTime, func(s *models.Snapshot, h *models.SnapshotHour) interface{} { return s.IssuedAt }},
+	{"fetched_at", kindTime, func(s *models.Snapshot, h *models.SnapshotHour) interface{} { return s.FetchedAt }},
+	{"time", kindTime, func(s *models.Snapshot, h *models.SnapshotHour) interface{} { return h.Time }},
+	{"lead_hours", kindFloat, func(s *models.Snapshot, h *models.SnapshotHour) interface{} { return s.Lead(h).Hours() }},
+	{"temperature", kindFloat, func(s *models.Snapshot, h *models.SnapshotHour) interface{} { return h.Temperature }},
+	{"humidity", kindFloat, func(s *models.Snapshot, h *models.SnapshotHour) interface{} { return h.Humidity }},
+	{"pressure", kindFloat, func(s *models.Snapshot, h *models.SnapshotHour) interface{} { return h.Pressure }},
+	{"cloud_cover", kindFloat, func(s *models.Snapshot, h *models.SnapshotHour) interface{} { return h.CloudCover }},
+	{"wind_speed", kindFloat, func(s *models.Snapshot, h *models.SnapshotHour) interface{} { return h.WindSpeed }},
+	{"wind_gust", kindFloat, func(s *models.Snapshot, h *models.SnapshotHour) interface{} { return h.WindGust }},
+	{"wind_direction", kindFloat, func(s *models.Snapshot, h *models.SnapshotHour) interface{} { return h.WindDir }},
+	{"precipitation", kindFloat, func(s *models.Snapshot, h *models.SnapshotHour) interface{} { return h.Precipitation }},
+	{"precipitation_probability", kindFloat, func(s *models.Snapshot, h *models.SnapshotHour) interface{} { return h.PrecipitationProbability }},
+	{"symbol", kindString, func(s *models.Snapshot, h *models.SnapshotHour) interface{} { return h.Symbol }},
+}
+
+// Export writes the snapshots in a format, one row per hour of every
+// snapshot
+func Export(w io.Writer, snapshots []*models.Snapshot, format string) error {
+	switch strings.ToLower(format) {
+	case "csv":
+		return writeCSV(w, snapshots)
+	case "parquet":
+		return writeParquet(w, snapshots)
+	}
+	return fmt.Errorf("unknown export format '%s' (available: %s)", format, strings.Join(ExportFormats, ", "))
+}
+
+func writeCSV(w io.Writer, snapshots []*models.Snapshot) error {
+	cw := csv.NewWriter(w)
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = c.name
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	record := make([]string, len(columns))
+	for _, s := range snapshots {
+		for i := range s.Hours {
+			for j, c := range columns {
+				switch v := c.value(s, &s.Hours[i]).(type) {
+				case string:
+					record[j] = v
+				case float64:
+					record[j] = strconv.FormatFloat(v, 'f', -1, 64)
+				case time.Time:
+					record[j] = v.UTC().Format(time.RFC3339)
+				}
+			}
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
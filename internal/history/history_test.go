@@ -0,0 +1,386 @@
+package history
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+	"github.com/parquet-go/parquet-go"
+)
+
+var (
+	oslo   = &models.Location{Name: "Oslo", Latitude: 59.91273, Longitude: 10.74609}
+	bergen = &models.Location{Name: "Bergen", Latitude: 60.39299, Longitude: 5.32415}
+)
+
+// snapshot returns a snapshot issued at issued with an hour per
+// temperature, the first at the issue time
+func snapshot(loc *models.Location, issued time.Time, temperatures ...float64) *models.Snapshot {
+	s := &models.Snapshot{Location: loc, IssuedAt: issued, FetchedAt: issued.Add(10 * time.Minute)}
+	for i, temp := range temperatures {
+		s.Hours = append(s.Hours, models.SnapshotHour{
+			Time:          issued.Add(time.Duration(i) * time.Hour),
+			Temperature:   temp,
+			WindSpeed:     float64(i),
+			Precipitation: 0.5,
+			Symbol:        "cloudy",
+		})
+	}
+	return s
+}
+
+func openArchive(t *testing.T) *Archive {
+	t.Helper()
+	archive, err := Open(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	t.Cleanup(func() { archive.Close() })
+	return archive
+}
+
+func TestArchive(t *testing.T) {
+	archive := openArchive(t)
+	issued := time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC)
+
+	for _, s := range []*models.Snapshot{
+		snapshot(oslo, issued, -3, -2, -1),
+		snapshot(oslo, issued.Add(time.Hour), -2.5, -1.5),
+		snapshot(bergen, issued, 4, 5),
+	} {
+		added, err := archive.Record(s)
+		if err != nil || !added {
+			t.Fatalf("Record() = %v, %v; want true", added, err)
+		}
+	}
+
+	t.Run("Duplicate", func(t *testing.T) {
+		added, err := archive.Record(snapshot(oslo, issued, 10, 10, 10))
+		if err != nil || added {
+			t.Errorf("Record() = %v, %v; want the same issue skipped", added, err)
+		}
+	})
+
+	t.Run("Location", func(t *testing.T) {
+		// Coordinates with more decimals than MET Norway uses still match
+		nearby := &models.Location{Latitude: 59.912731, Longitude: 10.746091}
+		snapshots, err := archive.Snapshots(Filter{Location: nearby})
+		if err != nil {
+			t.Fatalf("Snapshots() failed: %v", err)
+		}
+		if len(snapshots) != 2 || len(snapshots[0].Hours) != 3 || len(snapshots[1].Hours) != 2 {
+			t.Fatalf("Snapshots() = %d snapshots; want Oslo's 2 with 3 and 2 hours", len(snapshots))
+		}
+		got := snapshots[0]
+		if got.Location.Name != "Oslo" || !got.IssuedAt.Equal(issued) || got.Hours[0].Temperature != -3 || got.Hours[2].Symbol != "cloudy" {
+			t.Errorf("Snapshots()[0] = %+v; want Oslo as recorded", got)
+		}
+	})
+
+	t.Run("Period", func(t *testing.T) {
+		snapshots, err := archive.Snapshots(Filter{Location: oslo, From: issued.Add(time.Hour), To: issued.Add(2 * time.Hour)})
+		if err != nil {
+			t.Fatalf("Snapshots() failed: %v", err)
+		}
+		if len(snapshots) != 2 || len(snapshots[0].Hours) != 1 || len(snapshots[1].Hours) != 1 {
+			t.Errorf("Snapshots() = %d snapshots; want 2 with the 13:00 hour only", len(snapshots))
+		}
+	})
+
+	t.Run("Analyses", func(t *testing.T) {
+		snapshots, err := archive.Snapshots(Filter{Analyses: true})
+		if err != nil {
+			t.Fatalf("Snapshots() failed: %v", err)
+		}
+		if len(snapshots) != 3 {
+			t.Fatalf("Snapshots() = %d snapshots; want 3", len(snapshots))
+		}
+		for _, s := range snapshots {
+			if len(s.Hours) != 1 || !s.Hours[0].Time.Equal(s.IssuedAt) {
+				t.Errorf("Snapshots() %s has %d hours; want the first only", s.Location.Name, len(s.Hours))
+			}
+		}
+	})
+
+	t.Run("Prune", func(t *testing.T) {
+		pruned, err := archive.Prune(issued.Add(time.Minute))
+		if err != nil || pruned != 2 {
+			t.Fatalf("Prune() = %d, %v; want 2", pruned, err)
+		}
+		snapshots, err := archive.Snapshots(Filter{})
+		if err != nil {
+			t.Fatalf("Snapshots() failed: %v", err)
+		}
+		if len(snapshots) != 1 || len(snapshots[0].Hours) != 2 {
+			t.Errorf("Snapshots() = %d snapshots; want the one issued after", len(snapshots))
+		}
+	})
+}
+
+func TestRecorder(t *testing.T) {
+	openArchive(t)
+	path := filepath.Join(t.TempDir(), "sky", "history.db")
+	var errs []error
+	recorder := &Recorder{Path: path, Retention: 48 * time.Hour, OnError: func(err error) { errs = append(errs, err) }}
+
+	old := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	recorder.Record(snapshot(oslo, old, 1))
+	recorder.Record(snapshot(oslo, old.Add(72*time.Hour), 2))
+	if len(errs) > 0 {
+		t.Fatalf("Record() failed: %v", errs)
+	}
+
+	archive, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	defer archive.Close()
+	snapshots, err := archive.Snapshots(Filter{})
+	if err != nil {
+		t.Fatalf("Snapshots() failed: %v", err)
+	}
+	if len(snapshots) != 1 || snapshots[0].Hours[0].Temperature != 2 {
+		t.Errorf("Snapshots() = %d snapshots; want the forecast older than the retention pruned", len(snapshots))
+	}
+}
+
+func TestAnalyses(t *testing.T) {
+	issued := time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC)
+	hours := Analyses([]*models.Snapshot{
+		snapshot(oslo, issued.Add(time.Hour), 3),
+		snapshot(oslo, issued, 1, 2),
+		// Issued later for the same hour, such as after a correction
+		{Location: oslo, IssuedAt: issued.Add(30 * time.Minute), Hours: []models.SnapshotHour{{Time: issued, Temperature: 1.5}}},
+		{Location: oslo, IssuedAt: issued},
+	})
+
+	if len(hours) != 2 {
+		t.Fatalf("Analyses() = %d hours; want 2", len(hours))
+	}
+	if !hours[0].Time.Equal(issued) || hours[0].Temperature != 1.5 {
+		t.Errorf("Analyses()[0] = %v %.1f; want the latest issue for 12:00", hours[0].Time, hours[0].Temperature)
+	}
+	if hours[1].Temperature != 3 {
+		t.Errorf("Analyses()[1].Temperature = %.1f; want 3", hours[1].Temperature)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	maxTemp, _ := FindStat("max-temp")
+	totalPrecip, _ := FindStat("TOTAL-PRECIP")
+	selected := []Stat{maxTemp, totalPrecip}
+
+	// Sunday 4 and Monday 5 January 2026, in ISO weeks 1 and 2
+	start := time.Date(2026, 1, 4, 22, 0, 0, 0, time.UTC)
+	var hours []models.SnapshotHour
+	for i := 0; i < 4; i++ {
+		hours = append(hours, models.SnapshotHour{Time: start.Add(time.Duration(i) * time.Hour), Temperature: float64(i), Precipitation: 1})
+	}
+
+	tests := []struct {
+		name string
+		by   string
+		tz   *time.Location
+		want []Row
+	}{
+		{"All", "", time.UTC, []Row{{Period: "all", Samples: 4, Values: []float64{3, 4}}}},
+		{"Day", "day", time.UTC, []Row{
+			{Period: "2026-01-04", Samples: 2, Values: []float64{1, 2}},
+			{Period: "2026-01-05", Samples: 2, Values: []float64{3, 2}},
+		}},
+		{"Week", "week", time.UTC, []Row{
+			{Period: "2026-W01", Samples: 2, Values: []float64{1, 2}},
+			{Period: "2026-W02", Samples: 2, Values: []float64{3, 2}},
+		}},
+		{"Week in local time", "week", time.FixedZone("CET", 3600), []Row{
+			{Period: "2026-W01", Samples: 1, Values: []float64{0, 1}},
+			{Period: "2026-W02", Samples: 3, Values: []float64{3, 3}},
+		}},
+		{"Month", "month", time.UTC, []Row{{Period: "2026-01", Samples: 4, Values: []float64{3, 4}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := Summarize(hours, selected, tt.by, tt.tz)
+			if err != nil {
+				t.Fatalf("Summarize() failed: %v", err)
+			}
+			if len(rows) != len(tt.want) {
+				t.Fatalf("Summarize() = %d rows; want %d", len(rows), len(tt.want))
+			}
+			for i, want := range tt.want {
+				got := rows[i]
+				if got.Period != want.Period || got.Samples != want.Samples ||
+					got.Values[0] != want.Values[0] || got.Values[1] != want.Values[1] {
+					t.Errorf("Summarize()[%d] = %s %d %v; want %s %d %v",
+						i, got.Period, got.Samples, got.Values, want.Period, want.Samples, want.Values)
+				}
+			}
+		})
+	}
+
+	if _, err := Summarize(hours, selected, "fortnight", time.UTC); err == nil {
+		t.Error("Summarize() with an unknown period succeeded; want an error")
+	}
+	if _, err := FindStat("median-temp"); err == nil {
+		t.Error("FindStat() with an unknown statistic succeeded; want an error")
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	issued := time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	if err := Export(&buf, []*models.Snapshot{snapshot(oslo, issued, -3, -2.5)}, "csv"); err != nil {
+		t.Fatalf("Export() failed: %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("exported CSV is invalid: %v", err)
+	}
+	if len(records) != 3 || len(records[0]) != len(columns) {
+		t.Fatalf("Export() = %d records; want a header and 2 hours", len(records))
+	}
+
+	row := make(map[string]string)
+	for i, name := range records[0] {
+		row[name] = records[2][i]
+	}
+	want := map[string]string{
+		"location":    "Oslo",
+		"issued_at":   "2026-01-05T12:00:00Z",
+		"time":        "2026-01-05T13:00:00Z",
+		"lead_hours":  "1",
+		"temperature": "-2.5",
+		"symbol":      "cloudy",
+	}
+	for name, value := range want {
+		if row[name] != value {
+			t.Errorf("%s = %q; want %q", name, row[name], value)
+		}
+	}
+
+	if err := Export(&buf, nil, "xlsx"); err == nil {
+		t.Error("Export() to an unknown format succeeded; want an error")
+	}
+}
+
+func TestExportParquet(t *testing.T) {
+	issued := time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	snapshots := []*models.Snapshot{snapshot(oslo, issued, -3, -2.5), snapshot(bergen, issued, 4)}
+	if err := Export(&buf, snapshots, "parquet"); err != nil {
+		t.Fatalf("Export() failed: %v", err)
+	}
+
+	file, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("exported Parquet is invalid: %v", err)
+	}
+	if file.NumRows() != 3 {
+		t.Errorf("NumRows() = %d; want 3", file.NumRows())
+	}
+	names := make(map[string]bool)
+	for _, path := range file.Schema().Columns() {
+		names[path[0]] = true
+	}
+	for _, c := range columns {
+		if !names[c.name] {
+			t.Errorf("schema has no %s column", c.name)
+		}
+	}
+
+	type row struct {
+		Location    string    `parquet:"location"`
+		Time        time.Time `parquet:"time,timestamp(millisecond)"`
+		LeadHours   float64   `parquet:"lead_hours"`
+		Temperature float64   `parquet:"temperature"`
+	}
+	rows, err := parquet.Read[row](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("failed to read the exported rows: %v", err)
+	}
+	want := []row{
+		{"Oslo", issued, 0, -3},
+		{"Oslo", issued.Add(time.Hour), 1, -2.5},
+		{"Bergen", issued, 0, 4},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("read %d rows; want %d", len(rows), len(want))
+	}
+	for i := range want {
+		if rows[i].Location != want[i].Location || !rows[i].Time.Equal(want[i].Time) ||
+			rows[i].LeadHours != want[i].LeadHours || rows[i].Temperature != want[i].Temperature {
+			t.Errorf("row %d = %+v; want %+v", i, rows[i], want[i])
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	noon := time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC)
+	hour := func(at time.Time, temp, precip float64) models.SnapshotHour {
+		return models.SnapshotHour{Time: at, Temperature: temp, Precipitation: precip}
+	}
+	snapshots := []*models.Snapshot{
+		// Forecast an hour and two hours ahead, then six-hourly
+		{Location: oslo, IssuedAt: noon.Add(-time.Hour), Hours: []models.SnapshotHour{
+			hour(noon.Add(-time.Hour), 9, 0), hour(noon, 12, 1), hour(noon.Add(time.Hour), 13, 3), hour(noon.Add(7*time.Hour), 5, 9),
+		}},
+		// Issued 20 minutes past, so its 13:00 hour rounds to an hour ahead,
+		// and its 14:00 hour has no analysis
+		{Location: oslo, IssuedAt: noon.Add(20 * time.Minute), Hours: []models.SnapshotHour{
+			hour(noon, 10, 2), hour(noon.Add(time.Hour), 12, 0.5), hour(noon.Add(2*time.Hour), 0, 0),
+		}},
+		// Analyses at 12:00 and 13:00, and nothing to compare 18:00 with
+		{Location: oslo, IssuedAt: noon, Hours: []models.SnapshotHour{hour(noon, 10, 2)}},
+		{Location: oslo, IssuedAt: noon.Add(time.Hour), Hours: []models.SnapshotHour{hour(noon.Add(time.Hour), 11, 0)}},
+	}
+
+	temperature, _ := FindVariable("temperature")
+	precipitation, _ := FindVariable("Precipitation")
+	leads := []time.Duration{time.Hour, 2 * time.Hour, 6 * time.Hour}
+	scores := Verify(snapshots, []Variable{temperature, precipitation}, leads)
+	if len(scores) != 6 {
+		t.Fatalf("Verify() = %d scores; want one per variable and lead", len(scores))
+	}
+
+	tests := []struct {
+		variable  string
+		lead      time.Duration
+		samples   int
+		bias, mae float64
+		rmse      float64
+	}{
+		// 12 against 10 and 12 against 11
+		{"temperature", time.Hour, 2, 1.5, 1.5, math.Sqrt(2.5)},
+		// 13 against 11
+		{"temperature", 2 * time.Hour, 1, 2, 2, 2},
+		{"temperature", 6 * time.Hour, 0, 0, 0, 0},
+		// 1 against 2 and 0.5 against 0
+		{"precipitation", time.Hour, 2, -0.25, 0.75, math.Sqrt(0.625)},
+		// The 13:00 hour is followed by a six-hour step
+		{"precipitation", 2 * time.Hour, 0, 0, 0, 0},
+		{"precipitation", 6 * time.Hour, 0, 0, 0, 0},
+	}
+	for i, tt := range tests {
+		s := scores[i]
+		if s.Variable.Name != tt.variable || s.Lead != tt.lead || s.Samples != tt.samples ||
+			!near(s.Bias, tt.bias) || !near(s.MAE, tt.mae) || !near(s.RMSE, tt.rmse) {
+			t.Errorf("Verify()[%d] = %s %v n=%d bias=%.3f mae=%.3f rmse=%.3f; want %s %v n=%d bias=%.3f mae=%.3f rmse=%.3f",
+				i, s.Variable.Name, s.Lead, s.Samples, s.Bias, s.MAE, s.RMSE,
+				tt.variable, tt.lead, tt.samples, tt.bias, tt.mae, tt.rmse)
+		}
+	}
+
+	if _, err := FindVariable("visibility"); err == nil {
+		t.Error("FindVariable() with an unknown variable succeeded; want an error")
+	}
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
@@ -0,0 +1,70 @@
+package history
+
+import (
+	"io"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/compress/snappy"
+)
+
+// parquetSchema returns the schema of a Parquet export: every column is
+// required, strings are UTF-8 and times are UTC timestamps in
+// milliseconds. Parquet groups order their columns by name.
+func parquetSchema() *parquet.Schema {
+	group := make(parquet.Group, len(columns))
+	for _, c := range columns {
+		switch c.kind {
+		case kindString:
+			group[c.name] = parquet.String()
+		case kindTime:
+			group[c.name] = parquet.Timestamp(parquet.Millisecond)
+		default:
+			group[c.name] = parquet.Leaf(parquet.DoubleType)
+		}
+	}
+	return parquet.NewSchema("sky", group)
+}
+
+// writeParquet writes the snapshots as a Snappy-compressed Parquet file
+func writeParquet(w io.Writer, snapshots []*models.Snapshot) error {
+	schema := parquetSchema()
+
+	// The index of each column in the rows of the schema
+	index := make(map[string]int, len(columns))
+	for i, path := range schema.Columns() {
+		index[path[0]] = i
+	}
+
+	var rows []parquet.Row
+	for _, s := range snapshots {
+		for i := range s.Hours {
+			row := make(parquet.Row, len(columns))
+			for _, c := range columns {
+				j := index[c.name]
+				row[j] = parquetValue(c.value(s, &s.Hours[i])).Level(0, 0, j)
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	pw := parquet.NewWriter(w, schema, parquet.Compression(&snappy.Codec{}))
+	if _, err := pw.WriteRows(rows); err != nil {
+		return err
+	}
+	return pw.Close()
+}
+
+// parquetValue converts a column value to its Parquet physical value
+func parquetValue(v interface{}) parquet.Value {
+	switch v := v.(type) {
+	case string:
+		return parquet.ByteArrayValue([]byte(v))
+	case float64:
+		return parquet.DoubleValue(v)
+	case time.Time:
+		return parquet.Int64Value(v.UnixMilli())
+	}
+	return parquet.NullValue()
+}
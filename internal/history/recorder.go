@@ -0,0 +1,41 @@
+package history
+
+import (
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+// Recorder records the forecasts a client fetches in the archive at Path,
+// and deletes those older than Retention
+type Recorder struct {
+	Path      string
+	Retention time.Duration // zero keeps every forecast
+
+	// OnError is called when a forecast cannot be recorded, since the
+	// client that fetched it carries on regardless
+	OnError func(err error)
+}
+
+// Record adds a snapshot to the archive. The archive is opened for every
+// snapshot, as commands fetch few and exit soon after.
+func (r *Recorder) Record(snapshot *models.Snapshot) {
+	if err := r.record(snapshot); err != nil && r.OnError != nil {
+		r.OnError(err)
+	}
+}
+
+func (r *Recorder) record(snapshot *models.Snapshot) error {
+	archive, err := Open(r.Path)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	added, err := archive.Record(snapshot)
+	if err != nil || !added || r.Retention <= 0 {
+		return err
+	}
+	_, err = archive.Prune(snapshot.FetchedAt.Add(-r.Retention))
+	return err
+}
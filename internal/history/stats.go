@@ -0,0 +1,182 @@
+package history
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+// Stat is a statistic of the analyses in a period, such as the highest
+// temperature
+type Stat struct {
+	Name        string
+	Description string
+	Unit        string
+
+	value  func(h *models.SnapshotHour) float64
+	reduce func(values []float64) float64
+}
+
+var stats = []Stat{
+	{"max-temp", "Highest temperature", "°C", temperature, maximum},
+	{"min-temp", "Lowest temperature", "°C", temperature, minimum},
+	{"mean-temp", "Average temperature", "°C", temperature, mean},
+	{"total-precip", "Precipitation, summed over the hours archived", "mm", precipitation, sum},
+	{"max-precip", "Most precipitation in an hour", "mm", precipitation, maximum},
+	{"max-wind", "Strongest wind", "m/s", func(h *models.SnapshotHour) float64 { return h.WindSpeed }, maximum},
+	{"mean-wind", "Average wind", "m/s", func(h *models.SnapshotHour) float64 { return h.WindSpeed }, mean},
+	{"max-gust", "Strongest gust", "m/s", func(h *models.SnapshotHour) float64 { return h.WindGust }, maximum},
+	{"mean-humidity", "Average humidity", "%", func(h *models.SnapshotHour) float64 { return h.Humidity }, mean},
+	{"mean-pressure", "Average air pressure", "hPa", func(h *models.SnapshotHour) float64 { return h.Pressure }, mean},
+	{"mean-cloud-cover", "Average cloud cover", "%", func(h *models.SnapshotHour) float64 { return h.CloudCover }, mean},
+}
+
+// Stats returns the statistics sky history can compute
+func Stats() []Stat {
+	return stats
+}
+
+// StatNames returns the names of the statistics
+func StatNames() []string {
+	names := make([]string, len(stats))
+	for i, s := range stats {
+		names[i] = s.Name
+	}
+	return names
+}
+
+// FindStat returns the statistic with a name
+func FindStat(name string) (Stat, error) {
+	for _, s := range stats {
+		if strings.EqualFold(s.Name, name) {
+			return s, nil
+		}
+	}
+	return Stat{}, fmt.Errorf("unknown statistic '%s' (available: %s)", name, strings.Join(StatNames(), ", "))
+}
+
+func temperature(h *models.SnapshotHour) float64   { return h.Temperature }
+func precipitation(h *models.SnapshotHour) float64 { return h.Precipitation }
+
+func maximum(values []float64) float64 {
+	m := math.Inf(-1)
+	for _, v := range values {
+		m = math.Max(m, v)
+	}
+	return m
+}
+
+func minimum(values []float64) float64 {
+	m := math.Inf(1)
+	for _, v := range values {
+		m = math.Min(m, v)
+	}
+	return m
+}
+
+func sum(values []float64) float64 {
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
+
+func mean(values []float64) float64 {
+	return sum(values) / float64(len(values))
+}
+
+// Periods are the periods statistics can be grouped by
+var Periods = []string{"hour", "day", "week", "month", "year"}
+
+// Analyses returns the analysis of every hour in the snapshots, oldest
+// first: the first hour of each snapshot, from the latest snapshot when
+// several start at the same time. It is the closest to an observation the
+// archive has.
+func Analyses(snapshots []*models.Snapshot) []models.SnapshotHour {
+	truth := analysisSnapshots(snapshots)
+	hours := make([]models.SnapshotHour, len(truth))
+	for i, s := range truth {
+		hours[i] = s.Hours[0]
+	}
+	return hours
+}
+
+// Row is the statistics of one period
+type Row struct {
+	Period  string    // such as "2026-01-05", "2026-W02" or "2026-01"
+	Start   time.Time // start of the period
+	Samples int       // hours the statistics are computed from
+	Values  []float64 // one per statistic
+}
+
+// Summarize computes statistics of hours per period in tz. An empty
+// period computes them over all the hours.
+func Summarize(hours []models.SnapshotHour, selected []Stat, by string, tz *time.Location) ([]Row, error) {
+	type group struct {
+		row   Row
+		hours []*models.SnapshotHour
+	}
+	var groups []*group
+	byKey := make(map[string]*group)
+	for i := range hours {
+		h := &hours[i]
+		start, label, err := period(h.Time.In(tz), by)
+		if err != nil {
+			return nil, err
+		}
+		g, ok := byKey[label]
+		if !ok {
+			g = &group{row: Row{Period: label, Start: start}}
+			byKey[label] = g
+			groups = append(groups, g)
+		}
+		g.hours = append(g.hours, h)
+	}
+
+	rows := make([]Row, len(groups))
+	for i, g := range groups {
+		g.row.Samples = len(g.hours)
+		for _, s := range selected {
+			values := make([]float64, len(g.hours))
+			for j, h := range g.hours {
+				values[j] = s.value(h)
+			}
+			g.row.Values = append(g.row.Values, s.reduce(values))
+		}
+		rows[i] = g.row
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].Start.Before(rows[j].Start) })
+	return rows, nil
+}
+
+// period returns the start and label of the period t is in
+func period(t time.Time, by string) (time.Time, string, error) {
+	switch by {
+	case "":
+		return time.Time{}, "all", nil
+	case "hour":
+		start := t.Truncate(time.Hour)
+		return start, start.Format("2006-01-02 15:00"), nil
+	case "day":
+		start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+		return start, start.Format("2006-01-02"), nil
+	case "week":
+		// Weeks start on Monday, as ISO weeks do
+		offset := (int(t.Weekday()) + 6) % 7
+		start := time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+		year, week := t.ISOWeek()
+		return start, fmt.Sprintf("%d-W%02d", year, week), nil
+	case "month":
+		start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+		return start, start.Format("2006-01"), nil
+	case "year":
+		start := time.Date(t.Year(), 1, 1, 0, 0, 0, 0, t.Location())
+		return start, start.Format("2006"), nil
+	}
+	return time.Time{}, "", fmt.Errorf("unknown period '%s' (available: %s)", by, strings.Join(Periods, ", "))
+}
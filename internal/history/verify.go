@@ -0,0 +1,205 @@
+package history
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+// MaxAnalysisGap is how far from a forecast hour the analysis it is
+// verified against may be. Analyses are on the hour, so this only allows
+// for forecasts with times off the hour; an hour without an analysis is
+// not verified.
+const MaxAnalysisGap = 30 * time.Minute
+
+// Variable is a forecast variable that can be verified
+type Variable struct {
+	Name string
+	Unit string
+
+	// value returns the value of the variable in an hour, and false when
+	// the hour has none to verify
+	value func(s *models.Snapshot, i int) (float64, bool)
+}
+
+var variables = []Variable{
+	{"temperature", "°C", func(s *models.Snapshot, i int) (float64, bool) { return s.Hours[i].Temperature, true }},
+	{"precipitation", "mm", hourlyPrecipitation},
+	{"wind", "m/s", func(s *models.Snapshot, i int) (float64, bool) { return s.Hours[i].WindSpeed, true }},
+	{"gust", "m/s", func(s *models.Snapshot, i int) (float64, bool) { return s.Hours[i].WindGust, s.Hours[i].WindGust > 0 }},
+	{"humidity", "%", func(s *models.Snapshot, i int) (float64, bool) { return s.Hours[i].Humidity, true }},
+	{"pressure", "hPa", func(s *models.Snapshot, i int) (float64, bool) { return s.Hours[i].Pressure, true }},
+	{"cloud-cover", "%", func(s *models.Snapshot, i int) (float64, bool) { return s.Hours[i].CloudCover, true }},
+}
+
+// hourlyPrecipitation returns the precipitation of an hour when it is for
+// that hour alone. Further ahead MET Norway forecasts six hours at a time,
+// and those sums cannot be compared with an hour of the analysis. The first
+// hour is always hourly.
+func hourlyPrecipitation(s *models.Snapshot, i int) (float64, bool) {
+	hourly := i == 0 || i+1 < len(s.Hours) && s.Hours[i+1].Time.Sub(s.Hours[i].Time) == time.Hour
+	return s.Hours[i].Precipitation, hourly
+}
+
+// Variables returns the variables sky verify can verify
+func Variables() []Variable {
+	return variables
+}
+
+// VariableNames returns the names of the variables
+func VariableNames() []string {
+	names := make([]string, len(variables))
+	for i, v := range variables {
+		names[i] = v.Name
+	}
+	return names
+}
+
+// FindVariable returns the variable with a name
+func FindVariable(name string) (Variable, error) {
+	for _, v := range variables {
+		if strings.EqualFold(v.Name, name) {
+			return v, nil
+		}
+	}
+	return Variable{}, fmt.Errorf("unknown variable '%s' (available: %s)", name, strings.Join(VariableNames(), ", "))
+}
+
+// Score is how accurate the forecasts of a variable were at a lead time,
+// from the errors of the forecasts against the analyses
+type Score struct {
+	Variable Variable
+	Lead     time.Duration
+	Samples  int
+	Bias     float64 // mean error; positive when the forecasts were too high
+	MAE      float64 // mean absolute error
+	RMSE     float64 // root mean square error
+}
+
+// Verify scores the forecasts in the snapshots against the analyses in
+// them. A forecast hour counts for a lead time when it was forecast that
+// long ahead, rounded to the hour, and is compared with the closest
+// analysis within MaxAnalysisGap. It returns a score per variable and lead
+// time, in that order, including those without samples.
+func Verify(snapshots []*models.Snapshot, selected []Variable, leads []time.Duration) []Score {
+	truth := analysisSnapshots(snapshots)
+	times := make([]time.Time, len(truth))
+	for i, a := range truth {
+		times[i] = a.Hours[0].Time
+	}
+
+	type sums struct {
+		n                int
+		errors, absolute float64
+		squared          float64
+	}
+	totals := make([][]sums, len(selected))
+	for i := range totals {
+		totals[i] = make([]sums, len(leads))
+	}
+
+	for _, s := range snapshots {
+		for i := range s.Hours {
+			lead := s.Lead(&s.Hours[i]).Round(time.Hour)
+			l := indexOfLead(leads, lead)
+			if l < 0 || lead <= 0 {
+				continue
+			}
+			a := closest(times, s.Hours[i].Time)
+			if a < 0 {
+				continue
+			}
+			for v, variable := range selected {
+				forecast, ok := variable.value(s, i)
+				if !ok {
+					continue
+				}
+				observed, ok := variable.value(truth[a], 0)
+				if !ok {
+					continue
+				}
+				e := forecast - observed
+				t := &totals[v][l]
+				t.n++
+				t.errors += e
+				t.absolute += math.Abs(e)
+				t.squared += e * e
+			}
+		}
+	}
+
+	var scores []Score
+	for v, variable := range selected {
+		for l, lead := range leads {
+			t := totals[v][l]
+			score := Score{Variable: variable, Lead: lead, Samples: t.n}
+			if t.n > 0 {
+				n := float64(t.n)
+				score.Bias = t.errors / n
+				score.MAE = t.absolute / n
+				score.RMSE = math.Sqrt(t.squared / n)
+			}
+			scores = append(scores, score)
+		}
+	}
+	return scores
+}
+
+// analysisSnapshots returns the snapshot of every analysis, oldest first:
+// the latest snapshot when several start at the same time
+func analysisSnapshots(snapshots []*models.Snapshot) []*models.Snapshot {
+	latest := make(map[time.Time]*models.Snapshot)
+	for _, s := range snapshots {
+		if len(s.Hours) == 0 {
+			continue
+		}
+		t := s.Hours[0].Time
+		if prev, ok := latest[t]; !ok || !s.IssuedAt.Before(prev.IssuedAt) {
+			latest[t] = s
+		}
+	}
+
+	truth := make([]*models.Snapshot, 0, len(latest))
+	for _, s := range latest {
+		truth = append(truth, s)
+	}
+	sort.Slice(truth, func(i, j int) bool { return truth[i].Hours[0].Time.Before(truth[j].Hours[0].Time) })
+	return truth
+}
+
+// closest returns the index of the time in sorted times closest to t, or
+// -1 when none is within MaxAnalysisGap
+func closest(times []time.Time, t time.Time) int {
+	i := sort.Search(len(times), func(i int) bool { return !times[i].Before(t) })
+	best := -1
+	for _, j := range []int{i - 1, i} {
+		if j < 0 || j >= len(times) {
+			continue
+		}
+		gap := absDuration(times[j].Sub(t))
+		if gap <= MaxAnalysisGap && (best < 0 || gap < absDuration(times[best].Sub(t))) {
+			best = j
+		}
+	}
+	return best
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+func indexOfLead(leads []time.Duration, lead time.Duration) int {
+	for i, l := range leads {
+		if l == lead {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,173 @@
+package locfile
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+// csvColumns are the columns written to CSV files
+var csvColumns = []string{"key", "name", "latitude", "longitude", "elevation", "timezone", "tags", "notes", "provider", "format"}
+
+// csvAliases maps other common column names to the columns sky uses
+var csvAliases = map[string]string{
+	"id":       "key",
+	"title":    "name",
+	"lat":      "latitude",
+	"y":        "latitude",
+	"lon":      "longitude",
+	"lng":      "longitude",
+	"long":     "longitude",
+	"x":        "longitude",
+	"ele":      "elevation",
+	"alt":      "elevation",
+	"altitude": "elevation",
+	"tz":       "timezone",
+}
+
+func decodeCSV(data []byte) ([]Entry, error) {
+	comma := csvDelimiter(data)
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if alias, ok := csvAliases[name]; ok {
+			name = alias
+		}
+		if _, ok := columns[name]; !ok {
+			columns[name] = i
+		}
+	}
+	for _, required := range []string{"latitude", "longitude"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("invalid CSV: no %s column", required)
+		}
+	}
+
+	// Spreadsheets that separate fields with semicolons usually write
+	// numbers with a decimal comma
+	number := func(s string) (float64, error) {
+		if comma == ';' {
+			s = strings.Replace(s, ",", ".", 1)
+		}
+		return parseFloat(s)
+	}
+
+	var entries []Entry
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		// The reader counts the header as line 1
+		line, _ := reader.FieldPos(0)
+		loc := &models.Location{
+			Name:     field("name"),
+			Timezone: field("timezone"),
+			Tags:     splitTags(field("tags")),
+			Notes:    field("notes"),
+			Provider: field("provider"),
+			Format:   field("format"),
+		}
+		entry := newEntry(field("key"), loc, fmt.Sprintf("line %d", line))
+
+		if loc.Latitude, err = number(field("latitude")); err != nil {
+			entry.Err = fmt.Errorf("invalid latitude '%s'", field("latitude"))
+		} else if loc.Longitude, err = number(field("longitude")); err != nil {
+			entry.Err = fmt.Errorf("invalid longitude '%s'", field("longitude"))
+		} else if s := field("elevation"); s != "" {
+			if loc.Elevation, err = number(s); err != nil {
+				entry.Err = fmt.Errorf("invalid elevation '%s'", s)
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	if entries == nil {
+		entries = []Entry{}
+	}
+	return entries, nil
+}
+
+// csvDelimiter guesses the field delimiter from the header line: a comma,
+// a semicolon or a tab
+func csvDelimiter(data []byte) rune {
+	header := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		header = data[:i]
+	}
+
+	delimiter, most := ',', bytes.Count(header, []byte(","))
+	for _, d := range []rune{';', '\t'} {
+		if n := bytes.Count(header, []byte(string(d))); n > most {
+			delimiter, most = d, n
+		}
+	}
+	return delimiter
+}
+
+func encodeCSV(w io.Writer, entries []Entry) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvColumns); err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		loc := e.Location
+
+		elevation := ""
+		if loc.Elevation != 0 {
+			elevation = formatFloat(loc.Elevation)
+		}
+
+		record := []string{
+			e.Key,
+			displayName(e),
+			formatFloat(loc.Latitude),
+			formatFloat(loc.Longitude),
+			elevation,
+			loc.Timezone,
+			strings.Join(loc.Tags, ","),
+			loc.Notes,
+			loc.Provider,
+			loc.Format,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// parseFloat parses a number, allowing surrounding spaces
+func parseFloat(s string) (float64, error) {
+	return strconv.ParseFloat(strings.TrimSpace(s), 64)
+}
@@ -0,0 +1,162 @@
+package locfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+// geoJSONFeatureCollection is a GeoJSON FeatureCollection (RFC 7946),
+// or a single Feature when Type is "Feature"
+type geoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+// geoJSONFeature is a GeoJSON Feature
+type geoJSONFeature struct {
+	Type       string            `json:"type"`
+	Geometry   *geoJSONGeometry  `json:"geometry"`
+	Properties geoJSONProperties `json:"properties"`
+}
+
+// geoJSONGeometry is a GeoJSON geometry. Only points are read, so the
+// coordinates of other geometries are left undecoded.
+type geoJSONGeometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+// geoJSONProperties are the feature properties sky reads and writes
+type geoJSONProperties struct {
+	Key      string  `json:"key,omitempty"`
+	Name     string  `json:"name,omitempty"`
+	Timezone string  `json:"timezone,omitempty"`
+	Tags     tagList `json:"tags,omitempty"`
+	Notes    string  `json:"notes,omitempty"`
+	Provider string  `json:"provider,omitempty"`
+	Format   string  `json:"format,omitempty"`
+}
+
+// tagList is a list of tags, written as an array and read from an array
+// or from a single string such as "coast, summer"
+type tagList []string
+
+// UnmarshalJSON implements json.Unmarshaler
+func (t *tagList) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*t = splitTags(s)
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("tags must be a string or a list of strings")
+	}
+	*t = list
+	return nil
+}
+
+func decodeGeoJSON(data []byte) ([]Entry, error) {
+	var fc geoJSONFeatureCollection
+	if err := json.Unmarshal(data, &fc); err != nil {
+		return nil, fmt.Errorf("invalid GeoJSON: %w", err)
+	}
+
+	switch fc.Type {
+	case "FeatureCollection":
+	case "Feature":
+		var f geoJSONFeature
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("invalid GeoJSON: %w", err)
+		}
+		fc.Features = []geoJSONFeature{f}
+	default:
+		return nil, fmt.Errorf("invalid GeoJSON: expected a FeatureCollection or Feature, got '%s'", fc.Type)
+	}
+
+	entries := make([]Entry, 0, len(fc.Features))
+	for i, f := range fc.Features {
+		source := fmt.Sprintf("feature %d", i+1)
+		p := f.Properties
+
+		loc := &models.Location{
+			Name:     p.Name,
+			Timezone: p.Timezone,
+			Tags:     p.Tags,
+			Notes:    p.Notes,
+			Provider: p.Provider,
+			Format:   p.Format,
+		}
+		entry := newEntry(p.Key, loc, source)
+
+		if err := readGeoJSONPoint(f.Geometry, loc); err != nil {
+			entry.Err = err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// readGeoJSONPoint sets the coordinates of loc from a Point geometry
+func readGeoJSONPoint(g *geoJSONGeometry, loc *models.Location) error {
+	if g == nil {
+		return fmt.Errorf("feature has no geometry")
+	}
+	if g.Type != "Point" {
+		return fmt.Errorf("unsupported geometry %s (only points can be imported)", g.Type)
+	}
+
+	var position []float64
+	if err := json.Unmarshal(g.Coordinates, &position); err != nil || len(position) < 2 {
+		return fmt.Errorf("invalid point coordinates")
+	}
+
+	// GeoJSON positions are longitude, latitude and optionally altitude
+	loc.Longitude, loc.Latitude = position[0], position[1]
+	if len(position) > 2 {
+		loc.Elevation = position[2]
+	}
+	return nil
+}
+
+func encodeGeoJSON(w io.Writer, entries []Entry) error {
+	fc := geoJSONFeatureCollection{
+		Type:     "FeatureCollection",
+		Features: make([]geoJSONFeature, 0, len(entries)),
+	}
+
+	for _, e := range entries {
+		loc := e.Location
+
+		position := []string{formatFloat(loc.Longitude), formatFloat(loc.Latitude)}
+		if loc.Elevation != 0 {
+			position = append(position, formatFloat(loc.Elevation))
+		}
+
+		fc.Features = append(fc.Features, geoJSONFeature{
+			Type: "Feature",
+			Geometry: &geoJSONGeometry{
+				Type:        "Point",
+				Coordinates: json.RawMessage("[" + strings.Join(position, ", ") + "]"),
+			},
+			Properties: geoJSONProperties{
+				Key:      e.Key,
+				Name:     displayName(e),
+				Timezone: loc.Timezone,
+				Tags:     loc.Tags,
+				Notes:    loc.Notes,
+				Provider: loc.Provider,
+				Format:   loc.Format,
+			},
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(fc)
+}
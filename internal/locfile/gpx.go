@@ -0,0 +1,103 @@
+package locfile
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+// gpxNamespace is the GPX 1.1 namespace
+const gpxNamespace = "http://www.topografix.com/GPX/1/1"
+
+// gpxFile is a GPX 1.1 document. Only waypoints are read.
+type gpxFile struct {
+	XMLName   xml.Name      `xml:"gpx"`
+	Version   string        `xml:"version,attr"`
+	Creator   string        `xml:"creator,attr"`
+	Namespace string        `xml:"xmlns,attr,omitempty"`
+	Waypoints []gpxWaypoint `xml:"wpt"`
+}
+
+// gpxWaypoint is a GPX waypoint. The notes of a location are written as
+// its description and the tags as its type.
+type gpxWaypoint struct {
+	Latitude  string   `xml:"lat,attr"`
+	Longitude string   `xml:"lon,attr"`
+	Elevation *float64 `xml:"ele,omitempty"`
+	Name      string   `xml:"name,omitempty"`
+	Desc      string   `xml:"desc,omitempty"`
+	Type      string   `xml:"type,omitempty"`
+}
+
+func decodeGPX(data []byte) ([]Entry, error) {
+	var file gpxFile
+	if err := xml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid GPX: %w", err)
+	}
+
+	entries := make([]Entry, 0, len(file.Waypoints))
+	for i, wpt := range file.Waypoints {
+		loc := &models.Location{
+			Name:  strings.TrimSpace(wpt.Name),
+			Notes: strings.TrimSpace(wpt.Desc),
+			Tags:  splitTags(wpt.Type),
+		}
+		if wpt.Elevation != nil {
+			loc.Elevation = *wpt.Elevation
+		}
+		entry := newEntry("", loc, fmt.Sprintf("waypoint %d", i+1))
+
+		var err error
+		if loc.Latitude, err = parseFloat(wpt.Latitude); err != nil {
+			entry.Err = fmt.Errorf("invalid latitude '%s'", wpt.Latitude)
+		} else if loc.Longitude, err = parseFloat(wpt.Longitude); err != nil {
+			entry.Err = fmt.Errorf("invalid longitude '%s'", wpt.Longitude)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func encodeGPX(w io.Writer, entries []Entry) error {
+	file := gpxFile{
+		Version:   "1.1",
+		Creator:   "sky",
+		Namespace: gpxNamespace,
+	}
+
+	for _, e := range entries {
+		loc := e.Location
+		wpt := gpxWaypoint{
+			Latitude:  formatFloat(loc.Latitude),
+			Longitude: formatFloat(loc.Longitude),
+			Name:      displayName(e),
+			Desc:      loc.Notes,
+			Type:      strings.Join(loc.Tags, ","),
+		}
+		if loc.Elevation != 0 {
+			elevation := loc.Elevation
+			wpt.Elevation = &elevation
+		}
+		file.Waypoints = append(file.Waypoints, wpt)
+	}
+
+	return writeXML(w, file)
+}
+
+// writeXML writes v as an indented XML document
+func writeXML(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,163 @@
+package locfile
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+// kmlNamespace is the KML 2.2 namespace
+const kmlNamespace = "http://www.opengis.net/kml/2.2"
+
+// kmlFile is a KML document with one placemark per location
+type kmlFile struct {
+	XMLName   xml.Name `xml:"kml"`
+	Namespace string   `xml:"xmlns,attr"`
+	Document  struct {
+		Name       string         `xml:"name"`
+		Placemarks []kmlPlacemark `xml:"Placemark"`
+	} `xml:"Document"`
+}
+
+// kmlPlacemark is a KML placemark. The key, timezone, tags, provider and
+// format of a location are written as extended data.
+type kmlPlacemark struct {
+	Name        string    `xml:"name,omitempty"`
+	Description string    `xml:"description,omitempty"`
+	Data        []kmlData `xml:"ExtendedData>Data,omitempty"`
+	Point       *kmlPoint `xml:"Point"`
+}
+
+// kmlPoint is a KML point
+type kmlPoint struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+// kmlData is a named value in the extended data of a placemark
+type kmlData struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value"`
+}
+
+func decodeKML(data []byte) ([]Entry, error) {
+	// Placemarks can be nested in documents and folders, so every
+	// Placemark element is read wherever it is
+	var entries []Entry
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid KML: %w", err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "Placemark" {
+			continue
+		}
+
+		var pm kmlPlacemark
+		if err := decoder.DecodeElement(&pm, &start); err != nil {
+			return nil, fmt.Errorf("invalid KML: %w", err)
+		}
+		entries = append(entries, placemarkEntry(pm, fmt.Sprintf("placemark %d", len(entries)+1)))
+	}
+
+	if entries == nil {
+		entries = []Entry{}
+	}
+	return entries, nil
+}
+
+// placemarkEntry returns the entry for a placemark
+func placemarkEntry(pm kmlPlacemark, source string) Entry {
+	loc := &models.Location{
+		Name:  strings.TrimSpace(pm.Name),
+		Notes: strings.TrimSpace(pm.Description),
+	}
+
+	var key string
+	for _, d := range pm.Data {
+		value := strings.TrimSpace(d.Value)
+		switch strings.ToLower(d.Name) {
+		case "key":
+			key = value
+		case "timezone":
+			loc.Timezone = value
+		case "tags":
+			loc.Tags = splitTags(value)
+		case "provider":
+			loc.Provider = value
+		case "format":
+			loc.Format = value
+		}
+	}
+	entry := newEntry(key, loc, source)
+
+	if pm.Point == nil {
+		entry.Err = fmt.Errorf("placemark is not a point")
+		return entry
+	}
+
+	// KML coordinates are longitude,latitude[,altitude]
+	parts := strings.Split(strings.TrimSpace(pm.Point.Coordinates), ",")
+	if len(parts) < 2 {
+		entry.Err = fmt.Errorf("invalid point coordinates '%s'", pm.Point.Coordinates)
+		return entry
+	}
+
+	var err error
+	if loc.Longitude, err = parseFloat(parts[0]); err != nil {
+		entry.Err = fmt.Errorf("invalid longitude '%s'", parts[0])
+	} else if loc.Latitude, err = parseFloat(parts[1]); err != nil {
+		entry.Err = fmt.Errorf("invalid latitude '%s'", parts[1])
+	} else if len(parts) > 2 {
+		if loc.Elevation, err = parseFloat(parts[2]); err != nil {
+			entry.Err = fmt.Errorf("invalid altitude '%s'", parts[2])
+		}
+	}
+	return entry
+}
+
+func encodeKML(w io.Writer, entries []Entry) error {
+	file := kmlFile{Namespace: kmlNamespace}
+	file.Document.Name = "sky locations"
+
+	for _, e := range entries {
+		loc := e.Location
+
+		coordinates := formatFloat(loc.Longitude) + "," + formatFloat(loc.Latitude)
+		if loc.Elevation != 0 {
+			coordinates += "," + formatFloat(loc.Elevation)
+		}
+
+		pm := kmlPlacemark{
+			Name:        displayName(e),
+			Description: loc.Notes,
+			Data:        []kmlData{{Name: "key", Value: e.Key}},
+			Point:       &kmlPoint{Coordinates: coordinates},
+		}
+
+		for _, d := range []kmlData{
+			{"timezone", loc.Timezone},
+			{"tags", strings.Join(loc.Tags, ",")},
+			{"provider", loc.Provider},
+			{"format", loc.Format},
+		} {
+			if d.Value != "" {
+				pm.Data = append(pm.Data, d)
+			}
+		}
+
+		file.Document.Placemarks = append(file.Document.Placemarks, pm)
+	}
+
+	return writeXML(w, file)
+}
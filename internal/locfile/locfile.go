@@ -0,0 +1,153 @@
+// Package locfile reads and writes saved locations as GeoJSON, GPX, KML
+// and CSV files
+package locfile
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/kristofferrisa/sky-cli/internal/geocode"
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+// File formats
+const (
+	FormatGeoJSON = "geojson"
+	FormatGPX     = "gpx"
+	FormatKML     = "kml"
+	FormatCSV     = "csv"
+)
+
+// Formats returns the supported file formats
+func Formats() []string {
+	return []string{FormatGeoJSON, FormatGPX, FormatKML, FormatCSV}
+}
+
+// Entry is a location read from or written to a file
+type Entry struct {
+	// Key is the name the location is saved under
+	Key      string
+	Location *models.Location
+
+	// Source describes where in the file the entry was read, such as
+	// "row 3", for messages
+	Source string
+
+	// Err is set when the entry could not be read, for example because
+	// it is not a point
+	Err error
+}
+
+// Detect returns the format of a file from its extension, or from its
+// content when the extension is not known
+func Detect(filename string, data []byte) (string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".geojson", ".json":
+		return FormatGeoJSON, nil
+	case ".gpx":
+		return FormatGPX, nil
+	case ".kml":
+		return FormatKML, nil
+	case ".csv", ".tsv", ".txt":
+		return FormatCSV, nil
+	}
+
+	head := bytes.TrimSpace(bytes.TrimPrefix(data, bom))
+	if len(head) > 1024 {
+		head = head[:1024]
+	}
+	switch {
+	case bytes.HasPrefix(head, []byte("{")):
+		return FormatGeoJSON, nil
+	case bytes.HasPrefix(head, []byte("<")) && bytes.Contains(head, []byte("<gpx")):
+		return FormatGPX, nil
+	case bytes.HasPrefix(head, []byte("<")) && bytes.Contains(head, []byte("<kml")):
+		return FormatKML, nil
+	case bytes.HasPrefix(head, []byte("<")):
+		return "", fmt.Errorf("unknown XML file format (expected GPX or KML)")
+	case len(head) > 0:
+		return FormatCSV, nil
+	}
+	return "", fmt.Errorf("file is empty")
+}
+
+// Decode reads the locations in data. Entries that cannot be read have
+// Err set; an error is returned only when the file itself is malformed.
+func Decode(format string, data []byte) ([]Entry, error) {
+	data = bytes.TrimPrefix(data, bom)
+
+	switch format {
+	case FormatGeoJSON:
+		return decodeGeoJSON(data)
+	case FormatGPX:
+		return decodeGPX(data)
+	case FormatKML:
+		return decodeKML(data)
+	case FormatCSV:
+		return decodeCSV(data)
+	default:
+		return nil, fmt.Errorf("unknown format: %s (available: %s)", format, strings.Join(Formats(), ", "))
+	}
+}
+
+// Encode writes the locations in entries to w
+func Encode(w io.Writer, format string, entries []Entry) error {
+	switch format {
+	case FormatGeoJSON:
+		return encodeGeoJSON(w, entries)
+	case FormatGPX:
+		return encodeGPX(w, entries)
+	case FormatKML:
+		return encodeKML(w, entries)
+	case FormatCSV:
+		return encodeCSV(w, entries)
+	default:
+		return fmt.Errorf("unknown format: %s (available: %s)", format, strings.Join(Formats(), ", "))
+	}
+}
+
+// bom is the UTF-8 byte order mark that spreadsheet programs put at the
+// start of CSV files
+var bom = []byte("\uFEFF")
+
+// newEntry returns the entry for a location read from a file. The key is
+// made from key, or from the location name when the file has no key.
+func newEntry(key string, loc *models.Location, source string) Entry {
+	if key == "" {
+		key = loc.Name
+	}
+	entry := Entry{Key: geocode.Slug(key), Location: loc, Source: source}
+	if entry.Key == "" {
+		entry.Err = fmt.Errorf("location has no name")
+	}
+	return entry
+}
+
+// displayName returns the name written to files for an entry
+func displayName(e Entry) string {
+	if e.Location.Name != "" {
+		return e.Location.Name
+	}
+	return e.Key
+}
+
+// splitTags splits a list of tags separated by commas, semicolons or
+// spaces. It returns nil when there are no tags.
+func splitTags(s string) []string {
+	tags := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' '
+	})
+	if len(tags) == 0 {
+		return nil
+	}
+	return tags
+}
+
+// formatFloat formats a coordinate without trailing zeros
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,278 @@
+package locfile
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files")
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		filename string
+		data     string
+		expected string
+		err      bool
+	}{
+		{"sites.geojson", "", FormatGeoJSON, false},
+		{"sites.JSON", "", FormatGeoJSON, false},
+		{"track.gpx", "", FormatGPX, false},
+		{"places.kml", "", FormatKML, false},
+		{"sites.csv", "", FormatCSV, false},
+		{"-", ` {"type": "FeatureCollection"}`, FormatGeoJSON, false},
+		{"-", `<?xml version="1.0"?><gpx version="1.1">`, FormatGPX, false},
+		{"-", `<?xml version="1.0"?><kml xmlns="http://www.opengis.net/kml/2.2">`, FormatKML, false},
+		{"-", "\uFEFFname,lat,lon\n", FormatCSV, false},
+		{"-", `<?xml version="1.0"?><osm>`, "", true},
+		{"-", "  \n", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := Detect(tt.filename, []byte(tt.data))
+		if tt.err {
+			if err == nil {
+				t.Errorf("Detect(%q, %q) = %s; want error", tt.filename, tt.data, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.expected {
+			t.Errorf("Detect(%q, %q) = %s, %v; want %s", tt.filename, tt.data, got, err, tt.expected)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	stavern := &models.Location{Name: "Stavern", Latitude: 59.0469, Longitude: 10.0344}
+	cabin := &models.Location{Name: "Hytta", Latitude: 61.6364, Longitude: 8.3125}
+
+	tests := []struct {
+		file string
+		keys []string
+		// invalid is the index of the entry that cannot be read
+		invalid int
+		// modify adds the details a format carries to the expected locations
+		modify func(stavern, cabin *models.Location)
+	}{
+		{
+			file: "sites.geojson", keys: []string{"stavern", "cabin", "ferry-route"}, invalid: 2,
+			modify: func(stavern, cabin *models.Location) {
+				stavern.Tags = []string{"coast", "summer"}
+				cabin.Elevation, cabin.Timezone, cabin.Tags = 1100, "Europe/Oslo", []string{"mountain"}
+			},
+		},
+		{
+			file: "waypoints.gpx", keys: []string{"stavern", "hytta", "nowhere"}, invalid: -1,
+			modify: func(stavern, cabin *models.Location) {
+				stavern.Elevation, stavern.Notes, stavern.Tags = 12.5, "Guest harbour", []string{"coast"}
+			},
+		},
+		{
+			file: "places.kml", keys: []string{"stavern", "cabin", "trail"}, invalid: 2,
+			modify: func(stavern, cabin *models.Location) {
+				stavern.Notes = "Guest harbour"
+				cabin.Elevation, cabin.Tags = 1100, []string{"mountain"}
+			},
+		},
+		{
+			file: "sites.csv", keys: []string{"stavern", "hytta", "broken"}, invalid: 2,
+			modify: func(stavern, cabin *models.Location) {
+				stavern.Notes, stavern.Tags = "Guest harbour", []string{"coast", "summer"}
+				cabin.Tags = []string{"mountain"}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", "import", tt.file))
+			if err != nil {
+				t.Fatalf("failed to read input: %v", err)
+			}
+
+			format, err := Detect(tt.file, data)
+			if err != nil {
+				t.Fatalf("Detect() failed: %v", err)
+			}
+			entries, err := Decode(format, data)
+			if err != nil {
+				t.Fatalf("Decode() failed: %v", err)
+			}
+
+			if len(entries) != len(tt.keys) {
+				t.Fatalf("Decode() returned %d entries; want %d", len(entries), len(tt.keys))
+			}
+			for i, e := range entries {
+				if e.Key != tt.keys[i] {
+					t.Errorf("entry %d key = %s; want %s", i, e.Key, tt.keys[i])
+				}
+				if (e.Err != nil) != (i == tt.invalid) {
+					t.Errorf("entry %d error = %v; want error: %v", i, e.Err, i == tt.invalid)
+				}
+			}
+
+			wantStavern, wantCabin := *stavern, *cabin
+			tt.modify(&wantStavern, &wantCabin)
+			if !reflect.DeepEqual(entries[0].Location, &wantStavern) {
+				t.Errorf("entry 0 = %+v; want %+v", entries[0].Location, &wantStavern)
+			}
+			if !reflect.DeepEqual(entries[1].Location, &wantCabin) {
+				t.Errorf("entry 1 = %+v; want %+v", entries[1].Location, &wantCabin)
+			}
+		})
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	tests := []struct {
+		format string
+		data   string
+	}{
+		{FormatGeoJSON, `{"type": "Point", "coordinates": [10, 59]}`},
+		{FormatGeoJSON, `{"type": "FeatureCollection", "features": [`},
+		{FormatGPX, `<gpx><wpt lat="59" lon="10">`},
+		{FormatKML, `<kml><Document><Placemark>`},
+		{FormatCSV, "name,elevation\nStavern,5\n"},
+		{FormatCSV, "name,lat,lon\n\"Stavern,59,10\n"},
+		{"shapefile", ""},
+	}
+
+	for _, tt := range tests {
+		if entries, err := Decode(tt.format, []byte(tt.data)); err == nil {
+			t.Errorf("Decode(%s, %q) = %v; want error", tt.format, tt.data, entries)
+		}
+	}
+}
+
+// exported are the locations used to test export and round trips
+var exported = []Entry{
+	{Key: "cabin", Location: &models.Location{
+		Name: "Hytta", Latitude: 61.6364, Longitude: 8.3125, Elevation: 1100,
+		Timezone: "Europe/Oslo", Tags: []string{"mountain", "weekend"},
+		Notes: "Key under the mat, \"blue\" door", Format: "summary",
+	}},
+	{Key: "stavern", Location: &models.Location{Name: "Stavern", Latitude: 59.0469, Longitude: 10.0344, Provider: "met"}},
+	{Key: "null-island", Location: &models.Location{Latitude: 0, Longitude: 0}},
+}
+
+func TestEncode(t *testing.T) {
+	for _, format := range Formats() {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Encode(&buf, format, exported); err != nil {
+				t.Fatalf("Encode() failed: %v", err)
+			}
+
+			golden := filepath.Join("testdata", "export", "locations."+format)
+			if *updateGolden {
+				if err := os.WriteFile(golden, buf.Bytes(), 0644); err != nil {
+					t.Fatalf("failed to update golden file: %v", err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read golden file: %v", err)
+			}
+			if buf.String() != string(want) {
+				t.Errorf("Encode() output mismatch\n--- got ---\n%s\n--- want ---\n%s", buf.String(), want)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, format := range Formats() {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Encode(&buf, format, exported); err != nil {
+				t.Fatalf("Encode() failed: %v", err)
+			}
+
+			entries, err := Decode(format, buf.Bytes())
+			if err != nil {
+				t.Fatalf("Decode() failed: %v", err)
+			}
+			if len(entries) != len(exported) {
+				t.Fatalf("Decode() returned %d entries; want %d", len(entries), len(exported))
+			}
+
+			for i, e := range entries {
+				want := *exported[i].Location
+				wantKey := exported[i].Key
+				if want.Name == "" {
+					want.Name = wantKey
+				}
+
+				// GPX has no place for the key, timezone, provider or format
+				if format == FormatGPX {
+					wantKey = strings.ReplaceAll(strings.ToLower(want.Name), " ", "-")
+					want.Timezone, want.Provider, want.Format = "", "", ""
+				}
+
+				if e.Err != nil {
+					t.Errorf("entry %d error = %v", i, e.Err)
+				}
+				if e.Key != wantKey {
+					t.Errorf("entry %d key = %s; want %s", i, e.Key, wantKey)
+				}
+				if !reflect.DeepEqual(e.Location, &want) {
+					t.Errorf("entry %d = %+v; want %+v", i, e.Location, &want)
+				}
+			}
+		})
+	}
+}
+
+func TestPlan(t *testing.T) {
+	existing := map[string]*models.Location{
+		"stavern":   {Name: "Stavern", Latitude: 59.0, Longitude: 10.03},
+		"stavern-2": {Name: "Stavern", Latitude: 59.0, Longitude: 10.03},
+	}
+	entries := []Entry{
+		{Key: "stavern", Location: &models.Location{Latitude: 59.05, Longitude: 10.03}},
+		{Key: "larvik", Location: &models.Location{Latitude: 59.05, Longitude: 10.03}},
+		{Key: "larvik", Location: &models.Location{Latitude: 59.06, Longitude: 10.04}},
+		{Key: "nowhere", Location: &models.Location{Latitude: 95, Longitude: 10}},
+		{Key: "trail", Location: &models.Location{}, Err: os.ErrInvalid},
+	}
+
+	tests := []struct {
+		strategy string
+		expected string
+	}{
+		{StrategySkip, "skip stavern, add larvik, skip larvik, invalid nowhere, invalid trail"},
+		{StrategyOverwrite, "overwrite stavern, add larvik, overwrite larvik, invalid nowhere, invalid trail"},
+		{StrategyRename, "rename stavern-3, add larvik, rename larvik-2, invalid nowhere, invalid trail"},
+	}
+
+	for _, tt := range tests {
+		changes, err := Plan(existing, entries, tt.strategy)
+		if err != nil {
+			t.Fatalf("Plan(%s) failed: %v", tt.strategy, err)
+		}
+
+		var got []string
+		for _, c := range changes {
+			got = append(got, c.Action.String()+" "+c.Key)
+		}
+		if strings.Join(got, ", ") != tt.expected {
+			t.Errorf("Plan(%s) = %s; want %s", tt.strategy, strings.Join(got, ", "), tt.expected)
+		}
+	}
+
+	if _, err := Plan(existing, entries, "merge"); err == nil {
+		t.Error("Plan() with an unknown strategy expected error but got none")
+	}
+
+	// Planning must not change the existing locations or entries
+	if len(existing) != 2 || entries[0].Key != "stavern" {
+		t.Errorf("Plan() changed its input")
+	}
+}
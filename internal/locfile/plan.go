@@ -0,0 +1,133 @@
+package locfile
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+// Strategies for imported locations whose name is already taken
+const (
+	// StrategySkip keeps the existing location
+	StrategySkip = "skip"
+
+	// StrategyOverwrite replaces the existing location
+	StrategyOverwrite = "overwrite"
+
+	// StrategyRename saves the imported location under a new name, such
+	// as "stavern-2"
+	StrategyRename = "rename"
+)
+
+// Strategies returns the strategies for duplicate names
+func Strategies() []string {
+	return []string{StrategySkip, StrategyOverwrite, StrategyRename}
+}
+
+// Action is what importing an entry does
+type Action int
+
+const (
+	// ActionAdd adds a new location
+	ActionAdd Action = iota
+
+	// ActionSkip leaves an existing location with the same name unchanged
+	ActionSkip
+
+	// ActionOverwrite replaces an existing location with the same name
+	ActionOverwrite
+
+	// ActionRename adds the location under a new name
+	ActionRename
+
+	// ActionInvalid skips an entry that is not a valid location
+	ActionInvalid
+)
+
+// String returns the name of the action
+func (a Action) String() string {
+	switch a {
+	case ActionAdd:
+		return "add"
+	case ActionSkip:
+		return "skip"
+	case ActionOverwrite:
+		return "overwrite"
+	case ActionRename:
+		return "rename"
+	case ActionInvalid:
+		return "invalid"
+	default:
+		return "unknown"
+	}
+}
+
+// Change is the result of importing one entry
+type Change struct {
+	// Entry is the imported entry. Its key is the name the location is
+	// saved under, which differs from the name in the file when renamed.
+	Entry
+	Action Action
+
+	// From is the name in the file of a renamed location
+	From string
+}
+
+// Plan works out how entries are imported into the existing locations,
+// without changing them. Entries with Err set or that fail validation are
+// invalid. A name already taken, by an existing location or by an earlier
+// entry, is resolved with strategy.
+func Plan(existing map[string]*models.Location, entries []Entry, strategy string) ([]Change, error) {
+	switch strategy {
+	case StrategySkip, StrategyOverwrite, StrategyRename:
+	default:
+		return nil, fmt.Errorf("unknown duplicate strategy: %s (available: %s)", strategy, strings.Join(Strategies(), ", "))
+	}
+
+	taken := make(map[string]bool, len(existing))
+	for key := range existing {
+		taken[key] = true
+	}
+
+	changes := make([]Change, 0, len(entries))
+	for _, e := range entries {
+		change := Change{Entry: e, Action: ActionAdd}
+
+		if change.Err == nil {
+			change.Err = e.Location.Validate()
+		}
+		if change.Err != nil {
+			change.Action = ActionInvalid
+			changes = append(changes, change)
+			continue
+		}
+
+		if taken[e.Key] {
+			switch strategy {
+			case StrategySkip:
+				change.Action = ActionSkip
+			case StrategyOverwrite:
+				change.Action = ActionOverwrite
+			case StrategyRename:
+				change.Action = ActionRename
+				change.From = e.Key
+				change.Key = freeName(taken, e.Key)
+			}
+		}
+
+		taken[change.Key] = true
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// freeName returns the first of "name-2", "name-3", ... that is not taken
+func freeName(taken map[string]bool, name string) string {
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s-%d", name, i)
+		if !taken[candidate] {
+			return candidate
+		}
+	}
+}
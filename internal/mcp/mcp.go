@@ -0,0 +1,237 @@
+// Package mcp serves weather tools to language models over the Model
+// Context Protocol, as JSON-RPC 2.0 messages on stdin and stdout
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+)
+
+// ProtocolVersions are the versions of the protocol the server speaks,
+// latest first
+var ProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// JSON-RPC error codes
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+)
+
+// maxMessageSize bounds a message, so that a broken client cannot make the
+// server buffer without end
+const maxMessageSize = 4 << 20
+
+// request is a JSON-RPC request, or a notification when it has no ID
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// response is a JSON-RPC response with either a result or an error
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Server answers MCP requests with the weather tools
+type Server struct {
+	// Name and Version identify the server to clients
+	Name    string
+	Version string
+
+	Tools *Tools
+
+	// Log receives protocol errors; it defaults to discarding them
+	Log io.Writer
+
+	writeMu sync.Mutex
+
+	// calls cancels the requests in progress by ID, for
+	// notifications/cancelled
+	mu    sync.Mutex
+	calls map[string]context.CancelFunc
+}
+
+// Serve answers the newline-delimited messages read from r on w until r
+// ends, and waits for the answers in progress. Requests are answered
+// concurrently, so that a slow forecast does not hold up the others;
+// cancelling ctx cancels them.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var req request
+		if err := json.Unmarshal(line, &req); err != nil {
+			s.write(w, response{ID: json.RawMessage("null"), Error: &rpcError{codeParseError, "parse error: " + err.Error()}})
+			continue
+		}
+		if req.JSONRPC != "2.0" || req.Method == "" {
+			if req.ID != nil {
+				s.write(w, response{ID: req.ID, Error: &rpcError{codeInvalidRequest, "invalid request: want jsonrpc 2.0 and a method"}})
+			}
+			continue
+		}
+
+		if req.ID == nil {
+			s.notify(req)
+			continue
+		}
+
+		callCtx, cancelCall := context.WithCancel(ctx)
+		s.track(req.ID, cancelCall)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer s.untrack(req.ID)
+
+			result, err := s.handle(callCtx, req)
+			if err != nil {
+				s.write(w, response{ID: req.ID, Error: err})
+				return
+			}
+			s.write(w, response{ID: req.ID, Result: result})
+		}()
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read message: %w", err)
+	}
+	return nil
+}
+
+// handle answers a request
+func (s *Server) handle(ctx context.Context, req request) (interface{}, *rpcError) {
+	switch req.Method {
+	case "initialize":
+		var params struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		if err := unmarshalParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+		version := ProtocolVersions[0]
+		if slices.Contains(ProtocolVersions, params.ProtocolVersion) {
+			version = params.ProtocolVersion
+		}
+		return map[string]interface{}{
+			"protocolVersion": version,
+			"capabilities": map[string]interface{}{
+				"tools": map[string]bool{"listChanged": false},
+			},
+			"serverInfo": map[string]string{"name": s.Name, "version": s.Version},
+			"instructions": "Weather forecasts from MET Norway. Locations are saved location names, " +
+				"place names or coordinates like \"59.91,10.75\"; leave them out for the default location.",
+		}, nil
+
+	case "ping":
+		return struct{}{}, nil
+
+	case "tools/list":
+		return map[string]interface{}{"tools": s.Tools.list()}, nil
+
+	case "tools/call":
+		var params struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := unmarshalParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+		tool, ok := s.Tools.find(params.Name)
+		if !ok {
+			return nil, &rpcError{codeInvalidParams, fmt.Sprintf("unknown tool '%s'", params.Name)}
+		}
+		return s.Tools.call(ctx, tool, params.Arguments), nil
+	}
+	return nil, &rpcError{codeMethodNotFound, fmt.Sprintf("method '%s' not found", req.Method)}
+}
+
+// notify handles a notification, which gets no response
+func (s *Server) notify(req request) {
+	if req.Method != "notifications/cancelled" {
+		return
+	}
+	var params struct {
+		RequestID json.RawMessage `json:"requestId"`
+	}
+	if json.Unmarshal(req.Params, &params) != nil {
+		return
+	}
+	s.mu.Lock()
+	cancel, ok := s.calls[string(params.RequestID)]
+	s.mu.Unlock()
+	if ok {
+		cancel()
+	}
+}
+
+func (s *Server) track(id json.RawMessage, cancel context.CancelFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.calls == nil {
+		s.calls = make(map[string]context.CancelFunc)
+	}
+	s.calls[string(id)] = cancel
+}
+
+func (s *Server) untrack(id json.RawMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cancel, ok := s.calls[string(id)]; ok {
+		cancel()
+		delete(s.calls, string(id))
+	}
+}
+
+// write sends a response as one line
+func (s *Server) write(w io.Writer, resp response) {
+	resp.JSONRPC = "2.0"
+	data, err := json.Marshal(resp)
+	if err != nil {
+		data, _ = json.Marshal(response{JSONRPC: "2.0", ID: resp.ID, Error: &rpcError{codeInternalError, "failed to encode result: " + err.Error()}})
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if _, err := w.Write(append(data, '\n')); err != nil && s.Log != nil {
+		fmt.Fprintf(s.Log, "failed to write response: %v\n", err)
+	}
+}
+
+// unmarshalParams decodes the params of a request, which may be left out
+func unmarshalParams(raw json.RawMessage, v interface{}) *rpcError {
+	if len(raw) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return &rpcError{codeInvalidParams, "invalid params: " + err.Error()}
+	}
+	return nil
+}
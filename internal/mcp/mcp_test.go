@@ -0,0 +1,463 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/api"
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+var now = time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC)
+
+// fakeClient returns weather whose temperature is the latitude. Requests
+// for a latitude above 80 fail, and those for 0 wait until cancelled.
+type fakeClient struct {
+	api.WeatherClient
+}
+
+func (c *fakeClient) GetCurrentWeather(ctx context.Context, loc *models.Location) (*models.Weather, error) {
+	if loc.Latitude == 0 {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	if loc.Latitude > 80 {
+		return nil, errors.New("upstream unavailable")
+	}
+	return &models.Weather{Location: loc, Timestamp: now, UpdatedAt: now, Temperature: loc.Latitude, Symbol: "cloudy"}, nil
+}
+
+func (c *fakeClient) GetHourlyForecast(ctx context.Context, loc *models.Location, hours int) (*models.Forecast, error) {
+	forecast := &models.Forecast{Location: loc}
+	for i := 0; i < hours; i++ {
+		forecast.Hours = append(forecast.Hours, models.HourlyForecast{
+			Time:        now.Add(time.Duration(i) * time.Hour),
+			Temperature: 10,
+			WindSpeed:   float64(i % 12), // calm at the start of every 12 hours
+		})
+	}
+	return forecast, nil
+}
+
+func (c *fakeClient) GetDailyForecast(ctx context.Context, loc *models.Location, days int) (*models.DailyForecast, error) {
+	daily := &models.DailyForecast{Location: loc}
+	for i := 0; i < days; i++ {
+		daily.Days = append(daily.Days, models.DailySummary{Date: now.AddDate(0, 0, i)})
+	}
+	return daily, nil
+}
+
+var (
+	home   = &models.Location{Name: "Home", Latitude: 59.05, Longitude: 10.03}
+	oslo   = &models.Location{Name: "Oslo", Latitude: 59.91, Longitude: 10.75}
+	arctic = &models.Location{Name: "Arctic", Latitude: 85, Longitude: 10}
+	island = &models.Location{Name: "Null Island"}
+)
+
+func newTools() *Tools {
+	maxWind := 4.0
+	return &Tools{
+		Client: &fakeClient{},
+		Locate: func(name string) (*models.Location, error) {
+			switch strings.ToLower(name) {
+			case "":
+				return home, nil
+			case "oslo":
+				return oslo, nil
+			case "arctic":
+				return arctic, nil
+			case "null island":
+				return island, nil
+			}
+			return nil, fmt.Errorf("location '%s' not found", name)
+		},
+		Locations:       map[string]*models.Location{"home": home, "oslo": oslo},
+		DefaultLocation: "home",
+		Activity: func(name string) (*models.Activity, error) {
+			if name != "running" {
+				return nil, fmt.Errorf("unknown activity '%s'", name)
+			}
+			return &models.Activity{MaxWind: &maxWind}, nil
+		},
+		Activities: []string{"running"},
+		Now:        func() time.Time { return now },
+	}
+}
+
+// client talks to a server over in-memory pipes
+type client struct {
+	t  *testing.T
+	in io.WriteCloser
+
+	mu        sync.Mutex
+	responses map[string]chan map[string]interface{}
+	done      chan error
+}
+
+func startServer(t *testing.T) *client {
+	t.Helper()
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+
+	c := &client{t: t, in: clientOut, responses: make(map[string]chan map[string]interface{}), done: make(chan error, 1)}
+	s := &Server{Name: "sky", Version: "test", Tools: newTools()}
+	go func() {
+		c.done <- s.Serve(context.Background(), serverIn, serverOut)
+		serverOut.Close()
+	}()
+
+	go func() {
+		scanner := bufio.NewScanner(clientIn)
+		for scanner.Scan() {
+			var resp map[string]interface{}
+			if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil {
+				t.Errorf("invalid response %s: %v", scanner.Text(), err)
+				continue
+			}
+			c.response(fmt.Sprint(resp["id"])) <- resp
+		}
+	}()
+
+	t.Cleanup(func() {
+		c.in.Close()
+		select {
+		case err := <-c.done:
+			if err != nil {
+				t.Errorf("Serve = %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Error("Serve did not return after stdin closed")
+		}
+	})
+	return c
+}
+
+func (c *client) response(id string) chan map[string]interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.responses[id] == nil {
+		c.responses[id] = make(chan map[string]interface{}, 1)
+	}
+	return c.responses[id]
+}
+
+func (c *client) send(line string) {
+	c.t.Helper()
+	if _, err := io.WriteString(c.in, line+"\n"); err != nil {
+		c.t.Fatalf("failed to send %s: %v", line, err)
+	}
+}
+
+// wait returns the response with an ID
+func (c *client) wait(id string) map[string]interface{} {
+	c.t.Helper()
+	select {
+	case resp := <-c.response(id):
+		return resp
+	case <-time.After(5 * time.Second):
+		c.t.Fatalf("no response to request %s", id)
+		return nil
+	}
+}
+
+// call sends a request and returns its result
+func (c *client) call(id int, method string, params interface{}) map[string]interface{} {
+	c.t.Helper()
+	data, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": id, "method": method, "params": params})
+	c.send(string(data))
+	resp := c.wait(fmt.Sprint(id))
+	result, ok := resp["result"].(map[string]interface{})
+	if !ok {
+		c.t.Fatalf("%s = %v; want a result", method, resp)
+	}
+	return result
+}
+
+// callTool calls a tool and returns its structured content, or the JSON
+// error in the text of a failed call. Structured content is checked
+// against the tool's output schema.
+func (c *client) callTool(id int, name string, args map[string]interface{}) (map[string]interface{}, bool) {
+	c.t.Helper()
+	result := c.call(id, "tools/call", map[string]interface{}{"name": name, "arguments": args})
+	text, _ := result["content"].([]interface{})[0].(map[string]interface{})["text"].(string)
+	if text == "" {
+		c.t.Errorf("%s returned no text content", name)
+	}
+
+	if structured, ok := result["structuredContent"]; ok {
+		if err := validate(outputSchema(c.t, name), structured, "structuredContent"); err != nil {
+			c.t.Errorf("%s %v: %v", name, args, err)
+		}
+	}
+
+	if result["isError"] == true {
+		var toolErr map[string]interface{}
+		if err := json.Unmarshal([]byte(text), &toolErr); err != nil {
+			c.t.Errorf("%s returned the error %q; want JSON", name, text)
+		}
+		return toolErr, true
+	}
+	content, _ := result["structuredContent"].(map[string]interface{})
+	return content, false
+}
+
+// outputSchema returns the output schema of a tool as a client decodes it
+func outputSchema(t *testing.T, name string) map[string]interface{} {
+	t.Helper()
+	tl, ok := newTools().find(name)
+	if !ok {
+		t.Fatalf("unknown tool %s", name)
+	}
+	data, _ := json.Marshal(tl.OutputSchema)
+	var schema map[string]interface{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("invalid schema of %s: %v", name, err)
+	}
+	return schema
+}
+
+// validate checks a decoded JSON value against the parts of JSON Schema
+// that schemaOf uses
+func validate(schema map[string]interface{}, value interface{}, path string) error {
+	switch schema["type"] {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s is %T; want an object", path, value)
+		}
+		required, _ := schema["required"].([]interface{})
+		for _, name := range required {
+			if _, ok := object[name.(string)]; !ok {
+				return fmt.Errorf("%s lacks the required %s", path, name)
+			}
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		additional, _ := schema["additionalProperties"].(map[string]interface{})
+		for name, v := range object {
+			property, ok := properties[name].(map[string]interface{})
+			if !ok {
+				property = additional
+			}
+			if property == nil {
+				if properties != nil {
+					return fmt.Errorf("%s has the unknown property %s", path, name)
+				}
+				continue
+			}
+			if err := validate(property, v, path+"."+name); err != nil {
+				return err
+			}
+		}
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s is %T; want an array", path, value)
+		}
+		items, _ := schema["items"].(map[string]interface{})
+		for i, v := range array {
+			if err := validate(items, v, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("%s is %T; want a string", path, value)
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return fmt.Errorf("%s is %T; want a number", path, value)
+		}
+	case "integer":
+		if n, ok := value.(float64); !ok || n != float64(int64(n)) {
+			return fmt.Errorf("%s is %v; want an integer", path, value)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s is %T; want a boolean", path, value)
+		}
+	}
+	return nil
+}
+
+func TestInitialize(t *testing.T) {
+	c := startServer(t)
+
+	result := c.call(1, "initialize", map[string]interface{}{
+		"protocolVersion": "2025-03-26",
+		"clientInfo":      map[string]string{"name": "test", "version": "1"},
+	})
+	if result["protocolVersion"] != "2025-03-26" {
+		t.Errorf("protocolVersion = %v; want the client's", result["protocolVersion"])
+	}
+	if info := result["serverInfo"].(map[string]interface{}); info["name"] != "sky" || info["version"] != "test" {
+		t.Errorf("serverInfo = %v; want sky test", info)
+	}
+	if _, ok := result["capabilities"].(map[string]interface{})["tools"]; !ok {
+		t.Errorf("capabilities = %v; want tools", result["capabilities"])
+	}
+
+	result = c.call(2, "initialize", map[string]interface{}{"protocolVersion": "1999-01-01"})
+	if result["protocolVersion"] != ProtocolVersions[0] {
+		t.Errorf("protocolVersion for an unknown version = %v; want %s", result["protocolVersion"], ProtocolVersions[0])
+	}
+
+	// Notifications get no response, so the ping is answered first
+	c.send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	c.call(3, "ping", nil)
+}
+
+func TestProtocolErrors(t *testing.T) {
+	c := startServer(t)
+
+	tests := []struct {
+		line string
+		id   string
+		code float64
+	}{
+		{`{not json`, "<nil>", codeParseError},
+		{`{"jsonrpc":"1.0","id":1,"method":"ping"}`, "1", codeInvalidRequest},
+		{`{"jsonrpc":"2.0","id":2,"method":"resources/list"}`, "2", codeMethodNotFound},
+		{`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"get_tide"}}`, "3", codeInvalidParams},
+		{`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":"oslo"}`, "4", codeInvalidParams},
+		{`{"jsonrpc":"2.0","id":"five","method":"nothing"}`, "five", codeMethodNotFound},
+	}
+	for _, tt := range tests {
+		c.send(tt.line)
+		resp := c.wait(tt.id)
+		rpcErr, ok := resp["error"].(map[string]interface{})
+		if !ok || rpcErr["code"] != tt.code || resp["jsonrpc"] != "2.0" {
+			t.Errorf("%s = %v; want error %v", tt.line, resp, tt.code)
+		}
+	}
+}
+
+func TestToolsList(t *testing.T) {
+	c := startServer(t)
+
+	result := c.call(1, "tools/list", nil)
+	tools := map[string]map[string]interface{}{}
+	for _, tl := range result["tools"].([]interface{}) {
+		tl := tl.(map[string]interface{})
+		tools[tl["name"].(string)] = tl
+	}
+	for _, name := range []string{"get_current_weather", "get_hourly_forecast", "get_daily_forecast", "list_locations", "plan_activity"} {
+		tl, ok := tools[name]
+		if !ok {
+			t.Errorf("tools/list lacks %s", name)
+			continue
+		}
+		if tl["description"] == "" || tl["inputSchema"].(map[string]interface{})["type"] != "object" ||
+			tl["outputSchema"].(map[string]interface{})["type"] != "object" {
+			t.Errorf("%s = %v; want a description and object schemas", name, tl)
+		}
+	}
+
+	// The schemas follow the models
+	hours := tools["get_hourly_forecast"]["inputSchema"].(map[string]interface{})["properties"].(map[string]interface{})["hours"].(map[string]interface{})
+	if hours["type"] != "integer" || hours["minimum"] != 1.0 || hours["maximum"] != float64(MaxHours) {
+		t.Errorf("hours schema = %v; want an integer from 1 to %d", hours, MaxHours)
+	}
+	if _, ok := tools["get_hourly_forecast"]["inputSchema"].(map[string]interface{})["properties"].(map[string]interface{})["location"]; !ok {
+		t.Error("get_hourly_forecast takes no location; want the embedded location argument")
+	}
+	plan := tools["plan_activity"]["inputSchema"].(map[string]interface{})
+	if required := fmt.Sprint(plan["required"]); required != "[activity]" {
+		t.Errorf("plan_activity requires %s; want [activity]", required)
+	}
+	weather := tools["get_current_weather"]["outputSchema"].(map[string]interface{})["properties"].(map[string]interface{})
+	if weather["feels_like"].(map[string]interface{})["type"] != "number" ||
+		weather["location"].(map[string]interface{})["properties"].(map[string]interface{})["latitude"] == nil {
+		t.Errorf("current weather schema = %v; want the JSON weather", weather)
+	}
+}
+
+func TestToolsCall(t *testing.T) {
+	c := startServer(t)
+
+	weather, isError := c.callTool(1, "get_current_weather", map[string]interface{}{"location": "oslo"})
+	if isError || weather["temperature"] != 59.91 || weather["location"].(map[string]interface{})["name"] != "Oslo" {
+		t.Errorf("get_current_weather = %v; want the weather in Oslo", weather)
+	}
+
+	forecast, _ := c.callTool(2, "get_hourly_forecast", map[string]interface{}{"hours": 24})
+	if hours := forecast["hours"].([]interface{}); len(hours) != 24 {
+		t.Errorf("get_hourly_forecast = %d hours; want 24", len(hours))
+	}
+
+	daily, _ := c.callTool(3, "get_daily_forecast", nil)
+	if days := daily["days"].([]interface{}); len(days) != DefaultDays {
+		t.Errorf("get_daily_forecast = %d days; want %d", len(days), DefaultDays)
+	}
+
+	saved, _ := c.callTool(4, "list_locations", nil)
+	locations := saved["locations"].([]interface{})
+	if saved["default"] != "home" || len(locations) != 2 || locations[0].(map[string]interface{})["key"] != "home" {
+		t.Errorf("list_locations = %v; want home and oslo", saved)
+	}
+
+	plan, isError := c.callTool(5, "plan_activity", map[string]interface{}{"activity": "Running", "duration_minutes": 120, "within_hours": 24, "top": 2})
+	if isError {
+		t.Fatalf("plan_activity failed: %v", plan)
+	}
+	windows := plan["windows"].([]interface{})
+	if plan["activity"] != "running" || plan["duration_minutes"] != 120.0 || len(windows) != 2 {
+		t.Errorf("plan_activity = %v; want 2 two-hour running windows", plan)
+	}
+	if start := windows[0].(map[string]interface{})["start"]; start != now.Format(time.RFC3339) {
+		t.Errorf("best window starts %v; want the calm hours from now", start)
+	}
+}
+
+func TestToolErrors(t *testing.T) {
+	c := startServer(t)
+
+	tests := []struct {
+		tool string
+		args map[string]interface{}
+		code string
+	}{
+		{"get_current_weather", map[string]interface{}{"location": "atlantis"}, ErrLocationNotFound},
+		{"get_current_weather", map[string]interface{}{"location": "arctic"}, ErrUpstream},
+		{"get_current_weather", map[string]interface{}{"place": "oslo"}, ErrInvalidArguments},
+		{"get_hourly_forecast", map[string]interface{}{"hours": 500}, ErrInvalidArguments},
+		{"get_daily_forecast", map[string]interface{}{"days": "three"}, ErrInvalidArguments},
+		{"plan_activity", map[string]interface{}{"activity": "skydiving"}, ErrUnknownActivity},
+		{"plan_activity", map[string]interface{}{}, ErrInvalidArguments},
+		{"plan_activity", map[string]interface{}{"activity": "running", "duration_minutes": 180, "within_hours": 2}, ErrInvalidArguments},
+	}
+	for i, tt := range tests {
+		content, isError := c.callTool(i+1, tt.tool, tt.args)
+		toolErr, _ := content["error"].(map[string]interface{})
+		if !isError || toolErr["code"] != tt.code || toolErr["message"] == "" {
+			t.Errorf("%s %v = %v; want a %s error", tt.tool, tt.args, content, tt.code)
+		}
+	}
+
+	// callTool checks structured content against the output schema, which
+	// an error object would not match
+	if err := validate(outputSchema(t, "get_current_weather"), map[string]interface{}{"error": map[string]interface{}{}}, "error"); err == nil {
+		t.Error("validate() accepted an error object as current weather")
+	}
+}
+
+func TestCancel(t *testing.T) {
+	c := startServer(t)
+
+	c.send(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"get_current_weather","arguments":{"location":"null island"}}}`)
+	c.call(2, "ping", nil) // the slow call does not hold up others
+	c.send(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":1}}`)
+
+	resp := c.wait("1")
+	result := resp["result"].(map[string]interface{})
+	if result["isError"] != true || !strings.Contains(fmt.Sprint(result["content"]), "context canceled") {
+		t.Errorf("cancelled call = %v; want a cancelled error", resp)
+	}
+}
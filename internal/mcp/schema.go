@@ -0,0 +1,95 @@
+package mcp
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema is a JSON Schema
+type Schema map[string]interface{}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaOf derives the JSON Schema of the JSON encoding of v, so that the
+// schemas of the tools follow the models and formatters they return.
+// Struct fields are required unless they are omitempty, and may describe
+// themselves with description, minimum and maximum tags.
+func schemaOf(v interface{}) Schema {
+	return schemaOfType(reflect.TypeOf(v))
+}
+
+func schemaOfType(t reflect.Type) Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return Schema{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return Schema{"type": "array", "items": schemaOfType(t.Elem())}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": schemaOfType(t.Elem())}
+	case reflect.Struct:
+		return structSchema(t)
+	}
+	return Schema{}
+}
+
+func structSchema(t reflect.Type) Schema {
+	properties := Schema{}
+	required := []string{}
+	addFields(t, properties, &required)
+
+	schema := Schema{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// addFields adds the fields of a struct to properties, and those of
+// embedded structs as if they were its own, like encoding/json does
+func addFields(t reflect.Type, properties Schema, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			addFields(field.Type, properties, required)
+			continue
+		}
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := schemaOfType(field.Type)
+		if description := field.Tag.Get("description"); description != "" {
+			property["description"] = description
+		}
+		for _, limit := range []string{"minimum", "maximum"} {
+			if value, err := strconv.ParseFloat(field.Tag.Get(limit), 64); err == nil {
+				property[limit] = value
+			}
+		}
+		properties[name] = property
+
+		if !strings.Contains(opts, "omitempty") {
+			*required = append(*required, name)
+		}
+	}
+}
@@ -0,0 +1,369 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/api"
+	"github.com/kristofferrisa/sky-cli/internal/formatter"
+	"github.com/kristofferrisa/sky-cli/internal/models"
+	"github.com/kristofferrisa/sky-cli/internal/planner"
+)
+
+// Limits of the arguments of the tools
+const (
+	DefaultHours = 12
+	MaxHours     = 240
+	DefaultDays  = 7
+	MaxDays      = 10
+)
+
+// callTimeout bounds a tool call
+const callTimeout = 30 * time.Second
+
+// Codes of the errors tools return
+const (
+	ErrInvalidArguments = "invalid_arguments"
+	ErrLocationNotFound = "location_not_found"
+	ErrUnknownActivity  = "unknown_activity"
+	ErrUpstream         = "upstream_error"
+)
+
+// ToolError is the structured error a tool returns, so that a model can
+// tell a typo in a place name from MET Norway being down
+type ToolError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *ToolError) Error() string {
+	return e.Message
+}
+
+func toolErrorf(code, format string, args ...interface{}) *ToolError {
+	return &ToolError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Tools are the weather tools and what they need to answer
+type Tools struct {
+	Client api.WeatherClient
+
+	// Locate finds a location by name, such as a saved location, a place
+	// or coordinates like "59.05,10.03". An empty name asks for the
+	// default location.
+	Locate func(name string) (*models.Location, error)
+
+	// Locations are the saved locations by name, and DefaultLocation the
+	// name of the default one
+	Locations       map[string]*models.Location
+	DefaultLocation string
+
+	// Activity returns the limits of a named activity, one of Activities
+	Activity   func(name string) (*models.Activity, error)
+	Activities []string
+
+	// Now returns the current time; it defaults to time.Now
+	Now func() time.Time
+}
+
+// tool is a tool a client can call
+type tool struct {
+	Name         string `json:"name"`
+	Title        string `json:"title"`
+	Description  string `json:"description"`
+	InputSchema  Schema `json:"inputSchema"`
+	OutputSchema Schema `json:"outputSchema"`
+
+	run func(ctx context.Context, args json.RawMessage) (interface{}, error)
+}
+
+// locationArgs are the arguments of the tools that take a location
+type locationArgs struct {
+	Location string `json:"location,omitempty" description:"Saved location, place name or coordinates like \"59.91,10.75\"; the default location when left out"`
+}
+
+type forecastArgs struct {
+	locationArgs
+	Hours int `json:"hours,omitempty" minimum:"1" maximum:"240" description:"Hours ahead, 12 when left out"`
+}
+
+type dailyArgs struct {
+	locationArgs
+	Days int `json:"days,omitempty" minimum:"1" maximum:"10" description:"Days ahead, 7 when left out"`
+}
+
+type planArgs struct {
+	locationArgs
+	Activity        string `json:"activity" description:"Activity to plan, such as running, cycling, hiking, painting or sailing"`
+	DurationMinutes int    `json:"duration_minutes,omitempty" minimum:"1" maximum:"1440" description:"Length of the activity in minutes, 60 when left out"`
+	WithinHours     int    `json:"within_hours,omitempty" minimum:"1" maximum:"240" description:"How many hours ahead to look, 48 when left out"`
+	Top             int    `json:"top,omitempty" minimum:"1" maximum:"10" description:"Number of windows to return, 3 when left out"`
+}
+
+// SavedLocations lists the saved locations
+type SavedLocations struct {
+	Default   string          `json:"default,omitempty"`
+	Locations []SavedLocation `json:"locations"`
+}
+
+// SavedLocation is a saved location with the name it is saved under
+type SavedLocation struct {
+	Key      string           `json:"key"`
+	Location *models.Location `json:"location"`
+}
+
+// list returns the tools, in the order clients show them
+func (t *Tools) list() []tool {
+	return []tool{
+		{
+			Name:        "get_current_weather",
+			Title:       "Current weather",
+			Description: "Current weather at a location: temperature, feels-like temperature, humidity, pressure, wind, cloud cover and precipitation in the next hour.",
+			InputSchema: schemaOf(locationArgs{}), OutputSchema: schemaOf(formatter.JSONWeather{}),
+			run: t.currentWeather,
+		},
+		{
+			Name:        "get_hourly_forecast",
+			Title:       "Hourly forecast",
+			Description: "Hourly forecast for a location: temperature, humidity, wind, gusts, precipitation and its probability per hour.",
+			InputSchema: schemaOf(forecastArgs{}), OutputSchema: schemaOf(formatter.JSONForecast{}),
+			run: t.hourlyForecast,
+		},
+		{
+			Name:        "get_daily_forecast",
+			Title:       "Daily forecast",
+			Description: "Daily forecast for a location: lowest, highest and average temperature, total precipitation and strongest wind per day.",
+			InputSchema: schemaOf(dailyArgs{}), OutputSchema: schemaOf(formatter.JSONDailyForecast{}),
+			run: t.dailyForecast,
+		},
+		{
+			Name:        "list_locations",
+			Title:       "Saved locations",
+			Description: "The locations the user has saved, with the name to pass as location and the default location.",
+			InputSchema: schemaOf(struct{}{}), OutputSchema: schemaOf(SavedLocations{}),
+			run: t.listLocations,
+		},
+		{
+			Name:  "plan_activity",
+			Title: "Best time for an activity",
+			Description: "The best times for an outdoor activity in the hourly forecast, scored from 0 to 100 against the activity's limits on temperature, wind, precipitation, humidity and daylight, with the reasons for each score. " +
+				"Activities: " + strings.Join(t.Activities, ", ") + ".",
+			InputSchema: schemaOf(planArgs{}), OutputSchema: schemaOf(formatter.JSONPlan{}),
+			run: t.planActivity,
+		},
+	}
+}
+
+// find returns the tool with a name
+func (t *Tools) find(name string) (tool, bool) {
+	for _, tl := range t.list() {
+		if tl.Name == name {
+			return tl, true
+		}
+	}
+	return tool{}, false
+}
+
+// call runs a tool. Its errors are results, flagged with isError, so that
+// the model sees them and can correct itself. Errors are sent as JSON text
+// only: structured content must match the tool's output schema.
+func (t *Tools) call(ctx context.Context, tl tool, args json.RawMessage) map[string]interface{} {
+	ctx, cancel := context.WithTimeout(ctx, callTimeout)
+	defer cancel()
+
+	result, err := tl.run(ctx, args)
+	if err != nil {
+		toolErr, ok := err.(*ToolError)
+		if !ok {
+			toolErr = &ToolError{Code: ErrUpstream, Message: err.Error()}
+		}
+		text, _ := json.Marshal(map[string]interface{}{"error": toolErr})
+		return map[string]interface{}{
+			"content": []map[string]string{{"type": "text", "text": string(text)}},
+			"isError": true,
+		}
+	}
+
+	text, _ := json.Marshal(result)
+	return map[string]interface{}{
+		"content":           []map[string]string{{"type": "text", "text": string(text)}},
+		"structuredContent": result,
+		"isError":           false,
+	}
+}
+
+func (t *Tools) currentWeather(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+	var args locationArgs
+	if err := decodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	loc, err := t.locate(args.Location)
+	if err != nil {
+		return nil, err
+	}
+	weather, err := t.Client.GetCurrentWeather(ctx, loc)
+	if err != nil {
+		return nil, upstreamError(loc, err)
+	}
+	return formatter.NewJSONWeather(weather), nil
+}
+
+func (t *Tools) hourlyForecast(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+	var args forecastArgs
+	if err := decodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	hours, err := limit("hours", args.Hours, DefaultHours, MaxHours)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := t.locate(args.Location)
+	if err != nil {
+		return nil, err
+	}
+	forecast, err := t.Client.GetHourlyForecast(ctx, loc, hours)
+	if err != nil {
+		return nil, upstreamError(loc, err)
+	}
+	return formatter.NewJSONForecast(forecast), nil
+}
+
+func (t *Tools) dailyForecast(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+	var args dailyArgs
+	if err := decodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	days, err := limit("days", args.Days, DefaultDays, MaxDays)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := t.locate(args.Location)
+	if err != nil {
+		return nil, err
+	}
+	daily, err := t.Client.GetDailyForecast(ctx, loc, days)
+	if err != nil {
+		return nil, upstreamError(loc, err)
+	}
+	return formatter.NewJSONDailyForecast(daily), nil
+}
+
+func (t *Tools) listLocations(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+	var args struct{}
+	if err := decodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(t.Locations))
+	for key := range t.Locations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	saved := SavedLocations{Default: t.DefaultLocation, Locations: make([]SavedLocation, len(keys))}
+	for i, key := range keys {
+		saved.Locations[i] = SavedLocation{Key: key, Location: t.Locations[key]}
+	}
+	return saved, nil
+}
+
+func (t *Tools) planActivity(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+	var args planArgs
+	if err := decodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+
+	name := strings.ToLower(strings.TrimSpace(args.Activity))
+	if name == "" {
+		return nil, toolErrorf(ErrInvalidArguments, "activity is required (available: %s)", strings.Join(t.Activities, ", "))
+	}
+	activity, err := t.Activity(name)
+	if err != nil {
+		return nil, toolErrorf(ErrUnknownActivity, "%v (available: %s)", err, strings.Join(t.Activities, ", "))
+	}
+
+	minutes, err := limit("duration_minutes", args.DurationMinutes, 60, 24*60)
+	if err != nil {
+		return nil, err
+	}
+	withinHours, err := limit("within_hours", args.WithinHours, 48, MaxHours)
+	if err != nil {
+		return nil, err
+	}
+	top, err := limit("top", args.Top, 3, 10)
+	if err != nil {
+		return nil, err
+	}
+	duration := time.Duration(minutes) * time.Minute
+	within := time.Duration(withinHours) * time.Hour
+	if within < duration {
+		return nil, toolErrorf(ErrInvalidArguments, "within_hours must cover duration_minutes")
+	}
+
+	loc, err := t.locate(args.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	// The current hour is included, since the first window starts at the
+	// next full hour
+	forecast, err := t.Client.GetHourlyForecast(ctx, loc, int(math.Ceil(within.Hours()))+1)
+	if err != nil {
+		return nil, upstreamError(loc, err)
+	}
+
+	now := time.Now()
+	if t.Now != nil {
+		now = t.Now()
+	}
+	return formatter.NewJSONPlan(&models.Plan{
+		Location: loc,
+		Activity: name,
+		Duration: duration,
+		Windows:  planner.Rank(forecast, activity, duration, now, now.Add(within), top),
+	}), nil
+}
+
+// locate finds the location a tool is asked about
+func (t *Tools) locate(name string) (*models.Location, error) {
+	loc, err := t.Locate(strings.TrimSpace(name))
+	if err != nil {
+		return nil, &ToolError{Code: ErrLocationNotFound, Message: err.Error()}
+	}
+	return loc, nil
+}
+
+// decodeArgs decodes the arguments of a tool call, rejecting arguments the
+// tool does not take
+func decodeArgs(raw json.RawMessage, v interface{}) error {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return toolErrorf(ErrInvalidArguments, "invalid arguments: %v", err)
+	}
+	return nil
+}
+
+// limit returns n, or def when n is left out, if it is from 1 to max
+func limit(name string, n, def, max int) (int, error) {
+	if n == 0 {
+		return def, nil
+	}
+	if n < 1 || n > max {
+		return 0, toolErrorf(ErrInvalidArguments, "%s must be a number from 1 to %d", name, max)
+	}
+	return n, nil
+}
+
+func upstreamError(loc *models.Location, err error) error {
+	return toolErrorf(ErrUpstream, "failed to fetch weather for %s: %v", loc, err)
+}
@@ -0,0 +1,199 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/api"
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+// ForecastHours is how many hours of forecast a scrape reads, enough to
+// cover the longest horizon
+const ForecastHours = 25
+
+// scrapeTimeout bounds the requests of one scrape
+const scrapeTimeout = 30 * time.Second
+
+// maxParallel is how many locations a scrape fetches at once
+const maxParallel = 4
+
+// horizons are how far ahead the forecast gauges look
+var horizons = []struct {
+	label string
+	ahead time.Duration
+}{
+	{"1h", time.Hour},
+	{"6h", 6 * time.Hour},
+	{"24h", 24 * time.Hour},
+}
+
+// Exporter serves the weather at saved locations as Prometheus metrics.
+// Give it a cached client, so that scrapes read the cache and only reach
+// MET Norway when the cached data expires.
+type Exporter struct {
+	Client api.WeatherClient
+
+	// Locations are the locations to report, by the name they are
+	// labeled with
+	Locations map[string]*models.Location
+
+	// Metrics are the upstream and cache metrics to report, if any
+	Metrics *Metrics
+
+	// Now returns the current time; it defaults to time.Now
+	Now func() time.Time
+
+	// mu runs one scrape at a time, so that overlapping scrapes find the
+	// data the first one cached instead of fetching it again
+	mu sync.Mutex
+}
+
+// observation is the weather at a location when it was scraped
+type observation struct {
+	name     string
+	weather  *models.Weather
+	forecast *models.Forecast
+	err      error
+}
+
+// ServeHTTP answers a scrape
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(r.Context(), scrapeTimeout)
+	defer cancel()
+	observations := e.observe(ctx)
+
+	var buf bytes.Buffer
+	e.write(&promWriter{w: &buf}, observations)
+
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// observe fetches the current weather and forecast at every location,
+// in the order of their names
+func (e *Exporter) observe(ctx context.Context) []observation {
+	names := make([]string, 0, len(e.Locations))
+	for name := range e.Locations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	observations := make([]observation, len(names))
+	sem := make(chan struct{}, maxParallel)
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(o *observation, loc *models.Location) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if o.weather, o.err = e.Client.GetCurrentWeather(ctx, loc); o.err != nil {
+				return
+			}
+			o.forecast, o.err = e.Client.GetHourlyForecast(ctx, loc, ForecastHours)
+		}(&observations[i], e.Locations[name])
+		observations[i].name = name
+	}
+	wg.Wait()
+	return observations
+}
+
+// write writes the metrics of a scrape
+func (e *Exporter) write(p *promWriter, observations []observation) {
+	now := time.Now()
+	if e.Now != nil {
+		now = e.Now()
+	}
+
+	p.family("sky_location_up", "gauge", "Whether the weather at the location could be read (1) or not (0).")
+	for _, o := range observations {
+		up := 1.0
+		if o.err != nil {
+			up = 0
+		}
+		p.sample("sky_location_up", up, label{"location", o.name})
+	}
+
+	current := []struct {
+		name, help string
+		value      func(*models.Weather) float64
+	}{
+		{"sky_temperature_celsius", "Air temperature.", func(w *models.Weather) float64 { return w.Temperature }},
+		{"sky_feels_like_celsius", "Apparent temperature, with wind chill or heat index.", func(w *models.Weather) float64 { return w.FeelsLike() }},
+		{"sky_humidity_percent", "Relative humidity.", func(w *models.Weather) float64 { return w.Humidity }},
+		{"sky_pressure_hpa", "Air pressure at sea level.", func(w *models.Weather) float64 { return w.Pressure }},
+		{"sky_wind_speed_meters_per_second", "Wind speed.", func(w *models.Weather) float64 { return w.WindSpeed }},
+		{"sky_wind_direction_degrees", "Direction the wind blows from, clockwise from north.", func(w *models.Weather) float64 { return w.WindDir }},
+		{"sky_cloud_cover_percent", "Cloud cover.", func(w *models.Weather) float64 { return w.CloudCover }},
+		{"sky_precipitation_millimeters", "Precipitation expected in the next hour.", func(w *models.Weather) float64 { return w.Precipitation }},
+		{"sky_weather_updated_timestamp_seconds", "When MET Norway last updated the forecast the weather comes from.", func(w *models.Weather) float64 { return float64(w.UpdatedAt.Unix()) }},
+	}
+	for _, metric := range current {
+		p.family(metric.name, "gauge", metric.help)
+		for _, o := range observations {
+			if o.err == nil {
+				p.sample(metric.name, metric.value(o.weather), label{"location", o.name})
+			}
+		}
+	}
+
+	forecast := []struct {
+		name, help string
+		value      func(*models.HourlyForecast) float64
+	}{
+		{"sky_forecast_temperature_celsius", "Forecast air temperature.", func(h *models.HourlyForecast) float64 { return h.Temperature }},
+		{"sky_forecast_wind_speed_meters_per_second", "Forecast wind speed.", func(h *models.HourlyForecast) float64 { return h.WindSpeed }},
+		{"sky_forecast_precipitation_millimeters", "Forecast precipitation in the hour.", func(h *models.HourlyForecast) float64 { return h.Precipitation }},
+		{"sky_forecast_precipitation_probability_percent", "Forecast chance of precipitation in the hour.", func(h *models.HourlyForecast) float64 { return h.PrecipitationProbability }},
+	}
+	for _, metric := range forecast {
+		p.family(metric.name, "gauge", metric.help+" The horizon label is how far ahead.")
+		for _, o := range observations {
+			if o.err != nil {
+				continue
+			}
+			for _, horizon := range horizons {
+				if hour := o.forecast.At(now.Add(horizon.ahead)); hour != nil {
+					p.sample(metric.name, metric.value(hour), label{"location", o.name}, label{"horizon", horizon.label})
+				}
+			}
+		}
+	}
+
+	if e.Metrics != nil {
+		e.Metrics.write(p)
+	}
+}
+
+// write writes the upstream and cache metrics
+func (m *Metrics) write(p *promWriter) {
+	m.mu.Lock()
+	buckets := append([]uint64(nil), m.buckets...)
+	sum, count := m.sum, m.count
+	m.mu.Unlock()
+
+	p.family("sky_upstream_request_duration_seconds", "histogram", "Duration of requests to MET Norway.")
+	p.histogram("sky_upstream_request_duration_seconds", latencyBuckets, buckets, sum, count)
+
+	p.family("sky_upstream_errors_total", "counter", "Requests to MET Norway that failed or did not return 200 OK.")
+	p.sample("sky_upstream_errors_total", float64(m.errors.Load()))
+
+	p.family("sky_cache_hits_total", "counter", "Cache lookups that found fresh weather data.")
+	p.sample("sky_cache_hits_total", float64(m.hits.Load()))
+
+	p.family("sky_cache_misses_total", "counter", "Cache lookups that found no fresh weather data.")
+	p.sample("sky_cache_misses_total", float64(m.misses.Load()))
+
+	p.family("sky_cache_hit_ratio", "gauge", "Share of cache lookups that found fresh weather data.")
+	p.sample("sky_cache_hit_ratio", m.CacheHitRatio())
+}
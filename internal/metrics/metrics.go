@@ -0,0 +1,104 @@
+// Package metrics exposes the weather at saved locations and the health of
+// sky itself as Prometheus metrics
+package metrics
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/cache"
+)
+
+// latencyBuckets are the upper bounds of the upstream latency histogram,
+// in seconds
+var latencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Metrics counts upstream requests and cache lookups. Wrap the transport
+// of the API client with Transport and its cache with Cache.
+type Metrics struct {
+	mu      sync.Mutex
+	buckets []uint64 // requests per latency bucket, the last one +Inf
+	sum     float64  // seconds spent on requests
+	count   uint64
+
+	errors atomic.Uint64
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// New creates empty metrics
+func New() *Metrics {
+	return &Metrics{buckets: make([]uint64, len(latencyBuckets)+1)}
+}
+
+// observe records an upstream request that took d
+func (m *Metrics) observe(d time.Duration) {
+	seconds := d.Seconds()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i := 0
+	for i < len(latencyBuckets) && seconds > latencyBuckets[i] {
+		i++
+	}
+	m.buckets[i]++
+	m.sum += seconds
+	m.count++
+}
+
+// CacheHitRatio returns the share of cache lookups that found fresh
+// data, or 0 before the first lookup
+func (m *Metrics) CacheHitRatio() float64 {
+	hits, misses := m.hits.Load(), m.misses.Load()
+	if hits+misses == 0 {
+		return 0
+	}
+	return float64(hits) / float64(hits+misses)
+}
+
+// Transport wraps base, or http.DefaultTransport when nil, to time every
+// request and count the ones that fail
+func (m *Metrics) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{base: base, metrics: m}
+}
+
+type transport struct {
+	base    http.RoundTripper
+	metrics *Metrics
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	t.metrics.observe(time.Since(start))
+
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.metrics.errors.Add(1)
+	}
+	return resp, err
+}
+
+// Cache wraps c to count hits and misses
+func (m *Metrics) Cache(c cache.Cache) cache.Cache {
+	return &countingCache{Cache: c, metrics: m}
+}
+
+type countingCache struct {
+	cache.Cache
+	metrics *Metrics
+}
+
+func (c *countingCache) Get(key string) ([]byte, error) {
+	value, err := c.Cache.Get(key)
+	if err != nil {
+		c.metrics.misses.Add(1)
+	} else {
+		c.metrics.hits.Add(1)
+	}
+	return value, err
+}
@@ -0,0 +1,163 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/api/met"
+	"github.com/kristofferrisa/sky-cli/internal/cache"
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+var start = time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC)
+
+// fakeMET answers forecast requests like MET Norway, with a temperature
+// that is the hour of the forecast. Latitudes above 80 fail.
+type fakeMET struct {
+	mu       sync.Mutex
+	requests int
+}
+
+func (f *fakeMET) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.mu.Lock()
+	f.requests++
+	f.mu.Unlock()
+
+	var lat float64
+	fmt.Sscanf(req.URL.Query().Get("lat"), "%f", &lat)
+	if lat > 80 {
+		return &http.Response{StatusCode: http.StatusInternalServerError, Body: io.NopCloser(strings.NewReader("down")), Request: req}, nil
+	}
+
+	resp := met.Response{Properties: met.Properties{Meta: met.Meta{UpdatedAt: start}}}
+	for i := 0; i < 30; i++ {
+		ts := met.Timeseries{Time: start.Add(time.Duration(i) * time.Hour)}
+		ts.Data.Instant.Details = met.InstantDetails{
+			AirTemperature:        float64(i),
+			AirPressureAtSeaLevel: 1013,
+			RelativeHumidity:      80,
+			WindSpeed:             4,
+			WindFromDirection:     225,
+			CloudAreaFraction:     50,
+		}
+		probability := 40.0
+		ts.Data.Next1Hours = &met.NextNHours{Details: met.ForecastDetails{PrecipitationAmount: 0.5, ProbabilityOfPrecipitation: &probability}}
+		resp.Properties.Timeseries = append(resp.Properties.Timeseries, ts)
+	}
+	body, _ := json.Marshal(resp)
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(string(body))), Request: req}, nil
+}
+
+func (f *fakeMET) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests
+}
+
+func scrape(t *testing.T, url string) string {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("scrape failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != ContentType {
+		t.Fatalf("scrape = %d %s; want 200 in the text format", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	body, _ := io.ReadAll(resp.Body)
+	return string(body)
+}
+
+func TestExporter(t *testing.T) {
+	upstream := &fakeMET{}
+	m := New()
+	client := met.NewCachedClientWithClient(met.NewClientWithTransport(m.Transport(upstream)), m.Cache(cache.NewMemoryCache()), 10*time.Minute)
+
+	exporter := &Exporter{
+		Client: client,
+		Locations: map[string]*models.Location{
+			"home":     {Name: "Home", Latitude: 59.05, Longitude: 10.03},
+			`"arctic"`: {Name: "Arctic", Latitude: 85, Longitude: 10},
+		},
+		Metrics: m,
+		Now:     func() time.Time { return start.Add(30 * time.Minute) },
+	}
+	ts := httptest.NewServer(exporter)
+	defer ts.Close()
+
+	body := scrape(t, ts.URL)
+	for _, want := range []string{
+		"# TYPE sky_temperature_celsius gauge\n",
+		`sky_location_up{location="home"} 1`,
+		`sky_location_up{location="\"arctic\""} 0`,
+		`sky_temperature_celsius{location="home"} 0`,
+		`sky_pressure_hpa{location="home"} 1013`,
+		`sky_wind_direction_degrees{location="home"} 225`,
+		`sky_precipitation_millimeters{location="home"} 0.5`,
+		`sky_forecast_temperature_celsius{location="home",horizon="1h"} 1`,
+		`sky_forecast_temperature_celsius{location="home",horizon="6h"} 6`,
+		`sky_forecast_temperature_celsius{location="home",horizon="24h"} 24`,
+		`sky_forecast_precipitation_probability_percent{location="home",horizon="24h"} 40`,
+		`sky_upstream_request_duration_seconds_bucket{le="+Inf"} 3`,
+		"sky_upstream_request_duration_seconds_count 3\n",
+		"sky_upstream_errors_total 1\n",
+		"sky_cache_hits_total 0\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("scrape lacks %q:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, `sky_temperature_celsius{location="\"arctic\""}`) {
+		t.Errorf("scrape reports the temperature of a location that failed:\n%s", body)
+	}
+
+	// The second scrape finds home in the cache and only retries the
+	// location that failed
+	before := upstream.count()
+	body = scrape(t, ts.URL)
+	if got := upstream.count() - before; got != 1 {
+		t.Errorf("second scrape made %d upstream requests; want 1", got)
+	}
+	for _, want := range []string{
+		"sky_cache_hits_total 2\n",
+		"sky_cache_misses_total 4\n",
+		"sky_cache_hit_ratio 0.3333333333333333\n",
+		"sky_upstream_errors_total 2\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("second scrape lacks %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	m := New()
+	for _, d := range []time.Duration{10 * time.Millisecond, 300 * time.Millisecond, time.Second, 20 * time.Second} {
+		m.observe(d)
+	}
+
+	var b strings.Builder
+	m.write(&promWriter{w: &b})
+	for _, want := range []string{
+		`sky_upstream_request_duration_seconds_bucket{le="0.05"} 1`,
+		`sky_upstream_request_duration_seconds_bucket{le="0.25"} 1`,
+		`sky_upstream_request_duration_seconds_bucket{le="0.5"} 2`,
+		`sky_upstream_request_duration_seconds_bucket{le="1"} 3`,
+		`sky_upstream_request_duration_seconds_bucket{le="10"} 3`,
+		`sky_upstream_request_duration_seconds_bucket{le="+Inf"} 4`,
+		"sky_upstream_request_duration_seconds_sum 21.31\n",
+		"sky_upstream_request_duration_seconds_count 4\n",
+		"sky_cache_hit_ratio 0\n",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("metrics lack %q:\n%s", want, b.String())
+		}
+	}
+}
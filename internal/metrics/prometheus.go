@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ContentType is the content type of the Prometheus text format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// label is a name and value that tells samples of a metric apart
+type label struct {
+	name, value string
+}
+
+// promWriter writes metrics in the Prometheus text format
+type promWriter struct {
+	w io.Writer
+}
+
+// family starts a metric with its help text and type
+func (p *promWriter) family(name, typ, help string) {
+	fmt.Fprintf(p.w, "# HELP %s %s\n# TYPE %s %s\n", name, escapeHelp(help), name, typ)
+}
+
+// sample writes a value of a metric
+func (p *promWriter) sample(name string, value float64, labels ...label) {
+	fmt.Fprint(p.w, name)
+	if len(labels) > 0 {
+		pairs := make([]string, len(labels))
+		for i, l := range labels {
+			pairs[i] = fmt.Sprintf(`%s="%s"`, l.name, escapeLabel(l.value))
+		}
+		fmt.Fprintf(p.w, "{%s}", strings.Join(pairs, ","))
+	}
+	fmt.Fprintf(p.w, " %s\n", formatValue(value))
+}
+
+// histogram writes the cumulative buckets, sum and count of a histogram
+func (p *promWriter) histogram(name string, bounds []float64, buckets []uint64, sum float64, count uint64) {
+	var cumulative uint64
+	for i, n := range buckets {
+		cumulative += n
+		le := math.Inf(1)
+		if i < len(bounds) {
+			le = bounds[i]
+		}
+		p.sample(name+"_bucket", float64(cumulative), label{"le", formatValue(le)})
+	}
+	p.sample(name+"_sum", sum)
+	p.sample(name+"_count", float64(count))
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
@@ -0,0 +1,71 @@
+package models
+
+import "time"
+
+// Activity describes the conditions an outdoor activity needs. Limits
+// that are not set are not checked.
+type Activity struct {
+	MinTemp          *float64 `yaml:"min_temp,omitempty" json:"min_temp,omitempty" mapstructure:"min_temp"`                            // Celsius
+	MaxTemp          *float64 `yaml:"max_temp,omitempty" json:"max_temp,omitempty" mapstructure:"max_temp"`                            // Celsius
+	MinWind          *float64 `yaml:"min_wind,omitempty" json:"min_wind,omitempty" mapstructure:"min_wind"`                            // m/s
+	MaxWind          *float64 `yaml:"max_wind,omitempty" json:"max_wind,omitempty" mapstructure:"max_wind"`                            // m/s
+	MaxPrecipitation *float64 `yaml:"max_precipitation,omitempty" json:"max_precipitation,omitempty" mapstructure:"max_precipitation"` // mm per hour
+	MaxHumidity      *float64 `yaml:"max_humidity,omitempty" json:"max_humidity,omitempty" mapstructure:"max_humidity"`                // percentage
+	Daylight         *bool    `yaml:"daylight,omitempty" json:"daylight,omitempty" mapstructure:"daylight"`                            // only between sunrise and sunset
+}
+
+// Merge returns a copy of a with the limits set in override replacing
+// its own
+func (a Activity) Merge(override *Activity) *Activity {
+	if override == nil {
+		return &a
+	}
+	if override.MinTemp != nil {
+		a.MinTemp = override.MinTemp
+	}
+	if override.MaxTemp != nil {
+		a.MaxTemp = override.MaxTemp
+	}
+	if override.MinWind != nil {
+		a.MinWind = override.MinWind
+	}
+	if override.MaxWind != nil {
+		a.MaxWind = override.MaxWind
+	}
+	if override.MaxPrecipitation != nil {
+		a.MaxPrecipitation = override.MaxPrecipitation
+	}
+	if override.MaxHumidity != nil {
+		a.MaxHumidity = override.MaxHumidity
+	}
+	if override.Daylight != nil {
+		a.Daylight = override.Daylight
+	}
+	return &a
+}
+
+// NeedsDaylight reports whether the activity is only done in daylight
+func (a *Activity) NeedsDaylight() bool {
+	return a.Daylight != nil && *a.Daylight
+}
+
+// Plan is the best times for an activity at a location
+type Plan struct {
+	Location *Location
+	Activity string
+	Duration time.Duration
+	Windows  []Window // best first
+}
+
+// Window is a period of consecutive forecast hours scored for an activity
+type Window struct {
+	Start time.Time
+	End   time.Time
+	Hours []HourlyForecast
+
+	// Score rates the conditions from 0 (unsuitable) to 100 (ideal)
+	Score float64
+
+	// Reasons explain the score, such as "wind up to 9.1 m/s (max 8)"
+	Reasons []string
+}
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// Rule is a user-defined condition on the forecast, such as
+// "gust > 20" or "temp < 0 within 12h"
+type Rule struct {
+	When    string `yaml:"when" json:"when" mapstructure:"when"`
+	Message string `yaml:"message,omitempty" json:"message,omitempty" mapstructure:"message"`
+}
+
+// Alert is a rule that matched the forecast for a location
+type Alert struct {
+	Rule     string // name of the rule
+	When     string // condition of the rule
+	Message  string
+	Location *Location
+
+	// Start is the start of the first matching forecast period and End
+	// the end of the last one
+	Start time.Time
+	End   time.Time
+
+	// Periods is the number of matching forecast periods
+	Periods int
+
+	// Values are the variables of the condition in the first matching
+	// period, in the order they appear in the rule
+	Values []AlertValue
+}
+
+// AlertValue is the value of a rule variable, such as gust = 21.3 m/s
+type AlertValue struct {
+	Name  string
+	Value float64
+	Unit  string
+}
+
+// Check is the result of checking rules against the forecast
+type Check struct {
+	Time      time.Time
+	Rules     int // number of rules checked
+	Locations []*Location
+	Alerts    []Alert
+}
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// ForecastDiff is how the forecast for a location changed since it was
+// last seen
+type ForecastDiff struct {
+	Location *Location
+
+	// SeenAt is when the current forecast was fetched and PreviousSeenAt
+	// when the one it is compared with was. PreviousSeenAt is zero when
+	// the forecast had not been seen before.
+	SeenAt         time.Time
+	PreviousSeenAt time.Time
+
+	// Hours are the hours whose forecast changed by at least a threshold,
+	// in order
+	Hours []HourChange
+}
+
+// First reports whether the forecast had not been seen before, so there
+// is nothing to compare it with
+func (d *ForecastDiff) First() bool {
+	return d.PreviousSeenAt.IsZero()
+}
+
+// HourChange is an hour whose forecast changed
+type HourChange struct {
+	Time    time.Time
+	Changes []Change
+}
+
+// Change is a forecast variable that changed, such as rain going from
+// 0.4 to 3.2 mm
+type Change struct {
+	Name     string // short name, such as "temp" or "rain"
+	Unit     string
+	Previous float64
+	Current  float64
+}
+
+// Delta returns how much the variable changed
+func (c Change) Delta() float64 {
+	return c.Current - c.Previous
+}
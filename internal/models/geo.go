@@ -0,0 +1,85 @@
+package models
+
+import (
+	"math"
+	"sort"
+)
+
+// earthRadiusKm is the mean radius of the Earth in kilometers
+const earthRadiusKm = 6371.0
+
+// DistanceTo returns the great-circle distance to another location in
+// kilometers, using the haversine formula
+func (l *Location) DistanceTo(other *Location) float64 {
+	lat1, lat2 := radians(l.Latitude), radians(other.Latitude)
+	dLat := lat2 - lat1
+	dLon := radians(other.Longitude - l.Longitude)
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// BearingTo returns the initial bearing towards another location in
+// degrees clockwise from north (0-360)
+func (l *Location) BearingTo(other *Location) float64 {
+	lat1, lat2 := radians(l.Latitude), radians(other.Latitude)
+	dLon := radians(other.Longitude - l.Longitude)
+
+	y := math.Sin(dLon) * math.Cos(lat2)
+	x := math.Cos(lat1)*math.Sin(lat2) - math.Sin(lat1)*math.Cos(lat2)*math.Cos(dLon)
+	return math.Mod(math.Atan2(y, x)*180/math.Pi+360, 360)
+}
+
+// CompassPoint returns the eight-point compass direction of a bearing,
+// such as "NE"
+func CompassPoint(bearing float64) string {
+	points := []string{"N", "NE", "E", "SE", "S", "SW", "W", "NW"}
+	i := int(math.Round(math.Mod(bearing+360, 360)/45)) % len(points)
+	return points[i]
+}
+
+// Neighbor is a location near another, with its distance and direction
+type Neighbor struct {
+	Key      string
+	Location *Location
+
+	// Distance is the great-circle distance in kilometers
+	Distance float64
+
+	// Bearing is the initial bearing from the other location, in degrees
+	// clockwise from north
+	Bearing float64
+}
+
+// Nearest returns the n locations closest to origin, nearest first.
+// Locations at the same distance are ordered by key. With n <= 0 all
+// locations are returned.
+func Nearest(origin *Location, locations map[string]*Location, n int) []Neighbor {
+	neighbors := make([]Neighbor, 0, len(locations))
+	for key, loc := range locations {
+		neighbors = append(neighbors, Neighbor{
+			Key:      key,
+			Location: loc,
+			Distance: origin.DistanceTo(loc),
+			Bearing:  origin.BearingTo(loc),
+		})
+	}
+
+	sort.Slice(neighbors, func(i, j int) bool {
+		if neighbors[i].Distance != neighbors[j].Distance {
+			return neighbors[i].Distance < neighbors[j].Distance
+		}
+		return neighbors[i].Key < neighbors[j].Key
+	})
+
+	if n > 0 && len(neighbors) > n {
+		neighbors = neighbors[:n]
+	}
+	return neighbors
+}
+
+// radians converts degrees to radians
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
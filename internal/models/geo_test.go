@@ -0,0 +1,100 @@
+package models
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDistanceAndBearing(t *testing.T) {
+	oslo := &Location{Latitude: 59.9139, Longitude: 10.7522}
+	bergen := &Location{Latitude: 60.3913, Longitude: 5.3221}
+	accra := &Location{Latitude: 5.556, Longitude: -0.1969}
+	greenwich := &Location{Latitude: 51.4769, Longitude: 0}
+
+	tests := []struct {
+		name     string
+		from, to *Location
+		distance float64
+		bearing  float64
+	}{
+		{"Same place", oslo, oslo, 0, 0},
+		{"Oslo to Bergen", oslo, bergen, 305, 283},
+		{"Bergen to Oslo", bergen, oslo, 305, 98},
+		{"Greenwich to Accra", greenwich, accra, 5106, 181},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.from.DistanceTo(tt.to); math.Abs(got-tt.distance) > 1 {
+				t.Errorf("DistanceTo() = %.1f km; want %.0f km", got, tt.distance)
+			}
+			if tt.distance == 0 {
+				return
+			}
+			if got := tt.from.BearingTo(tt.to); math.Abs(got-tt.bearing) > 1 {
+				t.Errorf("BearingTo() = %.1f°; want %.0f°", got, tt.bearing)
+			}
+		})
+	}
+}
+
+func TestCompassPoint(t *testing.T) {
+	tests := []struct {
+		bearing  float64
+		expected string
+	}{
+		{0, "N"},
+		{22, "N"},
+		{23, "NE"},
+		{90, "E"},
+		{200, "S"},
+		{247, "SW"},
+		{338, "N"},
+		{359.9, "N"},
+		{-45, "NW"},
+	}
+
+	for _, tt := range tests {
+		if got := CompassPoint(tt.bearing); got != tt.expected {
+			t.Errorf("CompassPoint(%v) = %s; want %s", tt.bearing, got, tt.expected)
+		}
+	}
+}
+
+func TestNearest(t *testing.T) {
+	locations := map[string]*Location{
+		"oslo":    {Latitude: 59.9139, Longitude: 10.7522},
+		"bergen":  {Latitude: 60.3913, Longitude: 5.3221},
+		"stavern": {Latitude: 59.0, Longitude: 10.03},
+		"larvik":  {Latitude: 59.05, Longitude: 10.03},
+		"kjelsås": {Latitude: 59.9139, Longitude: 10.7522},
+	}
+	origin := &Location{Latitude: 59.91, Longitude: 10.75}
+
+	tests := []struct {
+		n        int
+		expected []string
+	}{
+		{1, []string{"kjelsås"}},
+		{3, []string{"kjelsås", "oslo", "larvik"}},
+		{0, []string{"kjelsås", "oslo", "larvik", "stavern", "bergen"}},
+		{10, []string{"kjelsås", "oslo", "larvik", "stavern", "bergen"}},
+	}
+
+	for _, tt := range tests {
+		got := Nearest(origin, locations, tt.n)
+		if len(got) != len(tt.expected) {
+			t.Fatalf("Nearest(%d) returned %d locations; want %d", tt.n, len(got), len(tt.expected))
+		}
+		for i, neighbor := range got {
+			if neighbor.Key != tt.expected[i] {
+				t.Errorf("Nearest(%d)[%d] = %s; want %s", tt.n, i, neighbor.Key, tt.expected[i])
+			}
+		}
+	}
+
+	larvik := Nearest(origin, locations, 3)[2]
+	if math.Abs(larvik.Distance-104) > 1 || CompassPoint(larvik.Bearing) != "SW" {
+		t.Errorf("Nearest() larvik = %.1f km %s; want 104 km SW", larvik.Distance, CompassPoint(larvik.Bearing))
+	}
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Snapshot is a forecast as MET Norway issued it, kept in the history
+// archive
+type Snapshot struct {
+	Location  *Location
+	IssuedAt  time.Time // when MET Norway updated the forecast
+	FetchedAt time.Time
+	Hours     []SnapshotHour
+}
+
+// SnapshotHour is the forecast for one time in a snapshot. The first one
+// is the analysis, the closest to an observation a forecast has.
+type SnapshotHour struct {
+	Time                     time.Time
+	Temperature              float64 // Celsius
+	Humidity                 float64 // percentage
+	Pressure                 float64 // hPa
+	CloudCover               float64 // percentage
+	WindSpeed                float64 // m/s
+	WindGust                 float64 // m/s, 0 when not forecast
+	WindDir                  float64 // degrees
+	Precipitation            float64 // mm in the next hour, or the next 6 where the forecast is 6-hourly
+	PrecipitationProbability float64 // percentage, 0 when not forecast
+	Symbol                   string
+}
+
+// Lead returns how long before its time the hour was forecast
+func (s *Snapshot) Lead(hour *SnapshotHour) time.Duration {
+	return hour.Time.Sub(s.IssuedAt)
+}
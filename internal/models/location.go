@@ -1,13 +1,21 @@
 package models
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // Location represents a geographic location
 type Location struct {
-	Name      string  `yaml:"name" json:"name"`
-	Latitude  float64 `yaml:"latitude" json:"latitude"`
-	Longitude float64 `yaml:"longitude" json:"longitude"`
-	Timezone  string  `yaml:"timezone,omitempty" json:"timezone,omitempty"`
+	Name      string   `yaml:"name" json:"name"`
+	Latitude  float64  `yaml:"latitude" json:"latitude"`
+	Longitude float64  `yaml:"longitude" json:"longitude"`
+	Elevation float64  `yaml:"elevation,omitempty" json:"elevation,omitempty"` // meters above sea level
+	Timezone  string   `yaml:"timezone,omitempty" json:"timezone,omitempty"`
+	Tags      []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Notes     string   `yaml:"notes,omitempty" json:"notes,omitempty"`
+	Provider  string   `yaml:"provider,omitempty" json:"provider,omitempty"` // preferred weather provider
+	Format    string   `yaml:"format,omitempty" json:"format,omitempty"`     // preferred output format
 }
 
 // String returns a human-readable string representation
@@ -18,6 +26,16 @@ func (l *Location) String() string {
 	return fmt.Sprintf("%.2f°N, %.2f°E", l.Latitude, l.Longitude)
 }
 
+// HasTag reports whether the location is tagged with tag, ignoring case
+func (l *Location) HasTag(tag string) bool {
+	for _, t := range l.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
 // Validate checks if the location has valid coordinates
 func (l *Location) Validate() error {
 	if l.Latitude < -90 || l.Latitude > 90 {
@@ -26,5 +44,10 @@ func (l *Location) Validate() error {
 	if l.Longitude < -180 || l.Longitude > 180 {
 		return fmt.Errorf("invalid longitude: %f (must be between -180 and 180)", l.Longitude)
 	}
+	for _, tag := range l.Tags {
+		if tag == "" || strings.ContainsAny(tag, ", ") {
+			return fmt.Errorf("invalid tag: '%s' (must be a single word)", tag)
+		}
+	}
 	return nil
 }
@@ -67,6 +67,24 @@ func TestLocationValidate(t *testing.T) {
 			},
 			shouldErr: false,
 		},
+		{
+			name: "Valid tags",
+			location: &Location{
+				Latitude:  59,
+				Longitude: 10,
+				Tags:      []string{"coast", "summer-house"},
+			},
+			shouldErr: false,
+		},
+		{
+			name: "Tag with space",
+			location: &Location{
+				Latitude:  59,
+				Longitude: 10,
+				Tags:      []string{"summer house"},
+			},
+			shouldErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -116,3 +134,24 @@ func TestLocationString(t *testing.T) {
 		})
 	}
 }
+
+func TestLocationHasTag(t *testing.T) {
+	loc := &Location{Tags: []string{"coast", "Cabin"}}
+
+	tests := []struct {
+		tag      string
+		expected bool
+	}{
+		{"coast", true},
+		{"cabin", true},
+		{"COAST", true},
+		{"mountain", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := loc.HasTag(tt.tag); got != tt.expected {
+			t.Errorf("HasTag(%q) = %v; want %v", tt.tag, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package models
+
+// Notifier is a destination for notifications, such as a chat room or a
+// push topic
+type Notifier struct {
+	// Type is one of webhook, slack, teams, ntfy and matrix
+	Type string `yaml:"type" json:"type" mapstructure:"type"`
+
+	// URL is the webhook or topic URL, or the homeserver for Matrix
+	URL string `yaml:"url" json:"url" mapstructure:"url"`
+
+	// Token is sent as a bearer token; Matrix requires one
+	Token string `yaml:"token,omitempty" json:"token,omitempty" mapstructure:"token"`
+
+	// Room is the Matrix room ID, such as !abc123:matrix.org
+	Room string `yaml:"room,omitempty" json:"room,omitempty" mapstructure:"room"`
+
+	// Tags are ntfy tags, such as warning or umbrella, which ntfy shows
+	// as emoji in front of the title
+	Tags []string `yaml:"tags,omitempty" json:"tags,omitempty" mapstructure:"tags"`
+
+	// Template renders the JSON body of a generic webhook
+	Template string `yaml:"template,omitempty" json:"template,omitempty" mapstructure:"template"`
+
+	// Retries is how often a failed delivery is retried; 0 uses the
+	// default of 3 and a negative number disables retries
+	Retries int `yaml:"retries,omitempty" json:"retries,omitempty" mapstructure:"retries"`
+}
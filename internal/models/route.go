@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// Route is the weather along a track, at the time each point is reached
+type Route struct {
+	Name      string
+	Departure time.Time
+	Speed     float64 // km/h
+	Length    float64 // km
+	Points    []RoutePoint
+}
+
+// RoutePoint is a point along a route with the forecast for the time it
+// is reached
+type RoutePoint struct {
+	Location *Location
+	Distance float64 // km from the start
+	Arrival  time.Time
+
+	// Forecast is nil when the forecast does not reach the arrival time or
+	// could not be fetched
+	Forecast *HourlyForecast
+}
+
+// Arrival returns the time the end of the route is reached
+func (r *Route) Arrival() time.Time {
+	return r.Departure.Add(time.Duration(r.Length / r.Speed * float64(time.Hour)))
+}
+
+// RouteExtremes are the points with the worst conditions along a route.
+// Each is nil when no point has a forecast; Wettest is also nil when no
+// precipitation is expected.
+type RouteExtremes struct {
+	Coldest  *RoutePoint // lowest feels-like temperature
+	Windiest *RoutePoint
+	Wettest  *RoutePoint
+}
+
+// Extremes returns the points with the worst conditions. The first point
+// wins a tie.
+func (r *Route) Extremes() RouteExtremes {
+	var e RouteExtremes
+	for i := range r.Points {
+		p := &r.Points[i]
+		if p.Forecast == nil {
+			continue
+		}
+		if e.Coldest == nil || p.Forecast.FeelsLike() < e.Coldest.Forecast.FeelsLike() {
+			e.Coldest = p
+		}
+		if e.Windiest == nil || p.Forecast.WindSpeed > e.Windiest.Forecast.WindSpeed {
+			e.Windiest = p
+		}
+		if p.Forecast.Precipitation > 0 && (e.Wettest == nil || p.Forecast.Precipitation > e.Wettest.Forecast.Precipitation) {
+			e.Wettest = p
+		}
+	}
+	return e
+}
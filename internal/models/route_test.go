@@ -0,0 +1,47 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRouteExtremes(t *testing.T) {
+	depart := time.Date(2026, 6, 1, 8, 0, 0, 0, time.UTC)
+	r := &Route{
+		Departure: depart,
+		Speed:     20,
+		Length:    60,
+		Points: []RoutePoint{
+			{Distance: 0, Forecast: &HourlyForecast{Temperature: 12, Humidity: 70, WindSpeed: 3}},
+			{Distance: 20, Forecast: &HourlyForecast{Temperature: 9, Humidity: 80, WindSpeed: 8, Precipitation: 0.4}},
+			{Distance: 40},
+			{Distance: 60, Forecast: &HourlyForecast{Temperature: 11, Humidity: 90, WindSpeed: 8, Precipitation: 1.2}},
+		},
+	}
+
+	e := r.Extremes()
+	if e.Coldest != &r.Points[1] {
+		t.Errorf("Coldest = %+v; want the point at 20 km", e.Coldest)
+	}
+	if e.Windiest != &r.Points[1] {
+		t.Errorf("Windiest = %+v; want the first of the windiest points", e.Windiest)
+	}
+	if e.Wettest != &r.Points[3] {
+		t.Errorf("Wettest = %+v; want the point at 60 km", e.Wettest)
+	}
+
+	if got := r.Arrival(); !got.Equal(depart.Add(3 * time.Hour)) {
+		t.Errorf("Arrival() = %v; want %v", got, depart.Add(3*time.Hour))
+	}
+
+	// Without precipitation there is no wettest point
+	dry := &Route{Points: []RoutePoint{{Forecast: &HourlyForecast{Temperature: 20}}}}
+	if e := dry.Extremes(); e.Wettest != nil || e.Coldest == nil {
+		t.Errorf("Extremes() of a dry route = %+v; want no wettest point", e)
+	}
+
+	// Without forecasts there are no extremes
+	if e := (&Route{Points: []RoutePoint{{Distance: 0}}}).Extremes(); e.Coldest != nil || e.Windiest != nil {
+		t.Errorf("Extremes() without forecasts = %+v; want none", e)
+	}
+}
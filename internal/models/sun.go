@@ -0,0 +1,47 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// sunriseElevation is the elevation of the center of the sun at sunrise
+// and sunset in degrees, allowing for refraction and the sun's radius
+const sunriseElevation = -0.833
+
+// SunElevation returns the elevation of the sun above the horizon at the
+// location and time in degrees, using the NOAA solar position equations.
+// The result is accurate to about a tenth of a degree.
+func (l *Location) SunElevation(t time.Time) float64 {
+	t = t.UTC()
+
+	// Fractional year in radians
+	days := float64(t.YearDay() - 1)
+	hours := float64(t.Hour()) + float64(t.Minute())/60 + float64(t.Second())/3600
+	daysInYear := 365.0
+	if y := t.Year(); y%4 == 0 && (y%100 != 0 || y%400 == 0) {
+		daysInYear = 366
+	}
+	g := 2 * math.Pi / daysInYear * (days + (hours-12)/24)
+
+	// Equation of time in minutes and declination in radians
+	eqTime := 229.18 * (0.000075 + 0.001868*math.Cos(g) - 0.032077*math.Sin(g) -
+		0.014615*math.Cos(2*g) - 0.040849*math.Sin(2*g))
+	decl := 0.006918 - 0.399912*math.Cos(g) + 0.070257*math.Sin(g) -
+		0.006758*math.Cos(2*g) + 0.000907*math.Sin(2*g) -
+		0.002697*math.Cos(3*g) + 0.00148*math.Sin(3*g)
+
+	// Hour angle from the true solar time
+	solarMinutes := hours*60 + eqTime + 4*l.Longitude
+	hourAngle := radians(solarMinutes/4 - 180)
+
+	lat := radians(l.Latitude)
+	cosZenith := math.Sin(lat)*math.Sin(decl) + math.Cos(lat)*math.Cos(decl)*math.Cos(hourAngle)
+	return 90 - math.Acos(math.Max(-1, math.Min(1, cosZenith)))*180/math.Pi
+}
+
+// IsDaylight reports whether the sun is above the horizon at the location
+// and time, between sunrise and sunset
+func (l *Location) IsDaylight(t time.Time) bool {
+	return l.SunElevation(t) > sunriseElevation
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSunElevation(t *testing.T) {
+	oslo := &Location{Latitude: 59.91, Longitude: 10.75}
+	tromso := &Location{Latitude: 69.65, Longitude: 18.96}
+
+	tests := []struct {
+		name     string
+		loc      *Location
+		time     time.Time
+		want     float64
+		daylight bool
+	}{
+		{"Oslo midsummer noon", oslo, time.Date(2026, 6, 21, 11, 15, 0, 0, time.UTC), 53.5, true},
+		{"Oslo midsummer midnight", oslo, time.Date(2026, 6, 21, 23, 15, 0, 0, time.UTC), -6.6, false},
+		{"Oslo midwinter noon", oslo, time.Date(2026, 12, 21, 11, 15, 0, 0, time.UTC), 6.6, true},
+		{"Tromsø midnight sun", tromso, time.Date(2026, 6, 21, 22, 45, 0, 0, time.UTC), 3.1, true},
+		{"Tromsø polar night", tromso, time.Date(2026, 12, 21, 10, 45, 0, 0, time.UTC), -3.1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.loc.SunElevation(tt.time); math.Abs(got-tt.want) > 0.3 {
+				t.Errorf("SunElevation() = %.2f; want %.1f", got, tt.want)
+			}
+			if got := tt.loc.IsDaylight(tt.time); got != tt.daylight {
+				t.Errorf("IsDaylight() = %v; want %v", got, tt.daylight)
+			}
+		})
+	}
+}
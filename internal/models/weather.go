@@ -79,15 +79,44 @@ type Forecast struct {
 	Hours    []HourlyForecast
 }
 
+// At returns the forecast period that contains t, or nil if the forecast
+// does not cover t. A period lasts until the next one starts; the last
+// one lasts an hour.
+func (f *Forecast) At(t time.Time) *HourlyForecast {
+	for i := len(f.Hours) - 1; i >= 0; i-- {
+		hour := &f.Hours[i]
+		if hour.Time.After(t) {
+			continue
+		}
+		end := hour.Time.Add(time.Hour)
+		if i+1 < len(f.Hours) {
+			end = f.Hours[i+1].Time
+		}
+		if t.Before(end) {
+			return hour
+		}
+		return nil
+	}
+	return nil
+}
+
 // HourlyForecast represents weather forecast for a specific hour
 type HourlyForecast struct {
-	Time          time.Time
-	Temperature   float64
-	Humidity      float64
-	WindSpeed     float64
-	Precipitation float64
-	Symbol        string
-	Description   string
+	Time                     time.Time
+	Temperature              float64
+	Humidity                 float64
+	WindSpeed                float64
+	WindGust                 float64 // m/s, 0 when not forecast
+	WindDir                  float64 // degrees (0-360)
+	Precipitation            float64
+	PrecipitationProbability float64 // percentage, 0 when not forecast
+	Symbol                   string
+	Description              string
+
+	// Whether WindGust and PrecipitationProbability were forecast. MET
+	// leaves them out for some periods, and rules must not read 0 then.
+	HasWindGust                 bool
+	HasPrecipitationProbability bool
 }
 
 // FeelsLike calculates the apparent temperature (feels like)
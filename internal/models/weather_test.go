@@ -2,6 +2,7 @@ package models
 
 import (
 	"testing"
+	"time"
 )
 
 func TestWeatherWindDirection(t *testing.T) {
@@ -349,3 +350,40 @@ func TestFeelsLikeConsistency(t *testing.T) {
 			weatherFeels, forecastFeels)
 	}
 }
+
+func TestForecastAt(t *testing.T) {
+	base := time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)
+	forecast := &Forecast{Hours: []HourlyForecast{
+		{Time: base},
+		{Time: base.Add(time.Hour)},
+		{Time: base.Add(2 * time.Hour)},
+		{Time: base.Add(8 * time.Hour)}, // six-hour periods further ahead
+	}}
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want int // index in forecast.Hours, -1 for none
+	}{
+		{"Start of an hour", base, 0},
+		{"Within an hour", base.Add(50 * time.Minute), 0},
+		{"Next hour", base.Add(70 * time.Minute), 1},
+		{"Within a longer period", base.Add(5 * time.Hour), 2},
+		{"Last hour", base.Add(8*time.Hour + 30*time.Minute), 3},
+		{"Before the forecast", base.Add(-time.Minute), -1},
+		{"After the forecast", base.Add(9 * time.Hour), -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := forecast.At(tt.at)
+			var want *HourlyForecast
+			if tt.want >= 0 {
+				want = &forecast.Hours[tt.want]
+			}
+			if got != want {
+				t.Errorf("At(%v) = %v; want %v", tt.at, got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/kristofferrisa/sky-cli/internal/formatter"
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+// CheckMessage renders the result of a rule check: the markdown format
+// for chat rooms, the summary format for push notifications and the JSON
+// format as data for webhook templates
+func CheckMessage(check *models.Check, opts formatter.Options) (Message, error) {
+	// Colors would end up as escape codes in the notification
+	opts.NoColor = true
+
+	var markdown, summary, data bytes.Buffer
+	if err := formatter.NewMarkdownFormatter().FormatCheck(&markdown, check, opts); err != nil {
+		return Message{}, err
+	}
+	if err := formatter.NewSummaryFormatter().FormatCheck(&summary, check, opts); err != nil {
+		return Message{}, err
+	}
+	if err := formatter.NewJSONFormatter().FormatCheck(&data, check, opts); err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		Title:    checkTitle(check),
+		Text:     strings.TrimSpace(summary.String()),
+		Markdown: strings.TrimSpace(markdown.String()),
+		Data:     json.RawMessage(bytes.TrimSpace(data.Bytes())),
+	}, nil
+}
+
+// checkTitle names the matched rules and where they matched, such as
+// "frost, gusts at Stavern"
+func checkTitle(check *models.Check) string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, a := range check.Alerts {
+		if !seen[a.Rule] {
+			seen[a.Rule] = true
+			names = append(names, a.Rule)
+		}
+	}
+	if len(names) == 0 {
+		return "No rule matched"
+	}
+
+	place := fmt.Sprintf("%d locations", len(check.Locations))
+	if len(check.Locations) == 1 {
+		place = check.Locations[0].Name
+		if place == "" {
+			place = check.Locations[0].String()
+		}
+	}
+	return fmt.Sprintf("%s at %s", strings.Join(names, ", "), place)
+}
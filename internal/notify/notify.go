@@ -0,0 +1,198 @@
+// Package notify delivers weather notifications to chat rooms, push
+// services and webhooks, retrying deliveries that fail
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+// Notifier types
+const (
+	TypeWebhook = "webhook"
+	TypeSlack   = "slack"
+	TypeTeams   = "teams"
+	TypeNtfy    = "ntfy"
+	TypeMatrix  = "matrix"
+)
+
+// Types lists the notifier types
+func Types() []string {
+	return []string{TypeWebhook, TypeSlack, TypeTeams, TypeNtfy, TypeMatrix}
+}
+
+// DefaultRetries is how often a failed delivery is retried unless the
+// notifier says otherwise
+const DefaultRetries = 3
+
+// retryDelay is the wait before the first retry, doubling for every
+// further one. Tests shorten it.
+var retryDelay = time.Second
+
+// maxRetryDelay caps the wait a server asks for with Retry-After
+const maxRetryDelay = time.Minute
+
+// Message is a notification, rendered for the different kinds of
+// notifiers
+type Message struct {
+	// Title is a short heading, such as "frost at Stavern"
+	Title string `json:"title"`
+
+	// Text is a line or two of plain text for push notifications
+	Text string `json:"text"`
+
+	// Markdown is the full message for chat rooms
+	Markdown string `json:"markdown"`
+
+	// Data is the JSON form of what is notified, such as a rule check or
+	// a watch event
+	Data interface{} `json:"data,omitempty"`
+}
+
+// Notifier delivers messages to one destination
+type Notifier interface {
+	Notify(ctx context.Context, msg Message) error
+}
+
+// New creates the notifier described by n
+func New(n *models.Notifier) (Notifier, error) {
+	if n == nil || n.Type == "" {
+		return nil, fmt.Errorf("missing type (available: %s)", strings.Join(Types(), ", "))
+	}
+	typ := strings.ToLower(n.Type)
+	unknown := fmt.Errorf("unknown type '%s' (available: %s)", n.Type, strings.Join(Types(), ", "))
+	if !slices.Contains(Types(), typ) {
+		return nil, unknown
+	}
+	if n.URL == "" {
+		return nil, fmt.Errorf("missing url")
+	}
+	u, err := url.Parse(n.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid url '%s': must be an http or https URL", n.URL)
+	}
+
+	s := newSender(n)
+	switch typ {
+	case TypeWebhook:
+		return newWebhook(s, n.Template)
+	case TypeSlack:
+		return &slackNotifier{s}, nil
+	case TypeTeams:
+		return &teamsNotifier{s}, nil
+	case TypeNtfy:
+		return &ntfyNotifier{sender: s, tags: n.Tags}, nil
+	case TypeMatrix:
+		if n.Room == "" {
+			return nil, fmt.Errorf("missing room, such as !abc123:matrix.org")
+		}
+		if n.Token == "" {
+			return nil, fmt.Errorf("missing token (a Matrix access token)")
+		}
+		return &matrixNotifier{sender: s, room: n.Room}, nil
+	default:
+		return nil, unknown
+	}
+}
+
+// sender makes the HTTP requests of a notifier, retrying network errors,
+// rate limiting and server errors
+type sender struct {
+	url        string
+	token      string
+	retries    int
+	httpClient *http.Client
+}
+
+func newSender(n *models.Notifier) *sender {
+	retries := n.Retries
+	switch {
+	case retries == 0:
+		retries = DefaultRetries
+	case retries < 0:
+		retries = 0
+	}
+
+	return &sender{
+		url:     strings.TrimSuffix(n.URL, "/"),
+		token:   n.Token,
+		retries: retries,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// send makes a request with body, retrying it until it succeeds, fails
+// for good or the retries run out
+func (s *sender) send(ctx context.Context, method, url string, header http.Header, body []byte) error {
+	delay := retryDelay
+	for attempt := 0; ; attempt++ {
+		wait, err := s.do(ctx, method, url, header, body)
+		if err == nil || wait < 0 || attempt >= s.retries {
+			if err != nil && attempt > 0 {
+				return fmt.Errorf("%w (after %d attempts)", err, attempt+1)
+			}
+			return err
+		}
+
+		if wait == 0 {
+			wait = delay
+		}
+		delay *= 2
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// do makes one request. When it fails, wait is negative if a retry
+// cannot help, or the delay the server asked for with Retry-After.
+func (s *sender) do(ctx context.Context, method, url string, header http.Header, body []byte) (wait time.Duration, err error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return -1, fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return -1, ctx.Err()
+		}
+		return 0, fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return 0, nil
+	}
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("server returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+		return -1, err
+	}
+	if seconds, convErr := strconv.Atoi(resp.Header.Get("Retry-After")); convErr == nil && seconds > 0 {
+		return min(time.Duration(seconds)*time.Second, maxRetryDelay), err
+	}
+	return 0, err
+}
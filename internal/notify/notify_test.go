@@ -0,0 +1,377 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode"
+
+	"github.com/kristofferrisa/sky-cli/internal/formatter"
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+func init() {
+	retryDelay = time.Millisecond
+}
+
+// request is what a receiver got
+type request struct {
+	method string
+	path   string
+	header http.Header
+	body   string
+}
+
+// receiver records requests and answers them with the given statuses in
+// turn, then with 200 OK
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []request
+}
+
+func newReceiver(t *testing.T, statuses ...int) (*receiver, *httptest.Server) {
+	rcv := &receiver{statuses: statuses}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		rcv.mu.Lock()
+		defer rcv.mu.Unlock()
+		rcv.requests = append(rcv.requests, request{r.Method, r.URL.EscapedPath(), r.Header, string(body)})
+		if len(rcv.statuses) > 0 {
+			w.WriteHeader(rcv.statuses[0])
+			rcv.statuses = rcv.statuses[1:]
+		}
+	}))
+	t.Cleanup(server.Close)
+	return rcv, server
+}
+
+// last returns the last request, decoding its body into v if given
+func (r *receiver) last(t *testing.T, v interface{}) request {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.requests) == 0 {
+		t.Fatal("receiver got no request")
+	}
+	req := r.requests[len(r.requests)-1]
+	if v != nil {
+		if err := json.Unmarshal([]byte(req.body), v); err != nil {
+			t.Fatalf("body is not JSON: %v\n%s", err, req.body)
+		}
+	}
+	return req
+}
+
+func testMessage(t *testing.T) Message {
+	t.Helper()
+	loc := &models.Location{Name: "Stavern", Latitude: 59.0, Longitude: 10.0}
+	start := time.Date(2026, 1, 5, 3, 0, 0, 0, time.UTC)
+	check := &models.Check{
+		Time:      start.Add(-3 * time.Hour),
+		Rules:     2,
+		Locations: []*models.Location{loc},
+		Alerts: []models.Alert{{
+			Rule:     "frost",
+			When:     "temp < 0 within 12h",
+			Message:  "Cover the plants",
+			Location: loc,
+			Start:    start,
+			End:      start.Add(3 * time.Hour),
+			Periods:  3,
+			Values:   []models.AlertValue{{Name: "temp", Value: -1.5, Unit: "°C"}},
+		}},
+	}
+
+	msg, err := CheckMessage(check, formatter.Options{})
+	if err != nil {
+		t.Fatalf("CheckMessage() failed: %v", err)
+	}
+	return msg
+}
+
+func TestCheckMessage(t *testing.T) {
+	msg := testMessage(t)
+
+	if msg.Title != "frost at Stavern" {
+		t.Errorf("Title = %q; want %q", msg.Title, "frost at Stavern")
+	}
+	if want := "Stavern: 1 of 2 rules matched: frost (Stavern, Mon 03:00)"; msg.Text != want {
+		t.Errorf("Text = %q; want %q", msg.Text, want)
+	}
+	if !strings.Contains(msg.Markdown, "| frost | Stavern |") || !strings.Contains(msg.Markdown, "`temp < 0 within 12h`") {
+		t.Errorf("Markdown is not the markdown table:\n%s", msg.Markdown)
+	}
+
+	var data formatter.JSONCheck
+	if err := json.Unmarshal(msg.Data.(json.RawMessage), &data); err != nil || data.Matched != 1 {
+		t.Errorf("Data = %s; want the JSON check with 1 matched rule", msg.Data)
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name     string
+		notifier *models.Notifier
+		wantErr  string
+	}{
+		{"Slack", &models.Notifier{Type: "slack", URL: "https://hooks.slack.com/x"}, ""},
+		{"Type is case-insensitive", &models.Notifier{Type: "Teams", URL: "https://example.com/x"}, ""},
+		{"Missing type", &models.Notifier{URL: "https://example.com"}, "missing type"},
+		{"Unknown type", &models.Notifier{Type: "irc", URL: "https://example.com"}, "unknown type 'irc'"},
+		{"Missing URL", &models.Notifier{Type: "ntfy"}, "missing url"},
+		{"Not HTTP", &models.Notifier{Type: "ntfy", URL: "ntfy.sh/topic"}, "invalid url"},
+		{"Matrix without room", &models.Notifier{Type: "matrix", URL: "https://matrix.org", Token: "t"}, "missing room"},
+		{"Matrix without token", &models.Notifier{Type: "matrix", URL: "https://matrix.org", Room: "!a:b"}, "missing token"},
+		{"Bad template", &models.Notifier{Type: "webhook", URL: "https://example.com", Template: "{{.Title"}, "invalid template"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.notifier)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("New() failed: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("New() error = %v; want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	msg := testMessage(t)
+	rcv, server := newReceiver(t)
+
+	n, err := New(&models.Notifier{Type: "webhook", URL: server.URL, Token: "secret"})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	if err := n.Notify(context.Background(), msg); err != nil {
+		t.Fatalf("Notify() failed: %v", err)
+	}
+
+	var body struct {
+		Title string
+		Data  formatter.JSONCheck
+	}
+	req := rcv.last(t, &body)
+	if req.method != http.MethodPost || req.header.Get("Content-Type") != "application/json" {
+		t.Errorf("webhook got %s with %s; want POST with application/json", req.method, req.header.Get("Content-Type"))
+	}
+	if req.header.Get("Authorization") != "Bearer secret" {
+		t.Errorf("Authorization = %q; want the bearer token", req.header.Get("Authorization"))
+	}
+	if body.Title != msg.Title || len(body.Data.Alerts) != 1 || body.Data.Alerts[0].Condition != "temp < 0 within 12h" {
+		t.Errorf("webhook body = %s; want the message", req.body)
+	}
+
+	n, err = New(&models.Notifier{
+		Type:     "webhook",
+		URL:      server.URL,
+		Template: `{"content": {{json .Markdown}}, "alert": {{json .Title}}, "data": {{json .Data}}}`,
+	})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	if err := n.Notify(context.Background(), msg); err != nil {
+		t.Fatalf("Notify() failed: %v", err)
+	}
+	var templated struct {
+		Content string
+		Alert   string
+		Data    formatter.JSONCheck
+	}
+	rcv.last(t, &templated)
+	if templated.Content != msg.Markdown || templated.Alert != msg.Title || templated.Data.Rules != 2 {
+		t.Errorf("templated body = %+v; want the markdown, title and data", templated)
+	}
+
+	// A template that renders no JSON fails before sending
+	n, _ = New(&models.Notifier{Type: "webhook", URL: server.URL, Template: `{"text": {{.Text}}}`})
+	if err := n.Notify(context.Background(), msg); err == nil || !strings.Contains(err.Error(), "valid JSON") {
+		t.Errorf("Notify() with a broken template error = %v; want invalid JSON", err)
+	}
+	if len(rcv.requests) != 2 {
+		t.Errorf("webhook got %d requests; want 2", len(rcv.requests))
+	}
+}
+
+func TestSlack(t *testing.T) {
+	msg := testMessage(t)
+	rcv, server := newReceiver(t)
+
+	n, _ := New(&models.Notifier{Type: "slack", URL: server.URL})
+	if err := n.Notify(context.Background(), msg); err != nil {
+		t.Fatalf("Notify() failed: %v", err)
+	}
+
+	var body map[string]string
+	req := rcv.last(t, &body)
+	if req.method != http.MethodPost || body["text"] != msg.Markdown {
+		t.Errorf("Slack got %s %s; want POST with the markdown as text", req.method, req.body)
+	}
+}
+
+func TestTeams(t *testing.T) {
+	msg := testMessage(t)
+	rcv, server := newReceiver(t)
+
+	n, _ := New(&models.Notifier{Type: "teams", URL: server.URL})
+	if err := n.Notify(context.Background(), msg); err != nil {
+		t.Fatalf("Notify() failed: %v", err)
+	}
+
+	var body struct {
+		Type        string
+		Attachments []struct {
+			ContentType string
+			Content     struct {
+				Type string
+				Body []struct{ Text string }
+			}
+		}
+	}
+	rcv.last(t, &body)
+	if body.Type != "message" || len(body.Attachments) != 1 {
+		t.Fatalf("Teams body = %+v; want a message with one attachment", body)
+	}
+	card := body.Attachments[0]
+	if card.ContentType != "application/vnd.microsoft.card.adaptive" || card.Content.Type != "AdaptiveCard" {
+		t.Errorf("attachment = %s %s; want an Adaptive Card", card.ContentType, card.Content.Type)
+	}
+	if len(card.Content.Body) != 2 || card.Content.Body[0].Text != msg.Title || card.Content.Body[1].Text != msg.Markdown {
+		t.Errorf("card body = %+v; want the title and the markdown", card.Content.Body)
+	}
+}
+
+func TestNtfy(t *testing.T) {
+	msg := testMessage(t)
+	rcv, server := newReceiver(t)
+
+	n, _ := New(&models.Notifier{Type: "ntfy", URL: server.URL + "/my-weather"})
+	if err := n.Notify(context.Background(), msg); err != nil {
+		t.Fatalf("Notify() failed: %v", err)
+	}
+
+	req := rcv.last(t, nil)
+	if req.method != http.MethodPost || req.path != "/my-weather" {
+		t.Errorf("ntfy got %s %s; want POST /my-weather", req.method, req.path)
+	}
+	if req.body != msg.Text || req.header.Get("Title") != msg.Title {
+		t.Errorf("ntfy got %q titled %q; want the summary titled %q", req.body, req.header.Get("Title"), msg.Title)
+	}
+	if req.header.Get("Authorization") != "" {
+		t.Errorf("Authorization = %q; want none without a token", req.header.Get("Authorization"))
+	}
+	if _, ok := req.header["Tags"]; ok {
+		t.Errorf("Tags = %q; want none without tags", req.header.Get("Tags"))
+	}
+
+	// Tags are sent as configured
+	tagged, _ := New(&models.Notifier{Type: "ntfy", URL: server.URL + "/my-weather", Tags: []string{"warning", "umbrella"}})
+	if err := tagged.Notify(context.Background(), msg); err != nil {
+		t.Fatalf("Notify() failed: %v", err)
+	}
+	if tags := rcv.last(t, nil).header.Get("Tags"); tags != "warning,umbrella" {
+		t.Errorf("Tags = %q; want warning,umbrella", tags)
+	}
+
+	// Titles beyond ASCII are encoded for the header
+	msg.Title = "frost at Tromsø"
+	if err := n.Notify(context.Background(), msg); err != nil {
+		t.Fatalf("Notify() failed: %v", err)
+	}
+	title := rcv.last(t, nil).header.Get("Title")
+	decoded, err := new(mime.WordDecoder).DecodeHeader(title)
+	if err != nil || decoded != msg.Title || strings.ContainsFunc(title, func(r rune) bool { return r > unicode.MaxASCII }) {
+		t.Errorf("Title header = %q, decoding to %q; want %q encoded as ASCII", title, decoded, msg.Title)
+	}
+}
+
+func TestMatrix(t *testing.T) {
+	msg := testMessage(t)
+	rcv, server := newReceiver(t, http.StatusBadGateway)
+
+	n, _ := New(&models.Notifier{Type: "matrix", URL: server.URL + "/", Room: "!abc:example.org", Token: "syt_token"})
+	if err := n.Notify(context.Background(), msg); err != nil {
+		t.Fatalf("Notify() failed: %v", err)
+	}
+
+	var body map[string]string
+	req := rcv.last(t, &body)
+	prefix := "/_matrix/client/v3/rooms/%21abc:example.org/send/m.room.message/"
+	if req.method != http.MethodPut || !strings.HasPrefix(req.path, prefix) {
+		t.Errorf("Matrix got %s %s; want PUT %s...", req.method, req.path, prefix)
+	}
+	if req.header.Get("Authorization") != "Bearer syt_token" {
+		t.Errorf("Authorization = %q; want the access token", req.header.Get("Authorization"))
+	}
+	if body["msgtype"] != "m.notice" || body["body"] != msg.Markdown {
+		t.Errorf("Matrix body = %v; want a notice with the markdown", body)
+	}
+	// The markdown is rendered as HTML for clients to show
+	html := body["formatted_body"]
+	if body["format"] != "org.matrix.custom.html" || !strings.Contains(html, "<table>") ||
+		!strings.Contains(html, "<td>frost</td>") || strings.Contains(html, "| frost |") {
+		t.Errorf("Matrix formatted_body = %q; want the markdown as HTML", html)
+	}
+
+	// The retry after the failure reuses the transaction
+	if len(rcv.requests) != 2 || rcv.requests[0].path != req.path {
+		t.Errorf("Matrix got %d requests; want 2 to the same transaction", len(rcv.requests))
+	}
+}
+
+func TestRetries(t *testing.T) {
+	msg := testMessage(t)
+
+	tests := []struct {
+		name     string
+		retries  int
+		statuses []int
+		requests int
+		wantErr  bool
+	}{
+		{"Succeeds at once", 0, nil, 1, false},
+		{"Retries server errors", 0, []int{500, 503}, 3, false},
+		{"Retries rate limiting", 0, []int{429}, 2, false},
+		{"Gives up after the retries", 2, []int{500, 500, 500, 500}, 3, true},
+		{"Client errors are final", 0, []int{404}, 1, true},
+		{"Retries disabled", -1, []int{500}, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rcv, server := newReceiver(t, tt.statuses...)
+			n, _ := New(&models.Notifier{Type: "slack", URL: server.URL, Retries: tt.retries})
+
+			err := n.Notify(context.Background(), msg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Notify() error = %v; want error %v", err, tt.wantErr)
+			}
+			if len(rcv.requests) != tt.requests {
+				t.Errorf("Notify() made %d requests; want %d", len(rcv.requests), tt.requests)
+			}
+		})
+	}
+
+	// A cancelled context stops delivering
+	rcv, server := newReceiver(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	n, _ := New(&models.Notifier{Type: "ntfy", URL: server.URL})
+	if err := n.Notify(ctx, msg); err == nil || len(rcv.requests) != 0 {
+		t.Errorf("Notify() with a cancelled context = %v after %d requests; want an error", err, len(rcv.requests))
+	}
+}
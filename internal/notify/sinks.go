@@ -0,0 +1,186 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"text/template"
+	"time"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// jsonHeader is the header of requests with a JSON body
+var jsonHeader = http.Header{"Content-Type": {"application/json"}}
+
+// webhookNotifier posts a JSON body rendered from a template, or the
+// message itself, to any URL
+type webhookNotifier struct {
+	*sender
+	tmpl *template.Template
+}
+
+// templateFuncs are the functions available to webhook templates. json
+// encodes a value, so that {{json .Text}} is a quoted JSON string.
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		data, err := marshal(v)
+		return string(bytes.TrimSpace(data)), err
+	},
+}
+
+func newWebhook(s *sender, text string) (*webhookNotifier, error) {
+	n := &webhookNotifier{sender: s}
+	if text != "" {
+		tmpl, err := template.New("webhook").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template: %w", err)
+		}
+		n.tmpl = tmpl
+	}
+	return n, nil
+}
+
+// Notify posts the message
+func (n *webhookNotifier) Notify(ctx context.Context, msg Message) error {
+	if n.tmpl == nil {
+		body, err := marshal(msg)
+		if err != nil {
+			return err
+		}
+		return n.send(ctx, http.MethodPost, n.url, jsonHeader, body)
+	}
+
+	var buf bytes.Buffer
+	if err := n.tmpl.Execute(&buf, msg); err != nil {
+		return fmt.Errorf("failed to render template: %w", err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		return fmt.Errorf("template did not render valid JSON: %s", buf.String())
+	}
+	return n.send(ctx, http.MethodPost, n.url, jsonHeader, buf.Bytes())
+}
+
+// slackNotifier posts markdown to a Slack-compatible incoming webhook,
+// as offered by Slack, Mattermost, Rocket.Chat and Discord (with /slack
+// appended to its webhook URL)
+type slackNotifier struct {
+	*sender
+}
+
+// Notify posts the message
+func (n *slackNotifier) Notify(ctx context.Context, msg Message) error {
+	body, err := marshal(map[string]string{"text": msg.Markdown})
+	if err != nil {
+		return err
+	}
+	return n.send(ctx, http.MethodPost, n.url, jsonHeader, body)
+}
+
+// teamsNotifier posts an Adaptive Card to a Microsoft Teams webhook, as
+// created by the Workflows app or an incoming webhook connector
+type teamsNotifier struct {
+	*sender
+}
+
+// Notify posts the message
+func (n *teamsNotifier) Notify(ctx context.Context, msg Message) error {
+	card := map[string]interface{}{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.4",
+		"body": []map[string]interface{}{
+			{"type": "TextBlock", "text": msg.Title, "weight": "Bolder", "size": "Medium", "wrap": true},
+			{"type": "TextBlock", "text": msg.Markdown, "wrap": true},
+		},
+	}
+	body, err := marshal(map[string]interface{}{
+		"type": "message",
+		"attachments": []map[string]interface{}{
+			{"contentType": "application/vnd.microsoft.card.adaptive", "content": card},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return n.send(ctx, http.MethodPost, n.url, jsonHeader, body)
+}
+
+// ntfyNotifier publishes the short text of a message to an ntfy topic,
+// such as https://ntfy.sh/my-weather
+type ntfyNotifier struct {
+	*sender
+	tags []string
+}
+
+// Notify publishes the message with the configured tags. Header values
+// must be ASCII, so a title such as "frost at Tromsø" is sent RFC 2047
+// encoded, which ntfy decodes.
+func (n *ntfyNotifier) Notify(ctx context.Context, msg Message) error {
+	header := http.Header{
+		"Content-Type": {"text/plain; charset=utf-8"},
+		"Title":        {mime.QEncoding.Encode("utf-8", msg.Title)},
+	}
+	if len(n.tags) > 0 {
+		header.Set("Tags", mime.QEncoding.Encode("utf-8", strings.Join(n.tags, ",")))
+	}
+	return n.send(ctx, http.MethodPost, n.url, header, []byte(msg.Text))
+}
+
+// matrixNotifier sends a message to a Matrix room through the
+// client-server API of a homeserver
+type matrixNotifier struct {
+	*sender
+	room string
+}
+
+// transactions numbers the Matrix messages sent by this process
+var transactions atomic.Int64
+
+// matrixMarkdown renders markdown to the HTML Matrix clients show. Raw
+// HTML in the markdown is left out rather than passed on.
+var matrixMarkdown = goldmark.New(goldmark.WithExtensions(extension.Table, extension.Strikethrough))
+
+// Notify sends the message as a notice, which bots use so that other
+// bots do not answer. The markdown is the plain body, for clients that
+// do not show HTML, and is rendered as the formatted body. Retries reuse
+// the transaction ID, so the homeserver delivers the message only once.
+func (n *matrixNotifier) Notify(ctx context.Context, msg Message) error {
+	var html bytes.Buffer
+	if err := matrixMarkdown.Convert([]byte(msg.Markdown), &html); err != nil {
+		return fmt.Errorf("failed to render markdown: %w", err)
+	}
+	body, err := marshal(map[string]string{
+		"msgtype":        "m.notice",
+		"body":           msg.Markdown,
+		"format":         "org.matrix.custom.html",
+		"formatted_body": strings.TrimSpace(html.String()),
+	})
+	if err != nil {
+		return err
+	}
+
+	txn := fmt.Sprintf("sky-%d-%d", time.Now().UnixNano(), transactions.Add(1))
+	endpoint := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		n.url, url.PathEscape(n.room), url.PathEscape(txn))
+	return n.send(ctx, http.MethodPut, endpoint, jsonHeader, body)
+}
+
+// marshal encodes v as JSON, leaving the comparison operators of rules
+// readable
+func marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
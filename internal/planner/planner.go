@@ -0,0 +1,206 @@
+// Package planner ranks forecast windows by how well their conditions
+// suit an activity
+package planner
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+// Penalties subtracted from an hour's score of 100 for each unit beyond a
+// limit. Each limit costs at most its max penalty; any precipitation above
+// the limit costs rainPenalty on top of the amount.
+const (
+	tempPenalty        = 5  // per °C
+	windPenalty        = 10 // per m/s
+	rainPenalty        = 30 // per mm
+	humidityPenalty    = 2  // per percentage point
+	tempMaxPenalty     = 50
+	windMaxPenalty     = 60
+	rainMaxPenalty     = 80
+	humidityMaxPenalty = 40
+)
+
+// Rank scores every window of duration that starts at a forecast hour
+// no earlier than from and ends no later than to, and returns the n best
+// windows that do not overlap, best first. Windows are made of
+// consecutive hourly forecasts, so the six-hourly periods far ahead are
+// never used. When the activity needs daylight, windows with any part
+// between sunset and sunrise are left out. Window times are in the time
+// zone of from.
+func Rank(forecast *models.Forecast, activity *models.Activity, duration time.Duration, from, to time.Time, n int) []models.Window {
+	hours := int(math.Ceil(duration.Hours()))
+	if hours < 1 || n < 1 {
+		return nil
+	}
+
+	var windows []models.Window
+	for i := 0; i+hours <= len(forecast.Hours); i++ {
+		span := forecast.Hours[i : i+hours]
+		start := span[0].Time.In(from.Location())
+		end := start.Add(duration)
+		if start.Before(from) || end.After(to) || !consecutive(span) {
+			continue
+		}
+		if activity.NeedsDaylight() && !inDaylight(forecast.Location, start, end) {
+			continue
+		}
+		windows = append(windows, Score(span, activity, start, end))
+	}
+
+	sort.SliceStable(windows, func(i, j int) bool {
+		if windows[i].Score != windows[j].Score {
+			return windows[i].Score > windows[j].Score
+		}
+		return windows[i].Start.Before(windows[j].Start)
+	})
+
+	var best []models.Window
+	for _, w := range windows {
+		if len(best) == n {
+			break
+		}
+		if !overlapsAny(w, best) {
+			best = append(best, w)
+		}
+	}
+	return best
+}
+
+// Score rates the forecast hours of a window for an activity. The score
+// is the average of the mean and the worst hourly score, so that one bad
+// hour pulls a window down more than it would in a plain average.
+func Score(hours []models.HourlyForecast, activity *models.Activity, start, end time.Time) models.Window {
+	w := models.Window{Start: start, End: end, Hours: hours}
+	if len(hours) == 0 {
+		return w
+	}
+
+	total, worst := 0.0, 100.0
+	for i := range hours {
+		s := scoreHour(&hours[i], activity)
+		total += s
+		worst = math.Min(worst, s)
+	}
+	w.Score = math.Round((total/float64(len(hours))+worst)/2*10) / 10
+	w.Reasons = reasons(hours, activity)
+	return w
+}
+
+// scoreHour rates a single forecast hour from 0 to 100
+func scoreHour(hour *models.HourlyForecast, a *models.Activity) float64 {
+	penalty := 0.0
+	if a.MinTemp != nil && hour.Temperature < *a.MinTemp {
+		penalty += math.Min(tempMaxPenalty, (*a.MinTemp-hour.Temperature)*tempPenalty)
+	}
+	if a.MaxTemp != nil && hour.Temperature > *a.MaxTemp {
+		penalty += math.Min(tempMaxPenalty, (hour.Temperature-*a.MaxTemp)*tempPenalty)
+	}
+	if a.MinWind != nil && hour.WindSpeed < *a.MinWind {
+		penalty += math.Min(windMaxPenalty, (*a.MinWind-hour.WindSpeed)*windPenalty)
+	}
+	if a.MaxWind != nil && hour.WindSpeed > *a.MaxWind {
+		penalty += math.Min(windMaxPenalty, (hour.WindSpeed-*a.MaxWind)*windPenalty)
+	}
+	if a.MaxPrecipitation != nil && hour.Precipitation > *a.MaxPrecipitation {
+		penalty += math.Min(rainMaxPenalty, rainPenalty+(hour.Precipitation-*a.MaxPrecipitation)*rainPenalty)
+	}
+	if a.MaxHumidity != nil && hour.Humidity > *a.MaxHumidity {
+		penalty += math.Min(humidityMaxPenalty, (hour.Humidity-*a.MaxHumidity)*humidityPenalty)
+	}
+	return math.Max(0, 100-penalty)
+}
+
+// reasons describes the conditions during a window, with the limits
+// they break
+func reasons(hours []models.HourlyForecast, a *models.Activity) []string {
+	minTemp, maxTemp := hours[0].Temperature, hours[0].Temperature
+	minWind, maxWind := hours[0].WindSpeed, hours[0].WindSpeed
+	maxRain, totalRain := 0.0, 0.0
+	maxHumidity := hours[0].Humidity
+	for _, h := range hours {
+		minTemp, maxTemp = math.Min(minTemp, h.Temperature), math.Max(maxTemp, h.Temperature)
+		minWind, maxWind = math.Min(minWind, h.WindSpeed), math.Max(maxWind, h.WindSpeed)
+		maxRain = math.Max(maxRain, h.Precipitation)
+		totalRain += h.Precipitation
+		maxHumidity = math.Max(maxHumidity, h.Humidity)
+	}
+
+	var list []string
+
+	switch {
+	case a.MinTemp != nil && minTemp < *a.MinTemp:
+		list = append(list, fmt.Sprintf("too cold: down to %.1f°C (min %g)", minTemp, *a.MinTemp))
+	case a.MaxTemp != nil && maxTemp > *a.MaxTemp:
+		list = append(list, fmt.Sprintf("too warm: up to %.1f°C (max %g)", maxTemp, *a.MaxTemp))
+	default:
+		list = append(list, fmt.Sprintf("%.0f-%.0f°C", minTemp, maxTemp))
+	}
+
+	switch {
+	case a.MaxWind != nil && maxWind > *a.MaxWind:
+		list = append(list, fmt.Sprintf("too windy: up to %.1f m/s (max %g)", maxWind, *a.MaxWind))
+	case a.MinWind != nil && minWind < *a.MinWind:
+		list = append(list, fmt.Sprintf("too little wind: down to %.1f m/s (min %g)", minWind, *a.MinWind))
+	case a.MinWind != nil:
+		list = append(list, fmt.Sprintf("wind %.1f-%.1f m/s", minWind, maxWind))
+	default:
+		list = append(list, fmt.Sprintf("wind up to %.1f m/s", maxWind))
+	}
+
+	switch {
+	case a.MaxPrecipitation != nil && maxRain > *a.MaxPrecipitation:
+		list = append(list, fmt.Sprintf("too wet: up to %.1f mm/h (max %g)", maxRain, *a.MaxPrecipitation))
+	case totalRain == 0:
+		list = append(list, "dry")
+	default:
+		list = append(list, fmt.Sprintf("%.1f mm precipitation", totalRain))
+	}
+
+	if a.MaxHumidity != nil && maxHumidity > *a.MaxHumidity {
+		list = append(list, fmt.Sprintf("too humid: up to %.0f%% (max %g)", maxHumidity, *a.MaxHumidity))
+	}
+	if a.NeedsDaylight() {
+		list = append(list, "daylight")
+	}
+	return list
+}
+
+// consecutive reports whether the forecast hours follow each other an
+// hour apart
+func consecutive(hours []models.HourlyForecast) bool {
+	for i := 1; i < len(hours); i++ {
+		if hours[i].Time.Sub(hours[i-1].Time) != time.Hour {
+			return false
+		}
+	}
+	return true
+}
+
+// inDaylight reports whether the sun is up at loc from start to end,
+// checked at every hour and at the end
+func inDaylight(loc *models.Location, start, end time.Time) bool {
+	if loc == nil {
+		return true
+	}
+	for t := start; t.Before(end); t = t.Add(time.Hour) {
+		if !loc.IsDaylight(t) {
+			return false
+		}
+	}
+	return loc.IsDaylight(end)
+}
+
+// overlapsAny reports whether w overlaps any of the windows
+func overlapsAny(w models.Window, windows []models.Window) bool {
+	for _, other := range windows {
+		if w.Start.Before(other.End) && other.Start.Before(w.End) {
+			return true
+		}
+	}
+	return false
+}
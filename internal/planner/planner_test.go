@@ -0,0 +1,187 @@
+package planner
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+func limit(v float64) *float64 { return &v }
+
+var (
+	yes = true
+	no  = false
+)
+
+// running is like the built-in running activity
+var running = &models.Activity{
+	MinTemp:          limit(5),
+	MaxTemp:          limit(18),
+	MaxWind:          limit(8),
+	MaxPrecipitation: limit(0.5),
+	Daylight:         &yes,
+}
+
+// day is the equinox at Stavern, with sunrise at about 05:20 UTC and
+// sunset at about 17:40 UTC
+var day = time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC)
+
+// testForecast returns two days of ideal conditions for running, with
+// rain from 06:00 to 09:00 and a strong wind at 13:00 on the first day
+func testForecast() *models.Forecast {
+	forecast := &models.Forecast{Location: &models.Location{Name: "Stavern", Latitude: 59.0, Longitude: 10.03}}
+	for i := 0; i < 48; i++ {
+		hour := models.HourlyForecast{
+			Time:        day.Add(time.Duration(i) * time.Hour),
+			Temperature: 12,
+			Humidity:    60,
+			WindSpeed:   3,
+		}
+		switch i {
+		case 6, 7, 8:
+			hour.Precipitation = 2
+		case 13:
+			hour.WindSpeed = 12
+		}
+		forecast.Hours = append(forecast.Hours, hour)
+	}
+	return forecast
+}
+
+func TestScoreHour(t *testing.T) {
+	tests := []struct {
+		name string
+		hour models.HourlyForecast
+		want float64
+	}{
+		{"Ideal", models.HourlyForecast{Temperature: 12, WindSpeed: 3}, 100},
+		{"Cold", models.HourlyForecast{Temperature: 2, WindSpeed: 3}, 85},
+		{"Very cold", models.HourlyForecast{Temperature: -20, WindSpeed: 3}, 50},
+		{"Windy", models.HourlyForecast{Temperature: 12, WindSpeed: 10}, 80},
+		{"Light rain", models.HourlyForecast{Temperature: 12, WindSpeed: 3, Precipitation: 1}, 55},
+		{"Heavy rain", models.HourlyForecast{Temperature: 12, WindSpeed: 3, Precipitation: 5}, 20},
+		{"Everything", models.HourlyForecast{Temperature: -20, WindSpeed: 20, Precipitation: 5}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scoreHour(&tt.hour, running); got != tt.want {
+				t.Errorf("scoreHour() = %v; want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Limits that are not set are not checked
+	if got := scoreHour(&models.HourlyForecast{Temperature: 40, WindSpeed: 30, Precipitation: 20}, &models.Activity{}); got != 100 {
+		t.Errorf("scoreHour() without limits = %v; want 100", got)
+	}
+
+	// Sailing needs wind
+	sailing := &models.Activity{MinWind: limit(4), MaxHumidity: limit(85)}
+	if got := scoreHour(&models.HourlyForecast{WindSpeed: 1, Humidity: 95}, sailing); got != 50 {
+		t.Errorf("scoreHour() for sailing = %v; want 50", got)
+	}
+}
+
+func TestScore(t *testing.T) {
+	forecast := testForecast()
+
+	// One hour in three with 4 m/s too much wind: mean 86.7, worst 60
+	hours := forecast.Hours[12:15]
+	w := Score(hours, running, hours[0].Time, hours[0].Time.Add(3*time.Hour))
+	if w.Score != 73.3 {
+		t.Errorf("Score() = %v; want 73.3", w.Score)
+	}
+	want := "12-12°C; too windy: up to 12.0 m/s (max 8); dry; daylight"
+	if got := strings.Join(w.Reasons, "; "); got != want {
+		t.Errorf("Reasons = %q; want %q", got, want)
+	}
+
+	hours = forecast.Hours[7:10]
+	w = Score(hours, running, hours[0].Time, hours[0].Time.Add(3*time.Hour))
+	if !strings.Contains(strings.Join(w.Reasons, "; "), "too wet: up to 2.0 mm/h (max 0.5)") {
+		t.Errorf("Reasons = %v; want too wet", w.Reasons)
+	}
+}
+
+func TestRank(t *testing.T) {
+	forecast := testForecast()
+	to := day.Add(48 * time.Hour)
+
+	tests := []struct {
+		name     string
+		activity *models.Activity
+		duration time.Duration
+		from, to time.Time
+		n        int
+		want     []string
+	}{
+		{
+			name:     "Best windows in daylight",
+			activity: running,
+			duration: 2 * time.Hour,
+			from:     day, to: to, n: 3,
+			want: []string{"Mar 20 09:00-11:00", "Mar 20 11:00-13:00", "Mar 20 14:00-16:00"},
+		},
+		{
+			name:     "Within",
+			activity: running,
+			duration: 2 * time.Hour,
+			from:     day, to: day.Add(12 * time.Hour), n: 2,
+			want: []string{"Mar 20 09:00-11:00", "Mar 20 06:00-08:00"},
+		},
+		{
+			name:     "From",
+			activity: running,
+			duration: 90 * time.Minute,
+			from:     day.Add(20 * time.Hour), to: to, n: 1,
+			want: []string{"Mar 21 06:00-07:30"},
+		},
+		{
+			name:     "Night allowed",
+			activity: running.Merge(&models.Activity{Daylight: &no}),
+			duration: 2 * time.Hour,
+			from:     day, to: to, n: 1,
+			want: []string{"Mar 20 00:00-02:00"},
+		},
+		{
+			name:     "Longer than the daylight",
+			activity: running,
+			duration: 14 * time.Hour,
+			from:     day, to: to, n: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			windows := Rank(forecast, tt.activity, tt.duration, tt.from, tt.to, tt.n)
+
+			var got []string
+			for _, w := range windows {
+				got = append(got, w.Start.Format("Jan 2 15:04")+"-"+w.End.Format("15:04"))
+			}
+			if strings.Join(got, ", ") != strings.Join(tt.want, ", ") {
+				t.Errorf("Rank() = %v; want %v", got, tt.want)
+			}
+
+			for i := 1; i < len(windows); i++ {
+				if windows[i].Score > windows[i-1].Score {
+					t.Errorf("Rank() is not ordered by score: %v after %v", windows[i].Score, windows[i-1].Score)
+				}
+			}
+		})
+	}
+}
+
+func TestRankSixHourly(t *testing.T) {
+	forecast := &models.Forecast{Hours: []models.HourlyForecast{
+		{Time: day},
+		{Time: day.Add(6 * time.Hour)},
+		{Time: day.Add(12 * time.Hour)},
+	}}
+	if windows := Rank(forecast, &models.Activity{}, 2*time.Hour, day, day.Add(24*time.Hour), 3); len(windows) != 0 {
+		t.Errorf("Rank() over six-hourly periods = %d windows; want none", len(windows))
+	}
+}
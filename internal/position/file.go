@@ -0,0 +1,85 @@
+package position
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+// File reads the position from a JSON file written by another tool, such
+// as {"lat": 59.05, "lon": 10.03, "time": "2026-06-01T10:00:00Z"}
+type File struct {
+	path   string
+	maxAge time.Duration
+}
+
+// NewFile creates a source for the JSON file at path. Positions older
+// than maxAge are rejected; zero accepts positions of any age.
+func NewFile(path string, maxAge time.Duration) *File {
+	return &File{path: path, maxAge: maxAge}
+}
+
+// String implements Source
+func (f *File) String() string {
+	return "location file " + f.path
+}
+
+// positionFile is the content of a location file. Both short and long
+// names are accepted, so gpsd TPV reports can be written to the file as
+// they are.
+type positionFile struct {
+	Lat       *float64  `json:"lat"`
+	Lon       *float64  `json:"lon"`
+	Latitude  *float64  `json:"latitude"`
+	Longitude *float64  `json:"longitude"`
+	Alt       float64   `json:"alt"`
+	Elevation float64   `json:"elevation"`
+	Time      time.Time `json:"time"`
+}
+
+// Position implements Source. The time of the position is the "time"
+// field, or the modification time of the file.
+func (f *File) Position(ctx context.Context) (*models.Location, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, err
+	}
+
+	var p positionFile
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("invalid location file: %w", err)
+	}
+
+	lat, lon := p.Lat, p.Lon
+	if lat == nil {
+		lat = p.Latitude
+	}
+	if lon == nil {
+		lon = p.Longitude
+	}
+	if lat == nil || lon == nil {
+		return nil, fmt.Errorf("location file has no lat and lon")
+	}
+
+	written := p.Time
+	if written.IsZero() {
+		written = info.ModTime()
+	}
+	if age := time.Since(written); f.maxAge > 0 && age > f.maxAge {
+		return nil, fmt.Errorf("position is %s old (max %s)", age.Round(time.Second), f.maxAge)
+	}
+
+	loc := &models.Location{Latitude: *lat, Longitude: *lon, Elevation: p.Alt}
+	if p.Elevation != 0 {
+		loc.Elevation = p.Elevation
+	}
+	return loc, nil
+}
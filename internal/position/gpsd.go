@@ -0,0 +1,88 @@
+package position
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+// DefaultGPSDAddress is where gpsd listens by default
+const DefaultGPSDAddress = "localhost:2947"
+
+// GPSD reads the position from a gpsd daemon using its JSON protocol
+type GPSD struct {
+	addr string
+}
+
+// NewGPSD creates a source for the gpsd daemon at addr (host:port)
+func NewGPSD(addr string) *GPSD {
+	return &GPSD{addr: addr}
+}
+
+// String implements Source
+func (g *GPSD) String() string {
+	return "gpsd at " + g.addr
+}
+
+// gpsdReport is the part of a gpsd report that sky reads. TPV
+// (time-position-velocity) reports carry the position.
+type gpsdReport struct {
+	Class  string   `json:"class"`
+	Mode   int      `json:"mode"` // 0-1: no fix, 2: 2D fix, 3: 3D fix
+	Lat    *float64 `json:"lat"`
+	Lon    *float64 `json:"lon"`
+	Alt    *float64 `json:"alt"`
+	AltMSL *float64 `json:"altMSL"`
+}
+
+// Position implements Source. It waits for the first TPV report with a
+// fix.
+func (g *GPSD) Position(ctx context.Context) (*models.Location, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", g.addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// Unblock reads when the context is done
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	if _, err := fmt.Fprint(conn, `?WATCH={"enable":true,"json":true};`+"\n"); err != nil {
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		var report gpsdReport
+		if err := json.Unmarshal(scanner.Bytes(), &report); err != nil {
+			continue
+		}
+		if report.Class != "TPV" || report.Mode < 2 || report.Lat == nil || report.Lon == nil {
+			continue
+		}
+
+		loc := &models.Location{Latitude: *report.Lat, Longitude: *report.Lon}
+		if report.Mode == 3 {
+			if report.AltMSL != nil {
+				loc.Elevation = *report.AltMSL
+			} else if report.Alt != nil {
+				loc.Elevation = *report.Alt
+			}
+		}
+		return loc, nil
+	}
+
+	if ctx.Err() != nil {
+		return nil, contextError(ctx)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("connection closed before a fix: %w", ErrNoFix)
+}
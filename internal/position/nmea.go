@@ -0,0 +1,173 @@
+package position
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+// NMEA reads the position from a stream of NMEA 0183 sentences, such as
+// a GPS receiver's serial device or a pseudo-terminal
+type NMEA struct {
+	path string
+}
+
+// NewNMEA creates a source for the NMEA device or file at path. Serial
+// devices must already be configured with the receiver's baud rate.
+func NewNMEA(path string) *NMEA {
+	return &NMEA{path: path}
+}
+
+// String implements Source
+func (n *NMEA) String() string {
+	return "NMEA device " + n.path
+}
+
+// Position implements Source. It waits for the first GGA or RMC sentence
+// with a valid fix.
+func (n *NMEA) Position(ctx context.Context) (*models.Location, error) {
+	f, err := os.Open(n.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// Devices support read deadlines; closing the file unblocks the read
+	// for everything else
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = f.SetReadDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() { f.Close() })
+	defer stop()
+
+	loc, err := readNMEA(f)
+	if err != nil && ctx.Err() != nil {
+		return nil, contextError(ctx)
+	}
+	return loc, err
+}
+
+// readNMEA reads sentences from r until one has a position fix
+func readNMEA(r io.Reader) (*models.Location, error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if loc, ok := parseNMEA(scanner.Text()); ok {
+			return loc, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("end of stream before a fix: %w", ErrNoFix)
+}
+
+// parseNMEA returns the position in a GGA or RMC sentence from any
+// talker (GP, GN, GL, ...). Sentences without a fix or with a wrong
+// checksum are ignored.
+func parseNMEA(sentence string) (*models.Location, bool) {
+	sentence = strings.TrimSpace(sentence)
+	if !strings.HasPrefix(sentence, "$") {
+		return nil, false
+	}
+	body := sentence[1:]
+
+	if i := strings.LastIndexByte(body, '*'); i >= 0 {
+		want, err := strconv.ParseUint(body[i+1:], 16, 8)
+		if err != nil || nmeaChecksum(body[:i]) != byte(want) {
+			return nil, false
+		}
+		body = body[:i]
+	}
+
+	fields := strings.Split(body, ",")
+	if len(fields[0]) != 5 {
+		return nil, false
+	}
+
+	switch fields[0][2:] {
+	case "GGA":
+		// GGA: time, lat, N/S, lon, E/W, quality, satellites, HDOP,
+		// altitude, M, ...
+		if len(fields) < 10 || fields[6] == "" || fields[6] == "0" {
+			return nil, false
+		}
+		loc, ok := nmeaPosition(fields[2], fields[3], fields[4], fields[5])
+		if !ok {
+			return nil, false
+		}
+		if alt, err := strconv.ParseFloat(fields[9], 64); err == nil {
+			loc.Elevation = alt
+		}
+		return loc, true
+
+	case "RMC":
+		// RMC: time, status (A = valid), lat, N/S, lon, E/W, ...
+		if len(fields) < 7 || fields[2] != "A" {
+			return nil, false
+		}
+		return nmeaPosition(fields[3], fields[4], fields[5], fields[6])
+	}
+	return nil, false
+}
+
+// nmeaPosition converts NMEA coordinates (ddmm.mmmm and dddmm.mmmm with
+// hemisphere letters) to a location
+func nmeaPosition(lat, ns, lon, ew string) (*models.Location, bool) {
+	latitude, ok := nmeaDegrees(lat, 2)
+	if !ok {
+		return nil, false
+	}
+	longitude, ok := nmeaDegrees(lon, 3)
+	if !ok {
+		return nil, false
+	}
+
+	switch ns {
+	case "N":
+	case "S":
+		latitude = -latitude
+	default:
+		return nil, false
+	}
+	switch ew {
+	case "E":
+	case "W":
+		longitude = -longitude
+	default:
+		return nil, false
+	}
+
+	return &models.Location{Latitude: latitude, Longitude: longitude}, true
+}
+
+// nmeaDegrees converts degrees and decimal minutes, with the given number
+// of degree digits, to decimal degrees
+func nmeaDegrees(s string, digits int) (float64, bool) {
+	if len(s) < digits+2 {
+		return 0, false
+	}
+	degrees, err := strconv.Atoi(s[:digits])
+	if err != nil {
+		return 0, false
+	}
+	minutes, err := strconv.ParseFloat(s[digits:], 64)
+	if err != nil || minutes >= 60 {
+		return 0, false
+	}
+	return float64(degrees) + minutes/60, true
+}
+
+// nmeaChecksum is the XOR of the bytes between "$" and "*"
+func nmeaChecksum(s string) byte {
+	var sum byte
+	for i := 0; i < len(s); i++ {
+		sum ^= s[i]
+	}
+	return sum
+}
@@ -0,0 +1,76 @@
+// Package position finds the current position of the device from gpsd,
+// an NMEA stream or a location file
+package position
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+// ErrNoFix is returned when a source is available but does not know the
+// position yet
+var ErrNoFix = errors.New("no position fix")
+
+// Source reports the current position of the device
+type Source interface {
+	// Position returns the current position. It returns when ctx is done
+	// if no position is available before then.
+	Position(ctx context.Context) (*models.Location, error)
+
+	// String describes the source for messages, such as
+	// "gpsd at localhost:2947"
+	String() string
+}
+
+// First asks all sources at once and returns the first position found.
+// If no source finds a position, the error describes every failure.
+func First(ctx context.Context, sources ...Source) (*models.Location, error) {
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no position sources configured")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		loc *models.Location
+		err error
+	}
+	results := make(chan result, len(sources))
+
+	for _, s := range sources {
+		go func(s Source) {
+			loc, err := s.Position(ctx)
+			if err == nil {
+				err = loc.Validate()
+			}
+			if err != nil {
+				err = fmt.Errorf("%s: %w", s, err)
+			}
+			results <- result{loc, err}
+		}(s)
+	}
+
+	var problems []string
+	for range sources {
+		r := <-results
+		if r.err == nil {
+			return r.loc, nil
+		}
+		problems = append(problems, r.err.Error())
+	}
+	return nil, errors.New(strings.Join(problems, "; "))
+}
+
+// contextError returns the reason ctx is done, worded for messages about
+// waiting for a position
+func contextError(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out waiting for a position")
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,306 @@
+package position
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+// fakeGPSD serves one client like gpsd: it waits for the WATCH command
+// and then sends reports
+func fakeGPSD(t *testing.T, reports ...string) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	done := make(chan struct{})
+	t.Cleanup(func() { close(done) })
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		fmt.Fprintln(conn, `{"class":"VERSION","release":"3.25","proto_major":3,"proto_minor":15}`)
+		command, _ := bufio.NewReader(conn).ReadString('\n')
+		if !strings.HasPrefix(command, "?WATCH=") {
+			return
+		}
+		for _, r := range reports {
+			fmt.Fprintln(conn, r)
+		}
+		<-done
+	}()
+
+	return ln.Addr().String()
+}
+
+func TestGPSD(t *testing.T) {
+	tests := []struct {
+		name     string
+		reports  []string
+		lat, lon float64
+		alt      float64
+		err      bool
+	}{
+		{
+			name: "3D fix",
+			reports: []string{
+				`{"class":"DEVICES","devices":[{"class":"DEVICE","path":"/dev/ttyACM0"}]}`,
+				`{"class":"WATCH","enable":true,"json":true}`,
+				`{"class":"TPV","device":"/dev/ttyACM0","mode":1}`,
+				`{"class":"SKY","satellites":[]}`,
+				`{"class":"TPV","device":"/dev/ttyACM0","mode":3,"lat":59.05,"lon":10.03,"alt":51.6,"altMSL":12.5}`,
+			},
+			lat: 59.05, lon: 10.03, alt: 12.5,
+		},
+		{
+			name:    "2D fix",
+			reports: []string{`{"class":"TPV","mode":2,"lat":-33.8568,"lon":151.2153,"alt":100}`},
+			lat:     -33.8568, lon: 151.2153,
+		},
+		{
+			name:    "No fix",
+			reports: []string{`{"class":"TPV","mode":1}`},
+			err:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr := fakeGPSD(t, tt.reports...)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+
+			loc, err := NewGPSD(addr).Position(ctx)
+			if tt.err {
+				if err == nil {
+					t.Errorf("Position() = %v; want error", loc)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Position() failed: %v", err)
+			}
+			if loc.Latitude != tt.lat || loc.Longitude != tt.lon || loc.Elevation != tt.alt {
+				t.Errorf("Position() = %v, %v, %v; want %v, %v, %v",
+					loc.Latitude, loc.Longitude, loc.Elevation, tt.lat, tt.lon, tt.alt)
+			}
+		})
+	}
+}
+
+func TestGPSDTimeout(t *testing.T) {
+	addr := fakeGPSD(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := NewGPSD(addr).Position(ctx)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Position() error = %v; want timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Position() returned after %s; want about 100ms", elapsed)
+	}
+}
+
+func TestGPSDNotRunning(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	if loc, err := NewGPSD(addr).Position(context.Background()); err == nil {
+		t.Errorf("Position() = %v; want error", loc)
+	}
+}
+
+func TestParseNMEA(t *testing.T) {
+	tests := []struct {
+		sentence string
+		lat, lon float64
+		alt      float64
+		ok       bool
+	}{
+		{"$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*47", 48.1173, 11.5167, 545.4, true},
+		{"$GNGGA,101500.00,5903.0000,N,01001.8000,E,1,08,0.9,12.5,M,39.1,M,,*4C", 59.05, 10.03, 12.5, true},
+		{"$GPRMC,123519,A,4807.038,N,01131.000,E,022.4,084.4,230394,003.1,W*6A", 48.1173, 11.5167, 0, true},
+		{"$GPRMC,225446,A,3351.408,S,15112.918,E,000.5,054.7,191194,020.3,E*61", -33.8568, 151.2153, 0, true},
+		{"$GPGGA,123519,4807.038,N,01131.000,W,1,08,0.9,545.4,M,46.9,M,,", 48.1173, -11.5167, 545.4, true},
+
+		// No fix, wrong checksum, other sentences and garbage
+		{"$GPGGA,123519,,,,,0,00,,,M,,M,,*6B", 0, 0, 0, false},
+		{"$GPRMC,123519,V,,,,,,,230394,,*33", 0, 0, 0, false},
+		{"$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*48", 0, 0, 0, false},
+		{"$GPGSA,A,3,04,05,,09,12,,,24,,,,,2.5,1.3,2.1*39", 0, 0, 0, false},
+		{"$GPGGA,123519,4867.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,", 0, 0, 0, false},
+		{"GPGGA,123519,4807.038,N,01131.000,E,1", 0, 0, 0, false},
+		{"", 0, 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		loc, ok := parseNMEA(tt.sentence)
+		if ok != tt.ok {
+			t.Errorf("parseNMEA(%q) ok = %v; want %v", tt.sentence, ok, tt.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if math.Abs(loc.Latitude-tt.lat) > 1e-4 || math.Abs(loc.Longitude-tt.lon) > 1e-4 || loc.Elevation != tt.alt {
+			t.Errorf("parseNMEA(%q) = %v, %v, %v; want %v, %v, %v",
+				tt.sentence, loc.Latitude, loc.Longitude, loc.Elevation, tt.lat, tt.lon, tt.alt)
+		}
+	}
+}
+
+func TestNMEAEndOfStream(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nmea.log")
+	if err := os.WriteFile(path, []byte("$GPRMC,123519,V,,,,,,,230394,,*33\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	if _, err := NewNMEA(path).Position(context.Background()); !errors.Is(err, ErrNoFix) {
+		t.Errorf("Position() error = %v; want ErrNoFix", err)
+	}
+}
+
+func TestFile(t *testing.T) {
+	now := time.Now().UTC()
+
+	tests := []struct {
+		name     string
+		content  string
+		lat, lon float64
+		alt      float64
+		err      bool
+	}{
+		{name: "Short names", content: `{"lat": 59.05, "lon": 10.03, "alt": 12}`, lat: 59.05, lon: 10.03, alt: 12},
+		{name: "Long names", content: `{"latitude": 59.05, "longitude": 10.03, "elevation": 12}`, lat: 59.05, lon: 10.03, alt: 12},
+		{name: "gpsd report", content: fmt.Sprintf(`{"class":"TPV","mode":3,"time":"%s","lat":0,"lon":0}`, now.Format(time.RFC3339)), lat: 0, lon: 0},
+		{name: "Stale", content: fmt.Sprintf(`{"lat": 59.05, "lon": 10.03, "time": "%s"}`, now.Add(-time.Hour).Format(time.RFC3339)), err: true},
+		{name: "No coordinates", content: `{"lat": 59.05}`, err: true},
+		{name: "Invalid JSON", content: `59.05,10.03`, err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "position.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to write file: %v", err)
+			}
+
+			loc, err := NewFile(path, 10*time.Minute).Position(context.Background())
+			if tt.err {
+				if err == nil {
+					t.Errorf("Position() = %v; want error", loc)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Position() failed: %v", err)
+			}
+			if loc.Latitude != tt.lat || loc.Longitude != tt.lon || loc.Elevation != tt.alt {
+				t.Errorf("Position() = %v, %v, %v; want %v, %v, %v",
+					loc.Latitude, loc.Longitude, loc.Elevation, tt.lat, tt.lon, tt.alt)
+			}
+		})
+	}
+
+	// Without a time field, the modification time of the file counts
+	path := filepath.Join(t.TempDir(), "position.json")
+	if err := os.WriteFile(path, []byte(`{"lat": 59.05, "lon": 10.03}`), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	old := now.Add(-time.Hour)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatalf("failed to change file time: %v", err)
+	}
+	if _, err := NewFile(path, 10*time.Minute).Position(context.Background()); err == nil {
+		t.Error("Position() of an old file expected error but got none")
+	}
+	if _, err := NewFile(path, 0).Position(context.Background()); err != nil {
+		t.Errorf("Position() without a maximum age failed: %v", err)
+	}
+}
+
+// fakeSource is a source that answers after a delay
+type fakeSource struct {
+	name  string
+	delay time.Duration
+	loc   *models.Location
+	err   error
+}
+
+func (f *fakeSource) Position(ctx context.Context) (*models.Location, error) {
+	select {
+	case <-time.After(f.delay):
+		return f.loc, f.err
+	case <-ctx.Done():
+		return nil, contextError(ctx)
+	}
+}
+
+func (f *fakeSource) String() string { return f.name }
+
+func TestFirst(t *testing.T) {
+	oslo := &models.Location{Latitude: 59.91, Longitude: 10.75}
+	bergen := &models.Location{Latitude: 60.39, Longitude: 5.32}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	loc, err := First(ctx,
+		&fakeSource{name: "slow", delay: 500 * time.Millisecond, loc: bergen},
+		&fakeSource{name: "broken", err: errors.New("no device")},
+		&fakeSource{name: "fast", delay: 10 * time.Millisecond, loc: oslo},
+	)
+	if err != nil {
+		t.Fatalf("First() failed: %v", err)
+	}
+	if loc != oslo {
+		t.Errorf("First() = %v; want %v", loc, oslo)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = First(ctx,
+		&fakeSource{name: "broken", err: errors.New("no device")},
+		&fakeSource{name: "invalid", loc: &models.Location{Latitude: 95}},
+		&fakeSource{name: "slow", delay: time.Second, loc: bergen},
+	)
+	if err == nil {
+		t.Fatal("First() expected error but got none")
+	}
+	for _, want := range []string{"broken: no device", "invalid: invalid latitude", "slow: timed out"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("First() error = %v; want it to mention %q", err, want)
+		}
+	}
+
+	if _, err := First(context.Background()); err == nil {
+		t.Error("First() without sources expected error but got none")
+	}
+}
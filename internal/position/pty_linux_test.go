@@ -0,0 +1,77 @@
+package position
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+// openPTY opens a pseudo-terminal and returns its master side and the
+// path of its slave side, which stands in for a GPS receiver's serial
+// device
+func openPTY(t *testing.T) (*os.File, string) {
+	t.Helper()
+
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR, 0)
+	if err != nil {
+		t.Skipf("pseudo-terminals not available: %v", err)
+	}
+	t.Cleanup(func() { master.Close() })
+
+	fd := master.Fd()
+	var n uint32
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, syscall.TIOCGPTN, uintptr(unsafe.Pointer(&n))); errno != 0 {
+		t.Skipf("TIOCGPTN failed: %v", errno)
+	}
+	var unlock int32
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, syscall.TIOCSPTLCK, uintptr(unsafe.Pointer(&unlock))); errno != 0 {
+		t.Skipf("TIOCSPTLCK failed: %v", errno)
+	}
+
+	return master, fmt.Sprintf("/dev/pts/%d", n)
+}
+func TestNMEAFromPTY(t *testing.T) {
+	master, path := openPTY(t)
+
+	go func() {
+		for _, line := range []string{
+			"$GPGSV,3,1,11,03,03,111,00,04,15,270,00,06,01,010,00,13,06,292,00*74",
+			"$GPRMC,123519,V,,,,,,,230394,,*33",
+			"$GNGGA,101500.00,5903.0000,N,01001.8000,E,1,08,0.9,12.5,M,39.1,M,,*4C",
+		} {
+			io.WriteString(master, line+"\n")
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	loc, err := NewNMEA(path).Position(ctx)
+	if err != nil {
+		t.Fatalf("Position() failed: %v", err)
+	}
+	if math.Abs(loc.Latitude-59.05) > 1e-6 || math.Abs(loc.Longitude-10.03) > 1e-6 || loc.Elevation != 12.5 {
+		t.Errorf("Position() = %v, %v, %v; want 59.05, 10.03, 12.5", loc.Latitude, loc.Longitude, loc.Elevation)
+	}
+}
+
+func TestNMEATimeout(t *testing.T) {
+	_, path := openPTY(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if loc, err := NewNMEA(path).Position(ctx); err == nil {
+		t.Fatalf("Position() = %v; want timeout", loc)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Position() returned after %s; want about 100ms", elapsed)
+	}
+}
@@ -0,0 +1,108 @@
+package route
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+// gpxFile is the part of a GPX 1.0 or 1.1 document that describes a path
+type gpxFile struct {
+	Name     string `xml:"name"` // GPX 1.0
+	Metadata struct {
+		Name string `xml:"name"`
+	} `xml:"metadata"`
+	Tracks []gpxTrack `xml:"trk"`
+	Routes []gpxRoute `xml:"rte"`
+}
+
+// gpxTrack is a recorded track made of segments
+type gpxTrack struct {
+	Name     string `xml:"name"`
+	Segments []struct {
+		Points []gpxPoint `xml:"trkpt"`
+	} `xml:"trkseg"`
+}
+
+// gpxRoute is a planned route made of route points
+type gpxRoute struct {
+	Name   string     `xml:"name"`
+	Points []gpxPoint `xml:"rtept"`
+}
+
+// gpxPoint is a track or route point
+type gpxPoint struct {
+	Latitude  float64 `xml:"lat,attr"`
+	Longitude float64 `xml:"lon,attr"`
+	Elevation float64 `xml:"ele"`
+}
+
+// LoadGPX reads a track from a GPX file. Without a name in the file, the
+// track is named after the file.
+func LoadGPX(path string) (*Track, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	track, err := ReadGPX(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if track.Name == "" {
+		track.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return track, nil
+}
+
+// ReadGPX reads a track from a GPX document. The tracks in the document
+// are joined in order, segment by segment; documents without tracks are
+// read from their routes instead.
+func ReadGPX(r io.Reader) (*Track, error) {
+	var file gpxFile
+	if err := xml.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("invalid GPX: %w", err)
+	}
+
+	track := &Track{Name: strings.TrimSpace(file.Metadata.Name)}
+	if track.Name == "" {
+		track.Name = strings.TrimSpace(file.Name)
+	}
+
+	var points []gpxPoint
+	var names []string
+	for _, trk := range file.Tracks {
+		names = append(names, trk.Name)
+		for _, seg := range trk.Segments {
+			points = append(points, seg.Points...)
+		}
+	}
+	if len(points) == 0 {
+		names = nil
+		for _, rte := range file.Routes {
+			names = append(names, rte.Name)
+			points = append(points, rte.Points...)
+		}
+	}
+	if len(points) == 0 {
+		return nil, fmt.Errorf("no track or route points")
+	}
+	if track.Name == "" && len(names) == 1 {
+		track.Name = strings.TrimSpace(names[0])
+	}
+
+	for i, p := range points {
+		loc := &models.Location{Latitude: p.Latitude, Longitude: p.Longitude, Elevation: p.Elevation}
+		if err := loc.Validate(); err != nil {
+			return nil, fmt.Errorf("point %d: %w", i+1, err)
+		}
+		track.Points = append(track.Points, loc)
+	}
+	return track, nil
+}
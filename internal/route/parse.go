@@ -0,0 +1,84 @@
+package route
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// speedUnits converts speed units to km/h
+var speedUnits = map[string]float64{
+	"":      1,
+	"kmh":   1,
+	"km/h":  1,
+	"kph":   1,
+	"m/s":   3.6,
+	"mps":   3.6,
+	"mph":   1.609344,
+	"kn":    1.852,
+	"kt":    1.852,
+	"kts":   1.852,
+	"knot":  1.852,
+	"knots": 1.852,
+}
+
+// ParseSpeed parses a speed such as "15km/h", "4 m/s", "30 mph" or
+// "12kn" and returns it in km/h. A number without a unit is in km/h.
+func ParseSpeed(s string) (float64, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(s)
+	}
+
+	value, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid speed '%s' (use for example 15km/h, 4m/s, 30mph or 12kn)", s)
+	}
+	unit := strings.TrimSpace(s[i:])
+	factor, ok := speedUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("unknown speed unit '%s' (use km/h, m/s, mph or kn)", unit)
+	}
+	if value <= 0 {
+		return 0, fmt.Errorf("speed must be positive")
+	}
+	return value * factor, nil
+}
+
+// departureLayouts are the accepted layouts for a departure date and time
+var departureLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+}
+
+// ParseDeparture parses a departure time: "now", a time of day such as
+// "08:00", or a date and time such as "2026-06-01 08:00" or RFC 3339. A
+// time of day is today, or tomorrow if it was more than an hour ago.
+// Times without a zone are in now's zone.
+func ParseDeparture(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.EqualFold(s, "now") {
+		return now, nil
+	}
+
+	for _, layout := range []string{"15:04", "1504"} {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			depart := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, now.Location())
+			if now.Sub(depart) > time.Hour {
+				depart = depart.AddDate(0, 0, 1)
+			}
+			return depart, nil
+		}
+	}
+	for _, layout := range departureLayouts {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid departure time '%s' (use now, HH:MM or YYYY-MM-DD HH:MM)", s)
+}
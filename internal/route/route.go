@@ -0,0 +1,99 @@
+// Package route reads tracks from GPX files and works out when each point
+// along a track is reached
+package route
+
+import (
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+// Track is a path made of points in travel order
+type Track struct {
+	Name   string
+	Points []*models.Location
+}
+
+// Length returns the length of the track in kilometers
+func (t *Track) Length() float64 {
+	length := 0.0
+	for i := 1; i < len(t.Points); i++ {
+		length += t.Points[i-1].DistanceTo(t.Points[i])
+	}
+	return length
+}
+
+// Sample is a point along a track
+type Sample struct {
+	Location *models.Location
+	Distance float64 // km from the start
+}
+
+// Sample returns points every interval kilometers along the track,
+// interpolated between the track points. The start and the end of the
+// track are always included; an end closer than a quarter interval to the
+// last sample replaces it. A non-positive interval returns just the start
+// and the end.
+func (t *Track) Sample(interval float64) []Sample {
+	if len(t.Points) == 0 {
+		return nil
+	}
+
+	samples := []Sample{{Location: at(t.Points[0], t.Points[0], 0), Distance: 0}}
+	if len(t.Points) == 1 {
+		return samples
+	}
+
+	total := 0.0
+	next := interval
+	for i := 1; i < len(t.Points); i++ {
+		a, b := t.Points[i-1], t.Points[i]
+		d := a.DistanceTo(b)
+		for interval > 0 && d > 0 && next <= total+d {
+			samples = append(samples, Sample{Location: at(a, b, (next-total)/d), Distance: next})
+			next += interval
+		}
+		total += d
+	}
+
+	last := t.Points[len(t.Points)-1]
+	end := Sample{Location: at(last, last, 0), Distance: total}
+	if n := len(samples); n > 1 && total-samples[n-1].Distance < interval/4 {
+		samples[n-1] = end
+	} else if total > samples[n-1].Distance {
+		samples = append(samples, end)
+	}
+	return samples
+}
+
+// at returns the point a fraction f of the way from a to b. Track points
+// are close together, so interpolating the coordinates linearly is close
+// enough to the great circle.
+func at(a, b *models.Location, f float64) *models.Location {
+	return &models.Location{
+		Latitude:  a.Latitude + (b.Latitude-a.Latitude)*f,
+		Longitude: a.Longitude + (b.Longitude-a.Longitude)*f,
+		Elevation: a.Elevation + (b.Elevation-a.Elevation)*f,
+	}
+}
+
+// Plan returns the route for travelling the track at speed km/h, leaving
+// at depart. A point is sampled for every interval of travel time. The
+// points have no forecasts yet.
+func Plan(t *Track, depart time.Time, speed float64, every time.Duration) *models.Route {
+	r := &models.Route{
+		Name:      t.Name,
+		Departure: depart,
+		Speed:     speed,
+		Length:    t.Length(),
+	}
+
+	for _, s := range t.Sample(speed * every.Hours()) {
+		r.Points = append(r.Points, models.RoutePoint{
+			Location: s.Location,
+			Distance: s.Distance,
+			Arrival:  depart.Add(time.Duration(s.Distance / speed * float64(time.Hour))),
+		})
+	}
+	return r
+}
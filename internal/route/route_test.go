@@ -0,0 +1,240 @@
+package route
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+// segment is the length of each step of 0.045° north in the test track
+const segment = 5.0038
+
+func TestReadGPX(t *testing.T) {
+	tests := []struct {
+		file   string
+		name   string
+		points int
+		err    bool
+	}{
+		{"track.gpx", "Coast ride", 4, false},
+		{"route.gpx", "Sail to Larvik", 2, false},
+		{"waypoints.gpx", "", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			track, err := LoadGPX(filepath.Join("testdata", tt.file))
+			if tt.err {
+				if err == nil {
+					t.Errorf("LoadGPX(%q) expected error but got none", tt.file)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadGPX(%q) failed: %v", tt.file, err)
+			}
+			if track.Name != tt.name {
+				t.Errorf("Name = %q; want %q", track.Name, tt.name)
+			}
+			if len(track.Points) != tt.points {
+				t.Errorf("got %d points; want %d", len(track.Points), tt.points)
+			}
+		})
+	}
+}
+
+func TestLoadGPXName(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "evening-run.gpx")
+	content := `<gpx><trk><trkseg><trkpt lat="59.9" lon="10.7"/><trkpt lat="59.91" lon="10.71"/></trkseg></trk></gpx>`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	track, err := LoadGPX(path)
+	if err != nil {
+		t.Fatalf("LoadGPX() failed: %v", err)
+	}
+	if track.Name != "evening-run" {
+		t.Errorf("Name = %q; want %q", track.Name, "evening-run")
+	}
+}
+
+func TestReadGPXInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.gpx")
+	content := `<gpx><trk><trkseg><trkpt lat="95" lon="10.7"/></trkseg></trk></gpx>`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if _, err := LoadGPX(path); err == nil {
+		t.Error("LoadGPX() with an invalid point expected error but got none")
+	}
+	if _, err := LoadGPX(filepath.Join("testdata", "missing.gpx")); err == nil {
+		t.Error("LoadGPX() of a missing file expected error but got none")
+	}
+}
+
+func TestSample(t *testing.T) {
+	track, err := LoadGPX(filepath.Join("testdata", "track.gpx"))
+	if err != nil {
+		t.Fatalf("LoadGPX() failed: %v", err)
+	}
+	if length := track.Length(); math.Abs(length-3*segment) > 0.01 {
+		t.Errorf("Length() = %.3f; want %.3f", length, 3*segment)
+	}
+
+	tests := []struct {
+		name      string
+		interval  float64
+		distances []float64
+	}{
+		{"Every 4 km", 4, []float64{0, 4, 8, 12, 3 * segment}},
+		{"End replaces a close sample", 5, []float64{0, 5, 10, 3 * segment}},
+		{"Longer than the track", 50, []float64{0, 3 * segment}},
+		{"No interval", 0, []float64{0, 3 * segment}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			samples := track.Sample(tt.interval)
+			if len(samples) != len(tt.distances) {
+				t.Fatalf("Sample(%v) returned %d samples; want %d", tt.interval, len(samples), len(tt.distances))
+			}
+			for i, s := range samples {
+				if math.Abs(s.Distance-tt.distances[i]) > 0.01 {
+					t.Errorf("sample %d distance = %.3f; want %.3f", i, s.Distance, tt.distances[i])
+				}
+				// The track goes straight north, so the distance from the
+				// start follows from the latitude
+				start := track.Points[0]
+				if d := start.DistanceTo(s.Location); math.Abs(d-s.Distance) > 0.01 {
+					t.Errorf("sample %d is %.3f km from the start; want %.3f", i, d, s.Distance)
+				}
+			}
+		})
+	}
+
+	// Elevation is interpolated too
+	samples := track.Sample(2.5)
+	if ele := samples[1].Location.Elevation; math.Abs(ele-15) > 0.1 {
+		t.Errorf("elevation halfway along the first segment = %.1f; want 15", ele)
+	}
+
+	single := &Track{Points: []*models.Location{{Latitude: 59, Longitude: 10}}}
+	if samples := single.Sample(5); len(samples) != 1 {
+		t.Errorf("Sample() of a single point returned %d samples; want 1", len(samples))
+	}
+	if samples := (&Track{}).Sample(5); samples != nil {
+		t.Errorf("Sample() of an empty track = %v; want nil", samples)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	track, err := LoadGPX(filepath.Join("testdata", "track.gpx"))
+	if err != nil {
+		t.Fatalf("LoadGPX() failed: %v", err)
+	}
+
+	depart := time.Date(2026, 6, 1, 8, 0, 0, 0, time.UTC)
+	r := Plan(track, depart, 10, 30*time.Minute)
+
+	if r.Name != "Coast ride" || r.Speed != 10 || !r.Departure.Equal(depart) {
+		t.Errorf("Plan() = %q at %v km/h from %v; want %q at 10 km/h from %v", r.Name, r.Speed, r.Departure, "Coast ride", depart)
+	}
+
+	want := []string{"08:00", "08:30", "09:00", "09:30"}
+	if len(r.Points) != len(want) {
+		t.Fatalf("Plan() returned %d points; want %d", len(r.Points), len(want))
+	}
+	for i, p := range r.Points {
+		if got := p.Arrival.Format("15:04"); got != want[i] {
+			t.Errorf("point %d arrival = %s; want %s", i, got, want[i])
+		}
+		if p.Forecast != nil {
+			t.Errorf("point %d has a forecast before fetching", i)
+		}
+	}
+	if got := r.Arrival().Format("15:04"); got != "09:30" {
+		t.Errorf("Arrival() = %s; want 09:30", got)
+	}
+}
+
+func TestParseSpeed(t *testing.T) {
+	tests := []struct {
+		input string
+		want  float64
+		err   bool
+	}{
+		{"15km/h", 15, false},
+		{"15 km/h", 15, false},
+		{"4.5", 4.5, false},
+		{"80 KPH", 80, false},
+		{"5m/s", 18, false},
+		{"10 mph", 16.09344, false},
+		{"6kn", 11.112, false},
+		{"6 knots", 11.112, false},
+		{"0", 0, true},
+		{"fast", 0, true},
+		{"15 furlongs", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseSpeed(tt.input)
+		if tt.err {
+			if err == nil {
+				t.Errorf("ParseSpeed(%q) = %v; want error", tt.input, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseSpeed(%q) failed: %v", tt.input, err)
+			continue
+		}
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("ParseSpeed(%q) = %v; want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseDeparture(t *testing.T) {
+	oslo := time.FixedZone("CEST", 2*60*60)
+	now := time.Date(2026, 6, 1, 10, 30, 0, 0, oslo)
+
+	tests := []struct {
+		input string
+		want  time.Time
+		err   bool
+	}{
+		{"now", now, false},
+		{"", now, false},
+		{"14:00", time.Date(2026, 6, 1, 14, 0, 0, 0, oslo), false},
+		{"0945", time.Date(2026, 6, 1, 9, 45, 0, 0, oslo), false},
+		{"08:00", time.Date(2026, 6, 2, 8, 0, 0, 0, oslo), false},
+		{"2026-06-03 07:15", time.Date(2026, 6, 3, 7, 15, 0, 0, oslo), false},
+		{"2026-06-03T07:15", time.Date(2026, 6, 3, 7, 15, 0, 0, oslo), false},
+		{"2026-06-03T07:15:00Z", time.Date(2026, 6, 3, 7, 15, 0, 0, time.UTC), false},
+		{"25:00", time.Time{}, true},
+		{"tomorrow", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseDeparture(tt.input, now)
+		if tt.err {
+			if err == nil {
+				t.Errorf("ParseDeparture(%q) = %v; want error", tt.input, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseDeparture(%q) failed: %v", tt.input, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseDeparture(%q) = %v; want %v", tt.input, got, tt.want)
+		}
+	}
+}
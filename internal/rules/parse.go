@@ -0,0 +1,368 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// tokenKind is the kind of a token in a rule
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenIdent
+	tokenOperator
+	tokenRange
+	tokenLeftParen
+	tokenRightParen
+)
+
+// token is a lexical element of a rule, with its position in the source
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// lex splits a rule into tokens
+func lex(src string) ([]token, error) {
+	var tokens []token
+	runes := []rune(src)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || (runes[i] == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1]))) {
+				i++
+			}
+			tokens = append(tokens, token{tokenNumber, string(runes[start:i]), start})
+		case unicode.IsLetter(r) || r == '_':
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{tokenIdent, strings.ToLower(string(runes[start:i])), start})
+		case r == '(':
+			i++
+			tokens = append(tokens, token{tokenLeftParen, "(", start})
+		case r == ')':
+			i++
+			tokens = append(tokens, token{tokenRightParen, ")", start})
+		case r == '.' && i+1 < len(runes) && runes[i+1] == '.':
+			i += 2
+			tokens = append(tokens, token{tokenRange, "..", start})
+		default:
+			op := ""
+			for _, candidate := range []string{"<=", ">=", "==", "!=", "&&", "||", "<", ">", "=", "!", "-"} {
+				if strings.HasPrefix(string(runes[i:]), candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected '%c' at position %d", r, start+1)
+			}
+			i += len([]rune(op))
+			tokens = append(tokens, token{tokenOperator, op, start})
+		}
+	}
+
+	return append(tokens, token{tokenEOF, "", len(runes)}), nil
+}
+
+// parser builds the condition of a rule from its tokens:
+//
+//	rule       = or [ "within" number [ unit ] ]
+//	or         = and { ( "or" | "||" ) and }
+//	and        = not { ( "and" | "&&" ) not }
+//	not        = ( "not" | "!" ) not | "(" or ")" | comparison
+//	comparison = term ( compare term | [ "not" ] "in" term ".." term )
+//	term       = [ "-" ] ( number | variable )
+type parser struct {
+	tokens []token
+	pos    int
+	vars   []*variable
+}
+
+// parse compiles the source of a rule into its condition and window
+func parse(src string) (condition, time.Duration, []*variable, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	if p.peek().kind == tokenEOF {
+		return nil, 0, nil, fmt.Errorf("empty rule")
+	}
+
+	cond, err := p.parseOr()
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	var within time.Duration
+	if p.peekKeyword("within") {
+		p.next()
+		if within, err = p.parseWindow(); err != nil {
+			return nil, 0, nil, err
+		}
+	}
+
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, 0, nil, p.unexpected(t)
+	}
+	return cond, within, p.vars, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// peekKeyword reports whether the next token is the given keyword
+func (p *parser) peekKeyword(word string) bool {
+	t := p.peek()
+	return t.kind == tokenIdent && t.text == word
+}
+
+// peekOperator reports whether the next token is one of the operators
+func (p *parser) peekOperator(ops ...string) bool {
+	t := p.peek()
+	if t.kind != tokenOperator {
+		return false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			return true
+		}
+	}
+	return false
+}
+
+// unexpected reports a token that does not fit the grammar
+func (p *parser) unexpected(t token) error {
+	if t.kind == tokenEOF {
+		return fmt.Errorf("unexpected end of rule")
+	}
+	return fmt.Errorf("unexpected '%s' at position %d", t.text, t.pos+1)
+}
+
+func (p *parser) parseOr() (condition, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("or") || p.peekOperator("||") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orCondition{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (condition, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("and") || p.peekOperator("&&") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andCondition{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (condition, error) {
+	if p.peekKeyword("not") || p.peekOperator("!") {
+		p.next()
+		cond, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notCondition{cond}, nil
+	}
+
+	if p.peek().kind == tokenLeftParen {
+		p.next()
+		cond, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.kind != tokenRightParen {
+			return nil, fmt.Errorf("missing ')' at position %d", t.pos+1)
+		}
+		return cond, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (condition, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+
+	negate := false
+	if p.peekKeyword("not") {
+		p.next()
+		negate = true
+		if !p.peekKeyword("in") {
+			return nil, p.unexpected(p.peek())
+		}
+	}
+
+	if p.peekKeyword("in") {
+		p.next()
+		start := p.peek().pos
+		low, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.kind != tokenRange {
+			return nil, fmt.Errorf("expected '..' at position %d", t.pos+1)
+		}
+		high, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+
+		// Only hour and weekday wrap around; any other range from a
+		// higher to a lower number would never match
+		v, isVariable := left.(variableTerm)
+		wrap := isVariable && cyclic[v.v.name]
+		lo, loConst := constant(low)
+		hi, hiConst := constant(high)
+		if !wrap && loConst && hiConst && lo > hi {
+			return nil, fmt.Errorf("empty range %g..%g at position %d (write the lower bound first)", lo, hi, start+1)
+		}
+
+		var cond condition = inCondition{left, low, high, wrap}
+		if negate {
+			cond = notCondition{cond}
+		}
+		return cond, nil
+	}
+
+	t := p.next()
+	if t.kind != tokenOperator {
+		return nil, fmt.Errorf("expected a comparison such as '<' or 'in' at position %d", t.pos+1)
+	}
+	op := t.text
+	if op == "=" {
+		op = "=="
+	}
+	if _, ok := comparisons[op]; !ok {
+		return nil, p.unexpected(t)
+	}
+
+	right, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	return compareCondition{op, left, right}, nil
+}
+
+func (p *parser) parseTerm() (term, error) {
+	if p.peekOperator("-") {
+		p.next()
+		t, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		return negativeTerm{t}, nil
+	}
+
+	t := p.next()
+	switch t.kind {
+	case tokenNumber:
+		v, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number '%s' at position %d", t.text, t.pos+1)
+		}
+		return numberTerm(v), nil
+	case tokenIdent:
+		v := lookupVariable(t.text)
+		if v == nil {
+			return nil, fmt.Errorf("unknown variable '%s' at position %d (available: %s)", t.text, t.pos+1, strings.Join(VariableNames(), ", "))
+		}
+		p.use(v)
+		return variableTerm{v}, nil
+	}
+	return nil, p.unexpected(t)
+}
+
+// constant returns the value of a term that is a number, such as 7 or -2
+func constant(t term) (float64, bool) {
+	switch t := t.(type) {
+	case numberTerm:
+		return float64(t), true
+	case negativeTerm:
+		v, ok := constant(t.t)
+		return -v, ok
+	}
+	return 0, false
+}
+
+// parseWindow parses the length of the within clause, such as "12h",
+// "2d" or "90m". A number without a unit is in hours.
+func (p *parser) parseWindow() (time.Duration, error) {
+	t := p.next()
+	if t.kind != tokenNumber {
+		return 0, fmt.Errorf("expected a duration such as 12h after 'within' at position %d", t.pos+1)
+	}
+	n, err := strconv.ParseFloat(t.text, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid duration '%s' at position %d", t.text, t.pos+1)
+	}
+
+	unit := time.Hour
+	if u := p.peek(); u.kind == tokenIdent {
+		switch u.text {
+		case "m", "min", "minutes":
+			unit = time.Minute
+		case "h", "hour", "hours":
+			unit = time.Hour
+		case "d", "day", "days":
+			unit = 24 * time.Hour
+		default:
+			return 0, fmt.Errorf("unknown unit '%s' at position %d (use m, h or d)", u.text, u.pos+1)
+		}
+		p.next()
+	}
+	return time.Duration(n * float64(unit)), nil
+}
+
+// use records that the rule refers to a variable
+func (p *parser) use(v *variable) {
+	for _, used := range p.vars {
+		if used == v {
+			return
+		}
+	}
+	p.vars = append(p.vars, v)
+}
@@ -0,0 +1,247 @@
+// Package rules evaluates user-defined conditions on the forecast, such
+// as "gust > 20" or "precip_prob > 70 and hour in 7..9 within 24h"
+package rules
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+// DefaultWithin is how far ahead a rule without a within clause looks
+const DefaultWithin = 24 * time.Hour
+
+// variable is a value of a forecast period that rules can refer to.
+// When has is set, it reports whether the period has the value at all.
+type variable struct {
+	name  string
+	unit  string
+	value func(hour *models.HourlyForecast, t time.Time) float64
+	has   func(hour *models.HourlyForecast) bool
+}
+
+// variables are the names rules can use, in the order they are listed
+var variables = []*variable{
+	{"temp", "°C", func(h *models.HourlyForecast, _ time.Time) float64 { return h.Temperature }, nil},
+	{"feels_like", "°C", func(h *models.HourlyForecast, _ time.Time) float64 { return h.FeelsLike() }, nil},
+	{"humidity", "%", func(h *models.HourlyForecast, _ time.Time) float64 { return h.Humidity }, nil},
+	{"wind", " m/s", func(h *models.HourlyForecast, _ time.Time) float64 { return h.WindSpeed }, nil},
+	{"gust", " m/s", func(h *models.HourlyForecast, _ time.Time) float64 { return h.WindGust },
+		func(h *models.HourlyForecast) bool { return h.HasWindGust }},
+	{"precip", " mm", func(h *models.HourlyForecast, _ time.Time) float64 { return h.Precipitation }, nil},
+	{"precip_prob", "%", func(h *models.HourlyForecast, _ time.Time) float64 { return h.PrecipitationProbability },
+		func(h *models.HourlyForecast) bool { return h.HasPrecipitationProbability }},
+	{"hour", "", func(_ *models.HourlyForecast, t time.Time) float64 { return float64(t.Hour()) }, nil},
+	{"weekday", "", func(_ *models.HourlyForecast, t time.Time) float64 {
+		// Monday is 1 and Sunday 7
+		return float64((int(t.Weekday())+6)%7 + 1)
+	}, nil},
+}
+
+// cyclic are the variables that wrap around, so that a range from a
+// higher to a lower value such as "hour in 22..2" spans the wrap
+var cyclic = map[string]bool{"hour": true, "weekday": true}
+
+// VariableNames returns the names rules can refer to
+func VariableNames() []string {
+	names := make([]string, len(variables))
+	for i, v := range variables {
+		names[i] = v.name
+	}
+	return names
+}
+
+// lookupVariable returns the variable with the given name, or nil
+func lookupVariable(name string) *variable {
+	for _, v := range variables {
+		if v.name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// env is the forecast period a condition is evaluated for. The time is
+// local, for hour and weekday.
+type env struct {
+	hour *models.HourlyForecast
+	time time.Time
+}
+
+// condition is a boolean expression in a rule
+type condition interface {
+	eval(e env) bool
+}
+
+// term is a numeric expression in a rule
+type term interface {
+	value(e env) float64
+}
+
+type andCondition struct{ left, right condition }
+
+func (c andCondition) eval(e env) bool { return c.left.eval(e) && c.right.eval(e) }
+
+type orCondition struct{ left, right condition }
+
+func (c orCondition) eval(e env) bool { return c.left.eval(e) || c.right.eval(e) }
+
+type notCondition struct{ cond condition }
+
+func (c notCondition) eval(e env) bool { return !c.cond.eval(e) }
+
+// comparisons are the operators of compareCondition
+var comparisons = map[string]func(a, b float64) bool{
+	"<":  func(a, b float64) bool { return a < b },
+	"<=": func(a, b float64) bool { return a <= b },
+	">":  func(a, b float64) bool { return a > b },
+	">=": func(a, b float64) bool { return a >= b },
+	"==": func(a, b float64) bool { return a == b },
+	"!=": func(a, b float64) bool { return a != b },
+}
+
+type compareCondition struct {
+	op          string
+	left, right term
+}
+
+func (c compareCondition) eval(e env) bool {
+	return comparisons[c.op](c.left.value(e), c.right.value(e))
+}
+
+// inCondition checks that a term is in an inclusive range. When wrap is
+// set and low is above high, the range wraps around: 22..2 is 22 to 2.
+type inCondition struct {
+	value, low, high term
+	wrap             bool
+}
+
+func (c inCondition) eval(e env) bool {
+	v, low, high := c.value.value(e), c.low.value(e), c.high.value(e)
+	if c.wrap && low > high {
+		return v >= low || v <= high
+	}
+	return v >= low && v <= high
+}
+
+type numberTerm float64
+
+func (t numberTerm) value(env) float64 { return float64(t) }
+
+type variableTerm struct{ v *variable }
+
+func (t variableTerm) value(e env) float64 { return t.v.value(e.hour, e.time) }
+
+type negativeTerm struct{ t term }
+
+func (t negativeTerm) value(e env) float64 { return -t.t.value(e) }
+
+// Expr is a compiled rule
+type Expr struct {
+	source string
+	cond   condition
+	within time.Duration
+	vars   []*variable
+}
+
+// Compile parses a rule such as "temp < 0 within 12h". Conditions compare
+// forecast variables and numbers with <, <=, >, >=, == and !=, check
+// ranges with "in 7..9" and combine with and, or, not and parentheses.
+// The optional within clause at the end limits how far ahead the rule
+// looks; it defaults to DefaultWithin.
+func Compile(src string) (*Expr, error) {
+	cond, within, vars, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+	return &Expr{source: src, cond: cond, within: within, vars: vars}, nil
+}
+
+// String returns the source of the rule
+func (x *Expr) String() string {
+	return x.source
+}
+
+// Within returns how far ahead the rule looks
+func (x *Expr) Within() time.Duration {
+	if x.within == 0 {
+		return DefaultWithin
+	}
+	return x.within
+}
+
+// Match returns the indexes of the forecast periods that satisfy the rule
+// and overlap the time from now until now plus Within. Hours and
+// weekdays are in the time zone of now. It fails when one of those
+// periods lacks a value the rule refers to, such as gusts beyond the
+// first days, rather than treating the value as 0.
+func (x *Expr) Match(forecast *models.Forecast, now time.Time) ([]int, error) {
+	until := now.Add(x.Within())
+
+	var matches []int
+	for i := range forecast.Hours {
+		hour := &forecast.Hours[i]
+		if !hour.Time.Before(until) || !periodEnd(forecast, i).After(now) {
+			continue
+		}
+		t := hour.Time.In(now.Location())
+		for _, v := range x.vars {
+			if v.has != nil && !v.has(hour) {
+				return nil, fmt.Errorf("the forecast has no %s for %s", v.name, t.Format("2006-01-02 15:04"))
+			}
+		}
+		if x.cond.eval(env{hour: hour, time: t}) {
+			matches = append(matches, i)
+		}
+	}
+	return matches, nil
+}
+
+// Check evaluates a rule against the forecast and returns an alert when
+// it matches, or nil when it does not
+func Check(name string, rule *models.Rule, forecast *models.Forecast, now time.Time) (*models.Alert, error) {
+	x, err := Compile(rule.When)
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := x.Match(forecast, now)
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, nil
+	}
+
+	first, last := matches[0], matches[len(matches)-1]
+	alert := &models.Alert{
+		Rule:     name,
+		When:     rule.When,
+		Message:  rule.Message,
+		Location: forecast.Location,
+		Start:    forecast.Hours[first].Time.In(now.Location()),
+		End:      periodEnd(forecast, last).In(now.Location()),
+		Periods:  len(matches),
+	}
+
+	hour := &forecast.Hours[first]
+	for _, v := range x.vars {
+		alert.Values = append(alert.Values, models.AlertValue{
+			Name:  v.name,
+			Value: math.Round(v.value(hour, alert.Start)*10) / 10,
+			Unit:  v.unit,
+		})
+	}
+	return alert, nil
+}
+
+// periodEnd returns the end of the i-th forecast period: the start of the
+// next one, or an hour after the start for the last one
+func periodEnd(forecast *models.Forecast, i int) time.Time {
+	if i+1 < len(forecast.Hours) {
+		return forecast.Hours[i+1].Time
+	}
+	return forecast.Hours[i].Time.Add(time.Hour)
+}
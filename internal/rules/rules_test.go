@@ -0,0 +1,236 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+// testForecast returns 48 hourly periods from midnight UTC on Monday
+// 2026-01-05, getting colder and windier by the hour, with rain from
+// 07:00 to 09:00 on the first day
+func testForecast() *models.Forecast {
+	start := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	f := &models.Forecast{Location: &models.Location{Name: "Stavern", Latitude: 59.0, Longitude: 10.0}}
+	for i := 0; i < 48; i++ {
+		hour := models.HourlyForecast{
+			Time:        start.Add(time.Duration(i) * time.Hour),
+			Temperature: 5 - float64(i)*0.5,
+			Humidity:    80,
+			WindSpeed:   2 + float64(i)*0.25,
+			WindGust:    4 + float64(i)*0.5,
+
+			HasWindGust:                 true,
+			HasPrecipitationProbability: true,
+		}
+		if i >= 7 && i <= 9 {
+			hour.Precipitation = 1.2
+			hour.PrecipitationProbability = 90
+		}
+		f.Hours = append(f.Hours, hour)
+	}
+	return f
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		rule   string
+		within time.Duration
+		err    string
+	}{
+		{rule: "temp < 0", within: DefaultWithin},
+		{rule: "temp < 0 within 12h", within: 12 * time.Hour},
+		{rule: "gust > 20 within 2d", within: 48 * time.Hour},
+		{rule: "wind >= 10 within 90m", within: 90 * time.Minute},
+		{rule: "wind >= 10 within 6", within: 6 * time.Hour},
+		{rule: "precip_prob > 70 and hour in 7..9", within: DefaultWithin},
+		{rule: "not (temp > -5 || wind < 3) && weekday not in 6..7", within: DefaultWithin},
+		{rule: "TEMP = 0.5", within: DefaultWithin},
+		{rule: "", err: "empty rule"},
+		{rule: "tmp < 0", err: "unknown variable 'tmp' at position 1"},
+		{rule: "temp <", err: "unexpected end of rule"},
+		{rule: "temp 0", err: "expected a comparison such as '<' or 'in' at position 6"},
+		{rule: "temp < 0 and", err: "unexpected end of rule"},
+		{rule: "(temp < 0", err: "missing ')' at position 10"},
+		{rule: "hour in 7-9", err: "expected '..' at position 10"},
+		{rule: "hour in 22..2", within: DefaultWithin},
+		{rule: "temp in 5..-5", err: "empty range 5..-5 at position 9"},
+		{rule: "temp < 0 within", err: "expected a duration such as 12h after 'within' at position 16"},
+		{rule: "temp < 0 within 2w", err: "unknown unit 'w' at position 18"},
+		{rule: "temp < 0 wind > 5", err: "unexpected 'wind' at position 10"},
+		{rule: "temp ~ 0", err: "unexpected '~' at position 6"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			x, err := Compile(tt.rule)
+			if tt.err != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.err) {
+					t.Errorf("Compile(%q) error = %v; want %q", tt.rule, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Compile(%q) failed: %v", tt.rule, err)
+			}
+			if x.Within() != tt.within {
+				t.Errorf("Compile(%q).Within() = %v; want %v", tt.rule, x.Within(), tt.within)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	forecast := testForecast()
+	now := time.Date(2026, 1, 5, 0, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		rule     string
+		expected string // matching hours of the first day, or day:hour after it
+	}{
+		{"temp < 0", "11 12 13 14 15 16 17 18 19 20 21 22 23 1:00"},
+		{"temp < 0 within 12h", "11 12"},
+		{"temp <= 0 within 12h", "10 11 12"},
+		{"gust > 20 within 2d", "1:09 1:10 1:11 1:12 1:13 1:14 1:15 1:16 1:17 1:18 1:19 1:20 1:21 1:22 1:23"},
+		{"precip_prob > 70 and hour in 7..9", "7 8 9"},
+		{"precip > 0 and not hour in 8..9", "7"},
+		{"precip > 0 and hour not in 8..9", "7"},
+		{"temp > 4 or precip > 1", "0 1 7 8 9"},
+		{"(temp > 4 or precip > 1) and wind > 2", "1 7 8 9"},
+		{"temp > 4 within 90m", "0 1"},
+		{"temp == 4", "2"},
+		{"temp < -10 within 2d", "1:07 1:08 1:09 1:10 1:11 1:12 1:13 1:14 1:15 1:16 1:17 1:18 1:19 1:20 1:21 1:22 1:23"},
+		{"weekday == 2 and hour == 0 within 2d", "1:00"},
+		{"hour in 22..2", "0 1 2 22 23 1:00"},
+		{"hour not in 22..2 and hour < 4", "3"},
+		{"weekday in 7..1 and hour in 23..1 within 2d", "0 1 23"},
+		{"humidity > 90", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			x, err := Compile(tt.rule)
+			if err != nil {
+				t.Fatalf("Compile(%q) failed: %v", tt.rule, err)
+			}
+
+			matches, err := x.Match(forecast, now)
+			if err != nil {
+				t.Fatalf("Match(%q) failed: %v", tt.rule, err)
+			}
+
+			var got []string
+			for _, i := range matches {
+				if i < 24 {
+					got = append(got, fmt.Sprint(i))
+				} else {
+					got = append(got, fmt.Sprintf("1:%02d", i-24))
+				}
+			}
+			if strings.Join(got, " ") != tt.expected {
+				t.Errorf("Match(%q) = %s; want %s", tt.rule, strings.Join(got, " "), tt.expected)
+			}
+		})
+	}
+}
+
+func TestMatchLocalTime(t *testing.T) {
+	forecast := testForecast()
+
+	// 07:00 UTC is 08:00 in Oslo in winter
+	oslo := time.FixedZone("CET", 3600)
+	now := time.Date(2026, 1, 5, 1, 0, 0, 0, oslo)
+
+	x, err := Compile("precip > 0 and hour == 8")
+	if err != nil {
+		t.Fatalf("Compile() failed: %v", err)
+	}
+	got, err := x.Match(forecast, now)
+	if err != nil {
+		t.Fatalf("Match() failed: %v", err)
+	}
+	if len(got) != 1 || got[0] != 7 {
+		t.Errorf("Match() = %v; want [7]", got)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	forecast := testForecast()
+	now := time.Date(2026, 1, 5, 0, 30, 0, 0, time.UTC)
+
+	rule := &models.Rule{When: "temp < 0 and gust > 9 within 12h", Message: "Frost and wind"}
+	alert, err := Check("frost", rule, forecast, now)
+	if err != nil {
+		t.Fatalf("Check() failed: %v", err)
+	}
+	if alert == nil {
+		t.Fatal("Check() = nil; want an alert")
+	}
+
+	if alert.Rule != "frost" || alert.When != rule.When || alert.Message != rule.Message || alert.Location != forecast.Location {
+		t.Errorf("Check() = %+v; want the rule, message and location", alert)
+	}
+	if want := forecast.Hours[11].Time; !alert.Start.Equal(want) {
+		t.Errorf("Check().Start = %v; want %v", alert.Start, want)
+	}
+	if want := forecast.Hours[13].Time; !alert.End.Equal(want) {
+		t.Errorf("Check().End = %v; want %v", alert.End, want)
+	}
+	if alert.Periods != 2 {
+		t.Errorf("Check().Periods = %d; want 2", alert.Periods)
+	}
+
+	want := []models.AlertValue{{Name: "temp", Value: -0.5, Unit: "°C"}, {Name: "gust", Value: 9.5, Unit: " m/s"}}
+	if fmt.Sprint(alert.Values) != fmt.Sprint(want) {
+		t.Errorf("Check().Values = %v; want %v", alert.Values, want)
+	}
+
+	// A rule that does not match gives no alert
+	alert, err = Check("storm", &models.Rule{When: "gust > 50"}, forecast, now)
+	if err != nil || alert != nil {
+		t.Errorf("Check(storm) = %v, %v; want nil, nil", alert, err)
+	}
+
+	if _, err := Check("broken", &models.Rule{When: "gust >"}, forecast, now); err == nil {
+		t.Error("Check() of an invalid rule expected error but got none")
+	}
+}
+
+func TestCheckMissingValue(t *testing.T) {
+	// MET leaves out gusts and the chance of precipitation for some
+	// periods, such as from the third day on
+	forecast := testForecast()
+	for i := 24; i < len(forecast.Hours); i++ {
+		forecast.Hours[i].HasWindGust = false
+		forecast.Hours[i].HasPrecipitationProbability = false
+	}
+	now := time.Date(2026, 1, 5, 0, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		rule string
+		err  string
+	}{
+		{"gust > 50 within 2d", "the forecast has no gust for 2026-01-06 00:00"},
+		{"temp < 0 or precip_prob > 70 within 2d", "the forecast has no precip_prob for 2026-01-06 00:00"},
+		{"gust > 50 within 12h", ""},
+		{"temp < -100 within 2d", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			alert, err := Check("test", &models.Rule{When: tt.rule}, forecast, now)
+			if tt.err == "" {
+				if err != nil || alert != nil {
+					t.Errorf("Check() = %v, %v; want nil, nil", alert, err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("Check() error = %v; want %q", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"fmt"
+	"sync"
+)
+
+// flight is a request in progress, whose result every caller that
+// joined it receives
+type flight struct {
+	wg   sync.WaitGroup
+	body []byte
+	err  error
+}
+
+// flightGroup runs a function only once at a time per key: callers that
+// arrive while it runs wait for it and share its result, so that a burst
+// of requests for the same forecast makes one upstream request
+type flightGroup struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+// do runs fn for key unless it is already running, and returns its
+// result. A panic in fn is returned as an error, to the caller that ran
+// it and to every caller waiting for it.
+func (g *flightGroup) do(key string, fn func() ([]byte, error)) ([]byte, error) {
+	g.mu.Lock()
+	if g.flights == nil {
+		g.flights = make(map[string]*flight)
+	}
+	if f, ok := g.flights[key]; ok {
+		g.mu.Unlock()
+		f.wg.Wait()
+		return f.body, f.err
+	}
+
+	f := &flight{}
+	f.wg.Add(1)
+	g.flights[key] = f
+	g.mu.Unlock()
+
+	g.run(key, f, fn)
+	return f.body, f.err
+}
+
+// run runs fn for the flight of key and lets the callers waiting for it
+// go, even when fn panics
+func (g *flightGroup) run(key string, f *flight, fn func() ([]byte, error)) {
+	defer func() {
+		if r := recover(); r != nil {
+			f.body, f.err = nil, fmt.Errorf("panic: %v", r)
+		}
+
+		g.mu.Lock()
+		delete(g.flights, key)
+		g.mu.Unlock()
+		f.wg.Done()
+	}()
+
+	f.body, f.err = fn()
+}
@@ -0,0 +1,361 @@
+// Package server serves weather data as a JSON REST API, so that
+// dashboards and phones can share one cached sky instance
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/api"
+	"github.com/kristofferrisa/sky-cli/internal/formatter"
+	"github.com/kristofferrisa/sky-cli/internal/geocode"
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+// Limits of the hours and days a request can ask for
+const (
+	DefaultHours = 12
+	MaxHours     = 240
+	DefaultDays  = 7
+	MaxDays      = 10
+)
+
+// fetchTimeout bounds an upstream request. It does not depend on the
+// request that started it, since other requests may be waiting for it.
+const fetchTimeout = 30 * time.Second
+
+// maxLocations bounds the locations the server remembers, since every
+// pair of coordinates is a new one
+const maxLocations = 1024
+
+// Server answers weather requests from a shared client
+type Server struct {
+	Client api.WeatherClient
+
+	// Locate finds a location by name, such as a saved location, a place
+	// or coordinates like "59.05,10.03". An empty name asks for the
+	// default location. Errors for names that match no place, or several,
+	// wrap geocode.ErrNotFound or geocode.ErrAmbiguous, and those for bad
+	// coordinates geocode.ErrInvalidCoordinates; any other error is a
+	// failure to look the name up.
+	Locate func(name string) (*models.Location, error)
+
+	// Origins are the origins allowed to call the API from a browser;
+	// "*" allows any
+	Origins []string
+
+	// MaxAge is how long clients may cache responses, usually the cache
+	// TTL; zero makes clients revalidate every time
+	MaxAge time.Duration
+
+	// Metrics answers GET /metrics when set, such as a Prometheus
+	// exporter
+	Metrics http.Handler
+
+	// Log receives a line for every request; it defaults to discarding
+	// them
+	Log io.Writer
+
+	flights flightGroup
+	ready   atomic.Bool
+
+	// locations remembers found locations by name, since finding a place
+	// reads the gazetteer
+	mu        sync.Mutex
+	locations map[string]*models.Location
+}
+
+// SetReady marks the server ready to receive traffic, or not, as when it
+// is shutting down
+func (s *Server) SetReady(ready bool) {
+	s.ready.Store(ready)
+}
+
+// Handler returns the handler of the API:
+//
+//	GET /v1/current   current weather
+//	GET /v1/forecast  hourly forecast, with ?hours= (default 12)
+//	GET /v1/daily     daily forecast, with ?days= (default 7)
+//	GET /healthz      200 while the process runs
+//	GET /readyz       200 while the server accepts traffic
+//	GET /metrics      Prometheus metrics, when Metrics is set
+//
+// Weather endpoints take ?location= or ?lat=&lon=, and use the default
+// location without either.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/current", s.handleCurrent)
+	mux.HandleFunc("GET /v1/forecast", s.handleForecast)
+	mux.HandleFunc("GET /v1/daily", s.handleDaily)
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		if !s.ready.Load() {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "not ready"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ready"})
+	})
+	if s.Metrics != nil {
+		mux.Handle("GET /metrics", s.Metrics)
+	}
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, fmt.Errorf("no endpoint %s %s", r.Method, r.URL.Path))
+	})
+	return s.logRequests(s.cors(mux))
+}
+
+func (s *Server) handleCurrent(w http.ResponseWriter, r *http.Request) {
+	s.serveWeather(w, r, "current", 0, func(ctx context.Context, loc *models.Location) (interface{}, error) {
+		weather, err := s.Client.GetCurrentWeather(ctx, loc)
+		if err != nil {
+			return nil, err
+		}
+		return formatter.NewJSONWeather(weather), nil
+	})
+}
+
+func (s *Server) handleForecast(w http.ResponseWriter, r *http.Request) {
+	hours, err := intParam(r, "hours", DefaultHours, MaxHours)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.serveWeather(w, r, "forecast", hours, func(ctx context.Context, loc *models.Location) (interface{}, error) {
+		forecast, err := s.Client.GetHourlyForecast(ctx, loc, hours)
+		if err != nil {
+			return nil, err
+		}
+		return formatter.NewJSONForecast(forecast), nil
+	})
+}
+
+func (s *Server) handleDaily(w http.ResponseWriter, r *http.Request) {
+	days, err := intParam(r, "days", DefaultDays, MaxDays)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.serveWeather(w, r, "daily", days, func(ctx context.Context, loc *models.Location) (interface{}, error) {
+		daily, err := s.Client.GetDailyForecast(ctx, loc, days)
+		if err != nil {
+			return nil, err
+		}
+		return formatter.NewJSONDailyForecast(daily), nil
+	})
+}
+
+// serveWeather answers a weather request with the JSON fetch returns for
+// the requested location. Concurrent requests for the same data share
+// one fetch, and a client that already has the data gets 304 Not
+// Modified.
+func (s *Server) serveWeather(w http.ResponseWriter, r *http.Request, kind string, n int,
+	fetch func(context.Context, *models.Location) (interface{}, error)) {
+	loc, status, err := s.location(r)
+	if err != nil {
+		writeError(w, status, err)
+		return
+	}
+
+	key := fmt.Sprintf("%s:%s:%.4f:%.4f:%d", kind, loc.Name, loc.Latitude, loc.Longitude, n)
+	body, err := s.flights.do(key, func() ([]byte, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), fetchTimeout)
+		defer cancel()
+
+		v, err := fetch(ctx, loc)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(v)
+	})
+	if err != nil {
+		writeError(w, http.StatusBadGateway, fmt.Errorf("failed to fetch weather for %s: %w", loc, err))
+		return
+	}
+
+	h := fnv.New64a()
+	h.Write(body)
+	etag := fmt.Sprintf(`"%016x"`, h.Sum64())
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(s.MaxAge.Seconds())))
+	if matchesETag(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+	w.Write([]byte("\n"))
+}
+
+// location returns the location a request asks for, or the status to
+// answer with when it cannot be found
+func (s *Server) location(r *http.Request) (*models.Location, int, error) {
+	query := r.URL.Query()
+	name := strings.TrimSpace(query.Get("location"))
+
+	lat, lon := query.Get("lat"), query.Get("lon")
+	if lat != "" || lon != "" {
+		if name != "" {
+			return nil, http.StatusBadRequest, errors.New("use either location or lat and lon")
+		}
+		if lat == "" || lon == "" {
+			return nil, http.StatusBadRequest, errors.New("both lat and lon must be given")
+		}
+
+		coords := &models.Location{}
+		var err error
+		if coords.Latitude, err = strconv.ParseFloat(lat, 64); err != nil {
+			return nil, http.StatusBadRequest, fmt.Errorf("invalid lat '%s'", lat)
+		}
+		if coords.Longitude, err = strconv.ParseFloat(lon, 64); err != nil {
+			return nil, http.StatusBadRequest, fmt.Errorf("invalid lon '%s'", lon)
+		}
+		if err := coords.Validate(); err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		name = fmt.Sprintf("%.4f,%.4f", coords.Latitude, coords.Longitude)
+	}
+
+	s.mu.Lock()
+	loc, ok := s.locations[name]
+	s.mu.Unlock()
+	if ok {
+		return loc, 0, nil
+	}
+
+	loc, err := s.Locate(name)
+	switch {
+	case errors.Is(err, geocode.ErrNotFound), errors.Is(err, geocode.ErrAmbiguous):
+		return nil, http.StatusNotFound, err
+	case errors.Is(err, geocode.ErrInvalidCoordinates):
+		return nil, http.StatusBadRequest, err
+	case err != nil:
+		return nil, http.StatusServiceUnavailable, err
+	}
+
+	s.mu.Lock()
+	if s.locations == nil || len(s.locations) >= maxLocations {
+		s.locations = make(map[string]*models.Location)
+	}
+	s.locations[name] = loc
+	s.mu.Unlock()
+	return loc, 0, nil
+}
+
+// cors lets browsers on the allowed origins call the API and answers
+// their preflight requests
+func (s *Server) cors(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" || !s.allowsOrigin(origin) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if slices.Contains(s.Origins, "*") {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		} else {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Add("Vary", "Origin")
+		}
+		w.Header().Set("Access-Control-Expose-Headers", "ETag")
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "If-None-Match")
+			w.Header().Set("Access-Control-Max-Age", "86400")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) allowsOrigin(origin string) bool {
+	for _, o := range s.Origins {
+		if o == "*" || strings.EqualFold(strings.TrimSuffix(o, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// statusWriter records the status of a response for the request log
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// logRequests writes a line for every request to the log
+func (s *Server) logRequests(next http.Handler) http.Handler {
+	if s.Log == nil {
+		return next
+	}
+	var mu sync.Mutex
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r)
+
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprintf(s.Log, "%s %s %s %d %s\n", start.Format("2006-01-02 15:04:05"),
+			r.Method, r.URL.RequestURI(), sw.status, time.Since(start).Round(time.Millisecond))
+	})
+}
+
+// matchesETag reports whether an If-None-Match header lists etag
+func matchesETag(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// intParam reads a positive integer query parameter of at most limit
+func intParam(r *http.Request, name string, def, limit int) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 || n > limit {
+		return 0, fmt.Errorf("%s must be a number from 1 to %d", name, limit)
+	}
+	return n, nil
+}
+
+// writeJSON writes v as the JSON body of a response
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError answers with {"error": "..."}
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
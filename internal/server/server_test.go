@@ -0,0 +1,359 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/api"
+	"github.com/kristofferrisa/sky-cli/internal/formatter"
+	"github.com/kristofferrisa/sky-cli/internal/geocode"
+	"github.com/kristofferrisa/sky-cli/internal/models"
+)
+
+// fakeClient returns weather whose temperature is the latitude. Requests
+// wait for release when it is set.
+type fakeClient struct {
+	api.WeatherClient
+
+	mu       sync.Mutex
+	requests int
+	release  chan struct{}
+	fail     bool
+}
+
+func (c *fakeClient) start() error {
+	c.mu.Lock()
+	c.requests++
+	release, fail := c.release, c.fail
+	c.mu.Unlock()
+
+	if release != nil {
+		<-release
+	}
+	if fail {
+		return errors.New("upstream unavailable")
+	}
+	return nil
+}
+
+func (c *fakeClient) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.requests
+}
+
+func (c *fakeClient) GetCurrentWeather(ctx context.Context, loc *models.Location) (*models.Weather, error) {
+	if err := c.start(); err != nil {
+		return nil, err
+	}
+	now := time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC)
+	return &models.Weather{Location: loc, Timestamp: now, UpdatedAt: now, Temperature: loc.Latitude, Symbol: "cloudy"}, nil
+}
+
+func (c *fakeClient) GetHourlyForecast(ctx context.Context, loc *models.Location, hours int) (*models.Forecast, error) {
+	if err := c.start(); err != nil {
+		return nil, err
+	}
+	forecast := &models.Forecast{Location: loc}
+	start := time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < hours; i++ {
+		forecast.Hours = append(forecast.Hours, models.HourlyForecast{Time: start.Add(time.Duration(i) * time.Hour), Temperature: loc.Latitude})
+	}
+	return forecast, nil
+}
+
+func (c *fakeClient) GetDailyForecast(ctx context.Context, loc *models.Location, days int) (*models.DailyForecast, error) {
+	if err := c.start(); err != nil {
+		return nil, err
+	}
+	daily := &models.DailyForecast{Location: loc}
+	for i := 0; i < days; i++ {
+		daily.Days = append(daily.Days, models.DailySummary{Date: time.Date(2026, 1, 5+i, 0, 0, 0, 0, time.UTC)})
+	}
+	return daily, nil
+}
+
+// locate knows Oslo, coordinates and a default location
+func locate(name string) (*models.Location, error) {
+	switch {
+	case name == "":
+		return &models.Location{Name: "Home", Latitude: 59.05, Longitude: 10.03}, nil
+	case strings.EqualFold(name, "oslo"):
+		return &models.Location{Name: "Oslo", Latitude: 59.91, Longitude: 10.75}, nil
+	case name == "99,10":
+		return nil, fmt.Errorf("%w '%s': latitude out of range", geocode.ErrInvalidCoordinates, name)
+	case strings.Contains(name, ","):
+		var lat, lon float64
+		fmt.Sscanf(name, "%f,%f", &lat, &lon)
+		return &models.Location{Latitude: lat, Longitude: lon}, nil
+	case name == "springfield":
+		return nil, fmt.Errorf("%w: '%s' matches several places", geocode.ErrAmbiguous, name)
+	case name == "offline":
+		return nil, fmt.Errorf("failed to load the gazetteer")
+	}
+	return nil, fmt.Errorf("%w: '%s'", geocode.ErrNotFound, name)
+}
+
+func newTestServer(t *testing.T, client *fakeClient, origins ...string) (*Server, *httptest.Server) {
+	s := &Server{Client: client, Locate: locate, Origins: origins, MaxAge: 10 * time.Minute}
+	ts := httptest.NewServer(s.Handler())
+	t.Cleanup(ts.Close)
+	return s, ts
+}
+
+func get(t *testing.T, url string, header http.Header) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", url, err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestEndpoints(t *testing.T) {
+	client := &fakeClient{}
+	_, ts := newTestServer(t, client)
+
+	var weather formatter.JSONWeather
+	resp := get(t, ts.URL+"/v1/current?location=oslo", nil)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/json" {
+		t.Fatalf("current = %d %s; want 200 JSON", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&weather); err != nil || weather.Location.Name != "Oslo" || weather.Temperature != 59.91 {
+		t.Errorf("current = %+v (%v); want the weather in Oslo", weather, err)
+	}
+
+	var forecast formatter.JSONForecast
+	resp = get(t, ts.URL+"/v1/forecast?lat=60.5&lon=11&hours=24", nil)
+	if err := json.NewDecoder(resp.Body).Decode(&forecast); err != nil || len(forecast.Hours) != 24 || forecast.Hours[0].Temperature != 60.5 {
+		t.Errorf("forecast = %d hours (%v); want 24 at 60.5,11", len(forecast.Hours), err)
+	}
+
+	var daily formatter.JSONDailyForecast
+	resp = get(t, ts.URL+"/v1/daily", nil)
+	if err := json.NewDecoder(resp.Body).Decode(&daily); err != nil || len(daily.Days) != DefaultDays || daily.Location.Name != "Home" {
+		t.Errorf("daily = %d days at %v (%v); want %d at the default location", len(daily.Days), daily.Location, err, DefaultDays)
+	}
+
+	tests := []struct {
+		path   string
+		status int
+		err    string
+	}{
+		{"/v1/current?location=atlantis", http.StatusNotFound, "not found"},
+		{"/v1/current?location=springfield", http.StatusNotFound, "several places"},
+		{"/v1/current?location=99,10", http.StatusBadRequest, "invalid coordinates"},
+		{"/v1/current?location=offline", http.StatusServiceUnavailable, "gazetteer"},
+		{"/v1/current?lat=59", http.StatusBadRequest, "both lat and lon"},
+		{"/v1/current?lat=91&lon=10", http.StatusBadRequest, "latitude"},
+		{"/v1/current?lat=north&lon=10", http.StatusBadRequest, "invalid lat"},
+		{"/v1/current?location=oslo&lat=59&lon=10", http.StatusBadRequest, "either location"},
+		{"/v1/forecast?hours=0", http.StatusBadRequest, "hours must be"},
+		{"/v1/daily?days=11", http.StatusBadRequest, "days must be"},
+		{"/v2/current", http.StatusNotFound, "no endpoint"},
+	}
+	for _, tt := range tests {
+		resp := get(t, ts.URL+tt.path, nil)
+		var body map[string]string
+		json.NewDecoder(resp.Body).Decode(&body)
+		if resp.StatusCode != tt.status || !strings.Contains(strings.ToLower(body["error"]), tt.err) {
+			t.Errorf("GET %s = %d %q; want %d with %q", tt.path, resp.StatusCode, body["error"], tt.status, tt.err)
+		}
+	}
+
+	client.fail = true
+	if resp := get(t, ts.URL+"/v1/current?location=oslo", nil); resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("GET with a failing upstream = %d; want 502", resp.StatusCode)
+	}
+}
+
+func TestSingleFlight(t *testing.T) {
+	client := &fakeClient{release: make(chan struct{})}
+	_, ts := newTestServer(t, client)
+
+	const n = 5
+	var wg sync.WaitGroup
+	statuses := make([]int, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, err := http.Get(ts.URL + "/v1/current?location=oslo")
+			if err != nil {
+				t.Errorf("GET failed: %v", err)
+				return
+			}
+			resp.Body.Close()
+			statuses[i] = resp.StatusCode
+		}(i)
+	}
+
+	// Let the requests pile up behind the first before it returns
+	deadline := time.Now().Add(5 * time.Second)
+	for client.count() < 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(client.release)
+	wg.Wait()
+
+	if got := client.count(); got != 1 {
+		t.Errorf("%d concurrent requests made %d upstream requests; want 1", n, got)
+	}
+	for i, status := range statuses {
+		if status != http.StatusOK {
+			t.Errorf("request %d = %d; want 200", i, status)
+		}
+	}
+}
+
+func TestSingleFlightPanic(t *testing.T) {
+	var g flightGroup
+	started, release := make(chan struct{}), make(chan struct{})
+	panics := func() ([]byte, error) {
+		close(started)
+		<-release
+		panic("boom")
+	}
+
+	errs := make(chan error, 2)
+	go func() {
+		_, err := g.do("key", panics)
+		errs <- err
+	}()
+	<-started
+	go func() {
+		_, err := g.do("key", func() ([]byte, error) { panic("boom") })
+		errs <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-errs:
+			if err == nil || !strings.Contains(err.Error(), "boom") {
+				t.Errorf("do() error = %v; want the panic", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("do() did not return after the request panicked")
+		}
+	}
+
+	// The key is free again
+	body, err := g.do("key", func() ([]byte, error) { return []byte("ok"), nil })
+	if err != nil || string(body) != "ok" {
+		t.Errorf("do() after a panic = %q, %v; want ok", body, err)
+	}
+}
+
+func TestETag(t *testing.T) {
+	_, ts := newTestServer(t, &fakeClient{})
+
+	resp := get(t, ts.URL+"/v1/current?location=oslo", nil)
+	etag := resp.Header.Get("ETag")
+	if etag == "" || resp.Header.Get("Cache-Control") != "public, max-age=600" {
+		t.Fatalf("headers = %v; want an ETag and a max-age of the cache TTL", resp.Header)
+	}
+
+	resp = get(t, ts.URL+"/v1/current?location=oslo", http.Header{"If-None-Match": {`"other", ` + etag}})
+	if resp.StatusCode != http.StatusNotModified {
+		t.Errorf("GET with a matching ETag = %d; want 304", resp.StatusCode)
+	}
+
+	resp = get(t, ts.URL+"/v1/current?location=oslo", http.Header{"If-None-Match": {`"other"`}})
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("GET with another ETag = %d; want 200", resp.StatusCode)
+	}
+
+	resp = get(t, ts.URL+"/v1/forecast?location=oslo", http.Header{"If-None-Match": {etag}})
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("ETag") == etag {
+		t.Errorf("forecast with the ETag of current = %d %s; want 200 with its own ETag", resp.StatusCode, resp.Header.Get("ETag"))
+	}
+}
+
+func TestCORS(t *testing.T) {
+	_, ts := newTestServer(t, &fakeClient{}, "https://dash.example.com")
+
+	resp := get(t, ts.URL+"/v1/current", http.Header{"Origin": {"https://dash.example.com"}})
+	if got := resp.Header.Get("Access-Control-Allow-Origin"); got != "https://dash.example.com" {
+		t.Errorf("Allow-Origin = %q; want the dashboard", got)
+	}
+	if got := resp.Header.Get("Access-Control-Expose-Headers"); got != "ETag" {
+		t.Errorf("Expose-Headers = %q; want ETag", got)
+	}
+
+	resp = get(t, ts.URL+"/v1/current", http.Header{"Origin": {"https://evil.example.com"}})
+	if got := resp.Header.Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Allow-Origin for another origin = %q; want none", got)
+	}
+
+	req, _ := http.NewRequest(http.MethodOptions, ts.URL+"/v1/forecast", nil)
+	req.Header.Set("Origin", "https://dash.example.com")
+	req.Header.Set("Access-Control-Request-Method", "GET")
+	preflight, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("preflight failed: %v", err)
+	}
+	preflight.Body.Close()
+	if preflight.StatusCode != http.StatusNoContent || preflight.Header.Get("Access-Control-Allow-Methods") != "GET, OPTIONS" {
+		t.Errorf("preflight = %d %v; want 204 allowing GET", preflight.StatusCode, preflight.Header)
+	}
+
+	_, any := newTestServer(t, &fakeClient{}, "*")
+	resp = get(t, any.URL+"/v1/current", http.Header{"Origin": {"https://anywhere.example.com"}})
+	if got := resp.Header.Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Allow-Origin with * = %q; want *", got)
+	}
+}
+
+func TestHealth(t *testing.T) {
+	s, ts := newTestServer(t, &fakeClient{})
+
+	if resp := get(t, ts.URL+"/healthz", nil); resp.StatusCode != http.StatusOK {
+		t.Errorf("healthz = %d; want 200", resp.StatusCode)
+	}
+	if resp := get(t, ts.URL+"/readyz", nil); resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("readyz before SetReady = %d; want 503", resp.StatusCode)
+	}
+	s.SetReady(true)
+	if resp := get(t, ts.URL+"/readyz", nil); resp.StatusCode != http.StatusOK {
+		t.Errorf("readyz when ready = %d; want 200", resp.StatusCode)
+	}
+	s.SetReady(false)
+	if resp := get(t, ts.URL+"/readyz", nil); resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("readyz while shutting down = %d; want 503", resp.StatusCode)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	_, ts := newTestServer(t, &fakeClient{})
+	if resp := get(t, ts.URL+"/metrics", nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("metrics without an exporter = %d; want 404", resp.StatusCode)
+	}
+
+	s := &Server{Client: &fakeClient{}, Locate: locate, Metrics: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("sky_up 1\n"))
+	})}
+	withMetrics := httptest.NewServer(s.Handler())
+	defer withMetrics.Close()
+	if resp := get(t, withMetrics.URL+"/metrics", nil); resp.StatusCode != http.StatusOK {
+		t.Errorf("metrics = %d; want 200", resp.StatusCode)
+	}
+}
@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// DefaultWidth is the width assumed when the terminal's is unknown, such as
+// when the output is piped
+const DefaultWidth = 80
+
+// TerminalWidth returns the width of the terminal in columns: $COLUMNS
+// when set, else the width of the terminal on standard output, else
+// DefaultWidth
+func TerminalWidth() int {
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	if width := terminalWidth(os.Stdout); width > 0 {
+		return width
+	}
+	return DefaultWidth
+}
+
+// Unicode reports whether the terminal is likely to show Unicode, such as
+// the block and Braille characters of charts. It does not when TERM is
+// dumb or when the locale, from the first of LC_ALL, LC_CTYPE and LANG
+// that is set, is not UTF-8, such as C or en_US.ISO-8859-1. Without a
+// locale, as on Windows, Unicode is assumed.
+func Unicode() bool {
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		locale := os.Getenv(name)
+		if locale == "" {
+			continue
+		}
+		// Drop a modifier such as @euro, then compare the codeset
+		locale, _, _ = strings.Cut(strings.ToLower(locale), "@")
+		return strings.HasSuffix(locale, ".utf-8") || strings.HasSuffix(locale, ".utf8")
+	}
+	return true
+}
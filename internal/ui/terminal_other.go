@@ -0,0 +1,11 @@
+//go:build !unix
+
+package ui
+
+import "os"
+
+// terminalWidth returns 0, as the width of the terminal is only known on
+// Unix
+func terminalWidth(f *os.File) int {
+	return 0
+}
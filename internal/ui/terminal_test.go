@@ -0,0 +1,32 @@
+package ui
+
+import "testing"
+
+func TestUnicode(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want bool
+	}{
+		{"No locale", nil, true},
+		{"UTF-8 locale", map[string]string{"LANG": "en_US.UTF-8"}, true},
+		{"utf8 spelling", map[string]string{"LANG": "nb_NO.utf8"}, true},
+		{"Modifier", map[string]string{"LANG": "de_DE.UTF-8@euro"}, true},
+		{"C locale", map[string]string{"LANG": "C"}, false},
+		{"Latin-1", map[string]string{"LANG": "en_US.ISO-8859-1"}, false},
+		{"LC_ALL over LANG", map[string]string{"LC_ALL": "C", "LANG": "en_US.UTF-8"}, false},
+		{"LC_CTYPE over LANG", map[string]string{"LC_CTYPE": "C.UTF-8", "LANG": "C"}, true},
+		{"Dumb terminal", map[string]string{"TERM": "dumb", "LANG": "en_US.UTF-8"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"TERM", "LC_ALL", "LC_CTYPE", "LANG"} {
+				t.Setenv(name, tt.env[name])
+			}
+			if got := Unicode(); got != tt.want {
+				t.Errorf("Unicode() = %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/formatter"
+	"github.com/kristofferrisa/sky-cli/internal/models"
+	"github.com/kristofferrisa/sky-cli/internal/notify"
+)
+
+// Sink receives the events of a watcher
+type Sink interface {
+	Send(ctx context.Context, e Event) error
+}
+
+// NDJSONSink writes every event as a line of JSON
+type NDJSONSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewNDJSONSink creates a sink that writes events to w
+func NewNDJSONSink(w io.Writer) *NDJSONSink {
+	return &NDJSONSink{w: w}
+}
+
+// Send writes the event as one line
+func (s *NDJSONSink) Send(ctx context.Context, e Event) error {
+	data, err := marshalEvent(e)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(data)
+	return err
+}
+
+// CommandSink runs a local command for every event, with the title and
+// text of the event as its last two arguments, such as
+// notify-send "frost at Stavern" "temp < 0 from Tue 03:00 (temp -1.5°C)"
+type CommandSink struct {
+	args []string
+}
+
+// NewCommandSink creates a sink that runs command, which is split into
+// arguments at spaces
+func NewCommandSink(command string) (*CommandSink, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, fmt.Errorf("empty notify command")
+	}
+	if _, err := exec.LookPath(args[0]); err != nil {
+		return nil, fmt.Errorf("notify command: %w", err)
+	}
+	return &CommandSink{args: args}, nil
+}
+
+// Send runs the command for the event
+func (s *CommandSink) Send(ctx context.Context, e Event) error {
+	args := append(append([]string{}, s.args[1:]...), "Sky: "+e.Title(), e.Text)
+	out, err := exec.CommandContext(ctx, s.args[0], args...).CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%s: %w: %s", s.args[0], err, msg)
+		}
+		return fmt.Errorf("%s: %w", s.args[0], err)
+	}
+	return nil
+}
+
+// WebhookSink posts every event as JSON to a URL
+type WebhookSink struct {
+	url        string
+	httpClient *http.Client
+}
+
+// NewWebhookSink creates a sink that posts events to url
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{
+		url: url,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// Send posts the event
+func (s *WebhookSink) Send(ctx context.Context, e Event) error {
+	data, err := marshalEvent(e)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post event: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// NotifierSink sends every event to a notifier, such as a chat room
+type NotifierSink struct {
+	notifier notify.Notifier
+	opts     formatter.Options
+}
+
+// NewNotifierSink creates a sink that sends events to n, rendered with
+// opts
+func NewNotifierSink(n notify.Notifier, opts formatter.Options) *NotifierSink {
+	return &NotifierSink{notifier: n, opts: opts}
+}
+
+// Send notifies the event
+func (s *NotifierSink) Send(ctx context.Context, e Event) error {
+	msg, err := eventMessage(e, s.opts)
+	if err != nil {
+		return err
+	}
+	return s.notifier.Notify(ctx, msg)
+}
+
+// eventMessage renders an event as a notification. A rule that starts
+// matching is rendered like sky check; other events describe the change,
+// with the current weather for chat rooms.
+func eventMessage(e Event, opts formatter.Options) (notify.Message, error) {
+	var msg notify.Message
+	if e.alert != nil {
+		check := &models.Check{
+			Time:      e.Time,
+			Rules:     1,
+			Locations: []*models.Location{e.Location},
+			Alerts:    []models.Alert{*e.alert},
+		}
+		var err error
+		if msg, err = notify.CheckMessage(check, opts); err != nil {
+			return notify.Message{}, err
+		}
+	} else {
+		msg.Text = e.Text
+		msg.Markdown = fmt.Sprintf("**%s**\n\n%s", e.Title(), e.Text)
+		if e.weather != nil {
+			var buf bytes.Buffer
+			if err := formatter.NewMarkdownFormatter().FormatCurrent(&buf, e.weather, opts); err != nil {
+				return notify.Message{}, err
+			}
+			msg.Markdown += "\n\n" + strings.TrimSpace(buf.String())
+		}
+	}
+
+	msg.Title = e.Title()
+	msg.Data = e
+	return msg, nil
+}
+
+// marshalEvent encodes an event as a line of JSON, leaving the comparison
+// operators of rules readable
+func marshalEvent(e Event) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(e); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,315 @@
+// Package watch polls the weather for locations and reports meaningful
+// changes as events: rules that start or stop matching, changes in the
+// weather symbol and temperatures crossing thresholds
+package watch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/api"
+	"github.com/kristofferrisa/sky-cli/internal/formatter"
+	"github.com/kristofferrisa/sky-cli/internal/models"
+	"github.com/kristofferrisa/sky-cli/internal/rules"
+	"github.com/kristofferrisa/sky-cli/internal/ui"
+)
+
+// Event types
+const (
+	EventRuleStarted        = "rule_started"
+	EventRuleStopped        = "rule_stopped"
+	EventSymbolChanged      = "symbol_changed"
+	EventTemperatureCrossed = "temperature_crossed"
+)
+
+// hysteresis is how far in °C the temperature must move past a threshold
+// before it counts as crossed again, so that readings hovering around a
+// threshold do not raise an event on every poll
+const hysteresis = 0.5
+
+// Event is a meaningful change in the weather at a location
+type Event struct {
+	Time     time.Time        `json:"time"`
+	Type     string           `json:"type"`
+	Location *models.Location `json:"location"`
+	Text     string           `json:"text"`
+
+	// Set for rule events
+	Rule  string               `json:"rule,omitempty"`
+	Alert *formatter.JSONAlert `json:"alert,omitempty"`
+
+	// Set for symbol and temperature events
+	Symbol         string   `json:"symbol,omitempty"`
+	PreviousSymbol string   `json:"previous_symbol,omitempty"`
+	Temperature    *float64 `json:"temperature,omitempty"`
+	Threshold      *float64 `json:"threshold,omitempty"`
+
+	// What the event was raised from, for rendering notifications
+	alert   *models.Alert
+	weather *models.Weather
+}
+
+// Title is a short heading for the event, such as "frost at Stavern"
+func (e *Event) Title() string {
+	place := e.Location.Name
+	if place == "" {
+		place = e.Location.String()
+	}
+
+	switch e.Type {
+	case EventRuleStarted:
+		return fmt.Sprintf("%s at %s", e.Rule, place)
+	case EventRuleStopped:
+		return fmt.Sprintf("%s over at %s", e.Rule, place)
+	case EventSymbolChanged:
+		return fmt.Sprintf("Weather change at %s", place)
+	default:
+		return fmt.Sprintf("Temperature at %s", place)
+	}
+}
+
+// Rule is a rule the watcher checks, with the name it is reported under
+type Rule struct {
+	Name string
+	Rule *models.Rule
+}
+
+// Clock tells the time and waits, so that tests can control both
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock is the system clock
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Watcher polls the weather for its locations every interval and sends
+// an event to every sink when something meaningful changes
+type Watcher struct {
+	Client    api.WeatherClient
+	Locations []*models.Location
+	Rules     []Rule
+
+	// Thresholds are temperatures in °C whose crossing raises an event
+	Thresholds []float64
+
+	Interval time.Duration
+	Sinks    []Sink
+
+	// Clock defaults to the system clock
+	Clock Clock
+
+	// Log receives fetch and delivery errors, which do not stop the
+	// watcher; it defaults to discarding them
+	Log io.Writer
+
+	states map[*models.Location]*state
+}
+
+// state is what the watcher saw at a location on the previous poll
+type state struct {
+	symbol   string           // symbol code as forecast
+	above    map[float64]bool // side of each threshold
+	matching map[string]bool  // rules that matched
+}
+
+// Run polls until ctx is cancelled, starting right away. It returns nil
+// when ctx is cancelled.
+func (w *Watcher) Run(ctx context.Context) error {
+	if w.Interval <= 0 {
+		return fmt.Errorf("interval must be positive")
+	}
+	for _, r := range w.Rules {
+		if _, err := rules.Compile(r.Rule.When); err != nil {
+			return fmt.Errorf("rule '%s': %w", r.Name, err)
+		}
+	}
+
+	for {
+		for _, event := range w.Poll(ctx) {
+			w.send(ctx, event)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-w.clock().After(w.Interval):
+		}
+	}
+}
+
+// Poll fetches the weather for every location once and returns the
+// events since the previous poll. On the first poll, rules that already
+// match raise events, while the symbol and temperature are only
+// recorded.
+func (w *Watcher) Poll(ctx context.Context) []Event {
+	if w.states == nil {
+		w.states = make(map[*models.Location]*state)
+	}
+
+	// The current period started up to an hour ago
+	within := time.Duration(0)
+	for _, r := range w.Rules {
+		if x, err := rules.Compile(r.Rule.When); err == nil {
+			within = max(within, x.Within())
+		}
+	}
+	hours := int(math.Ceil(within.Hours())) + 1
+
+	var events []Event
+	for _, loc := range w.Locations {
+		if ctx.Err() != nil {
+			break
+		}
+
+		weather, err := w.Client.GetCurrentWeather(ctx, loc)
+		if err != nil {
+			w.logf("failed to fetch current weather for %s: %v", loc, err)
+			continue
+		}
+		var forecast *models.Forecast
+		if len(w.Rules) > 0 {
+			if forecast, err = w.Client.GetHourlyForecast(ctx, loc, hours); err != nil {
+				w.logf("failed to fetch forecast for %s: %v", loc, err)
+				continue
+			}
+		}
+
+		events = append(events, w.compare(loc, weather, forecast)...)
+	}
+	return events
+}
+
+// compare records the weather at a location and returns the events that
+// differ from the previous poll
+func (w *Watcher) compare(loc *models.Location, weather *models.Weather, forecast *models.Forecast) []Event {
+	now := w.clock().Now()
+	prev, seen := w.states[loc]
+	cur := &state{
+		symbol:   weather.Symbol,
+		above:    make(map[float64]bool),
+		matching: make(map[string]bool),
+	}
+	w.states[loc] = cur
+
+	var events []Event
+	newEvent := func(typ, text string) Event {
+		return Event{Time: now, Type: typ, Location: loc, Text: text, weather: weather}
+	}
+
+	for _, r := range w.Rules {
+		alert, err := rules.Check(r.Name, r.Rule, forecast, now)
+		if err != nil {
+			w.logf("rule '%s': %v", r.Name, err)
+			continue
+		}
+
+		matched := alert != nil
+		cur.matching[r.Name] = matched
+		wasMatched := seen && prev.matching[r.Name]
+		switch {
+		case matched && !wasMatched:
+			e := newEvent(EventRuleStarted, alertText(alert))
+			e.Rule = r.Name
+			ja := formatter.NewJSONAlert(*alert)
+			e.Alert, e.alert = &ja, alert
+			events = append(events, e)
+		case !matched && wasMatched:
+			e := newEvent(EventRuleStopped, fmt.Sprintf("%s no longer matches", r.Rule.When))
+			e.Rule = r.Name
+			events = append(events, e)
+		}
+	}
+
+	temp := weather.Temperature
+	for _, t := range w.Thresholds {
+		if !seen {
+			cur.above[t] = temp >= t
+			continue
+		}
+
+		above := prev.above[t]
+		switch {
+		case above && temp < t-hysteresis:
+			above = false
+		case !above && temp > t+hysteresis:
+			above = true
+		}
+		cur.above[t] = above
+
+		if above != prev.above[t] {
+			direction := "below"
+			if above {
+				direction = "above"
+			}
+			e := newEvent(EventTemperatureCrossed, fmt.Sprintf("Temperature is %s %g°C: %.1f°C", direction, t, temp))
+			e.Temperature, e.Threshold = &temp, &t
+			events = append(events, e)
+		}
+	}
+
+	if seen && cur.symbol != "" && prev.symbol != "" && baseSymbol(cur.symbol) != baseSymbol(prev.symbol) {
+		e := newEvent(EventSymbolChanged, fmt.Sprintf("%s → %s, %.1f°C",
+			ui.WeatherDescription(prev.symbol), ui.WeatherDescription(cur.symbol), temp))
+		e.Symbol, e.PreviousSymbol = cur.symbol, prev.symbol
+		e.Temperature = &temp
+		events = append(events, e)
+	}
+
+	return events
+}
+
+// send delivers an event to every sink, logging failures
+func (w *Watcher) send(ctx context.Context, e Event) {
+	for _, sink := range w.Sinks {
+		if err := sink.Send(ctx, e); err != nil {
+			w.logf("failed to send %s event: %v", e.Type, err)
+		}
+	}
+}
+
+func (w *Watcher) clock() Clock {
+	if w.Clock == nil {
+		return realClock{}
+	}
+	return w.Clock
+}
+
+func (w *Watcher) logf(format string, args ...interface{}) {
+	if w.Log != nil {
+		fmt.Fprintf(w.Log, "⚠️  "+format+"\n", args...)
+	}
+}
+
+// baseSymbol strips the time of day from a symbol code, so that
+// "clearsky_day" turning into "clearsky_night" at sunset is no change
+func baseSymbol(symbol string) string {
+	for _, suffix := range []string{"_day", "_night", "_polartwilight"} {
+		symbol = strings.TrimSuffix(symbol, suffix)
+	}
+	return symbol
+}
+
+// alertText describes when and how a rule matches
+func alertText(a *models.Alert) string {
+	values := make([]string, len(a.Values))
+	for i, v := range a.Values {
+		values[i] = fmt.Sprintf("%s %g%s", v.Name, v.Value, v.Unit)
+	}
+
+	text := fmt.Sprintf("%s from %s", a.When, a.Start.Format("Mon 15:04"))
+	if len(values) > 0 {
+		text += " (" + strings.Join(values, ", ") + ")"
+	}
+	if a.Message != "" {
+		text = a.Message + ": " + text
+	}
+	return text
+}
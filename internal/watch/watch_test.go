@@ -0,0 +1,399 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kristofferrisa/sky-cli/internal/api"
+	"github.com/kristofferrisa/sky-cli/internal/formatter"
+	"github.com/kristofferrisa/sky-cli/internal/models"
+	"github.com/kristofferrisa/sky-cli/internal/notify"
+)
+
+// fakeClient serves the current weather and a forecast with the same
+// temperature every hour, as set by the test
+type fakeClient struct {
+	api.WeatherClient
+
+	mu       sync.Mutex
+	now      func() time.Time
+	temp     float64
+	forecast float64 // temperature in the forecast
+	symbol   string
+	fail     bool
+	requests int
+}
+
+func (c *fakeClient) set(temp, forecast float64, symbol string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.temp, c.forecast, c.symbol = temp, forecast, symbol
+}
+
+func (c *fakeClient) GetCurrentWeather(ctx context.Context, loc *models.Location) (*models.Weather, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests++
+	if c.fail {
+		return nil, errors.New("unavailable")
+	}
+	return &models.Weather{Location: loc, Timestamp: c.now(), Temperature: c.temp, Symbol: c.symbol}, nil
+}
+
+func (c *fakeClient) GetHourlyForecast(ctx context.Context, loc *models.Location, hours int) (*models.Forecast, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	forecast := &models.Forecast{Location: loc}
+	start := c.now().Truncate(time.Hour)
+	for i := 0; i < hours; i++ {
+		forecast.Hours = append(forecast.Hours, models.HourlyForecast{
+			Time:        start.Add(time.Duration(i) * time.Hour),
+			Temperature: c.forecast,
+		})
+	}
+	return forecast, nil
+}
+
+// fakeClock is a clock that only moves when the test advances it. After
+// reports every wait on waits.
+type fakeClock struct {
+	mu    sync.Mutex
+	now   time.Time
+	timer chan time.Time
+	waits chan time.Duration
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now, waits: make(chan time.Duration, 10)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	c.timer = make(chan time.Time, 1)
+	timer := c.timer
+	c.mu.Unlock()
+
+	c.waits <- d
+	return timer
+}
+
+// advance moves the clock on by d and fires the pending timer
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	c.timer <- c.now
+}
+
+// eventTypes lists the types of events, for comparing them in tests
+func eventTypes(events []Event) string {
+	types := make([]string, len(events))
+	for i, e := range events {
+		types[i] = e.Type
+	}
+	return strings.Join(types, " ")
+}
+
+func TestPoll(t *testing.T) {
+	clock := newFakeClock(time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC))
+	client := &fakeClient{now: clock.Now}
+	loc := &models.Location{Name: "Stavern", Latitude: 59.0, Longitude: 10.0}
+
+	w := &Watcher{
+		Client:     client,
+		Locations:  []*models.Location{loc},
+		Rules:      []Rule{{Name: "frost", Rule: &models.Rule{When: "temp < 0 within 12h", Message: "Cover the plants"}}},
+		Thresholds: []float64{0},
+		Interval:   15 * time.Minute,
+		Clock:      clock,
+	}
+
+	tests := []struct {
+		name     string
+		temp     float64
+		forecast float64
+		symbol   string
+		expected string
+	}{
+		{"First poll only records", 1, 1, "cloudy", ""},
+		{"Nothing changed", 1, 1, "cloudy", ""},
+		{"Frost ahead", 1, -2, "cloudy", "rule_started"},
+		{"Still frost ahead", 1.2, -2, "cloudy", ""},
+		{"Freezing and snowing", -1, -2, "lightsnow", "temperature_crossed symbol_changed"},
+		{"Hovering around zero", 0.3, -2, "lightsnow", ""},
+		{"Frost is over", 0.4, 1, "lightsnow", "rule_stopped"},
+		{"Thawing", 0.6, 1, "cloudy", "temperature_crossed symbol_changed"},
+		{"Day turns to night", 0.6, 1, "clearsky_day", "symbol_changed"},
+		{"Night is no change", 0.6, 1, "clearsky_night", ""},
+	}
+
+	for _, tt := range tests {
+		client.set(tt.temp, tt.forecast, tt.symbol)
+		events := w.Poll(context.Background())
+		if got := eventTypes(events); got != tt.expected {
+			t.Errorf("%s: Poll() = [%s]; want [%s]", tt.name, got, tt.expected)
+		}
+		clock.mu.Lock()
+		clock.now = clock.now.Add(w.Interval)
+		clock.mu.Unlock()
+	}
+}
+
+func TestPollEvents(t *testing.T) {
+	clock := newFakeClock(time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC))
+	client := &fakeClient{now: clock.Now}
+	loc := &models.Location{Name: "Stavern", Latitude: 59.0, Longitude: 10.0}
+
+	w := &Watcher{
+		Client:     client,
+		Locations:  []*models.Location{loc},
+		Rules:      []Rule{{Name: "frost", Rule: &models.Rule{When: "temp < 0 within 12h", Message: "Cover the plants"}}},
+		Thresholds: []float64{0},
+		Interval:   15 * time.Minute,
+		Clock:      clock,
+	}
+
+	// A rule that matches on the first poll raises an event right away
+	client.set(1, -2, "cloudy")
+	events := w.Poll(context.Background())
+	if len(events) != 1 {
+		t.Fatalf("Poll() = [%s]; want [rule_started]", eventTypes(events))
+	}
+
+	e := events[0]
+	if e.Type != EventRuleStarted || e.Rule != "frost" || e.Location != loc || !e.Time.Equal(clock.Now()) {
+		t.Errorf("Poll() = %+v; want rule_started for frost at Stavern now", e)
+	}
+	if e.Title() != "frost at Stavern" {
+		t.Errorf("Title() = %q; want %q", e.Title(), "frost at Stavern")
+	}
+	if want := "Cover the plants: temp < 0 within 12h from Mon 12:00 (temp -2°C)"; e.Text != want {
+		t.Errorf("Text = %q; want %q", e.Text, want)
+	}
+	if e.Alert == nil || e.Alert.Periods != 12 {
+		t.Errorf("Alert = %+v; want 12 matching periods", e.Alert)
+	}
+
+	client.set(-1, -2, "lightsnow")
+	events = w.Poll(context.Background())
+	if got := eventTypes(events); got != "temperature_crossed symbol_changed" {
+		t.Fatalf("Poll() = [%s]; want [temperature_crossed symbol_changed]", got)
+	}
+	if e := events[0]; *e.Temperature != -1 || *e.Threshold != 0 || e.Text != "Temperature is below 0°C: -1.0°C" {
+		t.Errorf("temperature event = %+v (%v, %v)", e, *e.Temperature, *e.Threshold)
+	}
+	if e := events[1]; e.Symbol != "lightsnow" || e.PreviousSymbol != "cloudy" || e.Text != "Cloudy → Light snow, -1.0°C" {
+		t.Errorf("symbol event = %+v", e)
+	}
+
+	// Failed fetches are logged and keep the previous state
+	var log bytes.Buffer
+	w.Log = &log
+	client.fail = true
+	if events := w.Poll(context.Background()); len(events) != 0 {
+		t.Errorf("Poll() with a failing client = [%s]; want none", eventTypes(events))
+	}
+	if !strings.Contains(log.String(), "unavailable") {
+		t.Errorf("Log = %q; want the fetch error", log.String())
+	}
+
+	client.fail = false
+	if events := w.Poll(context.Background()); len(events) != 0 {
+		t.Errorf("Poll() after a failure = [%s]; want none", eventTypes(events))
+	}
+}
+
+func TestRun(t *testing.T) {
+	clock := newFakeClock(time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC))
+	client := &fakeClient{now: clock.Now}
+	client.set(5, 5, "cloudy")
+
+	var out syncBuffer
+	w := &Watcher{
+		Client:     client,
+		Locations:  []*models.Location{{Name: "Stavern", Latitude: 59.0, Longitude: 10.0}},
+		Thresholds: []float64{0},
+		Interval:   15 * time.Minute,
+		Sinks:      []Sink{NewNDJSONSink(&out)},
+		Clock:      clock,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- w.Run(ctx) }()
+
+	// The first poll happens right away, then the watcher waits
+	if d := <-clock.waits; d != 15*time.Minute {
+		t.Errorf("Run() waited %v; want 15m", d)
+	}
+
+	client.set(-5, -5, "cloudy")
+	clock.advance(15 * time.Minute)
+	<-clock.waits
+
+	client.set(-5, -5, "snow")
+	clock.advance(15 * time.Minute)
+	<-clock.waits
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Run() = %v; want nil after cancel", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() did not stop after cancel")
+	}
+
+	if client.requests != 3 {
+		t.Errorf("Run() polled %d times; want 3", client.requests)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Run() wrote %d lines; want 2:\n%s", len(lines), out.String())
+	}
+	for i, want := range []string{EventTemperatureCrossed, EventSymbolChanged} {
+		var e Event
+		if err := json.Unmarshal([]byte(lines[i]), &e); err != nil {
+			t.Fatalf("line %d is not JSON: %v", i+1, err)
+		}
+		if e.Type != want || e.Location.Name != "Stavern" {
+			t.Errorf("line %d = %s at %v; want %s at Stavern", i+1, e.Type, e.Location, want)
+		}
+	}
+
+	if err := (&Watcher{}).Run(context.Background()); err == nil {
+		t.Error("Run() without an interval expected error but got none")
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var received []Event
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("webhook got %s with %s; want POST with application/json", r.Method, r.Header.Get("Content-Type"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		var e Event
+		if err := json.Unmarshal(body, &e); err != nil {
+			t.Errorf("webhook body is not an event: %v", err)
+		}
+		received = append(received, e)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sink := NewWebhookSink(server.URL)
+	e := Event{Type: EventRuleStarted, Rule: "frost", Location: &models.Location{Name: "Stavern"}}
+	if err := sink.Send(context.Background(), e); err != nil {
+		t.Fatalf("Send() failed: %v", err)
+	}
+	if len(received) != 1 || received[0].Rule != "frost" {
+		t.Errorf("webhook received %+v; want the frost event", received)
+	}
+
+	status = http.StatusInternalServerError
+	if err := sink.Send(context.Background(), e); err == nil {
+		t.Error("Send() to a failing webhook expected error but got none")
+	}
+}
+
+// fakeNotifier records the messages it is sent
+type fakeNotifier struct {
+	messages []notify.Message
+}
+
+func (n *fakeNotifier) Notify(ctx context.Context, msg notify.Message) error {
+	n.messages = append(n.messages, msg)
+	return nil
+}
+
+func TestNotifierSink(t *testing.T) {
+	clock := newFakeClock(time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC))
+	client := &fakeClient{now: clock.Now}
+	n := &fakeNotifier{}
+	w := &Watcher{
+		Client:     client,
+		Locations:  []*models.Location{{Name: "Stavern", Latitude: 59.0, Longitude: 10.0}},
+		Rules:      []Rule{{Name: "frost", Rule: &models.Rule{When: "temp < 0 within 12h"}}},
+		Thresholds: []float64{0},
+		Interval:   15 * time.Minute,
+		Clock:      clock,
+	}
+	sink := NewNotifierSink(n, formatter.Options{})
+
+	for _, temp := range []float64{1, -1} {
+		client.set(temp, -2, "cloudy")
+		for _, e := range w.Poll(context.Background()) {
+			if err := sink.Send(context.Background(), e); err != nil {
+				t.Fatalf("Send() failed: %v", err)
+			}
+		}
+	}
+
+	if len(n.messages) != 2 {
+		t.Fatalf("notifier got %d messages; want 2", len(n.messages))
+	}
+
+	// A rule that starts matching is rendered like sky check
+	rule := n.messages[0]
+	if rule.Title != "frost at Stavern" || !strings.Contains(rule.Markdown, "| frost | Stavern |") {
+		t.Errorf("rule message = %+v; want the check of frost", rule)
+	}
+	if rule.Text != "Stavern: 1 of 1 rules matched: frost (Stavern, Mon 12:00)" {
+		t.Errorf("rule Text = %q; want the summary of the check", rule.Text)
+	}
+
+	temp := n.messages[1]
+	if temp.Text != "Temperature is below 0°C: -1.0°C" || !strings.Contains(temp.Markdown, "# Weather for Stavern") {
+		t.Errorf("temperature message = %+v; want the change and the current weather", temp)
+	}
+	if e, ok := temp.Data.(Event); !ok || e.Type != EventTemperatureCrossed {
+		t.Errorf("Data = %v; want the event", temp.Data)
+	}
+}
+
+func TestNewCommandSink(t *testing.T) {
+	if _, err := NewCommandSink(" "); err == nil {
+		t.Error("NewCommandSink() of an empty command expected error but got none")
+	}
+	if _, err := NewCommandSink("sky-no-such-command --urgency low"); err == nil {
+		t.Error("NewCommandSink() of a missing command expected error but got none")
+	}
+}
+
+// syncBuffer is a bytes.Buffer that is safe to write from the watcher
+// while the test reads it
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}